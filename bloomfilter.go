@@ -0,0 +1,250 @@
+package goparquet
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+const (
+	bloomFilterBlockSize = 32 // bytes per block, i.e. eight 32 bit words.
+
+	bloomFilterMinBytes = bloomFilterBlockSize
+	bloomFilterMaxBytes = 128 * 1024 * 1024
+
+	defaultBloomFilterFPP = 0.01
+)
+
+// salt values of the split block bloom filter as defined in the parquet specification.
+var bloomFilterSalt = [8]uint32{
+	0x47b6137b, 0x44974d91, 0x8824ad5b, 0xa2b7289d,
+	0x705495c7, 0x2df1424b, 0x9efc4947, 0x5c6bfb31,
+}
+
+// bloomFilter is a split block bloom filter as described in the parquet specification. Each
+// block consists of eight 32 bit words, and inserting a hash sets one bit in every word of
+// a single block.
+type bloomFilter struct {
+	blocks [][8]uint32
+}
+
+// bloomFilterNumBytes returns the size of a bloom filter in bytes that is required to store ndv distinct
+// values with a false-positive probability of fpp. The size is always a power of 2.
+func bloomFilterNumBytes(ndv int64, fpp float64) int {
+	if ndv <= 0 {
+		return bloomFilterMinBytes
+	}
+
+	numBits := -8 * float64(ndv) / math.Log(1-math.Pow(fpp, 1.0/8))
+
+	numBytes := bloomFilterMinBytes
+	for float64(numBytes*8) < numBits && numBytes < bloomFilterMaxBytes {
+		numBytes <<= 1
+	}
+
+	return numBytes
+}
+
+func newBloomFilter(numBytes int) *bloomFilter {
+	return &bloomFilter{
+		blocks: make([][8]uint32, numBytes/bloomFilterBlockSize),
+	}
+}
+
+func (f *bloomFilter) blockIndex(hash uint64) uint64 {
+	return ((hash >> 32) * uint64(len(f.blocks))) >> 32
+}
+
+func bloomFilterMask(key uint32) (mask [8]uint32) {
+	for i := range mask {
+		mask[i] = 1 << ((key * bloomFilterSalt[i]) >> 27)
+	}
+	return mask
+}
+
+func (f *bloomFilter) insert(hash uint64) {
+	block := &f.blocks[f.blockIndex(hash)]
+	mask := bloomFilterMask(uint32(hash))
+	for i := range block {
+		block[i] |= mask[i]
+	}
+}
+
+func (f *bloomFilter) check(hash uint64) bool {
+	block := &f.blocks[f.blockIndex(hash)]
+	mask := bloomFilterMask(uint32(hash))
+	for i := range block {
+		if block[i]&mask[i] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *bloomFilter) write(ctx context.Context, w io.Writer, cc *chunkCipher) error {
+	bitset := make([]byte, len(f.blocks)*bloomFilterBlockSize)
+	for i, block := range f.blocks {
+		for j, word := range block {
+			binary.LittleEndian.PutUint32(bitset[i*bloomFilterBlockSize+j*4:], word)
+		}
+	}
+
+	header := &parquet.BloomFilterHeader{
+		NumBytes:    int32(len(bitset)),
+		Algorithm:   &parquet.BloomFilterAlgorithm{BLOCK: &parquet.SplitBlockAlgorithm{}},
+		Hash:        &parquet.BloomFilterHash{XXHASH: &parquet.XxHash{}},
+		Compression: &parquet.BloomFilterCompression{UNCOMPRESSED: &parquet.Uncompressed{}},
+	}
+
+	if err := cc.writeThrift(ctx, header, w, moduleBloomFilterHeader, -1); err != nil {
+		return err
+	}
+
+	if cc != nil {
+		bitset = cc.encrypt(bitset, cc.aad(moduleBloomFilterBitset, -1))
+	}
+
+	return writeFull(w, bitset)
+}
+
+func readBloomFilter(ctx context.Context, r io.ReadSeeker, chunk *parquet.ColumnChunk, cc *chunkCipher) (*bloomFilter, error) {
+	if chunk.MetaData == nil || chunk.MetaData.BloomFilterOffset == nil {
+		return nil, nil
+	}
+
+	if _, err := r.Seek(*chunk.MetaData.BloomFilterOffset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seek to bloom filter failed: %w", err)
+	}
+
+	header := &parquet.BloomFilterHeader{}
+	if err := cc.readThrift(ctx, header, r, moduleBloomFilterHeader, -1); err != nil {
+		return nil, fmt.Errorf("read bloom filter header failed: %w", err)
+	}
+
+	if header.Algorithm == nil || !header.Algorithm.IsSetBLOCK() {
+		return nil, errors.New("unsupported bloom filter algorithm")
+	}
+	if header.Hash == nil || !header.Hash.IsSetXXHASH() {
+		return nil, errors.New("unsupported bloom filter hash")
+	}
+	if header.Compression == nil || !header.Compression.IsSetUNCOMPRESSED() {
+		return nil, errors.New("unsupported bloom filter compression")
+	}
+	if header.NumBytes <= 0 || header.NumBytes > bloomFilterMaxBytes || header.NumBytes%bloomFilterBlockSize != 0 {
+		return nil, fmt.Errorf("invalid bloom filter size %d", header.NumBytes)
+	}
+
+	bitset, err := readBloomFilterBitset(r, int(header.NumBytes), cc)
+	if err != nil {
+		return nil, fmt.Errorf("read bloom filter bitset failed: %w", err)
+	}
+
+	f := newBloomFilter(len(bitset))
+	for i := range f.blocks {
+		for j := range f.blocks[i] {
+			f.blocks[i][j] = binary.LittleEndian.Uint32(bitset[i*bloomFilterBlockSize+j*4:])
+		}
+	}
+
+	return f, nil
+}
+
+func readBloomFilterBitset(r io.Reader, numBytes int, cc *chunkCipher) ([]byte, error) {
+	if cc == nil {
+		bitset := make([]byte, numBytes)
+		if _, err := io.ReadFull(r, bitset); err != nil {
+			return nil, err
+		}
+		return bitset, nil
+	}
+
+	data, err := readModule(r)
+	if err != nil {
+		return nil, err
+	}
+
+	bitset, err := cc.open(data, cc.aad(moduleBloomFilterBitset, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bitset) != numBytes {
+		return nil, fmt.Errorf("expected %d bytes, got %d", numBytes, len(bitset))
+	}
+
+	return bitset, nil
+}
+
+// bloomFilterHash returns the hash of a value as it is inserted into the bloom filter of a column of
+// type typ. As required by the parquet specification, the hash is computed over the plain encoding of
+// the value, without a length prefix for byte arrays.
+func bloomFilterHash(typ parquet.Type, v interface{}) (uint64, error) {
+	if typ == parquet.Type_BOOLEAN {
+		return 0, fmt.Errorf("bloom filters are not supported for columns of type %s", typ)
+	}
+
+	buf, err := plainValueBytes(typ, v)
+	if err != nil {
+		return 0, err
+	}
+
+	return xxHash64(buf), nil
+}
+
+// newColumnBloomFilter creates a bloom filter for the provided distinct values of a column chunk.
+func newColumnBloomFilter(typ parquet.Type, cfg bloomFilterConfig, distinctValues []interface{}) (*bloomFilter, error) {
+	ndv := cfg.ndv
+	if ndv <= 0 {
+		ndv = int64(len(distinctValues))
+	}
+
+	fpp := cfg.fpp
+	if fpp <= 0 || fpp >= 1 {
+		fpp = defaultBloomFilterFPP
+	}
+
+	f := newBloomFilter(bloomFilterNumBytes(ndv, fpp))
+
+	for _, v := range distinctValues {
+		h, err := bloomFilterHash(typ, v)
+		if err != nil {
+			return nil, err
+		}
+		f.insert(h)
+	}
+
+	return f, nil
+}
+
+type bloomFilterConfig struct {
+	path ColumnPath
+	ndv  int64
+	fpp  float64
+}
+
+// writeBloomFilters writes the bloom filters of all column chunks of all row groups and sets
+// their offsets in the column chunks' meta data.
+func writeBloomFilters(ctx context.Context, w writePos, rowGroups []*parquet.RowGroup, indexes [][]*chunkIndexes) error {
+	for i, rg := range rowGroups {
+		for j, chunk := range rg.Columns {
+			idx := indexes[i][j]
+			if idx == nil || idx.bloomFilter == nil {
+				continue
+			}
+
+			pos := w.Pos()
+			if err := idx.bloomFilter.write(ctx, w, idx.cipher); err != nil {
+				return err
+			}
+
+			chunk.MetaData.BloomFilterOffset = &pos
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXXHash64(t *testing.T) {
+	testData := []struct {
+		input    string
+		expected uint64
+	}{
+		{"", 0xef46db3751d8e999},
+		{"a", 0xd24ec4f1a98c6e5b},
+		{"abc", 0x44bc2cf5ad770999},
+		{"Nobody inspects the spammish repetition", 0xfbcea83c8a378bf1},
+	}
+
+	for _, tt := range testData {
+		require.Equal(t, tt.expected, xxHash64([]byte(tt.input)), "input %q", tt.input)
+	}
+}
+
+func TestBloomFilterNumBytes(t *testing.T) {
+	require.Equal(t, 32, bloomFilterNumBytes(0, 0.01))
+	require.Equal(t, 32, bloomFilterNumBytes(1, 0.01))
+	require.Equal(t, 2048, bloomFilterNumBytes(1000, 0.01))
+	require.Equal(t, 4096, bloomFilterNumBytes(1000, 0.0001))
+	require.Equal(t, bloomFilterMaxBytes, bloomFilterNumBytes(1<<40, 0.01))
+}
+
+func TestBloomFilterInsertAndCheck(t *testing.T) {
+	const numValues = 10000
+
+	f := newBloomFilter(bloomFilterNumBytes(numValues, 0.01))
+
+	for i := 0; i < numValues; i++ {
+		f.insert(xxHash64([]byte(fmt.Sprintf("value-%d", i))))
+	}
+
+	for i := 0; i < numValues; i++ {
+		require.True(t, f.check(xxHash64([]byte(fmt.Sprintf("value-%d", i)))), "value %d", i)
+	}
+
+	falsePositives := 0
+	for i := numValues; i < 2*numValues; i++ {
+		if f.check(xxHash64([]byte(fmt.Sprintf("value-%d", i)))) {
+			falsePositives++
+		}
+	}
+	require.True(t, falsePositives < numValues/50, "too many false positives: %d", falsePositives)
+}
+
+func TestWriteThenReadBloomFilter(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		required double value;
+		required int32 other;
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	wr := NewFileWriter(&buf,
+		WithSchemaDefinition(sd),
+		WithBloomFilter(ColumnPath{"id"}, 0, 0),
+		WithBloomFilter(ColumnPath{"name"}, 5000, 0.001),
+		WithBloomFilter(ColumnPath{"value"}, 0, 0.05),
+	)
+
+	for rg := 0; rg < 2; rg++ {
+		for i := 0; i < 1000; i++ {
+			id := int64(rg*1000 + i)
+			data := map[string]interface{}{
+				"id":    id,
+				"value": float64(id) / 2,
+				"other": int32(id),
+			}
+			if id%3 != 0 {
+				data["name"] = []byte(fmt.Sprintf("name-%d", id))
+			}
+			require.NoError(t, wr.AddData(data))
+		}
+		require.NoError(t, wr.FlushRowGroup())
+	}
+
+	require.NoError(t, wr.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	for rg := 0; rg < 2; rg++ {
+		for _, path := range []ColumnPath{{"id"}, {"name"}, {"value"}} {
+			chunk, err := r.columnChunk(rg, path)
+			require.NoError(t, err)
+			require.NotNil(t, chunk.MetaData.BloomFilterOffset, "column %s", path.flatName())
+		}
+
+		chunk, err := r.columnChunk(rg, ColumnPath{"other"})
+		require.NoError(t, err)
+		require.Nil(t, chunk.MetaData.BloomFilterOffset)
+
+		falsePositives := 0
+		for i := 0; i < 2000; i++ {
+			id := int64(i)
+			inRowGroup := i/1000 == rg
+
+			ok, err := r.MightContainInRowGroup(rg, ColumnPath{"id"}, id)
+			require.NoError(t, err)
+			if inRowGroup {
+				require.True(t, ok, "id %d in row group %d", id, rg)
+			} else if ok {
+				falsePositives++
+			}
+
+			ok, err = r.MightContainInRowGroup(rg, ColumnPath{"value"}, float64(id)/2)
+			require.NoError(t, err)
+			if inRowGroup {
+				require.True(t, ok)
+			}
+
+			ok, err = r.MightContainInRowGroup(rg, ColumnPath{"name"}, fmt.Sprintf("name-%d", id))
+			require.NoError(t, err)
+			if inRowGroup && i%3 != 0 {
+				require.True(t, ok, "name-%d in row group %d", id, rg)
+			}
+
+			// columns without bloom filter might contain every value.
+			ok, err = r.MightContainInRowGroup(rg, ColumnPath{"other"}, int32(-1))
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+		require.True(t, falsePositives < 50, "too many false positives: %d", falsePositives)
+	}
+
+	ok, err := r.MightContain(ColumnPath{"id"}, int64(500))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = r.MightContain(ColumnPath{"id"}, int32(500))
+	require.Error(t, err)
+
+	_, err = r.MightContain(ColumnPath{"foo"}, int64(500))
+	require.Error(t, err)
+
+	for i := 0; i < 2000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), row["id"])
+	}
+}
+
+func TestWriteBloomFilterUnsupportedType(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required boolean flag;
+	}`)
+	require.NoError(t, err)
+
+	wr := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithBloomFilter(ColumnPath{"flag"}, 0, 0))
+
+	require.NoError(t, wr.AddData(map[string]interface{}{"flag": true}))
+	require.Error(t, wr.Close())
+}
+
+func TestBloomFilterHash(t *testing.T) {
+	h1, err := bloomFilterHash(parquet.Type_BYTE_ARRAY, "foo")
+	require.NoError(t, err)
+	h2, err := bloomFilterHash(parquet.Type_BYTE_ARRAY, []byte("foo"))
+	require.NoError(t, err)
+	require.Equal(t, h1, h2)
+	require.Equal(t, xxHash64([]byte("foo")), h1)
+
+	h, err := bloomFilterHash(parquet.Type_INT32, int32(1))
+	require.NoError(t, err)
+	require.Equal(t, xxHash64([]byte{1, 0, 0, 0}), h)
+
+	_, err = bloomFilterHash(parquet.Type_INT64, int32(1))
+	require.Error(t, err)
+
+	_, err = bloomFilterHash(parquet.Type_BOOLEAN, true)
+	require.Error(t, err)
+}
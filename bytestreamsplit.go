@@ -0,0 +1,275 @@
+package goparquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// byteStreamSplitDecoder decodes BYTE_STREAM_SPLIT encoded values. The encoding scatters the bytes of
+// each value of width K into K streams, so the stream for byte i holds the i-th byte of all values.
+type byteStreamSplitDecoder struct {
+	width int
+	// fromBytes converts the gathered bytes of a single value into the value.
+	fromBytes func([]byte) interface{}
+
+	data     []byte
+	count    int
+	position int
+}
+
+func (d *byteStreamSplitDecoder) init(r io.Reader) error {
+	if d.width <= 0 {
+		return fmt.Errorf("byte_stream_split: invalid value width %d", d.width)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if len(data)%d.width != 0 {
+		return fmt.Errorf("byte_stream_split: data length %d is not a multiple of the value width %d", len(data), d.width)
+	}
+
+	d.data = data
+	d.count = len(data) / d.width
+	d.position = 0
+
+	return nil
+}
+
+// next gathers the bytes of the next value into buf, which is d.width bytes long.
+func (d *byteStreamSplitDecoder) next(buf []byte) error {
+	if d.position >= d.count {
+		return io.EOF
+	}
+
+	for j := range buf {
+		buf[j] = d.data[j*d.count+d.position]
+	}
+	d.position++
+
+	return nil
+}
+
+func (d *byteStreamSplitDecoder) decodeValues(dst []interface{}) (int, error) {
+	for i := range dst {
+		buf := make([]byte, d.width)
+		if err := d.next(buf); err != nil {
+			return i, err
+		}
+		dst[i] = d.fromBytes(buf)
+	}
+
+	return len(dst), nil
+}
+
+func (d *byteStreamSplitDecoder) decodeFloats(dst []float32) (int, error) {
+	if d.width != 4 {
+		return 0, fmt.Errorf("byte_stream_split: can't decode values of width %d as FLOAT", d.width)
+	}
+
+	var buf [4]byte
+	for i := range dst {
+		if err := d.next(buf[:]); err != nil {
+			return i, err
+		}
+		dst[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[:]))
+	}
+
+	return len(dst), nil
+}
+
+func (d *byteStreamSplitDecoder) decodeDoubles(dst []float64) (int, error) {
+	if d.width != 8 {
+		return 0, fmt.Errorf("byte_stream_split: can't decode values of width %d as DOUBLE", d.width)
+	}
+
+	var buf [8]byte
+	for i := range dst {
+		if err := d.next(buf[:]); err != nil {
+			return i, err
+		}
+		dst[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[:]))
+	}
+
+	return len(dst), nil
+}
+
+func (d *byteStreamSplitDecoder) decodeByteArrays(dst [][]byte) (int, error) {
+	for i := range dst {
+		buf := make([]byte, d.width)
+		if err := d.next(buf); err != nil {
+			return i, err
+		}
+		dst[i] = buf
+	}
+
+	return len(dst), nil
+}
+
+// byteStreamSplitEncoder encodes values using the BYTE_STREAM_SPLIT encoding. As all values need to be
+// known to write the streams, the values are buffered and only written when the encoder is closed.
+type byteStreamSplitEncoder struct {
+	w io.Writer
+
+	width int
+	// toBytes returns the little-endian representation of a single value.
+	toBytes func(interface{}) ([]byte, error)
+
+	// data contains the little-endian representations of all values one after another.
+	data []byte
+}
+
+func (e *byteStreamSplitEncoder) init(w io.Writer) error {
+	if e.width <= 0 {
+		return fmt.Errorf("byte_stream_split: invalid value width %d", e.width)
+	}
+
+	e.w = w
+	e.data = nil
+
+	return nil
+}
+
+func (e *byteStreamSplitEncoder) encodeValues(values []interface{}) error {
+	for _, v := range values {
+		buf, err := e.toBytes(v)
+		if err != nil {
+			return err
+		}
+
+		if len(buf) != e.width {
+			return fmt.Errorf("byte_stream_split: the value should be %d bytes long but is %d", e.width, len(buf))
+		}
+
+		e.data = append(e.data, buf...)
+	}
+
+	return nil
+}
+
+func (e *byteStreamSplitEncoder) encodeFloats(values []float32) error {
+	if e.width != 4 {
+		return fmt.Errorf("byte_stream_split: can't encode FLOAT values with width %d", e.width)
+	}
+
+	var buf [4]byte
+	for _, v := range values {
+		binary.LittleEndian.PutUint32(buf[:], math.Float32bits(v))
+		e.data = append(e.data, buf[:]...)
+	}
+
+	return nil
+}
+
+func (e *byteStreamSplitEncoder) encodeDoubles(values []float64) error {
+	if e.width != 8 {
+		return fmt.Errorf("byte_stream_split: can't encode DOUBLE values with width %d", e.width)
+	}
+
+	var buf [8]byte
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		e.data = append(e.data, buf[:]...)
+	}
+
+	return nil
+}
+
+func (e *byteStreamSplitEncoder) encodeByteArrays(values [][]byte) error {
+	for _, v := range values {
+		if len(v) != e.width {
+			return fmt.Errorf("byte_stream_split: the value should be %d bytes long but is %d", e.width, len(v))
+		}
+		e.data = append(e.data, v...)
+	}
+
+	return nil
+}
+
+func (e *byteStreamSplitEncoder) Close() error {
+	count := len(e.data) / e.width
+	data := make([]byte, len(e.data))
+
+	for i := 0; i < count; i++ {
+		for j := 0; j < e.width; j++ {
+			data[j*count+i] = e.data[i*e.width+j]
+		}
+	}
+
+	return writeFull(e.w, data)
+}
+
+func newFloatByteStreamSplitDecoder() *byteStreamSplitDecoder {
+	return &byteStreamSplitDecoder{
+		width: 4,
+		fromBytes: func(b []byte) interface{} {
+			return math.Float32frombits(binary.LittleEndian.Uint32(b))
+		},
+	}
+}
+
+func newFloatByteStreamSplitEncoder() *byteStreamSplitEncoder {
+	return &byteStreamSplitEncoder{
+		width: 4,
+		toBytes: func(v interface{}) ([]byte, error) {
+			f, ok := v.(float32)
+			if !ok {
+				return nil, fmt.Errorf("byte_stream_split: unsupported type for float column: %T", v)
+			}
+			buf := make([]byte, 4)
+			binary.LittleEndian.PutUint32(buf, math.Float32bits(f))
+			return buf, nil
+		},
+	}
+}
+
+func newDoubleByteStreamSplitDecoder() *byteStreamSplitDecoder {
+	return &byteStreamSplitDecoder{
+		width: 8,
+		fromBytes: func(b []byte) interface{} {
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		},
+	}
+}
+
+func newDoubleByteStreamSplitEncoder() *byteStreamSplitEncoder {
+	return &byteStreamSplitEncoder{
+		width: 8,
+		toBytes: func(v interface{}) ([]byte, error) {
+			f, ok := v.(float64)
+			if !ok {
+				return nil, fmt.Errorf("byte_stream_split: unsupported type for double column: %T", v)
+			}
+			buf := make([]byte, 8)
+			binary.LittleEndian.PutUint64(buf, math.Float64bits(f))
+			return buf, nil
+		},
+	}
+}
+
+func newFixedLenByteArrayByteStreamSplitDecoder(length int) *byteStreamSplitDecoder {
+	return &byteStreamSplitDecoder{
+		width: length,
+		fromBytes: func(b []byte) interface{} {
+			return b
+		},
+	}
+}
+
+func newFixedLenByteArrayByteStreamSplitEncoder(length int) *byteStreamSplitEncoder {
+	return &byteStreamSplitEncoder{
+		width: length,
+		toBytes: func(v interface{}) ([]byte, error) {
+			b, ok := v.([]byte)
+			if !ok {
+				return nil, fmt.Errorf("byte_stream_split: unsupported type for fixed_len_byte_array column: %T", v)
+			}
+			return b, nil
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteStreamSplitFloatLayout(t *testing.T) {
+	// 1.0, 2.0 and 3.0 are 0x3f800000, 0x40000000 and 0x40400000. The first stream contains
+	// the lowest byte of every value, the last stream the highest byte.
+	expected, err := hex.DecodeString("000000" + "000000" + "800040" + "3f4040")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, encodeValue(&buf, newFloatByteStreamSplitEncoder(), []interface{}{float32(1), float32(2), float32(3)}))
+	assert.Equal(t, expected, buf.Bytes())
+
+	dec := newFloatByteStreamSplitDecoder()
+	require.NoError(t, dec.init(bytes.NewReader(expected)))
+
+	values := make([]interface{}, 3)
+	n, err := dec.decodeValues(values)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []interface{}{float32(1), float32(2), float32(3)}, values)
+
+	n, err = dec.decodeValues(make([]interface{}, 1))
+	assert.Equal(t, 0, n)
+	assert.Error(t, err)
+}
+
+func TestByteStreamSplitRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		enc    valuesEncoder
+		dec    valuesDecoder
+		values []interface{}
+	}{
+		{
+			name:   "float",
+			enc:    newFloatByteStreamSplitEncoder(),
+			dec:    newFloatByteStreamSplitDecoder(),
+			values: []interface{}{float32(1.5), float32(-23.42), float32(math.Inf(1)), float32(0), float32(math.MaxFloat32)},
+		},
+		{
+			name:   "double",
+			enc:    newDoubleByteStreamSplitEncoder(),
+			dec:    newDoubleByteStreamSplitDecoder(),
+			values: []interface{}{float64(1.5), float64(-23.42), math.Inf(-1), float64(0), math.SmallestNonzeroFloat64},
+		},
+		{
+			name:   "fixed_len_byte_array",
+			enc:    newFixedLenByteArrayByteStreamSplitEncoder(3),
+			dec:    newFixedLenByteArrayByteStreamSplitDecoder(3),
+			values: []interface{}{[]byte{1, 2, 3}, []byte{4, 5, 6}, []byte{7, 8, 9}},
+		},
+		{
+			name:   "empty",
+			enc:    newDoubleByteStreamSplitEncoder(),
+			dec:    newDoubleByteStreamSplitDecoder(),
+			values: []interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, encodeValue(&buf, tt.enc, tt.values))
+
+			require.NoError(t, tt.dec.init(&buf))
+			values := make([]interface{}, len(tt.values))
+			n, err := tt.dec.decodeValues(values)
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.values), n)
+			assert.Equal(t, tt.values, values)
+		})
+	}
+}
+
+func TestByteStreamSplitInvalidInput(t *testing.T) {
+	dec := newDoubleByteStreamSplitDecoder()
+	assert.Error(t, dec.init(bytes.NewReader([]byte{1, 2, 3})))
+
+	var buf bytes.Buffer
+	enc := newFixedLenByteArrayByteStreamSplitEncoder(4)
+	require.NoError(t, enc.init(&buf))
+	assert.Error(t, enc.encodeValues([]interface{}{[]byte{1, 2}}))
+	assert.Error(t, enc.encodeValues([]interface{}{"abcd"}))
+}
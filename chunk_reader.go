@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"hash/crc32"
 	"io"
+	"math"
 	"math/bits"
+	"sort"
+	"sync"
 
 	"github.com/fraugster/parquet-go/parquet"
 )
@@ -70,6 +73,8 @@ func getFixedLenByteArrayValuesDecoder(pageEncoding parquet.Encoding, len int, d
 		return &byteArrayPlainDecoder{length: len}, nil
 	case parquet.Encoding_DELTA_BYTE_ARRAY:
 		return &byteArrayDeltaDecoder{}, nil
+	case parquet.Encoding_BYTE_STREAM_SPLIT:
+		return newFixedLenByteArrayByteStreamSplitDecoder(len), nil
 	case parquet.Encoding_RLE_DICTIONARY:
 		return &dictDecoder{uniqueValues: dictValues}, nil
 	default:
@@ -125,6 +130,8 @@ func getValuesDecoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement,
 		switch pageEncoding {
 		case parquet.Encoding_PLAIN:
 			return &floatPlainDecoder{}, nil
+		case parquet.Encoding_BYTE_STREAM_SPLIT:
+			return newFloatByteStreamSplitDecoder(), nil
 		case parquet.Encoding_RLE_DICTIONARY:
 			return &dictDecoder{uniqueValues: dictValues}, nil
 		}
@@ -133,6 +140,8 @@ func getValuesDecoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement,
 		switch pageEncoding {
 		case parquet.Encoding_PLAIN:
 			return &doublePlainDecoder{}, nil
+		case parquet.Encoding_BYTE_STREAM_SPLIT:
+			return newDoubleByteStreamSplitDecoder(), nil
 		case parquet.Encoding_RLE_DICTIONARY:
 			return &dictDecoder{uniqueValues: dictValues}, nil
 		}
@@ -177,82 +186,274 @@ func readPageBlock(r io.Reader, codec parquet.CompressionCodec, compressedSize i
 	return dataPageBlock, nil
 }
 
-func readPages(ctx context.Context, sch *schema, r *offsetReader, col *Column, chunkMeta *parquet.ColumnMetaData, dDecoder, rDecoder getLevelDecoder) (pages []pageReader, useDict bool, err error) {
-	var (
-		dictPage *dictPageReader
-	)
+// chunkPageReader reads the data pages of a column chunk one at a time.
+type chunkPageReader struct {
+	ctx       context.Context
+	sch       *schema
+	r         *offsetReader
+	col       *Column
+	chunkMeta *parquet.ColumnMetaData
+	dDecoder  getLevelDecoder
+	rDecoder  getLevelDecoder
+	cc        *chunkCipher
+
+	dictPage *dictPageReader
+	numPages int
+
+	// if shared is true, the underlying reader is also used to read other column chunks, so the
+	// reader needs to seek to the next page before reading it.
+	shared bool
+}
+
+// nextPage reads the next data page of the column chunk. It returns io.EOF if all pages have been read.
+func (cr *chunkPageReader) nextPage() (pageReader, error) {
+	if cr.shared {
+		if _, err := cr.r.Seek(cr.r.offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
 
 	for {
-		if chunkMeta.TotalCompressedSize-r.Count() <= 0 {
-			break
+		if cr.done() {
+			return nil, io.EOF
 		}
-		ph := &parquet.PageHeader{}
-		if err := readThrift(ctx, ph, r); err != nil {
-			return nil, false, err
+
+		ph, dict, ordinal, err := cr.readPageHeader()
+		if err != nil {
+			return nil, err
 		}
 
-		if ph.Type == parquet.PageType_DICTIONARY_PAGE {
-			if dictPage != nil {
-				return nil, false, errors.New("there should be only one dictionary")
-			}
-			p := &dictPageReader{validateCRC: sch.validateCRC}
-			de, err := getDictValuesDecoder(col.Element())
-			if err != nil {
-				return nil, false, err
-			}
-			if err := p.init(de); err != nil {
-				return nil, false, err
-			}
+		p, err := cr.readPage(ph, dict, ordinal)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			continue // the dictionary page was read, go to the next page
+		}
 
-			if err := p.read(r, ph, chunkMeta.Codec); err != nil {
-				return nil, false, err
-			}
+		cr.numPages++
+		return p, nil
+	}
+}
+
+// done returns true if all pages of the column chunk have been read.
+func (cr *chunkPageReader) done() bool {
+	return cr.chunkMeta.TotalCompressedSize-cr.r.Count() <= 0
+}
+
+// readPageHeader reads the header of the next page. It also returns whether the page is expected
+// to be the dictionary page and the page ordinal, which are required for encrypted column chunks.
+func (cr *chunkPageReader) readPageHeader() (ph *parquet.PageHeader, dict bool, ordinal int, err error) {
+	// the dictionary page header is encrypted as a different module than data page headers,
+	// so the page type needs to be known before reading the page header.
+	dict = cr.dictPage == nil && cr.numPages == 0 && cr.chunkMeta.DictionaryPageOffset != nil
+	headerType, ordinal := moduleDataPageHeader, cr.numPages
+	if dict {
+		headerType, ordinal = moduleDictionaryPageHeader, -1
+	}
+
+	ph = &parquet.PageHeader{}
+	if err := cr.cc.readThrift(cr.ctx, ph, cr.r, headerType, ordinal); err != nil {
+		return nil, false, 0, err
+	}
+
+	if cr.cc != nil {
+		if dict != (ph.Type == parquet.PageType_DICTIONARY_PAGE) {
+			return nil, false, 0, fmt.Errorf("unexpected page type %s in encrypted column chunk", ph.Type)
+		}
+		if err := checkPageOrdinal(ordinal); err != nil {
+			return nil, false, 0, err
+		}
+	}
+
+	return ph, dict, ordinal, nil
+}
+
+// readPage reads the page data of the page with the header ph. If the page is the dictionary page,
+// it is kept for decoding the following data pages, and no pageReader is returned.
+func (cr *chunkPageReader) readPage(ph *parquet.PageHeader, dict bool, ordinal int) (pageReader, error) {
+	r := cr.r
+
+	var pageData io.Reader = r
+	if cr.cc != nil {
+		var err error
+		if pageData, err = cr.cc.decryptPage(r, ph, dict, ordinal, cr.sch.validateCRC); err != nil {
+			return nil, err
+		}
+	}
+
+	if ph.Type == parquet.PageType_DICTIONARY_PAGE {
+		if cr.dictPage != nil {
+			return nil, errors.New("there should be only one dictionary")
+		}
+		p := &dictPageReader{validateCRC: cr.sch.validateCRC}
+		de, err := getDictValuesDecoder(cr.col.Element())
+		if err != nil {
+			return nil, err
+		}
+		if err := p.init(de); err != nil {
+			return nil, err
+		}
+
+		if err := p.read(pageData, ph, cr.chunkMeta.Codec); err != nil {
+			return nil, err
+		}
 
-			dictPage = p
+		cr.dictPage = p
 
-			// Go to the next data Page
-			// if we have a DictionaryPageOffset we should return to DataPageOffset
-			if chunkMeta.DictionaryPageOffset != nil {
-				if *chunkMeta.DictionaryPageOffset != r.offset {
-					if _, err := r.Seek(chunkMeta.DataPageOffset, io.SeekStart); err != nil {
-						return nil, false, err
-					}
+		// Go to the next data Page
+		// if we have a DictionaryPageOffset we should return to DataPageOffset
+		if cr.chunkMeta.DictionaryPageOffset != nil {
+			if *cr.chunkMeta.DictionaryPageOffset != r.offset {
+				if _, err := r.Seek(cr.chunkMeta.DataPageOffset, io.SeekStart); err != nil {
+					return nil, err
 				}
 			}
-			continue // go to next page
+		}
+		return nil, nil
+	}
+
+	var p pageReader
+	switch ph.Type {
+	case parquet.PageType_DATA_PAGE:
+		p = &dataPageReaderV1{
+			ph: ph,
+		}
+	case parquet.PageType_DATA_PAGE_V2:
+		p = &dataPageReaderV2{
+			ph: ph,
+		}
+	default:
+		return nil, fmt.Errorf("DATA_PAGE or DATA_PAGE_V2 type supported, but was %s", ph.Type)
+	}
+	var dictValue []interface{}
+	if cr.dictPage != nil {
+		dictValue = cr.dictPage.values
+	}
+	var fn = func(typ parquet.Encoding) (valuesDecoder, error) {
+		return getValuesDecoder(typ, cr.col.Element(), clone(dictValue))
+	}
+	if err := p.init(cr.dDecoder, cr.rDecoder, fn); err != nil {
+		return nil, err
+	}
+
+	if err := p.read(pageData, ph, cr.chunkMeta.Codec, cr.sch.validateCRC); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// skipToRow skips all data pages that only contain rows before row, which is the index of a row
+// in the row group, without decoding them. If offsetIndex is set, it is used to seek directly to
+// the page containing the row. Otherwise, the number of rows is taken from the page headers, which
+// is only possible for data pages V2 and columns that are not repeated. It returns the index of the
+// first row of the next page, so the remaining rows need to be skipped after decoding the page.
+func (cr *chunkPageReader) skipToRow(row int64, offsetIndex *parquet.OffsetIndex) (int64, error) {
+	if offsetIndex != nil && len(offsetIndex.PageLocations) > 0 {
+		locs := offsetIndex.PageLocations
+		i := sort.Search(len(locs), func(i int) bool {
+			return locs[i].FirstRowIndex > row
+		}) - 1
+		if i <= 0 {
+			return 0, nil
 		}
 
-		var p pageReader
-		switch ph.Type {
-		case parquet.PageType_DATA_PAGE:
-			p = &dataPageReaderV1{
-				ph: ph,
+		// the dictionary page is needed to decode the data pages.
+		if cr.chunkMeta.DictionaryPageOffset != nil && cr.dictPage == nil {
+			ph, dict, ordinal, err := cr.readPageHeader()
+			if err != nil {
+				return 0, err
 			}
-		case parquet.PageType_DATA_PAGE_V2:
-			p = &dataPageReaderV2{
-				ph: ph,
+			if _, err := cr.readPage(ph, dict, ordinal); err != nil {
+				return 0, err
 			}
-		default:
-			return nil, false, fmt.Errorf("DATA_PAGE or DATA_PAGE_V2 type supported, but was %s", ph.Type)
 		}
-		var dictValue []interface{}
-		if dictPage != nil {
-			dictValue = dictPage.values
+
+		if _, err := cr.r.Seek(locs[i].Offset, io.SeekStart); err != nil {
+			return 0, err
 		}
-		var fn = func(typ parquet.Encoding) (valuesDecoder, error) {
-			return getValuesDecoder(typ, col.Element(), clone(dictValue))
+		cr.numPages = i
+		return locs[i].FirstRowIndex, nil
+	}
+
+	var first int64
+	for !cr.done() {
+		start := cr.r.offset
+		ph, dict, ordinal, err := cr.readPageHeader()
+		if err != nil {
+			return 0, err
 		}
-		if err := p.init(dDecoder, rDecoder, fn); err != nil {
-			return nil, false, err
+
+		if ph.Type == parquet.PageType_DICTIONARY_PAGE {
+			if _, err := cr.readPage(ph, dict, ordinal); err != nil {
+				return 0, err
+			}
+			continue
 		}
 
-		if err := p.read(r, ph, chunkMeta.Codec, sch.validateCRC); err != nil {
-			return nil, false, err
+		numRows, ok := cr.pageNumRows(ph)
+		if !ok || first+numRows > row {
+			// the page needs to be decoded, so go back to its header.
+			if _, err := cr.r.Seek(start, io.SeekStart); err != nil {
+				return 0, err
+			}
+			return first, nil
+		}
+
+		if _, err := cr.r.Seek(int64(ph.CompressedPageSize), io.SeekCurrent); err != nil {
+			return 0, err
+		}
+		cr.numPages++
+		first += numRows
+	}
+
+	return first, nil
+}
+
+// pageNumRows returns the number of rows in a data page if it is known from the page header.
+func (cr *chunkPageReader) pageNumRows(ph *parquet.PageHeader) (int64, bool) {
+	switch {
+	case ph.DataPageHeaderV2 != nil:
+		return int64(ph.DataPageHeaderV2.NumRows), true
+	case ph.DataPageHeader != nil && cr.col.MaxRepetitionLevel() == 0:
+		return int64(ph.DataPageHeader.NumValues), true
+	}
+	return 0, false
+}
+
+// useDict returns true if the column chunk has a dictionary page that has already been read.
+func (cr *chunkPageReader) useDict() bool {
+	return cr.dictPage != nil
+}
+
+// readAll reads all remaining data pages of the column chunk.
+func (cr *chunkPageReader) readAll() ([]pageReader, error) {
+	var pages []pageReader
+	for {
+		p, err := cr.nextPage()
+		if err == io.EOF {
+			return pages, nil
+		}
+		if err != nil {
+			return nil, err
 		}
 		pages = append(pages, p)
 	}
+}
 
-	return pages, dictPage != nil, nil
+// pageList is a pageIterator over data pages that have already been read into memory.
+type pageList struct {
+	pages []pageReader
+	idx   int
+}
+
+func (l *pageList) nextPage() (pageReader, error) {
+	if l.idx >= len(l.pages) {
+		return nil, fmt.Errorf("out of range: requested page index = %d total number of pages = %d", l.idx, len(l.pages))
+	}
+	p := l.pages[l.idx]
+	l.idx++
+	return p, nil
 }
 
 func clone(in []interface{}) []interface{} {
@@ -289,9 +490,10 @@ func skipChunk(r io.Seeker, col *Column, chunk *parquet.ColumnChunk) error {
 	return err
 }
 
-func readChunk(ctx context.Context, sch *schema, r io.ReadSeeker, col *Column, chunk *parquet.ColumnChunk) (pages []pageReader, useDict bool, err error) {
+// newChunkPageReader seeks to the first page of the column chunk and returns a reader for its pages.
+func newChunkPageReader(ctx context.Context, sch *schema, r io.ReadSeeker, col *Column, chunk *parquet.ColumnChunk, cc *chunkCipher) (*chunkPageReader, error) {
 	if chunk.FilePath != nil {
-		return nil, false, fmt.Errorf("nyi: data is in another file: '%s'", *chunk.FilePath)
+		return nil, fmt.Errorf("nyi: data is in another file: '%s'", *chunk.FilePath)
 	}
 
 	c := col.Index()
@@ -299,11 +501,11 @@ func readChunk(ctx context.Context, sch *schema, r io.ReadSeeker, col *Column, c
 	// as we cannot read it from r
 	// see https://issues.apache.org/jira/browse/PARQUET-291
 	if chunk.MetaData == nil {
-		return nil, false, fmt.Errorf("missing meta data for Column %c", c)
+		return nil, fmt.Errorf("missing meta data for Column %c", c)
 	}
 
 	if typ := *col.Element().Type; chunk.MetaData.Type != typ {
-		return nil, false, fmt.Errorf("wrong type in Column chunk metadata, expected %s was %s",
+		return nil, fmt.Errorf("wrong type in Column chunk metadata, expected %s was %s",
 			typ, chunk.MetaData.Type)
 	}
 
@@ -313,7 +515,7 @@ func readChunk(ctx context.Context, sch *schema, r io.ReadSeeker, col *Column, c
 	}
 	// Seek to the beginning of the first Page
 	if _, err := r.Seek(offset, io.SeekStart); err != nil {
-		return nil, false, err
+		return nil, err
 	}
 
 	reader := &offsetReader{
@@ -351,24 +553,78 @@ func readChunk(ctx context.Context, sch *schema, r io.ReadSeeker, col *Column, c
 			return &levelDecoderWrapper{decoder: constDecoder(0), max: col.MaxDefinitionLevel()}, nil
 		}
 	}
-	return readPages(ctx, sch, reader, col, chunk.MetaData, dDecoder, rDecoder)
+	return &chunkPageReader{
+		ctx:       ctx,
+		sch:       sch,
+		r:         reader,
+		col:       col,
+		chunkMeta: chunk.MetaData,
+		dDecoder:  dDecoder,
+		rDecoder:  rDecoder,
+		cc:        cc,
+	}, nil
 }
 
-func readPageData(col *Column, pages []pageReader, useDict bool) error {
+func readPageData(col *Column, pages pageIterator, useDict bool) error {
 	s := col.getColumnStore()
-	s.pageIdx, s.pages = 0, pages
+	s.pages = pages
 	s.useDict = useDict
-	if err := s.readNextPage(); err != nil {
-		return nil
+	return s.readNextPage()
+}
+
+// chunkReadTask describes a column chunk of a row group that needs to be read.
+type chunkReadTask struct {
+	col    *Column
+	chunk  *parquet.ColumnChunk
+	cipher *chunkCipher
+	// r is the reader to read the column chunk from.
+	r io.ReadSeeker
+
+	// firstRow is the index of the first row in the row group to read. If it is greater than 0,
+	// the pages before it are skipped, using the offset index if it is available.
+	firstRow    int64
+	offsetIndex *parquet.OffsetIndex
+}
+
+// readRowGroupOptions configure how the column chunks of a row group are read.
+type readRowGroupOptions struct {
+	decryptor   *fileDecryptor
+	concurrency int
+
+	// if rangeReader is set, the byte ranges of all selected column chunks are read from it before
+	// decoding the column chunks. Ranges that are at most maxReadGap bytes apart are read at once.
+	rangeReader io.ReaderAt
+	fileSize    int64
+	maxReadGap  int64
+
+	// if the selected column chunks of a row group are larger than streamingThreshold bytes when
+	// uncompressed, their pages are read one at a time while reading rows. A negative value
+	// means that whole column chunks are always read.
+	streamingThreshold int64
+}
+
+// streaming returns true if the pages of the column chunks need to be read one at a time.
+func (opts *readRowGroupOptions) streaming(tasks []chunkReadTask) bool {
+	if opts.streamingThreshold < 0 {
+		return false
 	}
 
-	return nil
+	var size int64
+	for _, t := range tasks {
+		if t.chunk.MetaData != nil {
+			size += t.chunk.MetaData.TotalUncompressedSize
+		}
+	}
+	return size > opts.streamingThreshold
 }
 
-func readRowGroup(ctx context.Context, r io.ReadSeeker, sch *schema, rowGroups *parquet.RowGroup) error {
+// readRowGroup reads the selected column chunks of a row group, starting at the row firstRow of the row group.
+func readRowGroup(ctx context.Context, r io.ReadSeeker, sch *schema, rowGroups *parquet.RowGroup, ordinal int, firstRow int64, opts *readRowGroupOptions) error {
 	dataCols := sch.Columns()
 	sch.resetData()
 	sch.setNumRecords(rowGroups.NumRows)
+
+	tasks := make([]chunkReadTask, 0, len(dataCols))
 	for _, c := range dataCols {
 		idx := c.Index()
 		if len(rowGroups.Columns) <= idx {
@@ -376,17 +632,141 @@ func readRowGroup(ctx context.Context, r io.ReadSeeker, sch *schema, rowGroups *
 		}
 		chunk := rowGroups.Columns[c.Index()]
 		if !sch.isSelectedByPath(c.path) {
-			if err := skipChunk(r, c, chunk); err != nil {
-				return err
+			// the meta data of encrypted columns is missing if their key is not available.
+			if chunk.MetaData != nil && opts.rangeReader == nil {
+				if err := skipChunk(r, c, chunk); err != nil {
+					return err
+				}
 			}
 			c.data.skipped = true
 			continue
 		}
-		pages, useDict, err := readChunk(ctx, sch, r, c, chunk)
+		cc, err := opts.decryptor.chunkCipher(ordinal, idx, chunk)
 		if err != nil {
+			return fmt.Errorf("column %q: %w", c.FlatName(), err)
+		}
+		t := chunkReadTask{col: c, chunk: chunk, cipher: cc, r: r, firstRow: firstRow}
+		if firstRow > 0 {
+			if t.offsetIndex, err = readOffsetIndex(ctx, r, chunk, cc); err != nil {
+				return fmt.Errorf("column %q: %w", c.FlatName(), err)
+			}
+		}
+		tasks = append(tasks, t)
+	}
+
+	streaming := opts.streaming(tasks)
+
+	ra, independent := r.(io.ReaderAt)
+	if opts.rangeReader != nil {
+		ra, independent = opts.rangeReader, true
+	}
+
+	switch {
+	case streaming && independent:
+		// every column chunk needs its own reader so that the pages of a column chunk can be
+		// read without seeking back and forth between column chunks. The reads are buffered, so
+		// that page headers and small pages don't need a read each.
+		for i := range tasks {
+			br, err := newBufferedRangeReader(ra, tasks[i].chunk, streamingReadSize)
+			if err != nil {
+				return fmt.Errorf("column %q: %w", tasks[i].col.FlatName(), err)
+			}
+			tasks[i].r = br
+		}
+	case opts.rangeReader != nil:
+		if err := fetchChunkRanges(opts.rangeReader, opts.fileSize, opts.maxReadGap, tasks); err != nil {
 			return err
 		}
-		if err := readPageData(c, pages, useDict); err != nil {
+		independent = true
+	case independent && opts.concurrency > 1:
+		for i := range tasks {
+			tasks[i].r = io.NewSectionReader(ra, 0, math.MaxInt64)
+		}
+	default:
+		independent = false
+	}
+
+	if independent && opts.concurrency > 1 && len(tasks) > 1 {
+		return readChunksConcurrently(ctx, sch, tasks, opts.concurrency, streaming)
+	}
+
+	for _, t := range tasks {
+		if err := readChunkData(ctx, sch, t, streaming); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readChunkData(ctx context.Context, sch *schema, t chunkReadTask, streaming bool) error {
+	cr, err := newChunkPageReader(ctx, sch, t.r, t.col, t.chunk, t.cipher)
+	if err != nil {
+		return err
+	}
+
+	var pageFirstRow int64
+	if t.firstRow > 0 {
+		if pageFirstRow, err = cr.skipToRow(t.firstRow, t.offsetIndex); err != nil {
+			return err
+		}
+	}
+
+	if streaming {
+		// the remaining pages are read when the column store needs them, and the reader may
+		// have been used for other column chunks in the meantime.
+		cr.shared = true
+		if err := readPageData(t.col, cr, false); err != nil {
+			return err
+		}
+		t.col.getColumnStore().useDict = cr.useDict()
+	} else {
+		pages, err := cr.readAll()
+		if err != nil {
+			return err
+		}
+		if err := readPageData(t.col, &pageList{pages: pages}, cr.useDict()); err != nil {
+			return err
+		}
+	}
+
+	if skip := t.firstRow - pageFirstRow; skip > 0 {
+		return t.col.getColumnStore().skipRows(skip, int32(t.col.MaxDefinitionLevel()))
+	}
+	return nil
+}
+
+// readChunksConcurrently reads and decodes the column chunks using up to concurrency goroutines.
+// The readers of all tasks need to be independent of each other. If reading a column chunk fails,
+// the error of the first column is returned.
+func readChunksConcurrently(ctx context.Context, sch *schema, tasks []chunkReadTask, concurrency int, streaming bool) error {
+	var (
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, concurrency)
+		errs = make([]error, len(tasks))
+	)
+
+	for i := range tasks {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = readChunkData(ctx, sch, tasks[i], streaming)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
 			return err
 		}
 	}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"math/bits"
 	"sort"
 
 	"github.com/fraugster/parquet-go/parquet"
@@ -42,6 +43,8 @@ func getFixedLenByteArrayValuesEncoder(pageEncoding parquet.Encoding, len int, d
 		return &byteArrayPlainEncoder{length: len}, nil
 	case parquet.Encoding_DELTA_BYTE_ARRAY:
 		return &byteArrayDeltaEncoder{}, nil
+	case parquet.Encoding_BYTE_STREAM_SPLIT:
+		return newFixedLenByteArrayByteStreamSplitEncoder(len), nil
 	case parquet.Encoding_RLE_DICTIONARY:
 		return &dictEncoder{dictValues: dictValues}, nil
 	default:
@@ -110,6 +113,8 @@ func getValuesEncoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement,
 		switch pageEncoding {
 		case parquet.Encoding_PLAIN:
 			return &floatPlainEncoder{}, nil
+		case parquet.Encoding_BYTE_STREAM_SPLIT:
+			return newFloatByteStreamSplitEncoder(), nil
 		case parquet.Encoding_RLE_DICTIONARY:
 			return &dictEncoder{
 				dictValues: dictValues,
@@ -120,6 +125,8 @@ func getValuesEncoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement,
 		switch pageEncoding {
 		case parquet.Encoding_PLAIN:
 			return &doublePlainEncoder{}, nil
+		case parquet.Encoding_BYTE_STREAM_SPLIT:
+			return newDoubleByteStreamSplitEncoder(), nil
 		case parquet.Encoding_RLE_DICTIONARY:
 			return &dictEncoder{
 				dictValues: dictValues,
@@ -173,7 +180,37 @@ func getDictValuesEncoder(typ *parquet.SchemaElement) (valuesEncoder, error) {
 	return nil, fmt.Errorf("type %s is not supported for dict value encoder", typ)
 }
 
-func writeChunk(ctx context.Context, w writePos, sch *schema, col *Column, codec parquet.CompressionCodec, pageFn newDataPageFunc, kvMetaData map[string]string) (*parquet.ColumnChunk, error) {
+// dictionaryReducesSize estimates whether dictionary encoding of the column chunk results in less data
+// than PLAIN encoding of all values.
+func dictionaryReducesSize(col *Column, dictValues []interface{}) bool {
+	// every PLAIN encoded BYTE_ARRAY value is prefixed by its length.
+	var overhead int64
+	if col.data.parquetType() == parquet.Type_BYTE_ARRAY {
+		overhead = 4
+	}
+
+	var numValues, plainSize, dictSize int64
+
+	for _, page := range col.data.dataPages {
+		if page.batchValues != nil {
+			plainSize += page.valuesSize + overhead*page.numValues
+		}
+		for _, v := range page.values {
+			plainSize += int64(col.data.sizeOf(v)) + overhead
+		}
+		numValues += page.numValues
+	}
+
+	for _, v := range dictValues {
+		dictSize += int64(col.data.sizeOf(v)) + overhead
+	}
+
+	indexSize := (numValues*int64(bits.Len(uint(len(dictValues)))) + 7) / 8
+
+	return dictSize+indexSize < plainSize
+}
+
+func writeChunk(ctx context.Context, w writePos, sch *schema, col *Column, codec parquet.CompressionCodec, pageFn newDataPageFunc, kvMetaData map[string]string, cc *chunkCipher) (*parquet.ColumnChunk, *chunkIndexes, error) {
 	pos := w.Pos() // Save the position before writing data
 	chunkOffset := pos
 	var (
@@ -189,13 +226,17 @@ func writeChunk(ctx context.Context, w writePos, sch *schema, col *Column, codec
 	)
 
 	// flush final data page before writing dictionary page (if applicable) and all data pages.
-	if err := col.data.flushPage(sch, true); err != nil {
-		return nil, err
+	if err := col.data.flushPage(sch.numRecords, true); err != nil {
+		return nil, nil, err
 	}
 
 	dictValues := []interface{}{}
 	indices := map[interface{}]int32{}
 
+	if col.data.batch != nil {
+		dictValues = col.data.batch.dictionary(col.data.dataPages)
+	}
+
 	for _, page := range col.data.dataPages {
 		for _, v := range page.values {
 			k := mapKey(v)
@@ -217,17 +258,38 @@ func writeChunk(ctx context.Context, w writePos, sch *schema, col *Column, codec
 	if !col.data.useDictionary() {
 		useDict = false
 	}
+	if useDict && col.data.autoDict {
+		useDict = dictionaryReducesSize(col, dictValues)
+	}
+
+	var bf *bloomFilter
+	if cfg, ok := sch.getBloomFilter(col.path); ok {
+		var err error
+		if bf, err = newColumnBloomFilter(*col.Type(), cfg, dictValues); err != nil {
+			return nil, nil, fmt.Errorf("creating bloom filter for column %s failed: %w", col.FlatName(), err)
+		}
+	}
 
 	if useDict {
 		tmp := pos // make a copy, do not use the pos here
 		dictPageOffset = &tmp
 		dict := &dictPageWriter{}
 		if err := dict.init(sch, col, codec, dictValues); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		compSize, unCompSize, err := dict.write(ctx, w)
+		var buf bytes.Buffer
+		compSize, unCompSize, err := dict.write(ctx, &buf)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		data := buf.Bytes()
+		if cc != nil {
+			if data, err = cc.encryptPage(ctx, data, true, -1); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := writeFull(w, data); err != nil {
+			return nil, nil, err
 		}
 		totalComp = w.Pos() - pos
 		// Header size plus the rLevel and dLevel size
@@ -241,26 +303,36 @@ func writeChunk(ctx context.Context, w writePos, sch *schema, col *Column, codec
 		numValues, nullValues int64
 	)
 
-	for _, page := range col.data.dataPages {
+	pageIndex := newPageIndexBuilder(col.Element())
+
+	for i, page := range col.data.dataPages {
 		pw := pageFn(useDict, dictValues, page, sch.enableCRC)
 
 		if err := pw.init(col, codec); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		var buf bytes.Buffer
 
 		compressed, uncompressed, err := pw.write(ctx, &buf)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		data := buf.Bytes()
+		if cc != nil {
+			if data, err = cc.encryptPage(ctx, data, false, i); err != nil {
+				return nil, nil, err
+			}
 		}
 
 		compSize += compressed
 		unCompSize += uncompressed
 		numValues += page.numValues
 		nullValues += page.nullValues
-		if _, err := w.Write(buf.Bytes()); err != nil {
-			return nil, err
+		pageIndex.addPage(page, w.Pos(), len(data))
+		if _, err := w.Write(data); err != nil {
+			return nil, nil, err
 		}
 	}
 
@@ -323,20 +395,35 @@ func writeChunk(ctx context.Context, w writePos, sch *schema, col *Column, codec
 		ColumnIndexLength: nil,
 	}
 
-	return ch, nil
+	return ch, &chunkIndexes{columnIndex: pageIndex.columnIndex(), offsetIndex: pageIndex.offsetIndex(), bloomFilter: bf, cipher: cc}, nil
 }
 
-func writeRowGroup(ctx context.Context, w writePos, sch *schema, codec parquet.CompressionCodec, pageFn newDataPageFunc, h *flushRowGroupOptionHandle) ([]*parquet.ColumnChunk, error) {
+func writeRowGroup(ctx context.Context, w writePos, sch *schema, codec parquet.CompressionCodec, pageFn newDataPageFunc, h *flushRowGroupOptionHandle, enc *fileEncryptor, ordinal int) ([]*parquet.ColumnChunk, []*chunkIndexes, error) {
 	dataCols := sch.Columns()
-	var res = make([]*parquet.ColumnChunk, 0, len(dataCols))
-	for _, ci := range dataCols {
-		ch, err := writeChunk(ctx, w, sch, ci, codec, pageFn, h.getMetaData(ci.Path()))
+	var (
+		res     = make([]*parquet.ColumnChunk, 0, len(dataCols))
+		indexes = make([]*chunkIndexes, 0, len(dataCols))
+	)
+	for i, ci := range dataCols {
+		// the column ordinal is the position of the column chunk in the row group.
+		cc, err := enc.chunkCipher(ordinal, i, ci.path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		colCodec := codec
+		if c, ok := sch.getColumnCodec(ci.path); ok {
+			colCodec = c
+		}
+
+		ch, idx, err := writeChunk(ctx, w, sch, ci, colCodec, pageFn, h.getMetaData(ci.Path()), cc)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		res = append(res, ch)
+		indexes = append(indexes, idx)
 	}
 
-	return res, nil
+	return res, indexes, nil
 }
@@ -0,0 +1,64 @@
+package cmds
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/fraugster/parquet-go/parquetschema/codegen"
+	"github.com/spf13/cobra"
+)
+
+var (
+	genGoOutput  *string
+	genGoPackage *string
+	genGoType    *string
+	genGoMethods *bool
+)
+
+func init() {
+	genGoOutput = genGoCmd.PersistentFlags().StringP("output", "o", "", "The Go file to write the generated code to, the code is printed if it's empty")
+	genGoPackage = genGoCmd.PersistentFlags().StringP("package", "p", "main", "The package name of the generated code")
+	genGoType = genGoCmd.PersistentFlags().StringP("type", "t", "", "The name of the generated struct type, the name of the schema is used if it's empty")
+	genGoMethods = genGoCmd.PersistentFlags().BoolP("methods", "m", false, "Generate MarshalParquet and UnmarshalParquet methods")
+	rootCmd.AddCommand(genGoCmd)
+}
+
+var genGoCmd = &cobra.Command{
+	Use:   "gen-go file-name.parquet",
+	Short: "Generate Go struct types for a schema",
+	Long: `Generate Go struct types with parquet struct tags for a schema. The schema is read from a
+parquet file or from a file containing a textual schema definition.
+
+The generated types can be used with the floor package. If methods are generated, floor uses
+them instead of reflection to marshal and unmarshal the types.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			_ = cmd.Usage()
+			os.Exit(1)
+		}
+
+		sd, err := readSchemaDefinition(args[0])
+		if err != nil {
+			log.Fatalf("Reading the schema of %s failed: %q", args[0], err)
+		}
+
+		src, err := codegen.Generate(sd, codegen.Options{
+			PackageName: *genGoPackage,
+			TypeName:    *genGoType,
+			Methods:     *genGoMethods,
+		})
+		if err != nil {
+			log.Fatalf("Generating Go code failed: %q", err)
+		}
+
+		if *genGoOutput == "" {
+			_, _ = os.Stdout.Write(src)
+			return
+		}
+
+		if err := ioutil.WriteFile(*genGoOutput, src, 0644); err != nil {
+			log.Fatalf("Writing %s failed: %q", *genGoOutput, err)
+		}
+	},
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
 )
 
 var acceptableSuffix = map[string]int64{
@@ -38,3 +40,24 @@ func humanToByte(in string) (int64, error) {
 
 	return 0, fmt.Errorf("invalid format")
 }
+
+func parseCompressionCodec(in string) (parquet.CompressionCodec, error) {
+	switch strings.ToUpper(in) {
+	case "SNAPPY":
+		return parquet.CompressionCodec_SNAPPY, nil
+	case "GZIP":
+		return parquet.CompressionCodec_GZIP, nil
+	case "BROTLI":
+		return parquet.CompressionCodec_BROTLI, nil
+	case "ZSTD":
+		return parquet.CompressionCodec_ZSTD, nil
+	case "LZ4_RAW":
+		return parquet.CompressionCodec_LZ4_RAW, nil
+	case "LZ4":
+		return parquet.CompressionCodec_LZ4, nil
+	case "NONE":
+		return parquet.CompressionCodec_UNCOMPRESSED, nil
+	default:
+		return 0, fmt.Errorf("invalid compression codec %q", in)
+	}
+}
@@ -3,6 +3,7 @@ package cmds
 import (
 	"testing"
 
+	"github.com/fraugster/parquet-go/parquet"
 	"github.com/stretchr/testify/require"
 )
 
@@ -76,3 +77,27 @@ func TestHumanToByte(t *testing.T) {
 		require.Equal(t, fix.Out, v, fix.In)
 	}
 }
+
+func TestParseCompressionCodec(t *testing.T) {
+	data := []struct {
+		In  string
+		Out parquet.CompressionCodec
+	}{
+		{In: "Snappy", Out: parquet.CompressionCodec_SNAPPY},
+		{In: "gzip", Out: parquet.CompressionCodec_GZIP},
+		{In: "Brotli", Out: parquet.CompressionCodec_BROTLI},
+		{In: "Zstd", Out: parquet.CompressionCodec_ZSTD},
+		{In: "Lz4_raw", Out: parquet.CompressionCodec_LZ4_RAW},
+		{In: "Lz4", Out: parquet.CompressionCodec_LZ4},
+		{In: "None", Out: parquet.CompressionCodec_UNCOMPRESSED},
+	}
+
+	for _, d := range data {
+		codec, err := parseCompressionCodec(d.In)
+		require.NoError(t, err, d.In)
+		require.Equal(t, d.Out, codec, d.In)
+	}
+
+	_, err := parseCompressionCodec("lzo")
+	require.Error(t, err)
+}
@@ -0,0 +1,291 @@
+package cmds
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mergeOutput       *string
+	mergeRowGroupSize *string
+	mergeCompression  *string
+)
+
+func init() {
+	mergeOutput = mergeCmd.PersistentFlags().StringP("output", "o", "", "The parquet file to write the merged data to")
+	mergeRowGroupSize = mergeCmd.PersistentFlags().StringP("row-group-size", "r", "", "Uncompressed row group size, all rows are re-encoded into new row groups if set")
+	mergeCompression = mergeCmd.PersistentFlags().StringP("compression", "c", "", "Compression method, valid values are Snappy, Gzip, Brotli, Zstd, Lz4_raw, Lz4, None. Row groups using a different compression method are re-encoded if set")
+	rootCmd.AddCommand(mergeCmd)
+}
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge -o output.parquet file-name.parquet [file-name.parquet ...]",
+	Short: "Merge multiple parquet files with compatible schemas into a single parquet file",
+	Long: `Merge multiple parquet files with compatible schemas into a single parquet file.
+
+The schema of the merged file contains the columns of all files. Row groups are copied without
+decoding and re-encoding them, unless a row group size is set, they don't use the requested
+compression method or the schema of their file differs from the merged schema.
+
+The key-value meta data of all files is copied to the merged file. If files contain the same key,
+the value of the first of them is used.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 || *mergeOutput == "" {
+			_ = cmd.Usage()
+			os.Exit(1)
+		}
+
+		var opts mergeOptions
+		if *mergeRowGroupSize != "" {
+			rgSize, err := humanToByte(*mergeRowGroupSize)
+			if err != nil {
+				log.Fatalf("Invalid row group size: %q", *mergeRowGroupSize)
+			}
+			opts.rowGroupSize = rgSize
+		}
+		if *mergeCompression != "" {
+			comp, err := parseCompressionCodec(*mergeCompression)
+			if err != nil {
+				log.Fatalf("Invalid compression codec: %q", *mergeCompression)
+			}
+			opts.codec = &comp
+		}
+
+		if err := mergeToFile(*mergeOutput, args, opts); err != nil {
+			log.Fatalf("Merging files failed: %q", err)
+		}
+	},
+}
+
+type mergeOptions struct {
+	// if rowGroupSize is greater than 0, all rows are re-encoded into row groups of this size.
+	rowGroupSize int64
+	// if codec is set, row groups that use a different compression codec are re-encoded.
+	codec *parquet.CompressionCodec
+	// if convert is set, all rows are re-encoded after converting them to these columns.
+	convert []*parquetschema.ColumnDefinition
+}
+
+// canCopy returns true if the row group can be copied without re-encoding it.
+func (opts mergeOptions) canCopy(rg *parquet.RowGroup) bool {
+	if opts.rowGroupSize > 0 || opts.convert != nil {
+		return false
+	}
+	if opts.codec != nil {
+		for _, chunk := range rg.Columns {
+			if chunk.MetaData == nil || chunk.MetaData.Codec != *opts.codec {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// mergeToFile merges files into the file output. If merging fails, output is removed.
+func mergeToFile(output string, files []string, opts mergeOptions) error {
+	fl, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("can not create the file: %w", err)
+	}
+
+	err = mergeFiles(fl, files, opts)
+	if closeErr := fl.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(output)
+		return err
+	}
+
+	return nil
+}
+
+// mergeInput is a file to merge.
+type mergeInput struct {
+	name string
+	meta *parquet.FileMetaData
+	sd   *parquetschema.SchemaDefinition
+}
+
+func mergeFiles(w io.Writer, files []string, opts mergeOptions) error {
+	if len(files) == 0 {
+		return errors.New("no files to merge")
+	}
+
+	inputs := make([]mergeInput, 0, len(files))
+	defs := make([]*parquetschema.SchemaDefinition, 0, len(files))
+	kv := make(map[string]string)
+	for _, file := range files {
+		in, err := readMergeInput(file)
+		if err != nil {
+			return fmt.Errorf("reading %s failed: %w", file, err)
+		}
+		inputs = append(inputs, in)
+		defs = append(defs, in.sd)
+
+		for _, v := range in.meta.KeyValueMetadata {
+			if _, ok := kv[v.Key]; !ok {
+				kv[v.Key] = v.GetValue()
+			}
+		}
+	}
+
+	sd, err := parquetschema.Merge(defs...)
+	if err != nil {
+		return fmt.Errorf("schemas are not compatible: %w", err)
+	}
+
+	writerOpts := []goparquet.FileWriterOption{goparquet.WithSchemaDefinition(sd), goparquet.WithMetaData(kv)}
+	if opts.codec != nil {
+		writerOpts = append(writerOpts, goparquet.WithCompressionCodec(*opts.codec))
+	}
+	if opts.rowGroupSize > 0 {
+		writerOpts = append(writerOpts, goparquet.WithMaxRowGroupSize(opts.rowGroupSize))
+	}
+	writer := goparquet.NewFileWriter(w, writerOpts...)
+
+	for _, in := range inputs {
+		if err := mergeFile(writer, in, sd, opts); err != nil {
+			return fmt.Errorf("merging %s failed: %w", in.name, err)
+		}
+	}
+
+	return writer.Close()
+}
+
+func readMergeInput(file string) (mergeInput, error) {
+	fl, err := os.Open(file)
+	if err != nil {
+		return mergeInput{}, err
+	}
+	defer fl.Close()
+
+	meta, err := goparquet.ReadFileMetaData(fl, true)
+	if err != nil {
+		return mergeInput{}, err
+	}
+
+	reader, err := goparquet.NewFileReaderWithOptions(fl, goparquet.WithFileMetaData(meta))
+	if err != nil {
+		return mergeInput{}, err
+	}
+
+	return mergeInput{name: file, meta: meta, sd: reader.GetSchemaDefinition()}, nil
+}
+
+func mergeFile(writer *goparquet.FileWriter, in mergeInput, sd *parquetschema.SchemaDefinition, opts mergeOptions) error {
+	fl, err := os.Open(in.name)
+	if err != nil {
+		return err
+	}
+	defer fl.Close()
+
+	reader, err := goparquet.NewFileReaderWithOptions(fl, goparquet.WithFileMetaData(in.meta))
+	if err != nil {
+		return err
+	}
+
+	// row groups can only be copied if the schema of the file is the merged schema.
+	if in.sd.String() != sd.String() {
+		opts.convert = sd.RootColumn.Children
+	}
+
+	return mergeRowGroups(writer, reader, in.meta, opts)
+}
+
+func mergeRowGroups(writer *goparquet.FileWriter, reader *goparquet.FileReader, meta *parquet.FileMetaData, opts mergeOptions) error {
+	var firstRow int64
+	for i, rg := range meta.RowGroups {
+		if rg.NumRows == 0 {
+			continue
+		}
+
+		if opts.canCopy(rg) {
+			if err := writer.AppendRowGroupFrom(reader, i); err != nil {
+				return err
+			}
+			firstRow += rg.NumRows
+			continue
+		}
+
+		rows, err := reader.ReadRows(firstRow, firstRow+rg.NumRows)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			convertRow(row, opts.convert)
+			if err := writer.AddData(row); err != nil {
+				return err
+			}
+		}
+		firstRow += rg.NumRows
+
+		// without a row group size, the row groups of the input files are kept.
+		if opts.rowGroupSize == 0 {
+			if err := writer.FlushRowGroup(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// convertRow converts the values of row to the types of the columns cols of the merged schema,
+// which may use wider types than the schema of the file the row was read from.
+func convertRow(row map[string]interface{}, cols []*parquetschema.ColumnDefinition) {
+	for _, col := range cols {
+		name := col.SchemaElement.GetName()
+		v, ok := row[name]
+		if !ok {
+			continue
+		}
+
+		if col.SchemaElement.Type == nil {
+			switch typed := v.(type) {
+			case map[string]interface{}:
+				convertRow(typed, col.Children)
+			case []map[string]interface{}:
+				for _, m := range typed {
+					convertRow(m, col.Children)
+				}
+			}
+			continue
+		}
+
+		switch typed := v.(type) {
+		case int32:
+			if col.SchemaElement.GetType() == parquet.Type_INT64 {
+				row[name] = int64(typed)
+			}
+		case []int32:
+			if col.SchemaElement.GetType() == parquet.Type_INT64 {
+				row[name] = convertSlice[int32, int64](typed)
+			}
+		case float32:
+			if col.SchemaElement.GetType() == parquet.Type_DOUBLE {
+				row[name] = float64(typed)
+			}
+		case []float32:
+			if col.SchemaElement.GetType() == parquet.Type_DOUBLE {
+				row[name] = convertSlice[float32, float64](typed)
+			}
+		}
+	}
+}
+
+func convertSlice[From int32 | float32, To int64 | float64](values []From) []To {
+	converted := make([]To, len(values))
+	for i, v := range values {
+		converted[i] = To(v)
+	}
+	return converted
+}
@@ -0,0 +1,133 @@
+package cmds
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func writeMergeTestFile(t *testing.T, path string, schema string, codec parquet.CompressionCodec, first int64) {
+	sd, err := parquetschema.ParseSchemaDefinition(schema)
+	require.NoError(t, err)
+
+	fl, err := os.Create(path)
+	require.NoError(t, err)
+	defer fl.Close()
+
+	wr := goparquet.NewFileWriter(fl, goparquet.WithSchemaDefinition(sd), goparquet.WithCompressionCodec(codec))
+	for rg := int64(0); rg < 2; rg++ {
+		for i := int64(0); i < 100; i++ {
+			id := first + rg*100 + i
+			require.NoError(t, wr.AddData(map[string]interface{}{"id": id, "name": []byte(fmt.Sprintf("name-%d", id))}))
+		}
+		require.NoError(t, wr.FlushRowGroup())
+	}
+	require.NoError(t, wr.Close())
+}
+
+func readMergedFile(t *testing.T, data []byte) (*parquet.FileMetaData, []int64) {
+	meta, err := goparquet.ReadFileMetaData(bytes.NewReader(data), true)
+	require.NoError(t, err)
+
+	r, err := goparquet.NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	var ids []int64
+	for i := int64(0); i < r.NumRows(); i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		ids = append(ids, row["id"].(int64))
+		require.Equal(t, []byte(fmt.Sprintf("name-%d", row["id"])), row["name"])
+	}
+
+	return meta, ids
+}
+
+func TestMergeFiles(t *testing.T) {
+	const schema = `message test { required int64 id; required binary name (STRING); }`
+
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "a.parquet"), filepath.Join(dir, "b.parquet")}
+	writeMergeTestFile(t, files[0], schema, parquet.CompressionCodec_SNAPPY, 0)
+	writeMergeTestFile(t, files[1], schema, parquet.CompressionCodec_UNCOMPRESSED, 200)
+
+	expectedIDs := make([]int64, 400)
+	for i := range expectedIDs {
+		expectedIDs[i] = int64(i)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, mergeFiles(&buf, files, mergeOptions{}))
+	meta, ids := readMergedFile(t, buf.Bytes())
+	require.Equal(t, expectedIDs, ids)
+	require.Len(t, meta.RowGroups, 4)
+	require.Equal(t, parquet.CompressionCodec_SNAPPY, meta.RowGroups[1].Columns[0].MetaData.Codec)
+	require.Equal(t, parquet.CompressionCodec_UNCOMPRESSED, meta.RowGroups[2].Columns[0].MetaData.Codec)
+
+	codec := parquet.CompressionCodec_SNAPPY
+	buf.Reset()
+	require.NoError(t, mergeFiles(&buf, files, mergeOptions{codec: &codec}))
+	meta, ids = readMergedFile(t, buf.Bytes())
+	require.Equal(t, expectedIDs, ids)
+	require.Len(t, meta.RowGroups, 4)
+	for _, rg := range meta.RowGroups {
+		require.Equal(t, parquet.CompressionCodec_SNAPPY, rg.Columns[0].MetaData.Codec)
+	}
+
+	buf.Reset()
+	require.NoError(t, mergeFiles(&buf, files, mergeOptions{rowGroupSize: 1 << 30}))
+	meta, ids = readMergedFile(t, buf.Bytes())
+	require.Equal(t, expectedIDs, ids)
+	require.Len(t, meta.RowGroups, 1)
+
+	require.Error(t, mergeFiles(&bytes.Buffer{}, nil, mergeOptions{}))
+}
+
+func TestMergeFilesSchemaMerge(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "a.parquet"), filepath.Join(dir, "b.parquet")}
+	writeMergeTestFile(t, files[0], `message test { required int64 id; required binary name (STRING); }`, parquet.CompressionCodec_SNAPPY, 0)
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test { required int32 id; optional binary name (STRING); optional int32 size; }`)
+	require.NoError(t, err)
+	fl, err := os.Create(files[1])
+	require.NoError(t, err)
+	wr := goparquet.NewFileWriter(fl, goparquet.WithSchemaDefinition(sd), goparquet.WithMetaData(map[string]string{"source": "b"}))
+	for id := int32(200); id < 300; id++ {
+		require.NoError(t, wr.AddData(map[string]interface{}{"id": id, "name": []byte(fmt.Sprintf("name-%d", id)), "size": id}))
+	}
+	require.NoError(t, wr.Close())
+	require.NoError(t, fl.Close())
+
+	var buf bytes.Buffer
+	require.NoError(t, mergeFiles(&buf, files, mergeOptions{}))
+	meta, ids := readMergedFile(t, buf.Bytes())
+	require.Len(t, ids, 300)
+	require.Equal(t, int64(299), ids[299])
+	require.Len(t, meta.RowGroups, 3)
+
+	r, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, "message test {\n  required int64 id;\n  optional binary name (STRING);\n  optional int32 size;\n}\n", r.GetSchemaDefinition().String())
+	require.Equal(t, map[string]string{"source": "b"}, r.MetaData())
+
+	other := filepath.Join(dir, "c.parquet")
+	writeMergeTestFile(t, other, `message test { required int64 id; required binary name (ENUM); }`, parquet.CompressionCodec_SNAPPY, 400)
+	require.Error(t, mergeFiles(&bytes.Buffer{}, append(files, other), mergeOptions{}))
+
+	output := filepath.Join(dir, "merged.parquet")
+	require.Error(t, mergeToFile(output, append(files, other), mergeOptions{}))
+	_, err = os.Stat(output)
+	require.True(t, os.IsNotExist(err))
+
+	require.NoError(t, mergeToFile(output, files, mergeOptions{}))
+	_, err = os.Stat(output)
+	require.NoError(t, err)
+}
@@ -0,0 +1,118 @@
+package cmds
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/spf13/cobra"
+)
+
+var schemaDiffMode *string
+
+func init() {
+	schemaDiffMode = schemaDiffCmd.PersistentFlags().StringP("mode", "m", "", "Check the compatibility of the schemas, valid values are backward (the new schema can read data written with the old schema), forward (the old schema can read data written with the new schema) and full")
+	rootCmd.AddCommand(schemaDiffCmd)
+}
+
+var schemaDiffCmd = &cobra.Command{
+	Use:   "schema-diff old.parquet new.parquet",
+	Short: "Print the differences between two schemas",
+	Long: `Print the differences between two schemas. Each schema is read from a parquet file or
+from a file containing a textual schema definition.
+
+If a compatibility mode is set, the command fails if the schemas are not compatible.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			_ = cmd.Usage()
+			os.Exit(1)
+		}
+
+		var mode parquetschema.CompatibilityMode
+		if *schemaDiffMode != "" {
+			var err error
+			if mode, err = parseCompatibilityMode(*schemaDiffMode); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		oldSchema, err := readSchemaDefinition(args[0])
+		if err != nil {
+			log.Fatalf("Reading the schema of %s failed: %q", args[0], err)
+		}
+		newSchema, err := readSchemaDefinition(args[1])
+		if err != nil {
+			log.Fatalf("Reading the schema of %s failed: %q", args[1], err)
+		}
+
+		if err := schemaDiff(os.Stdout, oldSchema, newSchema, mode); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func parseCompatibilityMode(in string) (parquetschema.CompatibilityMode, error) {
+	for _, mode := range []parquetschema.CompatibilityMode{parquetschema.BackwardCompatible, parquetschema.ForwardCompatible, parquetschema.FullCompatible} {
+		if mode.String() == in {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid compatibility mode %q", in)
+}
+
+// readSchemaDefinition reads the schema of a parquet file, or parses a textual schema definition
+// if the file is not a parquet file.
+func readSchemaDefinition(file string) (*parquetschema.SchemaDefinition, error) {
+	fl, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fl.Close()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(fl, magic); err == nil && string(magic) == "PAR1" {
+		reader, err := goparquet.NewFileReader(fl)
+		if err != nil {
+			return nil, err
+		}
+		return reader.GetSchemaDefinition(), nil
+	}
+
+	if _, err := fl.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(fl)
+	if err != nil {
+		return nil, err
+	}
+
+	return parquetschema.ParseSchemaDefinition(string(data))
+}
+
+// schemaDiff prints the changes between the old and the new schema. If mode is set, the new schema
+// is checked against the old schema, and an error is returned if they are not compatible.
+func schemaDiff(w io.Writer, oldSchema, newSchema *parquetschema.SchemaDefinition, mode parquetschema.CompatibilityMode) error {
+	for _, change := range parquetschema.Diff(oldSchema, newSchema) {
+		fmt.Fprintln(w, change)
+	}
+
+	if mode == 0 {
+		return nil
+	}
+
+	err := parquetschema.CheckCompatibility(newSchema, oldSchema, mode)
+	var compatErr *parquetschema.CompatibilityError
+	if errors.As(err, &compatErr) {
+		fmt.Fprintf(w, "\nThe schemas are not %s compatible:\n", mode)
+		for _, change := range compatErr.Changes {
+			fmt.Fprintln(w, change)
+		}
+		return fmt.Errorf("the schemas are not %s compatible", mode)
+	}
+	return err
+}
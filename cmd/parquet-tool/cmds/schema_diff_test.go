@@ -0,0 +1,50 @@
+package cmds
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaDiff(t *testing.T) {
+	const oldText = `message test { required int64 id; required binary name (STRING); }`
+
+	dir := t.TempDir()
+	oldFile := filepath.Join(dir, "old.parquet")
+	writeMergeTestFile(t, oldFile, oldText, parquet.CompressionCodec_SNAPPY, 0)
+
+	newFile := filepath.Join(dir, "new.schema")
+	require.NoError(t, ioutil.WriteFile(newFile, []byte(`message test { required int64 id; optional binary name (STRING); required int32 count; }`), 0644))
+
+	oldSchema, err := readSchemaDefinition(oldFile)
+	require.NoError(t, err)
+	expected, err := parquetschema.ParseSchemaDefinition(oldText)
+	require.NoError(t, err)
+	require.Equal(t, expected.String(), oldSchema.String())
+
+	newSchema, err := readSchemaDefinition(newFile)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, schemaDiff(&buf, oldSchema, newSchema, 0))
+	require.Equal(t, "repetition of column name changed from required to optional\ncolumn count added: required int32\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, schemaDiff(&buf, oldSchema, oldSchema, parquetschema.FullCompatible))
+	require.Empty(t, buf.String())
+
+	buf.Reset()
+	require.Error(t, schemaDiff(&buf, oldSchema, newSchema, parquetschema.BackwardCompatible))
+	require.Contains(t, buf.String(), "The schemas are not backward compatible:\ncolumn count added: required int32\n")
+
+	mode, err := parseCompatibilityMode("full")
+	require.NoError(t, err)
+	require.Equal(t, parquetschema.FullCompatible, mode)
+	_, err = parseCompatibilityMode("sideways")
+	require.Error(t, err)
+}
@@ -6,10 +6,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 
 	goparquet "github.com/fraugster/parquet-go"
-	"github.com/fraugster/parquet-go/parquet"
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +22,7 @@ func init() {
 	partSize = splitFile.PersistentFlags().StringP("file-size", "s", "100MB", "The target size of parquet files, it is not the *exact* size on the output")
 	targetFolder = splitFile.PersistentFlags().StringP("target-folder", "t", "", "Target folder to write the files, use the source file folder if it's empty")
 	rowGroupSize = splitFile.PersistentFlags().StringP("row-group-size", "r", "128MB", "Uncompressed row group size")
-	compressionMethod = splitFile.PersistentFlags().StringP("compression", "c", "Snappy", "Compression method, valid values are Snappy, Gzip, None")
+	compressionMethod = splitFile.PersistentFlags().StringP("compression", "c", "Snappy", "Compression method, valid values are Snappy, Gzip, Brotli, Zstd, Lz4_raw, Lz4, None")
 	rootCmd.AddCommand(splitFile)
 }
 
@@ -47,16 +45,9 @@ var splitFile = &cobra.Command{
 			log.Fatalf("Invalid file size: %q", *partSize)
 		}
 
-		comp := parquet.CompressionCodec_UNCOMPRESSED
-		switch strings.ToUpper(*compressionMethod) {
-		case "SNAPPY":
-			comp = parquet.CompressionCodec_SNAPPY
-		case "GZIP":
-			comp = parquet.CompressionCodec_GZIP
-		case "NONE":
-			comp = parquet.CompressionCodec_UNCOMPRESSED
-		default:
-			log.Fatalf("Invalid compression codec: %q", *rowGroupSize)
+		comp, err := parseCompressionCodec(*compressionMethod)
+		if err != nil {
+			log.Fatalf("Invalid compression codec: %q", *compressionMethod)
 		}
 
 		fl, err := os.Open(args[0])
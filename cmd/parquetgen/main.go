@@ -0,0 +1,65 @@
+// Command parquetgen generates MarshalParquet and UnmarshalParquet methods for the struct types
+// of a Go package, so that github.com/fraugster/parquet-go/floor doesn't need reflection to
+// marshal and unmarshal them. It is meant to be used with go generate:
+//
+//	//go:generate go run github.com/fraugster/parquet-go/cmd/parquetgen -type Record
+//
+// The methods are written to <type>_parquet.go, and a test that checks that they round-trip a
+// value like the reflection-based marshalling and unmarshalling of floor is written to
+// <type>_parquet_test.go.
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquetschema/codegen"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct type names; all struct types with parquet struct tags if empty")
+	outputFile := flag.String("output", "", "output file name; default <type>_parquet.go")
+	tests := flag.Bool("tests", true, "generate a test file next to the output file")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 1 {
+		log.Fatalf("Expected at most one package directory")
+	} else if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	var opts codegen.MethodOptions
+	if *typeNames != "" {
+		for _, name := range strings.Split(*typeNames, ",") {
+			opts.TypeNames = append(opts.TypeNames, strings.TrimSpace(name))
+		}
+	}
+
+	src, testSrc, err := codegen.GenerateMethods(dir, opts)
+	if err != nil {
+		log.Fatalf("Generating methods failed: %v", err)
+	}
+
+	if *outputFile == "" {
+		name := "parquet.go"
+		if len(opts.TypeNames) > 0 {
+			name = strings.ToLower(opts.TypeNames[0]) + "_parquet.go"
+		}
+		*outputFile = filepath.Join(dir, name)
+	}
+
+	if err := ioutil.WriteFile(*outputFile, src, 0644); err != nil {
+		log.Fatalf("Writing %s failed: %v", *outputFile, err)
+	}
+
+	if *tests {
+		testFile := strings.TrimSuffix(*outputFile, ".go") + "_test.go"
+		if err := ioutil.WriteFile(testFile, testSrc, 0644); err != nil {
+			log.Fatalf("Writing %s failed: %v", testFile, err)
+		}
+	}
+}
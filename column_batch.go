@@ -0,0 +1,760 @@
+package goparquet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// ColumnBatch holds a batch of values of a single column as returned by (*FileReader).ReadColumnBatch.
+// Only the values slice matching the physical type of the column is filled, and it only contains the
+// values that are not null. Every value of the column, including null values, has a definition level
+// and a repetition level. A value is null if its definition level is lower than the maximum definition
+// level of the column, and a repetition level of 0 starts a new row.
+type ColumnBatch struct {
+	Booleans   []bool
+	Int32s     []int32
+	Int64s     []int64
+	Int96s     [][12]byte
+	Floats     []float32
+	Doubles    []float64
+	ByteArrays [][]byte // used for BYTE_ARRAY and FIXED_LEN_BYTE_ARRAY columns.
+
+	DefinitionLevels []int32
+	RepetitionLevels []int32
+}
+
+// Len returns the number of values in the batch, including null values.
+func (b *ColumnBatch) Len() int {
+	return len(b.DefinitionLevels)
+}
+
+// Reset empties the batch while keeping the allocated slices for reuse.
+func (b *ColumnBatch) Reset() {
+	b.Booleans = b.Booleans[:0]
+	b.Int32s = b.Int32s[:0]
+	b.Int64s = b.Int64s[:0]
+	b.Int96s = b.Int96s[:0]
+	b.Floats = b.Floats[:0]
+	b.Doubles = b.Doubles[:0]
+	b.ByteArrays = b.ByteArrays[:0]
+	b.DefinitionLevels = b.DefinitionLevels[:0]
+	b.RepetitionLevels = b.RepetitionLevels[:0]
+}
+
+func (b *ColumnBatch) appendValues(typ parquet.Type, values []interface{}) error {
+	for _, v := range values {
+		ok := false
+		switch typ {
+		case parquet.Type_BOOLEAN:
+			var x bool
+			if x, ok = v.(bool); ok {
+				b.Booleans = append(b.Booleans, x)
+			}
+		case parquet.Type_INT32:
+			var x int32
+			if x, ok = v.(int32); ok {
+				b.Int32s = append(b.Int32s, x)
+			}
+		case parquet.Type_INT64:
+			var x int64
+			if x, ok = v.(int64); ok {
+				b.Int64s = append(b.Int64s, x)
+			}
+		case parquet.Type_INT96:
+			var x [12]byte
+			if x, ok = v.([12]byte); ok {
+				b.Int96s = append(b.Int96s, x)
+			}
+		case parquet.Type_FLOAT:
+			var x float32
+			if x, ok = v.(float32); ok {
+				b.Floats = append(b.Floats, x)
+			}
+		case parquet.Type_DOUBLE:
+			var x float64
+			if x, ok = v.(float64); ok {
+				b.Doubles = append(b.Doubles, x)
+			}
+		case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+			var x []byte
+			if x, ok = v.([]byte); ok {
+				b.ByteArrays = append(b.ByteArrays, x)
+			}
+		}
+		if !ok {
+			return fmt.Errorf("unexpected value of type %T for %s column", v, typ)
+		}
+	}
+	return nil
+}
+
+// decodeValues decodes n values of the physical type typ using dec, and appends them to the slice
+// of b matching typ. The values are decoded directly into the slice if dec supports it. It returns
+// the number of decoded values.
+func (b *ColumnBatch) decodeValues(dec valuesDecoder, typ parquet.Type, n int) (int, error) {
+	var (
+		read int
+		err  error
+	)
+
+	ok := false
+	switch typ {
+	case parquet.Type_BOOLEAN:
+		var d booleanValuesDecoder
+		if d, ok = dec.(booleanValuesDecoder); ok {
+			b.Booleans, read, err = decodeInto(b.Booleans, n, d.decodeBooleans)
+		}
+	case parquet.Type_INT32:
+		var d int32ValuesDecoder
+		if d, ok = dec.(int32ValuesDecoder); ok {
+			b.Int32s, read, err = decodeInto(b.Int32s, n, d.decodeInt32s)
+		}
+	case parquet.Type_INT64:
+		var d int64ValuesDecoder
+		if d, ok = dec.(int64ValuesDecoder); ok {
+			b.Int64s, read, err = decodeInto(b.Int64s, n, d.decodeInt64s)
+		}
+	case parquet.Type_INT96:
+		var d int96ValuesDecoder
+		if d, ok = dec.(int96ValuesDecoder); ok {
+			b.Int96s, read, err = decodeInto(b.Int96s, n, d.decodeInt96s)
+		}
+	case parquet.Type_FLOAT:
+		var d floatValuesDecoder
+		if d, ok = dec.(floatValuesDecoder); ok {
+			b.Floats, read, err = decodeInto(b.Floats, n, d.decodeFloats)
+		}
+	case parquet.Type_DOUBLE:
+		var d doubleValuesDecoder
+		if d, ok = dec.(doubleValuesDecoder); ok {
+			b.Doubles, read, err = decodeInto(b.Doubles, n, d.decodeDoubles)
+		}
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		var d byteArrayValuesDecoder
+		if d, ok = dec.(byteArrayValuesDecoder); ok {
+			b.ByteArrays, read, err = decodeInto(b.ByteArrays, n, d.decodeByteArrays)
+		}
+	}
+	if ok {
+		return read, err
+	}
+
+	values := make([]interface{}, n)
+	if read, err = dec.decodeValues(values); err != nil {
+		return read, err
+	}
+	return read, b.appendValues(typ, values)
+}
+
+// decodeInto decodes n values using decode and appends them to values.
+func decodeInto[T any](values []T, n int, decode func([]T) (int, error)) ([]T, int, error) {
+	start := len(values)
+	if cap(values)-start < n {
+		values = append(values, make([]T, n)...)
+	}
+	values = values[:start+n]
+
+	read, err := decode(values[start:])
+	return values[:start+read], read, err
+}
+
+func (b *ColumnBatch) appendLevels(dLevels, rLevels *packedArray) error {
+	for i := 0; i < dLevels.count; i++ {
+		d, err := dLevels.at(i)
+		if err != nil {
+			return err
+		}
+		r, err := rLevels.at(i)
+		if err != nil {
+			return err
+		}
+		b.DefinitionLevels = append(b.DefinitionLevels, d)
+		b.RepetitionLevels = append(b.RepetitionLevels, r)
+	}
+	return nil
+}
+
+// columnBatchReader reads the values of a single column across all row groups, one page at a time.
+type columnBatchReader struct {
+	col *Column
+
+	// the index of the next row group to read.
+	rowGroup int
+
+	pages         *chunkPageReader
+	page          pageReader
+	pageRemaining int
+}
+
+// ReadColumnBatch reads up to n values of the column identified by path into batch, and returns the
+// number of values that were read, including null values. The values are appended to the slices of
+// batch, so call Reset to reuse a batch for the next call. It returns io.EOF if all values of the
+// column have been read. Reading column batches is independent of reading rows using NextRow, and
+// only keeps the current page of each column in memory. Row groups that are skipped by the row group
+// filter are skipped as well.
+func (f *FileReader) ReadColumnBatch(path ColumnPath, n int, batch *ColumnBatch) (int, error) {
+	return f.ReadColumnBatchWithContext(f.ctx, path, n, batch)
+}
+
+// ReadColumnBatchWithContext reads up to n values of the column identified by path into batch, and
+// returns the number of values that were read, including null values. See ReadColumnBatch for details.
+func (f *FileReader) ReadColumnBatchWithContext(ctx context.Context, path ColumnPath, n int, batch *ColumnBatch) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid batch size %d", n)
+	}
+	if batch == nil {
+		return 0, errors.New("batch is nil")
+	}
+
+	cr, err := f.columnBatchReader(path)
+	if err != nil {
+		return 0, err
+	}
+
+	typ := *cr.col.Element().Type
+
+	read := 0
+	for read < n {
+		if cr.pageRemaining == 0 {
+			ok, err := f.nextColumnBatchPage(ctx, cr)
+			if err != nil {
+				return read, err
+			}
+			if !ok {
+				break
+			}
+			continue
+		}
+
+		size := n - read
+		if size > cr.pageRemaining {
+			size = cr.pageRemaining
+		}
+
+		dLevels, rLevels, err := cr.page.readBatch(size, typ, batch)
+		if err != nil {
+			return read, fmt.Errorf("column %q: %w", path.flatName(), err)
+		}
+		if dLevels == nil || dLevels.count != size {
+			return read, fmt.Errorf("column %q: expected %d values in page", path.flatName(), size)
+		}
+		if err := batch.appendLevels(dLevels, rLevels); err != nil {
+			return read, fmt.Errorf("column %q: %w", path.flatName(), err)
+		}
+
+		cr.pageRemaining -= size
+		read += size
+	}
+
+	if read == 0 {
+		return 0, io.EOF
+	}
+
+	return read, nil
+}
+
+func (f *FileReader) columnBatchReader(path ColumnPath) (*columnBatchReader, error) {
+	key := path.flatName()
+	if cr, ok := f.columnBatchReaders[key]; ok {
+		return cr, nil
+	}
+
+	col := f.schemaReader.GetColumnByPath(path)
+	if col == nil || !col.DataColumn() {
+		return nil, fmt.Errorf("column %q is not a data column", key)
+	}
+
+	cr := &columnBatchReader{col: col}
+	if f.columnBatchReaders == nil {
+		f.columnBatchReaders = make(map[string]*columnBatchReader)
+	}
+	f.columnBatchReaders[key] = cr
+
+	return cr, nil
+}
+
+// nextColumnBatchPage reads the next data page of the column, continuing with the next row group
+// if all pages of the current column chunk have been read. It returns false if all pages of the
+// column have been read.
+func (f *FileReader) nextColumnBatchPage(ctx context.Context, cr *columnBatchReader) (bool, error) {
+	for {
+		if cr.pages != nil {
+			p, err := cr.pages.nextPage()
+			if err == io.EOF {
+				cr.pages = nil
+				continue
+			}
+			if err != nil {
+				return false, fmt.Errorf("column %q: %w", cr.col.FlatName(), err)
+			}
+			cr.page, cr.pageRemaining = p, int(p.numValues())
+			return true, nil
+		}
+
+		for cr.rowGroup < len(f.meta.RowGroups) && f.rowGroupFilter != nil && f.rowGroupFilter.canSkip(f.chunkStats(cr.rowGroup)) {
+			cr.rowGroup++
+		}
+		if cr.rowGroup >= len(f.meta.RowGroups) {
+			return false, nil
+		}
+
+		rowGroup := cr.rowGroup
+		cr.rowGroup++
+
+		idx := cr.col.Index()
+		if idx >= len(f.meta.RowGroups[rowGroup].Columns) {
+			return false, fmt.Errorf("column index %d is out of bounds", idx)
+		}
+		chunk := f.meta.RowGroups[rowGroup].Columns[idx]
+
+		cc, err := f.readOptions.decryptor.chunkCipher(rowGroup, idx, chunk)
+		if err != nil {
+			return false, fmt.Errorf("column %q: %w", cr.col.FlatName(), err)
+		}
+
+		var r io.ReadSeeker = f.reader
+		if f.readOptions.rangeReader != nil {
+			r = io.NewSectionReader(f.readOptions.rangeReader, 0, math.MaxInt64)
+		}
+
+		pages, err := newChunkPageReader(ctx, f.schemaReader, r, cr.col, chunk, cc)
+		if err != nil {
+			return false, fmt.Errorf("column %q: %w", cr.col.FlatName(), err)
+		}
+		// the reader is shared with rows and other columns that are read in between.
+		pages.shared = true
+		cr.pages = pages
+	}
+}
+
+// WriteColumnBatch adds a batch of values of the column identified by path to the current row group.
+// values needs to be a slice matching the physical type of the column, i.e. []bool, []int32, []int64,
+// [][12]byte, []float32, []float64 or [][]byte, and only contains the values that are not null.
+// dLevels and rLevels contain the definition and repetition level of every value including null values,
+// like in a ColumnBatch. They can be nil if the maximum definition or repetition level of the column is 0.
+// A row group can either be written using column batches or using AddData, and the row group flush size
+// doesn't apply to column batches. Before the row group is flushed, all columns are checked to contain
+// the same number of rows. The values are not copied, so the slices, including the byte slices of
+// [][]byte values, must not be changed until the row group is flushed.
+func (fw *FileWriter) WriteColumnBatch(path ColumnPath, values interface{}, dLevels, rLevels []int32) error {
+	if fw.err != nil {
+		return fw.err
+	}
+	if len(fw.sortingColumns) > 0 || fw.globalSort != nil {
+		return errors.New("column batches can't be written when sorting rows")
+	}
+	return fw.schemaWriter.addColumnBatch(path, values, dLevels, rLevels)
+}
+
+func (r *schema) addColumnBatch(path ColumnPath, values interface{}, dLevels, rLevels []int32) error {
+	if r.numRecords > 0 && !r.columnBatches {
+		return errors.New("can't write column batches to a row group that contains data added using AddData")
+	}
+
+	col := r.GetColumnByPath(path)
+	if col == nil || !col.DataColumn() {
+		return fmt.Errorf("column %q is not a data column", path.flatName())
+	}
+
+	if err := col.data.addBatch(col, values, dLevels, rLevels); err != nil {
+		return fmt.Errorf("column %q: %w", path.flatName(), err)
+	}
+
+	r.readOnly = 1
+	r.ensureRoot()
+	r.columnBatches = true
+	return nil
+}
+
+// finishColumnBatches checks that all columns of a row group that was written using column batches
+// contain the same number of rows, and sets the number of records of the row group.
+func (r *schema) finishColumnBatches() error {
+	if !r.columnBatches {
+		return nil
+	}
+
+	cols := r.Columns()
+	numRecords := cols[0].data.batchNumRecords
+	for _, col := range cols[1:] {
+		if n := col.data.batchNumRecords; n != numRecords {
+			return fmt.Errorf("column %q contains %d rows, but column %q contains %d rows", col.FlatName(), n, cols[0].FlatName(), numRecords)
+		}
+	}
+
+	r.numRecords = numRecords
+	return nil
+}
+
+func (cs *ColumnStore) addBatch(col *Column, values interface{}, dLevels, rLevels []int32) error {
+	maxD, maxR := int32(col.MaxDefinitionLevel()), int32(col.MaxRepetitionLevel())
+
+	batch := cs.batch
+	if batch == nil {
+		batch = newBatchValues(*col.Element().Type)
+	}
+
+	numValues, err := batch.setBatch(values)
+	if err != nil {
+		return err
+	}
+
+	numLevels := numValues
+	if dLevels != nil {
+		numLevels = len(dLevels)
+	} else if maxD > 0 {
+		return errors.New("definition levels are required for optional and repeated columns")
+	}
+	if rLevels == nil && maxR > 0 {
+		return errors.New("repetition levels are required for repeated columns")
+	}
+	if rLevels != nil && len(rLevels) != numLevels {
+		return fmt.Errorf("got %d repetition levels for %d definition levels", len(rLevels), numLevels)
+	}
+
+	defined := 0
+	for i := 0; i < numLevels; i++ {
+		d, r := batchLevel(dLevels, i, maxD), batchLevel(rLevels, i, 0)
+		if d < 0 || d > maxD {
+			return fmt.Errorf("definition level %d of value %d is out of range [0, %d]", d, i, maxD)
+		}
+		if r < 0 || r > maxR {
+			return fmt.Errorf("repetition level %d of value %d is out of range [0, %d]", r, i, maxR)
+		}
+		if r > 0 && i == 0 && cs.rLevels.count == 0 {
+			return errors.New("the first value of a row group needs to start a new row with repetition level 0")
+		}
+		if d == maxD {
+			defined++
+		}
+	}
+	if defined != numValues {
+		return fmt.Errorf("got %d values for %d values that are not null", numValues, defined)
+	}
+
+	if err := setBatchMinMax(cs.typedColumnStore, values); err != nil {
+		return err
+	}
+
+	cs.batch = batch
+
+	next := 0
+	for i := 0; i < numLevels; i++ {
+		d, r := batchLevel(dLevels, i, maxD), batchLevel(rLevels, i, 0)
+		if r == 0 {
+			// pages are only flushed at row boundaries, so that every row is contained in a single page.
+			if cs.estimateSize() >= cs.getMaxPageSize() {
+				if err := cs.flushPage(cs.batchNumRecords, true); err != nil {
+					return err
+				}
+			}
+			cs.batchNumRecords++
+		}
+
+		if d == maxD {
+			batch.add(next)
+			next++
+		} else {
+			cs.values.addValue(nil, 0)
+		}
+		cs.appendRDLevel(uint16(r), uint16(d))
+	}
+
+	return nil
+}
+
+// batchLevel returns the level at position i, or def if no levels were provided.
+func batchLevel(levels []int32, i int, def int32) int32 {
+	if levels == nil {
+		return def
+	}
+	return levels[i]
+}
+
+// setBatchMinMax updates the statistics of the typed column store s with the values of a column
+// batch, which match the physical type of the column.
+func setBatchMinMax(s typedColumnStore, values interface{}) error {
+	switch s := s.(type) {
+	case *int32Store:
+		for _, v := range values.([]int32) {
+			s.setMinMax(v)
+		}
+	case *int64Store:
+		for _, v := range values.([]int64) {
+			s.setMinMax(v)
+		}
+	case *floatStore:
+		for _, v := range values.([]float32) {
+			s.setMinMax(v)
+		}
+	case *doubleStore:
+		for _, v := range values.([]float64) {
+			s.setMinMax(v)
+		}
+	case *int96Store:
+		typed := values.([][12]byte)
+		for i := range typed {
+			if err := s.setMinMax(typed[i][:]); err != nil {
+				return err
+			}
+		}
+	case *byteArrayStore:
+		for _, v := range values.([][]byte) {
+			if err := s.setMinMax(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// batchValues buffers the values of a page that are added using column batches in a slice of their
+// physical type, so that they aren't stored in interface{} values like the values added using AddData.
+type batchValues interface {
+	// setBatch sets the slice of values of the batch that is added, and returns its length or an
+	// error if it doesn't match the physical type of the column.
+	setBatch(values interface{}) (int, error)
+	// add adds the value at position i of the batch.
+	add(i int)
+
+	numValues() int
+	distinctValueCount() int64
+	sizes() (dictLen int64, noDictLen int64)
+
+	// flush returns the slice of the values of the page and their size, and starts a new page.
+	flush() (interface{}, int64)
+	// dictionary returns the distinct values of the pages of the column chunk, and sets the
+	// dictionary indexes of the values of every page.
+	dictionary(pages []*dataPage) []interface{}
+}
+
+func newBatchValues(typ parquet.Type) batchValues {
+	switch typ {
+	case parquet.Type_BOOLEAN:
+		// like the boolean store, the size is 0 so that booleans never use a dictionary.
+		return newTypedBatchValues(typ, func(bool) int { return 0 }, newDistinctKeys(func(v bool) bool { return v }))
+	case parquet.Type_INT32:
+		return newTypedBatchValues(typ, func(int32) int { return 4 }, newDistinctKeys(func(v int32) int32 { return v }))
+	case parquet.Type_INT64:
+		return newTypedBatchValues(typ, func(int64) int { return 8 }, newDistinctKeys(func(v int64) int64 { return v }))
+	case parquet.Type_INT96:
+		return newTypedBatchValues(typ, func([12]byte) int { return 12 }, newDistinctKeys(func(v [12]byte) [12]byte { return v }))
+	case parquet.Type_FLOAT:
+		return newTypedBatchValues(typ, func(float32) int { return 4 }, newDistinctKeys(math.Float32bits))
+	case parquet.Type_DOUBLE:
+		return newTypedBatchValues(typ, func(float64) int { return 8 }, newDistinctKeys(math.Float64bits))
+	default:
+		return newTypedBatchValues(typ, func(v []byte) int { return len(v) }, newDistinctByteArrays)
+	}
+}
+
+type typedBatchValues[T any] struct {
+	typ         parquet.Type
+	sizeOf      func(T) int
+	newDistinct func() distinctValues[T]
+
+	batch            []T
+	values           []T
+	distinct         distinctValues[T]
+	uniqueValuesSize int64
+	allValuesSize    int64
+}
+
+func newTypedBatchValues[T any](typ parquet.Type, sizeOf func(T) int, newDistinct func() distinctValues[T]) *typedBatchValues[T] {
+	return &typedBatchValues[T]{
+		typ:         typ,
+		sizeOf:      sizeOf,
+		newDistinct: newDistinct,
+		distinct:    newDistinct(),
+	}
+}
+
+func (b *typedBatchValues[T]) setBatch(values interface{}) (int, error) {
+	typed, ok := values.([]T)
+	if !ok {
+		return 0, fmt.Errorf("unsupported values of type %T for %s column", values, b.typ)
+	}
+	b.batch = typed
+	return len(typed), nil
+}
+
+func (b *typedBatchValues[T]) add(i int) {
+	v := b.batch[i]
+	size := int64(b.sizeOf(v))
+	if _, added := b.distinct.index(v); added {
+		b.uniqueValuesSize += size
+	}
+	b.allValuesSize += size
+	b.values = append(b.values, v)
+}
+
+func (b *typedBatchValues[T]) numValues() int {
+	return len(b.values)
+}
+
+func (b *typedBatchValues[T]) distinctValueCount() int64 {
+	return int64(b.distinct.len())
+}
+
+func (b *typedBatchValues[T]) sizes() (dictLen int64, noDictLen int64) {
+	return b.uniqueValuesSize + int64(4*len(b.values)), b.allValuesSize
+}
+
+func (b *typedBatchValues[T]) flush() (interface{}, int64) {
+	values, size := b.values, b.allValuesSize
+
+	b.values = nil
+	b.distinct = b.newDistinct()
+	b.uniqueValuesSize = 0
+	b.allValuesSize = 0
+
+	return values, size
+}
+
+func (b *typedBatchValues[T]) dictionary(pages []*dataPage) []interface{} {
+	dictValues := []interface{}{}
+	distinct := b.newDistinct()
+
+	for _, page := range pages {
+		values, _ := page.batchValues.([]T)
+		page.dictIndices = make([]int32, len(values))
+		for i, v := range values {
+			idx, added := distinct.index(v)
+			if added {
+				dictValues = append(dictValues, v)
+			}
+			page.dictIndices[i] = idx
+		}
+	}
+
+	return dictValues
+}
+
+// distinctValues assigns consecutive indexes to the distinct values of type T.
+type distinctValues[T any] interface {
+	// index returns the index of v, and true if v wasn't seen before.
+	index(v T) (int32, bool)
+	len() int
+}
+
+// distinctKeys finds distinct values using a key of a comparable type.
+type distinctKeys[T any, K comparable] struct {
+	key     func(T) K
+	indices map[K]int32
+}
+
+func newDistinctKeys[T any, K comparable](key func(T) K) func() distinctValues[T] {
+	return func() distinctValues[T] {
+		return &distinctKeys[T, K]{key: key, indices: make(map[K]int32)}
+	}
+}
+
+func (d *distinctKeys[T, K]) index(v T) (int32, bool) {
+	k := d.key(v)
+	if idx, ok := d.indices[k]; ok {
+		return idx, false
+	}
+	idx := int32(len(d.indices))
+	d.indices[k] = idx
+	return idx, true
+}
+
+func (d *distinctKeys[T, K]) len() int {
+	return len(d.indices)
+}
+
+// distinctByteArrays finds distinct byte arrays. It doesn't use distinctKeys, so that looking up a
+// byte array doesn't need to convert it to a string.
+type distinctByteArrays struct {
+	indices map[string]int32
+}
+
+func newDistinctByteArrays() distinctValues[[]byte] {
+	return &distinctByteArrays{indices: make(map[string]int32)}
+}
+
+func (d *distinctByteArrays) index(v []byte) (int32, bool) {
+	if idx, ok := d.indices[string(v)]; ok {
+		return idx, false
+	}
+	idx := int32(len(d.indices))
+	d.indices[string(v)] = idx
+	return idx, true
+}
+
+func (d *distinctByteArrays) len() int {
+	return len(d.indices)
+}
+
+// encodePageValues encodes the values of page using enc.
+func encodePageValues(w io.Writer, enc valuesEncoder, page *dataPage) error {
+	if page.batchValues == nil {
+		return encodeValue(w, enc, page.values)
+	}
+
+	if err := enc.init(w); err != nil {
+		return err
+	}
+
+	if d, ok := enc.(*dictEncoder); ok {
+		d.indices = append(d.indices, page.dictIndices...)
+	} else if err := encodeBatchValues(enc, page.batchValues); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// encodeBatchValues encodes the values of a page that were added using column batches, which are a
+// slice of the physical type of the column.
+func encodeBatchValues(enc valuesEncoder, values interface{}) error {
+	ok := false
+	var err error
+
+	switch values := values.(type) {
+	case []bool:
+		var e booleanValuesEncoder
+		if e, ok = enc.(booleanValuesEncoder); ok {
+			err = e.encodeBooleans(values)
+		}
+	case []int32:
+		var e int32ValuesEncoder
+		if e, ok = enc.(int32ValuesEncoder); ok {
+			err = e.encodeInt32s(values)
+		}
+	case []int64:
+		var e int64ValuesEncoder
+		if e, ok = enc.(int64ValuesEncoder); ok {
+			err = e.encodeInt64s(values)
+		}
+	case [][12]byte:
+		var e int96ValuesEncoder
+		if e, ok = enc.(int96ValuesEncoder); ok {
+			err = e.encodeInt96s(values)
+		}
+	case []float32:
+		var e floatValuesEncoder
+		if e, ok = enc.(floatValuesEncoder); ok {
+			err = e.encodeFloats(values)
+		}
+	case []float64:
+		var e doubleValuesEncoder
+		if e, ok = enc.(doubleValuesEncoder); ok {
+			err = e.encodeDoubles(values)
+		}
+	case [][]byte:
+		var e byteArrayValuesEncoder
+		if e, ok = enc.(byteArrayValuesEncoder); ok {
+			err = e.encodeByteArrays(values)
+		}
+	}
+
+	if !ok {
+		return fmt.Errorf("encoder %T doesn't support values of type %T", enc, values)
+	}
+	return err
+}
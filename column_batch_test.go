@@ -0,0 +1,428 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func readColumnBatches(t *testing.T, r *FileReader, path ColumnPath, n int) *ColumnBatch {
+	all := &ColumnBatch{}
+	batch := &ColumnBatch{}
+	for {
+		batch.Reset()
+		read, err := r.ReadColumnBatch(path, n, batch)
+		if err == io.EOF {
+			require.Equal(t, 0, read)
+			return all
+		}
+		require.NoError(t, err)
+		require.Equal(t, read, batch.Len())
+		require.LessOrEqual(t, read, n)
+
+		all.Booleans = append(all.Booleans, batch.Booleans...)
+		all.Int32s = append(all.Int32s, batch.Int32s...)
+		all.Int64s = append(all.Int64s, batch.Int64s...)
+		all.Int96s = append(all.Int96s, batch.Int96s...)
+		all.Floats = append(all.Floats, batch.Floats...)
+		all.Doubles = append(all.Doubles, batch.Doubles...)
+		all.ByteArrays = append(all.ByteArrays, batch.ByteArrays...)
+		all.DefinitionLevels = append(all.DefinitionLevels, batch.DefinitionLevels...)
+		all.RepetitionLevels = append(all.RepetitionLevels, batch.RepetitionLevels...)
+	}
+}
+
+func TestReadColumnBatch(t *testing.T) {
+	testData := []struct {
+		name string
+		opts []FileWriterOption
+	}{
+		{"v1", nil},
+		{"v2", []FileWriterOption{WithDataPageV2()}},
+		{"encrypted", []FileWriterOption{WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer")))}},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			data := writeEncryptionTestFile(t, tt.opts...)
+
+			r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(testKeys))
+			require.NoError(t, err)
+
+			ids := readColumnBatches(t, r, ColumnPath{"id"}, 97)
+			require.Len(t, ids.Int64s, 1000)
+			require.Len(t, ids.DefinitionLevels, 1000)
+			for i, id := range ids.Int64s {
+				require.Equal(t, int64(i), id)
+				require.Equal(t, int32(0), ids.DefinitionLevels[i])
+				require.Equal(t, int32(0), ids.RepetitionLevels[i])
+			}
+
+			// reading rows doesn't interfere with reading column batches.
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, int64(0), row["id"])
+
+			names := readColumnBatches(t, r, ColumnPath{"name"}, 1000)
+			require.Len(t, names.DefinitionLevels, 1000)
+			var expectedNames [][]byte
+			for i, d := range names.DefinitionLevels {
+				if i%3 == 0 {
+					require.Equal(t, int32(0), d, "value %d", i)
+					continue
+				}
+				require.Equal(t, int32(1), d, "value %d", i)
+				expectedNames = append(expectedNames, []byte(fmt.Sprintf("name-%d", i%50)))
+			}
+			require.Equal(t, expectedNames, names.ByteArrays)
+
+			values := readColumnBatches(t, r, ColumnPath{"values"}, 7)
+			require.Len(t, values.Int32s, 2000)
+			for i := 0; i < 1000; i++ {
+				require.Equal(t, []int32{int32(i), int32(i % 7)}, values.Int32s[2*i:2*i+2])
+				require.Equal(t, []int32{0, 1}, values.RepetitionLevels[2*i:2*i+2])
+				require.Equal(t, []int32{1, 1}, values.DefinitionLevels[2*i:2*i+2])
+			}
+
+			row, err = r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, int64(1), row["id"])
+		})
+	}
+}
+
+// columnBatchTypesTestData are the encodings used to test column batches of all physical types.
+var columnBatchTypesTestData = []struct {
+	name string
+	opts []FileWriterOption
+}{
+	{"plain", []FileWriterOption{WithColumnEncoding(ColumnPath{"bool"}, parquet.Encoding_PLAIN, false)}},
+	{"dict", []FileWriterOption{
+		WithColumnEncoding(ColumnPath{"i32"}, parquet.Encoding_PLAIN, true),
+		WithColumnEncoding(ColumnPath{"i64"}, parquet.Encoding_PLAIN, true),
+		WithColumnEncoding(ColumnPath{"i96"}, parquet.Encoding_PLAIN, true),
+		WithColumnEncoding(ColumnPath{"f32"}, parquet.Encoding_PLAIN, true),
+		WithColumnEncoding(ColumnPath{"f64"}, parquet.Encoding_PLAIN, true),
+		WithColumnEncoding(ColumnPath{"str"}, parquet.Encoding_PLAIN, true),
+		WithColumnEncoding(ColumnPath{"flba"}, parquet.Encoding_PLAIN, true),
+	}},
+	{"encoded", []FileWriterOption{
+		WithColumnEncoding(ColumnPath{"bool"}, parquet.Encoding_RLE, false),
+		WithColumnEncoding(ColumnPath{"i32"}, parquet.Encoding_DELTA_BINARY_PACKED, false),
+		WithColumnEncoding(ColumnPath{"i64"}, parquet.Encoding_DELTA_BINARY_PACKED, false),
+		WithColumnEncoding(ColumnPath{"f32"}, parquet.Encoding_BYTE_STREAM_SPLIT, false),
+		WithColumnEncoding(ColumnPath{"f64"}, parquet.Encoding_BYTE_STREAM_SPLIT, false),
+		WithColumnEncoding(ColumnPath{"str"}, parquet.Encoding_DELTA_BYTE_ARRAY, false),
+		WithColumnEncoding(ColumnPath{"flba"}, parquet.Encoding_BYTE_STREAM_SPLIT, false),
+	}},
+	{"delta_length", []FileWriterOption{
+		WithColumnEncoding(ColumnPath{"str"}, parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY, false),
+	}},
+}
+
+// columnBatchTypesSchema is the schema of a file with columns of all physical types.
+const columnBatchTypesSchema = `message test {
+	required boolean bool;
+	required int32 i32;
+	required int64 i64;
+	optional int96 i96;
+	required float f32;
+	required double f64;
+	optional binary str (STRING);
+	required fixed_len_byte_array(3) flba;
+}`
+
+// columnBatchTypesRows returns the rows of a file with the schema columnBatchTypesSchema.
+func columnBatchTypesRows() []map[string]interface{} {
+	var rows []map[string]interface{}
+	for i := 0; i < 300; i++ {
+		row := map[string]interface{}{
+			"bool": i%3 == 0,
+			"i32":  int32(i % 17),
+			"i64":  int64(i * 1000),
+			"f32":  float32(i%13) / 2,
+			"f64":  float64(i) / 4,
+			"flba": []byte{byte(i), byte(i % 5), 'x'},
+		}
+		if i%4 != 0 {
+			row["i96"] = [12]byte{byte(i % 7)}
+			row["str"] = []byte(fmt.Sprintf("value-%d", i%11))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestReadColumnBatchTypes(t *testing.T) {
+	for _, tt := range columnBatchTypesTestData {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := columnBatchTypesRows()
+
+			expected := &ColumnBatch{}
+			for _, row := range rows {
+				expected.Booleans = append(expected.Booleans, row["bool"].(bool))
+				expected.Int32s = append(expected.Int32s, row["i32"].(int32))
+				expected.Int64s = append(expected.Int64s, row["i64"].(int64))
+				expected.Floats = append(expected.Floats, row["f32"].(float32))
+				expected.Doubles = append(expected.Doubles, row["f64"].(float64))
+				expected.ByteArrays = append(expected.ByteArrays, row["flba"].([]byte))
+				if v, ok := row["i96"]; ok {
+					expected.Int96s = append(expected.Int96s, v.([12]byte))
+				}
+			}
+
+			data := writeTestFile(t, columnBatchTypesSchema, 1, len(rows), func(_, i int) map[string]interface{} {
+				return rows[i]
+			}, append([]FileWriterOption{WithMaxPageSize(256)}, tt.opts...)...)
+
+			r, err := NewFileReader(bytes.NewReader(data))
+			require.NoError(t, err)
+
+			require.Equal(t, expected.Booleans, readColumnBatches(t, r, ColumnPath{"bool"}, 64).Booleans)
+			require.Equal(t, expected.Int32s, readColumnBatches(t, r, ColumnPath{"i32"}, 64).Int32s)
+			require.Equal(t, expected.Int64s, readColumnBatches(t, r, ColumnPath{"i64"}, 64).Int64s)
+			require.Equal(t, expected.Int96s, readColumnBatches(t, r, ColumnPath{"i96"}, 64).Int96s)
+			require.Equal(t, expected.Floats, readColumnBatches(t, r, ColumnPath{"f32"}, 64).Floats)
+			require.Equal(t, expected.Doubles, readColumnBatches(t, r, ColumnPath{"f64"}, 64).Doubles)
+			require.Equal(t, expected.ByteArrays, readColumnBatches(t, r, ColumnPath{"flba"}, 64).ByteArrays)
+			require.Len(t, readColumnBatches(t, r, ColumnPath{"str"}, 64).ByteArrays, 225)
+		})
+	}
+}
+
+// TestValuesDecodersDecodeTypedValues checks that all values decoders can decode values into
+// typed slices, so that ReadColumnBatch doesn't need to store them in interface{} values.
+func TestValuesDecodersDecodeTypedValues(t *testing.T) {
+	length := int32(4)
+	for typ := parquet.Type_BOOLEAN; typ <= parquet.Type_FIXED_LEN_BYTE_ARRAY; typ++ {
+		for enc := parquet.Encoding_PLAIN; enc <= parquet.Encoding_BYTE_STREAM_SPLIT; enc++ {
+			dec, err := getValuesDecoder(enc, &parquet.SchemaElement{Type: parquet.TypePtr(typ), TypeLength: &length}, nil)
+			if err != nil {
+				continue
+			}
+
+			var ok bool
+			switch typ {
+			case parquet.Type_BOOLEAN:
+				_, ok = dec.(booleanValuesDecoder)
+			case parquet.Type_INT32:
+				_, ok = dec.(int32ValuesDecoder)
+			case parquet.Type_INT64:
+				_, ok = dec.(int64ValuesDecoder)
+			case parquet.Type_INT96:
+				_, ok = dec.(int96ValuesDecoder)
+			case parquet.Type_FLOAT:
+				_, ok = dec.(floatValuesDecoder)
+			case parquet.Type_DOUBLE:
+				_, ok = dec.(doubleValuesDecoder)
+			case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+				_, ok = dec.(byteArrayValuesDecoder)
+			}
+			require.True(t, ok, "%T for %s with encoding %s", dec, typ, enc)
+		}
+	}
+}
+
+func TestReadColumnBatchReusesBuffers(t *testing.T) {
+	data := writeEncryptionTestFile(t)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithRowGroupFilter(GtEq(ColumnPath{"id"}, int64(500))))
+	require.NoError(t, err)
+
+	batch := &ColumnBatch{Int64s: make([]int64, 0, 100)}
+	buf := batch.Int64s[:1]
+
+	n, err := r.ReadColumnBatch(ColumnPath{"id"}, 100, batch)
+	require.NoError(t, err)
+	require.Equal(t, 100, n)
+	require.Equal(t, int64(500), batch.Int64s[0], "expected the first row group to be skipped")
+	require.Equal(t, int64(500), buf[0], "expected the buffer of the batch to be reused")
+
+	batch.Reset()
+	n, err = r.ReadColumnBatch(ColumnPath{"id"}, 100, batch)
+	require.NoError(t, err)
+	require.Equal(t, 100, n)
+	require.Equal(t, int64(600), buf[0])
+
+	_, err = r.ReadColumnBatch(ColumnPath{"id"}, 0, batch)
+	require.Error(t, err)
+
+	_, err = r.ReadColumnBatch(ColumnPath{"missing"}, 10, batch)
+	require.Error(t, err)
+
+	_, err = r.ReadColumnBatch(ColumnPath{"id"}, 10, nil)
+	require.Error(t, err)
+}
+
+func TestWriteColumnBatch(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		repeated int32 values;
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	wr := NewFileWriter(&buf, WithSchemaDefinition(sd), WithMaxPageSize(512))
+
+	for rg := 0; rg < 2; rg++ {
+		for start := rg * 500; start < (rg+1)*500; start += 100 {
+			var (
+				ids                          []int64
+				names                        [][]byte
+				nameLevels                   []int32
+				values                       []int32
+				valuesDLevels, valuesRLevels []int32
+			)
+			for id := start; id < start+100; id++ {
+				ids = append(ids, int64(id))
+				if id%3 != 0 {
+					names = append(names, []byte(fmt.Sprintf("name-%d", id%50)))
+					nameLevels = append(nameLevels, 1)
+				} else {
+					nameLevels = append(nameLevels, 0)
+				}
+				values = append(values, int32(id), int32(id%7))
+				valuesDLevels = append(valuesDLevels, 1, 1)
+				valuesRLevels = append(valuesRLevels, 0, 1)
+			}
+
+			require.NoError(t, wr.WriteColumnBatch(ColumnPath{"id"}, ids, nil, nil))
+			require.NoError(t, wr.WriteColumnBatch(ColumnPath{"name"}, names, nameLevels, nil))
+			require.NoError(t, wr.WriteColumnBatch(ColumnPath{"values"}, values, valuesDLevels, valuesRLevels))
+		}
+		require.NoError(t, wr.FlushRowGroup())
+	}
+	require.NoError(t, wr.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), r.NumRows())
+	requireEncryptionTestRows(t, r)
+
+	offsetIdx, err := r.ReadOffsetIndex(0, ColumnPath{"values"})
+	require.NoError(t, err)
+	require.Greater(t, len(offsetIdx.PageLocations), 1)
+	for i := 1; i < len(offsetIdx.PageLocations); i++ {
+		require.Greater(t, offsetIdx.PageLocations[i].FirstRowIndex, offsetIdx.PageLocations[i-1].FirstRowIndex)
+	}
+}
+
+// TestWriteColumnBatchTypes checks that writing the values of all physical types using column
+// batches results in the same data, encodings and statistics as writing rows using AddData.
+func TestWriteColumnBatchTypes(t *testing.T) {
+	for _, tt := range columnBatchTypesTestData {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := columnBatchTypesRows()
+			opts := append([]FileWriterOption{WithMaxPageSize(256)}, tt.opts...)
+
+			expectedData := writeTestFile(t, columnBatchTypesSchema, 1, len(rows), func(_, i int) map[string]interface{} {
+				return rows[i]
+			}, opts...)
+
+			var batch ColumnBatch
+			var i96Levels, strLevels []int32
+			var strs [][]byte
+			for _, row := range rows {
+				batch.Booleans = append(batch.Booleans, row["bool"].(bool))
+				batch.Int32s = append(batch.Int32s, row["i32"].(int32))
+				batch.Int64s = append(batch.Int64s, row["i64"].(int64))
+				batch.Floats = append(batch.Floats, row["f32"].(float32))
+				batch.Doubles = append(batch.Doubles, row["f64"].(float64))
+				batch.ByteArrays = append(batch.ByteArrays, row["flba"].([]byte))
+				if v, ok := row["i96"]; ok {
+					batch.Int96s = append(batch.Int96s, v.([12]byte))
+					strs = append(strs, row["str"].([]byte))
+					i96Levels, strLevels = append(i96Levels, 1), append(strLevels, 1)
+				} else {
+					i96Levels, strLevels = append(i96Levels, 0), append(strLevels, 0)
+				}
+			}
+
+			var buf bytes.Buffer
+			w := newTestFileWriter(t, &buf, columnBatchTypesSchema, opts...)
+			require.NoError(t, w.WriteColumnBatch(ColumnPath{"bool"}, batch.Booleans, nil, nil))
+			require.NoError(t, w.WriteColumnBatch(ColumnPath{"i32"}, batch.Int32s, nil, nil))
+			require.NoError(t, w.WriteColumnBatch(ColumnPath{"i64"}, batch.Int64s, nil, nil))
+			require.NoError(t, w.WriteColumnBatch(ColumnPath{"i96"}, batch.Int96s, i96Levels, nil))
+			require.NoError(t, w.WriteColumnBatch(ColumnPath{"f32"}, batch.Floats, nil, nil))
+			require.NoError(t, w.WriteColumnBatch(ColumnPath{"f64"}, batch.Doubles, nil, nil))
+			require.NoError(t, w.WriteColumnBatch(ColumnPath{"str"}, strs, strLevels, nil))
+			require.NoError(t, w.WriteColumnBatch(ColumnPath{"flba"}, batch.ByteArrays, nil, nil))
+			require.NoError(t, w.Close())
+
+			expected, err := NewFileReader(bytes.NewReader(expectedData))
+			require.NoError(t, err)
+			r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+
+			for i, chunk := range r.meta.RowGroups[0].Columns {
+				expectedChunk := expected.meta.RowGroups[0].Columns[i]
+				require.Equal(t, expectedChunk.MetaData.Encodings, chunk.MetaData.Encodings, "%v", chunk.MetaData.PathInSchema)
+				require.Equal(t, expectedChunk.MetaData.Statistics, chunk.MetaData.Statistics, "%v", chunk.MetaData.PathInSchema)
+			}
+
+			require.Equal(t, readAllRows(t, expected), readAllRows(t, r))
+		})
+	}
+}
+
+// TestWriteColumnBatchAllocations checks that the values of a column batch aren't stored in
+// interface{} values, which would need an allocation for every value.
+func TestWriteColumnBatchAllocations(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	ids := make([]int64, 1000)
+	for i := range ids {
+		ids[i] = int64(i) << 32
+	}
+
+	w := NewFileWriter(io.Discard, WithSchemaDefinition(sd), WithColumnEncoding(ColumnPath{"id"}, parquet.Encoding_PLAIN, false))
+	allocs := testing.AllocsPerRun(10, func() {
+		require.NoError(t, w.WriteColumnBatch(ColumnPath{"id"}, ids, nil, nil))
+	})
+	require.Less(t, allocs, float64(len(ids)/10))
+}
+
+func TestWriteColumnBatchValidation(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		repeated int32 values;
+	}`)
+	require.NoError(t, err)
+
+	wr := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+
+	require.Error(t, wr.WriteColumnBatch(ColumnPath{"missing"}, []int64{1}, nil, nil), "unknown column")
+	require.Error(t, wr.WriteColumnBatch(ColumnPath{"id"}, []int32{1}, nil, nil), "wrong type")
+	require.Error(t, wr.WriteColumnBatch(ColumnPath{"name"}, [][]byte{[]byte("a")}, nil, nil), "missing definition levels")
+	require.Error(t, wr.WriteColumnBatch(ColumnPath{"name"}, [][]byte{[]byte("a")}, []int32{1, 1}, nil), "too few values")
+	require.Error(t, wr.WriteColumnBatch(ColumnPath{"name"}, [][]byte{[]byte("a")}, []int32{2}, nil), "definition level out of range")
+	require.Error(t, wr.WriteColumnBatch(ColumnPath{"values"}, []int32{1}, []int32{1}, nil), "missing repetition levels")
+	require.Error(t, wr.WriteColumnBatch(ColumnPath{"values"}, []int32{1}, []int32{1}, []int32{1}), "first value doesn't start a row")
+
+	require.NoError(t, wr.WriteColumnBatch(ColumnPath{"id"}, []int64{1, 2}, nil, nil))
+	require.NoError(t, wr.WriteColumnBatch(ColumnPath{"name"}, [][]byte{[]byte("a")}, []int32{1, 0}, nil))
+	require.NoError(t, wr.WriteColumnBatch(ColumnPath{"values"}, []int32{1, 2}, []int32{1, 1}, []int32{0, 1}))
+
+	require.Error(t, wr.AddData(map[string]interface{}{"id": int64(3)}), "mixing AddData and column batches")
+
+	err = wr.FlushRowGroup()
+	require.Error(t, err, "mismatching number of rows")
+	require.Contains(t, err.Error(), "values")
+
+	require.NoError(t, wr.WriteColumnBatch(ColumnPath{"values"}, []int32{3}, []int32{1}, []int32{0}))
+	require.NoError(t, wr.FlushRowGroup())
+
+	require.NoError(t, wr.AddData(map[string]interface{}{"id": int64(3)}))
+	require.Error(t, wr.WriteColumnBatch(ColumnPath{"id"}, []int64{4}, nil, nil), "mixing AddData and column batches")
+}
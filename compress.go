@@ -3,14 +3,18 @@ package goparquet
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"sync"
 
+	"github.com/andybalholm/brotli"
 	"github.com/fraugster/parquet-go/parquet"
 	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
 )
 
 var (
@@ -26,9 +30,19 @@ type (
 		DecompressBlock([]byte) ([]byte, error)
 	}
 
-	plainCompressor  struct{}
-	snappyCompressor struct{}
-	gzipCompressor   struct{}
+	plainCompressor     struct{}
+	snappyCompressor    struct{}
+	gzipCompressor      struct{}
+	brotliCompressor    struct{}
+	lz4RawCompressor    struct{}
+	lz4HadoopCompressor struct{}
+
+	zstdCompressor struct {
+		once sync.Once
+		enc  *zstd.Encoder
+		dec  *zstd.Decoder
+		err  error
+	}
 )
 
 func (plainCompressor) CompressBlock(block []byte) ([]byte, error) {
@@ -75,6 +89,165 @@ func (gzipCompressor) DecompressBlock(block []byte) ([]byte, error) {
 	return ret, r.Close()
 }
 
+func (brotliCompressor) CompressBlock(block []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := brotli.NewWriter(buf)
+	if _, err := w.Write(block); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (brotliCompressor) DecompressBlock(block []byte) ([]byte, error) {
+	return ioutil.ReadAll(brotli.NewReader(bytes.NewReader(block)))
+}
+
+func (c *zstdCompressor) init() error {
+	c.once.Do(func() {
+		if c.enc, c.err = zstd.NewWriter(nil); c.err != nil {
+			return
+		}
+		c.dec, c.err = zstd.NewReader(nil)
+	})
+	return c.err
+}
+
+func (c *zstdCompressor) CompressBlock(block []byte) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+
+	return c.enc.EncodeAll(block, nil), nil
+}
+
+func (c *zstdCompressor) DecompressBlock(block []byte) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+
+	return c.dec.DecodeAll(block, nil)
+}
+
+func lz4CompressBlock(block []byte) ([]byte, error) {
+	var c lz4.Compressor
+
+	buf := make([]byte, lz4.CompressBlockBound(len(block)))
+	n, err := c.CompressBlock(block, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// an empty input is encoded as a single token without literals or matches.
+	if n == 0 && len(block) == 0 {
+		return []byte{0}, nil
+	}
+
+	return buf[:n], nil
+}
+
+// lz4DecompressBlock decompresses a raw LZ4 block. As the raw block format doesn't contain
+// the size of the decompressed data, the output buffer is grown until the data fits.
+func lz4DecompressBlock(block []byte) ([]byte, error) {
+	// LZ4 can't compress data by more than a factor of 255.
+	maxSize := 255*len(block) + 16
+
+	size := 4 * len(block)
+	if size < 1024 {
+		size = 1024
+	}
+
+	for {
+		if size > maxSize {
+			size = maxSize
+		}
+
+		buf := make([]byte, size)
+		n, err := lz4.UncompressBlock(block, buf)
+		if err == nil {
+			return buf[:n], nil
+		}
+
+		if !errors.Is(err, lz4.ErrInvalidSourceShortBuffer) || size == maxSize {
+			return nil, err
+		}
+
+		size *= 2
+	}
+}
+
+func (lz4RawCompressor) CompressBlock(block []byte) ([]byte, error) {
+	return lz4CompressBlock(block)
+}
+
+func (lz4RawCompressor) DecompressBlock(block []byte) ([]byte, error) {
+	return lz4DecompressBlock(block)
+}
+
+// CompressBlock compresses the block using the framing used by Hadoop's Lz4Codec: the compressed
+// data is prefixed with the big-endian uncompressed and compressed sizes.
+func (lz4HadoopCompressor) CompressBlock(block []byte) ([]byte, error) {
+	comp, err := lz4CompressBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 8, 8+len(comp))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(block)))
+	binary.BigEndian.PutUint32(buf[4:8], uint32(len(comp)))
+
+	return append(buf, comp...), nil
+}
+
+// DecompressBlock decompresses Hadoop-framed LZ4 data. Some older writers used the LZ4 codec
+// for raw LZ4 blocks without any framing, so if the data can't be read as Hadoop frames, it is
+// read as a raw LZ4 block instead.
+func (lz4HadoopCompressor) DecompressBlock(block []byte) ([]byte, error) {
+	if res, ok := lz4DecompressHadoop(block); ok {
+		return res, nil
+	}
+
+	return lz4DecompressBlock(block)
+}
+
+func lz4DecompressHadoop(block []byte) ([]byte, bool) {
+	if len(block) == 0 {
+		return nil, false
+	}
+
+	res := []byte{}
+
+	for len(block) > 0 {
+		if len(block) < 8 {
+			return nil, false
+		}
+
+		uncompressedSize := binary.BigEndian.Uint32(block[0:4])
+		compressedSize := binary.BigEndian.Uint32(block[4:8])
+		block = block[8:]
+
+		if uint64(compressedSize) > uint64(len(block)) {
+			return nil, false
+		}
+
+		if uncompressedSize > 0 {
+			buf := make([]byte, uncompressedSize)
+			n, err := lz4.UncompressBlock(block[:compressedSize], buf)
+			if err != nil || n != int(uncompressedSize) {
+				return nil, false
+			}
+			res = append(res, buf...)
+		}
+
+		block = block[compressedSize:]
+	}
+
+	return res, true
+}
+
 func compressBlock(block []byte, method parquet.CompressionCodec) ([]byte, error) {
 	compressorLock.RLock()
 	defer compressorLock.RUnlock()
@@ -121,11 +294,11 @@ func newBlockReader(buf []byte, codec parquet.CompressionCodec, compressedSize i
 }
 
 // RegisterBlockCompressor is a function to to register additional block compressors to the package. By default,
-// only UNCOMPRESSED, GZIP and SNAPPY are supported as parquet compression algorithms. The parquet file format
-// supports more compression algorithms, such as LZO, BROTLI, LZ4 and ZSTD. To limit the amount of external dependencies,
-// the number of supported algorithms was reduced to a core set. If you want to use any of the other compression
-// algorithms, please provide your own implementation of it in a way that satisfies the BlockCompressor interface,
-// and register it using this function from your code.
+// UNCOMPRESSED, GZIP, SNAPPY, BROTLI, ZSTD, LZ4_RAW and LZ4 (using the Hadoop framing) are supported as parquet
+// compression algorithms. The parquet file format also supports LZO, which is not available by default. If you
+// want to use it, or if you want to replace any of the built-in implementations, please provide your own
+// implementation in a way that satisfies the BlockCompressor interface, and register it using this function
+// from your code.
 func RegisterBlockCompressor(method parquet.CompressionCodec, compressor BlockCompressor) {
 	compressorLock.Lock()
 	defer compressorLock.Unlock()
@@ -152,4 +325,8 @@ func init() {
 	RegisterBlockCompressor(parquet.CompressionCodec_UNCOMPRESSED, plainCompressor{})
 	RegisterBlockCompressor(parquet.CompressionCodec_GZIP, gzipCompressor{})
 	RegisterBlockCompressor(parquet.CompressionCodec_SNAPPY, snappyCompressor{})
+	RegisterBlockCompressor(parquet.CompressionCodec_BROTLI, brotliCompressor{})
+	RegisterBlockCompressor(parquet.CompressionCodec_ZSTD, &zstdCompressor{})
+	RegisterBlockCompressor(parquet.CompressionCodec_LZ4_RAW, lz4RawCompressor{})
+	RegisterBlockCompressor(parquet.CompressionCodec_LZ4, lz4HadoopCompressor{})
 }
@@ -1,6 +1,8 @@
 package goparquet
 
 import (
+	"bytes"
+	"encoding/hex"
 	"testing"
 
 	"github.com/fraugster/parquet-go/parquet"
@@ -20,6 +22,10 @@ occaecat cupidatat non proident, sunt in culpa qui officia deserunt mollit anim
 		parquet.CompressionCodec_GZIP,
 		parquet.CompressionCodec_SNAPPY,
 		parquet.CompressionCodec_UNCOMPRESSED,
+		parquet.CompressionCodec_BROTLI,
+		parquet.CompressionCodec_ZSTD,
+		parquet.CompressionCodec_LZ4_RAW,
+		parquet.CompressionCodec_LZ4,
 	}
 
 	for _, m := range methods {
@@ -30,3 +36,99 @@ occaecat cupidatat non proident, sunt in culpa qui officia deserunt mollit anim
 		assert.Equal(t, block, b2)
 	}
 }
+
+func TestCompressorEmptyAndLargeBlocks(t *testing.T) {
+	methods := []parquet.CompressionCodec{
+		parquet.CompressionCodec_BROTLI,
+		parquet.CompressionCodec_ZSTD,
+		parquet.CompressionCodec_LZ4_RAW,
+		parquet.CompressionCodec_LZ4,
+	}
+
+	large := bytes.Repeat([]byte("0123456789"), 100000)
+
+	for _, m := range methods {
+		t.Run(m.String(), func(t *testing.T) {
+			b, err := compressBlock([]byte{}, m)
+			require.NoError(t, err)
+			b2, err := decompressBlock(b, m)
+			require.NoError(t, err)
+			assert.Len(t, b2, 0)
+
+			b, err = compressBlock(large, m)
+			require.NoError(t, err)
+			assert.Less(t, len(b), len(large)/10)
+			b2, err = decompressBlock(b, m)
+			require.NoError(t, err)
+			assert.Equal(t, large, b2)
+		})
+	}
+}
+
+func TestDecompressReferenceData(t *testing.T) {
+	const text = "parquet-go parquet-go parquet-go parquet-go compression test"
+
+	// LZ4 block as produced by the lz4 reference implementation.
+	lz4Block := "bf706172717565742d676f200b000ef001636f6d7072657373696f6e2074657374"
+
+	tests := []struct {
+		name     string
+		codec    parquet.CompressionCodec
+		data     string
+		expected string
+	}{
+		{
+			name:     "zstd reference implementation",
+			codec:    parquet.CompressionCodec_ZSTD,
+			data:     "28b52ffd203c0d0100d8706172717565742d676f20636f6d7072657373696f6e207465737401001e8b17",
+			expected: text,
+		},
+		{
+			name:     "lz4 raw block",
+			codec:    parquet.CompressionCodec_LZ4_RAW,
+			data:     lz4Block,
+			expected: text,
+		},
+		{
+			name:     "lz4 with hadoop framing",
+			codec:    parquet.CompressionCodec_LZ4,
+			data:     "0000003c00000021" + lz4Block,
+			expected: text,
+		},
+		{
+			name:     "lz4 with multiple hadoop frames",
+			codec:    parquet.CompressionCodec_LZ4,
+			data:     "0000003c00000021" + lz4Block + "0000003c00000021" + lz4Block,
+			expected: text + text,
+		},
+		{
+			name:     "lz4 raw block without hadoop framing",
+			codec:    parquet.CompressionCodec_LZ4,
+			data:     lz4Block,
+			expected: text,
+		},
+		{
+			name:     "brotli empty stream",
+			codec:    parquet.CompressionCodec_BROTLI,
+			data:     "06",
+			expected: "",
+		},
+		{
+			name:     "brotli uncompressed meta-block",
+			codec:    parquet.CompressionCodec_BROTLI,
+			data:     "40001068656c6c6f03",
+			expected: "hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.data)
+			require.NoError(t, err)
+
+			res, err := decompressBlock(data, tt.codec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(res))
+		})
+	}
+}
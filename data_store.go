@@ -3,6 +3,7 @@ package goparquet
 import (
 	"errors"
 	"fmt"
+	"io"
 	"math/bits"
 
 	"github.com/fraugster/parquet-go/parquet"
@@ -17,8 +18,7 @@ type ColumnStore struct {
 
 	repTyp parquet.FieldRepetitionType
 
-	pages   []pageReader
-	pageIdx int
+	pages pageIterator
 
 	values *dictStore
 
@@ -29,6 +29,8 @@ type ColumnStore struct {
 	readPos int
 
 	useDict bool
+	// if autoDict is true, the dictionary is only used if it actually reduces the size of the column chunk.
+	autoDict bool
 
 	skipped bool
 
@@ -37,10 +39,22 @@ type ColumnStore struct {
 	maxPageSize int64
 
 	prevNumRecords int64 // this is just for correctly calculating how many rows are in a data page.
+
+	batchNumRecords int64 // number of records added using column batches in the current row group.
+
+	// batch buffers the values of the current page if the row group is written using column batches.
+	batch batchValues
 }
 
 type dataPage struct {
-	values     []interface{}
+	values []interface{}
+	// batchValues contains the values of a page that were added using column batches instead of
+	// values, in a slice of the physical type of the column. valuesSize is their size, and
+	// dictIndices are their indexes in the dictionary of the column chunk.
+	batchValues interface{}
+	valuesSize  int64
+	dictIndices []int32
+
 	rL         *packedArray
 	dL         *packedArray
 	numValues  int64
@@ -78,6 +92,9 @@ func (cs *ColumnStore) reset(rep parquet.FieldRepetitionType, maxR, maxD uint16)
 	cs.readPos = 0
 	cs.skipped = false
 	cs.prevNumRecords = 0
+	cs.batchNumRecords = 0
+	cs.batch = nil
+	cs.pages = nil
 
 	cs.typedColumnStore.reset(rep)
 }
@@ -134,6 +151,9 @@ func (cs *ColumnStore) add(v interface{}, dL uint16, maxRL, rL uint16) error {
 
 func (cs *ColumnStore) estimateSize() (total int64) {
 	dictSize, noDictSize := cs.values.sizes()
+	if cs.batch != nil {
+		dictSize, noDictSize = cs.batch.sizes()
+	}
 	if cs.useDictionary() {
 		total += dictSize
 	} else {
@@ -150,17 +170,19 @@ func (cs *ColumnStore) getMaxPageSize() int64 {
 	return cs.maxPageSize
 }
 
-func (cs *ColumnStore) flushPage(sch *schema, force bool) error {
+// flushPage adds the buffered values as a new data page if they reached the maximum page size or
+// force is true. numRecords is the number of records in the row group including the buffered ones.
+func (cs *ColumnStore) flushPage(numRecords int64, force bool) error {
 	size := cs.estimateSize()
 
 	if !force && size < cs.getMaxPageSize() {
 		return nil
 	}
 
-	numRows := sch.numRecords - cs.prevNumRecords
-	cs.prevNumRecords = sch.numRecords
+	numRows := numRecords - cs.prevNumRecords
+	cs.prevNumRecords = numRecords
 
-	cs.dataPages = append(cs.dataPages, &dataPage{
+	page := &dataPage{
 		values:     cs.values.getValues(),
 		rL:         cs.rLevels,
 		dL:         cs.dLevels,
@@ -173,7 +195,14 @@ func (cs *ColumnStore) flushPage(sch *schema, force bool) error {
 			MaxValue:      cs.getPageStats().maxValue(),
 			MinValue:      cs.getPageStats().minValue(),
 		},
-	})
+	}
+	if cs.batch != nil {
+		// the values were added using column batches.
+		page.numValues = int64(cs.batch.numValues())
+		page.stats.DistinctCount = int64Ptr(cs.batch.distinctValueCount())
+		page.batchValues, page.valuesSize = cs.batch.flush()
+	}
+	cs.dataPages = append(cs.dataPages, page)
 
 	cs.resetData()
 
@@ -231,16 +260,21 @@ func (cs *ColumnStore) resetData() {
 }
 
 func (cs *ColumnStore) readNextPage() error {
-	if cs.pageIdx >= len(cs.pages) {
-		return fmt.Errorf("out of range: requested page index = %d total number of pages = %d", cs.pageIdx, len(cs.pages))
+	if cs.pages == nil {
+		return errors.New("out of range: no pages to read")
 	}
 
-	data, dl, rl, err := cs.pages[cs.pageIdx].readValues(int(cs.pages[cs.pageIdx].numValues()))
-	if err != nil {
+	page, err := cs.pages.nextPage()
+	if err == io.EOF {
+		return errors.New("out of range: all pages of the column chunk have been read")
+	} else if err != nil {
 		return err
 	}
 
-	cs.pageIdx++
+	data, dl, rl, err := page.readValues(int(page.numValues()))
+	if err != nil {
+		return err
+	}
 
 	cs.resetData()
 
@@ -256,6 +290,94 @@ func (cs *ColumnStore) readNextPage() error {
 	return nil
 }
 
+// skipRows skips the next n rows of the column without returning their values.
+func (cs *ColumnStore) skipRows(n int64, maxD int32) error {
+	var skipped int64
+	for {
+		if cs.readPos >= cs.rLevels.count || cs.readPos >= cs.dLevels.count {
+			if err := cs.readNextPage(); err != nil {
+				return err
+			}
+		}
+		rl, dl, _ := cs.getRDLevelAt(cs.readPos)
+		if rl == 0 {
+			if skipped == n {
+				return nil
+			}
+			skipped++
+		}
+		if dl == maxD {
+			if _, err := cs.getNext(); err != nil {
+				return err
+			}
+		}
+		cs.readPos++
+	}
+}
+
+// peekRow returns the values of the next row of the column that are not null without advancing
+// the read position. Like get, it expects the row to end on the current page; it returns false if
+// the row reaches the end of the page, as it may continue on the next one.
+func (cs *ColumnStore) peekRow(maxD int32) ([]interface{}, bool, error) {
+	if cs.skipped {
+		return nil, false, nil
+	}
+
+	if cs.readPos >= cs.rLevels.count || cs.readPos >= cs.dLevels.count {
+		if err := cs.readNextPage(); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var values []interface{}
+	valuePos := cs.values.readPos
+	for pos := cs.readPos; ; {
+		if _, dl, _ := cs.getRDLevelAt(pos); dl == maxD {
+			if valuePos >= len(cs.values.valueList) {
+				return nil, false, errors.New("out of range")
+			}
+			values = append(values, cs.values.valueList[valuePos])
+			valuePos++
+		}
+
+		pos++
+		rl, _, last := cs.getRDLevelAt(pos)
+		if last {
+			return values, false, nil
+		}
+		if rl == 0 {
+			return values, true, nil
+		}
+	}
+}
+
+// skipRow skips the next row of the column without returning its values. Like get, it expects the
+// row to end on the current page.
+func (cs *ColumnStore) skipRow(maxD int32) error {
+	if cs.skipped {
+		return nil
+	}
+
+	if cs.readPos >= cs.rLevels.count || cs.readPos >= cs.dLevels.count {
+		if err := cs.readNextPage(); err != nil {
+			return err
+		}
+	}
+
+	for {
+		if _, dl, _ := cs.getRDLevelAt(cs.readPos); dl == maxD {
+			if _, err := cs.getNext(); err != nil {
+				return err
+			}
+		}
+
+		cs.readPos++
+		if rl, _, last := cs.getRDLevelAt(cs.readPos); last || rl == 0 {
+			return nil
+		}
+	}
+}
+
 func (cs *ColumnStore) get(maxD, maxR int32) (interface{}, int32, error) {
 	if cs.skipped {
 		return nil, 0, nil
@@ -406,7 +528,7 @@ func NewInt96Store(enc parquet.Encoding, useDict bool, params *ColumnParameters)
 // then a dictionary is used, otherwise a dictionary will never be used to encode the data.
 func NewFloatStore(enc parquet.Encoding, useDict bool, params *ColumnParameters) (*ColumnStore, error) {
 	switch enc {
-	case parquet.Encoding_PLAIN:
+	case parquet.Encoding_PLAIN, parquet.Encoding_BYTE_STREAM_SPLIT:
 	default:
 		return nil, fmt.Errorf("encoding %q is not supported on this type", enc)
 	}
@@ -417,7 +539,7 @@ func NewFloatStore(enc parquet.Encoding, useDict bool, params *ColumnParameters)
 // then a dictionary is used, otherwise a dictionary will never be used to encode the data.
 func NewDoubleStore(enc parquet.Encoding, useDict bool, params *ColumnParameters) (*ColumnStore, error) {
 	switch enc {
-	case parquet.Encoding_PLAIN:
+	case parquet.Encoding_PLAIN, parquet.Encoding_BYTE_STREAM_SPLIT:
 	default:
 		return nil, fmt.Errorf("encoding %q is not supported on this type", enc)
 	}
@@ -439,7 +561,7 @@ func NewByteArrayStore(enc parquet.Encoding, useDict bool, params *ColumnParamet
 // then a dictionary is used, otherwise a dictionary will never be used to encode the data.
 func NewFixedByteArrayStore(enc parquet.Encoding, useDict bool, params *ColumnParameters) (*ColumnStore, error) {
 	switch enc {
-	case parquet.Encoding_PLAIN, parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY, parquet.Encoding_DELTA_BYTE_ARRAY:
+	case parquet.Encoding_PLAIN, parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY, parquet.Encoding_DELTA_BYTE_ARRAY, parquet.Encoding_BYTE_STREAM_SPLIT:
 	default:
 		return nil, fmt.Errorf("encoding %q is not supported on this type", enc)
 	}
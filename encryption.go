@@ -0,0 +1,858 @@
+package goparquet
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// magicEncrypted is the file magic of parquet files with an encrypted footer.
+var magicEncrypted = []byte{'P', 'A', 'R', 'E'}
+
+const (
+	encryptionNonceLength         = 12
+	encryptionTagLength           = 16
+	encryptionAADFileUniqueLength = 8
+)
+
+// The module types as defined by the parquet modular encryption specification. The module type is
+// part of the additional authenticated data of every module, so that modules can't be swapped.
+const (
+	moduleFooter byte = iota
+	moduleColumnMetaData
+	moduleDataPage
+	moduleDictionaryPage
+	moduleDataPageHeader
+	moduleDictionaryPageHeader
+	moduleColumnIndex
+	moduleOffsetIndex
+	moduleBloomFilterHeader
+	moduleBloomFilterBitset
+)
+
+// EncryptionAlgorithm is the algorithm that is used to encrypt a parquet file.
+type EncryptionAlgorithm int
+
+const (
+	// EncryptionAESGCM encrypts all modules of the file using AES-GCM. This is the default.
+	EncryptionAESGCM EncryptionAlgorithm = iota
+	// EncryptionAESGCMCTR encrypts the page data using AES-CTR and all other modules using AES-GCM.
+	// It is faster than EncryptionAESGCM, but doesn't protect the integrity of the page data.
+	EncryptionAESGCMCTR
+)
+
+func (a EncryptionAlgorithm) String() string {
+	switch a {
+	case EncryptionAESGCM:
+		return "AES_GCM_V1"
+	case EncryptionAESGCMCTR:
+		return "AES_GCM_CTR_V1"
+	default:
+		return fmt.Sprintf("EncryptionAlgorithm(%d)", int(a))
+	}
+}
+
+// KeyRetriever is used by the FileReader to retrieve the keys of an encrypted file. The key metadata
+// is the metadata that was stored with the footer key or the column key when the file was written.
+// It is empty if no key metadata was stored. The returned key needs to be 16, 24 or 32 bytes long.
+type KeyRetriever interface {
+	RetrieveKey(keyMetadata []byte) ([]byte, error)
+}
+
+// KeyRetrieverFunc is an adapter to use an ordinary function as KeyRetriever.
+type KeyRetrieverFunc func(keyMetadata []byte) ([]byte, error)
+
+// RetrieveKey returns f(keyMetadata).
+func (f KeyRetrieverFunc) RetrieveKey(keyMetadata []byte) ([]byte, error) {
+	return f(keyMetadata)
+}
+
+// StaticKeyRetriever is a KeyRetriever that holds the keys in memory, identified by their key metadata.
+type StaticKeyRetriever map[string][]byte
+
+// RetrieveKey returns the key that is stored for keyMetadata.
+func (s StaticKeyRetriever) RetrieveKey(keyMetadata []byte) ([]byte, error) {
+	key, ok := s[string(keyMetadata)]
+	if !ok {
+		return nil, fmt.Errorf("no key found for key metadata %q", keyMetadata)
+	}
+	return key, nil
+}
+
+type columnKey struct {
+	path        ColumnPath
+	key         []byte
+	keyMetadata []byte
+}
+
+type encryptionConfig struct {
+	algorithm         EncryptionAlgorithm
+	footerKey         []byte
+	footerKeyMetadata []byte
+	plaintextFooter   bool
+	columnKeys        []columnKey
+	aadPrefix         []byte
+	supplyAADPrefix   bool
+}
+
+// EncryptionOption is an option to configure the encryption of a parquet file. It is passed to WithEncryption.
+type EncryptionOption func(cfg *encryptionConfig)
+
+// WithEncryptionAlgorithm sets the algorithm that is used to encrypt the file. By default, EncryptionAESGCM is used.
+func WithEncryptionAlgorithm(alg EncryptionAlgorithm) EncryptionOption {
+	return func(cfg *encryptionConfig) {
+		cfg.algorithm = alg
+	}
+}
+
+// WithFooterKeyMetadata sets the key metadata that is stored with the footer. It is passed to the
+// KeyRetriever when reading the file, so it should identify the footer key, e.g. by containing a key ID.
+func WithFooterKeyMetadata(keyMetadata []byte) EncryptionOption {
+	return func(cfg *encryptionConfig) {
+		cfg.footerKeyMetadata = keyMetadata
+	}
+}
+
+// WithColumnKey encrypts the column identified by path with its own key. The key metadata is stored
+// with the column and passed to the KeyRetriever when reading the column. If at least one column key
+// is configured, only the columns with a column key are encrypted, and all other columns are written
+// in plaintext. Otherwise, all columns are encrypted with the footer key.
+func WithColumnKey(path ColumnPath, key []byte, keyMetadata []byte) EncryptionOption {
+	return func(cfg *encryptionConfig) {
+		cfg.columnKeys = append(cfg.columnKeys, columnKey{path: path, key: key, keyMetadata: keyMetadata})
+	}
+}
+
+// WithAADPrefix sets a prefix for the additional authenticated data of all encrypted modules, which
+// can be used to protect the file against being replaced by another file, e.g. by using the file name
+// as prefix. If supply is true, the prefix is not stored in the file, and needs to be provided when
+// reading the file using WithDecryptionAADPrefix.
+func WithAADPrefix(prefix []byte, supply bool) EncryptionOption {
+	return func(cfg *encryptionConfig) {
+		cfg.aadPrefix = prefix
+		cfg.supplyAADPrefix = supply
+	}
+}
+
+// WithPlaintextFooter writes the footer in plaintext, so that readers without access to the keys
+// can still read the schema and the plaintext columns. The footer is signed with the footer key,
+// so that readers with access to the footer key can verify its integrity. The statistics of
+// encrypted columns are removed from the plaintext footer.
+func WithPlaintextFooter() EncryptionOption {
+	return func(cfg *encryptionConfig) {
+		cfg.plaintextFooter = true
+	}
+}
+
+// moduleCipher encrypts and decrypts the modules of a parquet file with a single key.
+type moduleCipher struct {
+	block   cipher.Block
+	gcm     cipher.AEAD
+	fileAAD []byte
+	// if ctr is set, page data is encrypted using AES-CTR instead of AES-GCM.
+	ctr bool
+}
+
+func newModuleCipher(key []byte, fileAAD []byte, alg EncryptionAlgorithm) (*moduleCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &moduleCipher{
+		block:   block,
+		gcm:     gcm,
+		fileAAD: fileAAD,
+		ctr:     alg == EncryptionAESGCMCTR,
+	}, nil
+}
+
+// moduleAAD returns the additional authenticated data of a module. The row group and column ordinals
+// are not used for the footer, and the page ordinal is only used if it is not negative.
+func (c *moduleCipher) moduleAAD(moduleType byte, rowGroup, column, page int) []byte {
+	aad := make([]byte, 0, len(c.fileAAD)+7)
+	aad = append(aad, c.fileAAD...)
+	aad = append(aad, moduleType)
+	if moduleType == moduleFooter {
+		return aad
+	}
+
+	var buf [2]byte
+	for _, ordinal := range []int{rowGroup, column, page} {
+		if ordinal < 0 {
+			break
+		}
+		binary.LittleEndian.PutUint16(buf[:], uint16(ordinal))
+		aad = append(aad, buf[:]...)
+	}
+
+	return aad
+}
+
+func newNonce() []byte {
+	nonce := make([]byte, encryptionNonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err) // crypto/rand doesn't fail on supported platforms.
+	}
+	return nonce
+}
+
+// seal encrypts data using AES-GCM and returns the nonce, followed by the ciphertext and the tag.
+func (c *moduleCipher) seal(data []byte, aad []byte) []byte {
+	nonce := newNonce()
+	return c.gcm.Seal(nonce, nonce, data, aad)
+}
+
+// open decrypts data that was encrypted by seal.
+func (c *moduleCipher) open(data []byte, aad []byte) ([]byte, error) {
+	if len(data) < encryptionNonceLength+encryptionTagLength {
+		return nil, errors.New("encrypted module is too short")
+	}
+
+	plain, err := c.gcm.Open(nil, data[:encryptionNonceLength], data[encryptionNonceLength:], aad)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting module failed: %w", err)
+	}
+
+	return plain, nil
+}
+
+// sign returns the nonce and the AES-GCM tag of data, which is used as signature of plaintext footers.
+func (c *moduleCipher) sign(data []byte, aad []byte) []byte {
+	sealed := c.seal(data, aad)
+	return append(sealed[:encryptionNonceLength:encryptionNonceLength], sealed[len(sealed)-encryptionTagLength:]...)
+}
+
+// verify checks the signature of data that was created by sign.
+func (c *moduleCipher) verify(data []byte, aad []byte, signature []byte) error {
+	if len(signature) != encryptionNonceLength+encryptionTagLength {
+		return fmt.Errorf("invalid footer signature length %d", len(signature))
+	}
+
+	sealed := c.gcm.Seal(nil, signature[:encryptionNonceLength], data, aad)
+	if subtle.ConstantTimeCompare(sealed[len(sealed)-encryptionTagLength:], signature[encryptionNonceLength:]) != 1 {
+		return errors.New("footer signature verification failed")
+	}
+
+	return nil
+}
+
+func lengthPrefixed(data []byte) []byte {
+	buf := make([]byte, 4, 4+len(data))
+	binary.LittleEndian.PutUint32(buf, uint32(len(data)))
+	return append(buf, data...)
+}
+
+// encrypt encrypts a module using AES-GCM. The result is prefixed by its length.
+func (c *moduleCipher) encrypt(data []byte, aad []byte) []byte {
+	return lengthPrefixed(c.seal(data, aad))
+}
+
+// encryptPageData encrypts the data of a page. Depending on the algorithm, either AES-GCM or AES-CTR is used.
+func (c *moduleCipher) encryptPageData(data []byte, aad []byte) []byte {
+	if !c.ctr {
+		return c.encrypt(data, aad)
+	}
+
+	nonce := newNonce()
+	buf := make([]byte, encryptionNonceLength+len(data))
+	copy(buf, nonce)
+	c.ctrStream(nonce).XORKeyStream(buf[encryptionNonceLength:], data)
+	return lengthPrefixed(buf)
+}
+
+// decryptPageData decrypts page data that was encrypted by encryptPageData, without the length prefix.
+func (c *moduleCipher) decryptPageData(data []byte, aad []byte) ([]byte, error) {
+	if !c.ctr {
+		return c.open(data, aad)
+	}
+
+	if len(data) < encryptionNonceLength {
+		return nil, errors.New("encrypted page is too short")
+	}
+
+	plain := make([]byte, len(data)-encryptionNonceLength)
+	c.ctrStream(data[:encryptionNonceLength]).XORKeyStream(plain, data[encryptionNonceLength:])
+	return plain, nil
+}
+
+func (c *moduleCipher) ctrStream(nonce []byte) cipher.Stream {
+	// the initial counter value is 1, as in AES-GCM.
+	iv := make([]byte, aes.BlockSize)
+	copy(iv, nonce)
+	iv[aes.BlockSize-1] = 1
+	return cipher.NewCTR(c.block, iv)
+}
+
+// readModule reads an encrypted module from r and returns it without its length prefix.
+func readModule(r io.Reader) ([]byte, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, fmt.Errorf("read encrypted module length failed: %w", err)
+	}
+
+	n := binary.LittleEndian.Uint32(buf[:])
+	if n < encryptionNonceLength || n > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid encrypted module length %d", n)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r, int64(n)))
+	if err != nil {
+		return nil, fmt.Errorf("read encrypted module failed: %w", err)
+	}
+	if len(data) != int(n) {
+		return nil, fmt.Errorf("encrypted module is truncated, expected %d bytes, got %d", n, len(data))
+	}
+
+	return data, nil
+}
+
+// chunkCipher encrypts and decrypts the modules of a single column chunk.
+type chunkCipher struct {
+	*moduleCipher
+
+	rowGroup       int
+	column         int
+	cryptoMetaData *parquet.ColumnCryptoMetaData
+}
+
+func (cc *chunkCipher) aad(moduleType byte, page int) []byte {
+	return cc.moduleAAD(moduleType, cc.rowGroup, cc.column, page)
+}
+
+// writeThrift writes obj to w. If the column chunk is encrypted, obj is written as encrypted module.
+// A nil chunkCipher indicates a plaintext column chunk.
+func (cc *chunkCipher) writeThrift(ctx context.Context, obj thriftWriter, w io.Writer, moduleType byte, page int) error {
+	if cc == nil {
+		return writeThrift(ctx, obj, w)
+	}
+
+	var buf bytes.Buffer
+	if err := writeThrift(ctx, obj, &buf); err != nil {
+		return err
+	}
+
+	return writeFull(w, cc.encrypt(buf.Bytes(), cc.aad(moduleType, page)))
+}
+
+// readThrift reads obj from r. If the column chunk is encrypted, obj is read from an encrypted module.
+// A nil chunkCipher indicates a plaintext column chunk.
+func (cc *chunkCipher) readThrift(ctx context.Context, obj thriftReader, r io.Reader, moduleType byte, page int) error {
+	if cc == nil {
+		return readThrift(ctx, obj, r)
+	}
+
+	data, err := readModule(r)
+	if err != nil {
+		return err
+	}
+
+	plain, err := cc.open(data, cc.aad(moduleType, page))
+	if err != nil {
+		return err
+	}
+
+	return readThrift(ctx, obj, bytes.NewReader(plain))
+}
+
+func checkPageOrdinal(page int) error {
+	if page > math.MaxInt16 {
+		return fmt.Errorf("encrypted column chunks can't have more than %d pages", math.MaxInt16+1)
+	}
+	return nil
+}
+
+// encryptPage encrypts a page as it was written by a page writer, i.e. a page header followed by
+// the page data. The compressed page size and the checksum in the page header are updated to refer
+// to the encrypted page data.
+func (cc *chunkCipher) encryptPage(ctx context.Context, page []byte, dict bool, ordinal int) ([]byte, error) {
+	r := bytes.NewReader(page)
+	ph := &parquet.PageHeader{}
+	if err := readThrift(ctx, ph, r); err != nil {
+		return nil, err
+	}
+	data := page[len(page)-r.Len():]
+
+	headerType, dataType := moduleDataPageHeader, moduleDataPage
+	if dict {
+		headerType, dataType, ordinal = moduleDictionaryPageHeader, moduleDictionaryPage, -1
+	} else if err := checkPageOrdinal(ordinal); err != nil {
+		return nil, err
+	}
+
+	encrypted := cc.encryptPageData(data, cc.aad(dataType, ordinal))
+	ph.CompressedPageSize = int32(len(encrypted))
+	if ph.Crc != nil {
+		crc := int32(crc32.ChecksumIEEE(encrypted))
+		ph.Crc = &crc
+	}
+
+	var buf bytes.Buffer
+	if err := cc.writeThrift(ctx, ph, &buf, headerType, ordinal); err != nil {
+		return nil, err
+	}
+	buf.Write(encrypted)
+
+	return buf.Bytes(), nil
+}
+
+// decryptPage reads the encrypted data of the page with the header ph from r and returns a
+// reader for the decrypted page data. The compressed page size and the checksum in ph are
+// updated to refer to the decrypted page data.
+func (cc *chunkCipher) decryptPage(r io.Reader, ph *parquet.PageHeader, dict bool, ordinal int, validateCRC bool) (io.Reader, error) {
+	if ph.CompressedPageSize < 4 {
+		return nil, fmt.Errorf("invalid encrypted page size %d", ph.CompressedPageSize)
+	}
+
+	encrypted, err := readPageBlock(r, parquet.CompressionCodec_UNCOMPRESSED, ph.CompressedPageSize, 0, validateCRC, ph.Crc)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encrypted) < 4 {
+		return nil, errors.New("encrypted page is truncated")
+	}
+	if n := binary.LittleEndian.Uint32(encrypted); int64(n) != int64(len(encrypted)-4) {
+		return nil, fmt.Errorf("encrypted page length %d doesn't match page size %d", n, len(encrypted)-4)
+	}
+
+	dataType := moduleDataPage
+	if dict {
+		dataType, ordinal = moduleDictionaryPage, -1
+	}
+
+	plain, err := cc.decryptPageData(encrypted[4:], cc.aad(dataType, ordinal))
+	if err != nil {
+		return nil, err
+	}
+
+	ph.CompressedPageSize = int32(len(plain))
+	ph.Crc = nil
+
+	return bytes.NewReader(plain), nil
+}
+
+// encryptionAlgorithm returns the thrift representation of the algorithm.
+func encryptionAlgorithm(alg EncryptionAlgorithm, aadPrefix []byte, aadFileUnique []byte, supplyAADPrefix *bool) *parquet.EncryptionAlgorithm {
+	if alg == EncryptionAESGCMCTR {
+		return &parquet.EncryptionAlgorithm{
+			AES_GCM_CTR_V1: &parquet.AesGcmCtrV1{AadPrefix: aadPrefix, AadFileUnique: aadFileUnique, SupplyAadPrefix: supplyAADPrefix},
+		}
+	}
+	return &parquet.EncryptionAlgorithm{
+		AES_GCM_V1: &parquet.AesGcmV1{AadPrefix: aadPrefix, AadFileUnique: aadFileUnique, SupplyAadPrefix: supplyAADPrefix},
+	}
+}
+
+// fileEncryptor holds the ciphers to encrypt a parquet file.
+type fileEncryptor struct {
+	cfg       *encryptionConfig
+	algorithm *parquet.EncryptionAlgorithm
+	footer    *moduleCipher
+	columns   []*moduleCipher
+}
+
+func newFileEncryptor(cfg *encryptionConfig, sch *schema) (*fileEncryptor, error) {
+	if cfg.algorithm != EncryptionAESGCM && cfg.algorithm != EncryptionAESGCMCTR {
+		return nil, fmt.Errorf("unsupported encryption algorithm %s", cfg.algorithm)
+	}
+
+	aadFileUnique := make([]byte, encryptionAADFileUniqueLength)
+	if _, err := rand.Read(aadFileUnique); err != nil {
+		return nil, err
+	}
+	fileAAD := append(append([]byte{}, cfg.aadPrefix...), aadFileUnique...)
+
+	var (
+		storedPrefix []byte
+		supply       *bool
+	)
+	if len(cfg.aadPrefix) > 0 {
+		if cfg.supplyAADPrefix {
+			supply = &cfg.supplyAADPrefix
+		} else {
+			storedPrefix = cfg.aadPrefix
+		}
+	}
+
+	footer, err := newModuleCipher(cfg.footerKey, fileAAD, cfg.algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("invalid footer key: %w", err)
+	}
+
+	e := &fileEncryptor{
+		cfg:       cfg,
+		algorithm: encryptionAlgorithm(cfg.algorithm, storedPrefix, aadFileUnique, supply),
+		footer:    footer,
+	}
+
+	for _, ck := range cfg.columnKeys {
+		if !isDataColumn(sch, ck.path) {
+			return nil, fmt.Errorf("column key for unknown column %s", ck.path.flatName())
+		}
+		c, err := newModuleCipher(ck.key, fileAAD, cfg.algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key for column %s: %w", ck.path.flatName(), err)
+		}
+		e.columns = append(e.columns, c)
+	}
+
+	return e, nil
+}
+
+func isDataColumn(sch *schema, path ColumnPath) bool {
+	for _, col := range sch.Columns() {
+		if col.path.Equal(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// chunkCipher returns the cipher of a column chunk, or nil if the column chunk is not encrypted.
+// A nil fileEncryptor indicates a plaintext file.
+func (e *fileEncryptor) chunkCipher(rowGroup, column int, path ColumnPath) (*chunkCipher, error) {
+	if e == nil {
+		return nil, nil
+	}
+
+	if rowGroup > math.MaxInt16 || column > math.MaxInt16 {
+		return nil, fmt.Errorf("encrypted files can't have more than %d row groups or columns", math.MaxInt16+1)
+	}
+
+	if len(e.cfg.columnKeys) == 0 {
+		return &chunkCipher{
+			moduleCipher:   e.footer,
+			rowGroup:       rowGroup,
+			column:         column,
+			cryptoMetaData: &parquet.ColumnCryptoMetaData{ENCRYPTION_WITH_FOOTER_KEY: &parquet.EncryptionWithFooterKey{}},
+		}, nil
+	}
+
+	for i, ck := range e.cfg.columnKeys {
+		if ck.path.Equal(path) {
+			return &chunkCipher{
+				moduleCipher: e.columns[i],
+				rowGroup:     rowGroup,
+				column:       column,
+				cryptoMetaData: &parquet.ColumnCryptoMetaData{
+					ENCRYPTION_WITH_COLUMN_KEY: &parquet.EncryptionWithColumnKey{PathInSchema: path, KeyMetadata: ck.keyMetadata},
+				},
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// encryptColumnMetaData sets the crypto metadata of all encrypted column chunks and encrypts their
+// column metadata if required. Column metadata is encrypted separately if the column is encrypted
+// with a column key, or if the footer is written in plaintext. In the latter case, the plaintext
+// column metadata is kept without statistics for readers that have no access to the key.
+func (e *fileEncryptor) encryptColumnMetaData(ctx context.Context, rowGroups []*parquet.RowGroup, indexes [][]*chunkIndexes) error {
+	for i, rg := range rowGroups {
+		for j, chunk := range rg.Columns {
+			cc := indexes[i][j].cipher
+			if cc == nil {
+				continue
+			}
+
+			chunk.CryptoMetadata = cc.cryptoMetaData
+			if cc.cryptoMetaData.ENCRYPTION_WITH_FOOTER_KEY != nil && !e.cfg.plaintextFooter {
+				continue
+			}
+
+			var buf bytes.Buffer
+			if err := writeThrift(ctx, chunk.MetaData, &buf); err != nil {
+				return err
+			}
+			chunk.EncryptedColumnMetadata = cc.seal(buf.Bytes(), cc.aad(moduleColumnMetaData, -1))
+
+			if e.cfg.plaintextFooter {
+				stripped := *chunk.MetaData
+				stripped.Statistics = nil
+				stripped.EncodingStats = nil
+				chunk.MetaData = &stripped
+			} else {
+				chunk.MetaData = nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeFooter writes the file meta data, the footer length and the file magic. With an encrypted
+// footer, the file crypto meta data is written in plaintext before the encrypted file meta data.
+// A plaintext footer is followed by its signature.
+func (e *fileEncryptor) writeFooter(ctx context.Context, w writePos, meta *parquet.FileMetaData) error {
+	pos := w.Pos()
+	aad := e.footer.moduleAAD(moduleFooter, -1, -1, -1)
+
+	fileMagic := magicEncrypted
+	if e.cfg.plaintextFooter {
+		meta.EncryptionAlgorithm = e.algorithm
+		meta.FooterSigningKeyMetadata = e.cfg.footerKeyMetadata
+		fileMagic = magic
+	} else {
+		cryptoMeta := &parquet.FileCryptoMetaData{
+			EncryptionAlgorithm: e.algorithm,
+			KeyMetadata:         e.cfg.footerKeyMetadata,
+		}
+		if err := writeThrift(ctx, cryptoMeta, w); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeThrift(ctx, meta, &buf); err != nil {
+		return err
+	}
+
+	if e.cfg.plaintextFooter {
+		if err := writeFull(w, buf.Bytes()); err != nil {
+			return err
+		}
+		if err := writeFull(w, e.footer.sign(buf.Bytes(), aad)); err != nil {
+			return err
+		}
+	} else if err := writeFull(w, e.footer.encrypt(buf.Bytes(), aad)); err != nil {
+		return err
+	}
+
+	ln := int32(w.Pos() - pos)
+	if err := binary.Write(w, binary.LittleEndian, &ln); err != nil {
+		return err
+	}
+
+	return writeFull(w, fileMagic)
+}
+
+// fileDecryptor holds the information required to decrypt a parquet file.
+type fileDecryptor struct {
+	keys              KeyRetriever
+	algorithm         EncryptionAlgorithm
+	fileAAD           []byte
+	footerKeyMetadata []byte
+
+	// footer is the cipher of the footer key once it has been created.
+	footer *moduleCipher
+	// ciphers of column keys that have already been created, identified by their key metadata.
+	// They are kept apart from the footer cipher, as the key metadata of the footer key and of
+	// column keys can be the same, e.g. empty, for different keys.
+	ciphers map[string]*moduleCipher
+}
+
+func newFileDecryptor(keys KeyRetriever, aadPrefix []byte, alg *parquet.EncryptionAlgorithm, footerKeyMetadata []byte) (*fileDecryptor, error) {
+	var (
+		algorithm     EncryptionAlgorithm
+		storedPrefix  []byte
+		aadFileUnique []byte
+		supplyPrefix  bool
+	)
+
+	switch {
+	case alg == nil:
+		return nil, errors.New("encryption algorithm is missing")
+	case alg.AES_GCM_V1 != nil:
+		algorithm = EncryptionAESGCM
+		storedPrefix, aadFileUnique, supplyPrefix = alg.AES_GCM_V1.AadPrefix, alg.AES_GCM_V1.AadFileUnique, alg.AES_GCM_V1.GetSupplyAadPrefix()
+	case alg.AES_GCM_CTR_V1 != nil:
+		algorithm = EncryptionAESGCMCTR
+		storedPrefix, aadFileUnique, supplyPrefix = alg.AES_GCM_CTR_V1.AadPrefix, alg.AES_GCM_CTR_V1.AadFileUnique, alg.AES_GCM_CTR_V1.GetSupplyAadPrefix()
+	default:
+		return nil, errors.New("unsupported encryption algorithm")
+	}
+
+	switch {
+	case storedPrefix != nil && aadPrefix != nil && !bytes.Equal(storedPrefix, aadPrefix):
+		return nil, errors.New("provided AAD prefix doesn't match the AAD prefix stored in the file")
+	case storedPrefix != nil:
+		aadPrefix = storedPrefix
+	case supplyPrefix && aadPrefix == nil:
+		return nil, errors.New("file requires an AAD prefix, but none was provided")
+	}
+
+	return &fileDecryptor{
+		keys:              keys,
+		algorithm:         algorithm,
+		fileAAD:           append(append([]byte{}, aadPrefix...), aadFileUnique...),
+		footerKeyMetadata: footerKeyMetadata,
+		ciphers:           make(map[string]*moduleCipher),
+	}, nil
+}
+
+func (d *fileDecryptor) newCipher(keyMetadata []byte) (*moduleCipher, error) {
+	key, err := d.keys.RetrieveKey(keyMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return newModuleCipher(key, d.fileAAD, d.algorithm)
+}
+
+func (d *fileDecryptor) columnCipher(keyMetadata []byte) (*moduleCipher, error) {
+	if c, ok := d.ciphers[string(keyMetadata)]; ok {
+		return c, nil
+	}
+
+	c, err := d.newCipher(keyMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving column key failed: %w", err)
+	}
+	d.ciphers[string(keyMetadata)] = c
+
+	return c, nil
+}
+
+func (d *fileDecryptor) footerCipher() (*moduleCipher, error) {
+	if d.footer != nil {
+		return d.footer, nil
+	}
+
+	c, err := d.newCipher(d.footerKeyMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving footer key failed: %w", err)
+	}
+	d.footer = c
+
+	return c, nil
+}
+
+// chunkCipher returns the cipher of a column chunk, or nil if the column chunk is not encrypted.
+// A nil fileDecryptor indicates that no decryption was configured.
+func (d *fileDecryptor) chunkCipher(rowGroup, column int, chunk *parquet.ColumnChunk) (*chunkCipher, error) {
+	if chunk.CryptoMetadata == nil {
+		return nil, nil
+	}
+
+	if d == nil {
+		return nil, errors.New("column chunk is encrypted, but no decryption is configured")
+	}
+
+	var c *moduleCipher
+	var err error
+	if ck := chunk.CryptoMetadata.ENCRYPTION_WITH_COLUMN_KEY; ck != nil {
+		if c, err = d.columnCipher(ck.KeyMetadata); err != nil {
+			return nil, err
+		}
+	} else if c, err = d.footerCipher(); err != nil {
+		return nil, err
+	}
+
+	return &chunkCipher{
+		moduleCipher:   c,
+		rowGroup:       rowGroup,
+		column:         column,
+		cryptoMetaData: chunk.CryptoMetadata,
+	}, nil
+}
+
+// decryptColumnMetaData replaces the column metadata of all column chunks with encrypted column
+// metadata by its decrypted version. Column chunks whose key can't be retrieved are kept as they
+// are, so that the remaining columns can still be read.
+func (d *fileDecryptor) decryptColumnMetaData(ctx context.Context, meta *parquet.FileMetaData) error {
+	for i, rg := range meta.RowGroups {
+		for j, chunk := range rg.Columns {
+			if chunk.EncryptedColumnMetadata == nil {
+				continue
+			}
+
+			cc, err := d.chunkCipher(i, j, chunk)
+			if err != nil {
+				continue
+			}
+
+			plain, err := cc.open(chunk.EncryptedColumnMetadata, cc.aad(moduleColumnMetaData, -1))
+			if err != nil {
+				return fmt.Errorf("decrypting column meta data of column %d in row group %d failed: %w", j, i, err)
+			}
+
+			md := &parquet.ColumnMetaData{}
+			if err := readThrift(ctx, md, bytes.NewReader(plain)); err != nil {
+				return fmt.Errorf("read column meta data of column %d in row group %d failed: %w", j, i, err)
+			}
+			chunk.MetaData = md
+		}
+	}
+
+	return nil
+}
+
+// readEncryptedFooter reads the file meta data from an encrypted footer, which consists of the
+// plaintext file crypto meta data followed by the encrypted file meta data.
+func readEncryptedFooter(ctx context.Context, footer []byte, keys KeyRetriever, aadPrefix []byte) (*parquet.FileMetaData, *fileDecryptor, error) {
+	if keys == nil {
+		return nil, nil, errors.New("file has an encrypted footer, but no decryption is configured")
+	}
+
+	r := bytes.NewReader(footer)
+	cryptoMeta := &parquet.FileCryptoMetaData{}
+	if err := readThrift(ctx, cryptoMeta, r); err != nil {
+		return nil, nil, fmt.Errorf("read file crypto meta data failed: %w", err)
+	}
+
+	dec, err := newFileDecryptor(keys, aadPrefix, cryptoMeta.EncryptionAlgorithm, cryptoMeta.KeyMetadata)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c, err := dec.footerCipher()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := readModule(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plain, err := c.open(data, c.moduleAAD(moduleFooter, -1, -1, -1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("decrypting footer failed: %w", err)
+	}
+
+	meta := &parquet.FileMetaData{}
+	if err := readThrift(ctx, meta, bytes.NewReader(plain)); err != nil {
+		return nil, nil, fmt.Errorf("read file meta failed: %w", err)
+	}
+
+	return meta, dec, nil
+}
+
+// verifyPlaintextFooter creates the decryptor of a file with a plaintext footer and verifies the
+// footer signature. data is the serialized file meta data, followed by its signature.
+func verifyPlaintextFooter(meta *parquet.FileMetaData, data []byte, metaLength int, keys KeyRetriever, aadPrefix []byte) (*fileDecryptor, error) {
+	dec, err := newFileDecryptor(keys, aadPrefix, meta.EncryptionAlgorithm, meta.FooterSigningKeyMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := dec.footerCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.verify(data[:metaLength], c.moduleAAD(moduleFooter, -1, -1, -1), data[metaLength:]); err != nil {
+		return nil, err
+	}
+
+	return dec, nil
+}
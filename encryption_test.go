@@ -0,0 +1,308 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testFooterKey = []byte("0123456789012345")
+	testColumnKey = []byte("abcdefghijklmnopqrstuvwxyz012345")
+	testKeys      = StaticKeyRetriever{
+		"footer": testFooterKey,
+		"name":   testColumnKey,
+	}
+)
+
+func writeEncryptionTestFile(t *testing.T, opts ...FileWriterOption) []byte {
+	schema := `message test {
+		required int64 id;
+		optional binary name (STRING);
+		repeated int32 values;
+	}`
+
+	row := func(rg, i int) map[string]interface{} {
+		id := int64(rg*500 + i)
+		data := map[string]interface{}{
+			"id":     id,
+			"values": []int32{int32(id), int32(id % 7)},
+		}
+		if id%3 != 0 {
+			data["name"] = []byte(fmt.Sprintf("name-%d", id%50))
+		}
+		return data
+	}
+
+	return writeTestFile(t, schema, 2, 500, row, append([]FileWriterOption{WithMaxPageSize(512)}, opts...)...)
+}
+
+func requireEncryptionTestRows(t *testing.T, r *FileReader, columns ...string) {
+	for i := 0; i < 1000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+
+		expected := map[string]interface{}{
+			"id":     int64(i),
+			"values": []int32{int32(i), int32(i % 7)},
+		}
+		if i%3 != 0 {
+			expected["name"] = []byte(fmt.Sprintf("name-%d", i%50))
+		}
+		if len(columns) > 0 {
+			selected := map[string]interface{}{}
+			for _, c := range columns {
+				if v, ok := expected[c]; ok {
+					selected[c] = v
+				}
+			}
+			expected = selected
+		}
+		require.Equal(t, expected, row, "row %d", i)
+	}
+
+	_, err := r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestModuleAAD(t *testing.T) {
+	c, err := newModuleCipher(testFooterKey, []byte("prefix-unique"), EncryptionAESGCM)
+	require.NoError(t, err)
+
+	require.Equal(t, []byte("prefix-unique\x00"), c.moduleAAD(moduleFooter, 1, 2, 3))
+	require.Equal(t, []byte("prefix-unique\x01\x01\x00\x02\x00"), c.moduleAAD(moduleColumnMetaData, 1, 2, -1))
+	require.Equal(t, []byte("prefix-unique\x02\x01\x00\x02\x00\x03\x01"), c.moduleAAD(moduleDataPage, 1, 2, 259))
+}
+
+func TestModuleCipherRoundTrip(t *testing.T) {
+	for _, alg := range []EncryptionAlgorithm{EncryptionAESGCM, EncryptionAESGCMCTR} {
+		c, err := newModuleCipher(testColumnKey, []byte("aad"), alg)
+		require.NoError(t, err)
+
+		plain := []byte("hello, parquet")
+		aad := c.moduleAAD(moduleDataPage, 0, 0, 0)
+
+		module := c.encryptPageData(plain, aad)
+		data, err := readModule(bytes.NewReader(module))
+		require.NoError(t, err)
+
+		decrypted, err := c.decryptPageData(data, aad)
+		require.NoError(t, err, alg.String())
+		require.Equal(t, plain, decrypted)
+
+		if alg == EncryptionAESGCM {
+			_, err = c.decryptPageData(data, c.moduleAAD(moduleDataPage, 0, 0, 1))
+			require.Error(t, err, "page modules must not be interchangeable")
+		}
+
+		signature := c.sign(plain, aad)
+		require.NoError(t, c.verify(plain, aad, signature))
+		require.Error(t, c.verify([]byte("hello, Parquet"), aad, signature))
+	}
+}
+
+func TestWriteThenReadEncryptedFile(t *testing.T) {
+	testData := []struct {
+		name string
+		opts []FileWriterOption
+	}{
+		{"gcm", []FileWriterOption{WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer")))}},
+		{"gcm_ctr", []FileWriterOption{WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer")), WithEncryptionAlgorithm(EncryptionAESGCMCTR))}},
+		{"column_keys", []FileWriterOption{WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer")), WithColumnKey(ColumnPath{"name"}, testColumnKey, []byte("name")))}},
+		{"plaintext_footer", []FileWriterOption{WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer")), WithPlaintextFooter())}},
+		{"data_page_v2_crc", []FileWriterOption{WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer"))), WithDataPageV2(), WithCRC(true)}},
+		{"compressed", []FileWriterOption{WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer")), WithEncryptionAlgorithm(EncryptionAESGCMCTR)), WithCompressionCodec(parquet.CompressionCodec_SNAPPY)}},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]FileWriterOption{WithBloomFilter(ColumnPath{"id"}, 0, 0)}, tt.opts...)
+			data := writeEncryptionTestFile(t, opts...)
+			require.False(t, bytes.Contains(data, []byte("name-1")), "plaintext value found in encrypted file")
+
+			r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(testKeys), WithCRC32Validation(true))
+			require.NoError(t, err)
+			require.Equal(t, 2, r.RowGroupCount())
+
+			for rg := 0; rg < r.RowGroupCount(); rg++ {
+				for _, path := range []ColumnPath{{"id"}, {"name"}, {"values"}} {
+					offsetIdx, err := r.ReadOffsetIndex(rg, path)
+					require.NoError(t, err)
+					require.NotNil(t, offsetIdx)
+
+					colIdx, err := r.ReadColumnIndex(rg, path)
+					require.NoError(t, err)
+					require.NotNil(t, colIdx)
+				}
+
+				ok, err := r.MightContainInRowGroup(rg, ColumnPath{"id"}, int64(rg*500+42))
+				require.NoError(t, err)
+				require.True(t, ok)
+			}
+
+			requireEncryptionTestRows(t, r)
+
+			r, err = NewFileReaderAt(bytes.NewReader(data), int64(len(data)), WithDecryption(testKeys))
+			require.NoError(t, err)
+			requireEncryptionTestRows(t, r)
+
+			r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(testKeys), WithStreamingThreshold(0))
+			require.NoError(t, err)
+			requireEncryptionTestRows(t, r)
+		})
+	}
+}
+
+func TestReadEncryptedFooterWithoutDecryption(t *testing.T) {
+	data := writeEncryptionTestFile(t, WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer"))))
+	require.Equal(t, magicEncrypted, data[:4])
+	require.Equal(t, magicEncrypted, data[len(data)-4:])
+
+	_, err := ReadFileMetaData(bytes.NewReader(data), true)
+	require.Error(t, err)
+
+	_, err = NewFileReader(bytes.NewReader(data))
+	require.Error(t, err)
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(StaticKeyRetriever{"footer": testColumnKey}))
+	require.Error(t, err, "wrong footer key must be detected")
+}
+
+func TestReadPlaintextFooterWithoutDecryption(t *testing.T) {
+	data := writeEncryptionTestFile(t, WithEncryption(testFooterKey,
+		WithFooterKeyMetadata([]byte("footer")),
+		WithColumnKey(ColumnPath{"name"}, testColumnKey, []byte("name")),
+		WithPlaintextFooter(),
+	))
+	require.Equal(t, magic, data[:4])
+
+	meta, err := ReadFileMetaData(bytes.NewReader(data), true)
+	require.NoError(t, err)
+	require.NotNil(t, meta.EncryptionAlgorithm)
+	for _, rg := range meta.RowGroups {
+		require.Nil(t, rg.Columns[0].CryptoMetadata)
+		require.NotNil(t, rg.Columns[1].CryptoMetadata.ENCRYPTION_WITH_COLUMN_KEY)
+		require.NotNil(t, rg.Columns[1].EncryptedColumnMetadata)
+		require.Nil(t, rg.Columns[1].MetaData.Statistics, "statistics of encrypted columns must be removed")
+	}
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithColumnPaths(ColumnPath{"id"}, ColumnPath{"values"}))
+	require.NoError(t, err)
+	requireEncryptionTestRows(t, r, "id", "values")
+
+	r, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Error(t, err)
+
+	// the statistics are available after decrypting the column meta data.
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(testKeys))
+	require.NoError(t, err)
+	chunk, err := r.columnChunk(0, ColumnPath{"name"})
+	require.NoError(t, err)
+	require.NotNil(t, chunk.MetaData.Statistics)
+	requireEncryptionTestRows(t, r)
+}
+
+func TestReadEncryptedFileWithMissingColumnKey(t *testing.T) {
+	data := writeEncryptionTestFile(t, WithEncryption(testFooterKey,
+		WithFooterKeyMetadata([]byte("footer")),
+		WithColumnKey(ColumnPath{"name"}, testColumnKey, []byte("name")),
+	))
+
+	keys := StaticKeyRetriever{"footer": testFooterKey}
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(keys), WithColumnPaths(ColumnPath{"id"}, ColumnPath{"values"}))
+	require.NoError(t, err)
+	requireEncryptionTestRows(t, r, "id", "values")
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(keys))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Error(t, err)
+}
+
+func TestReadEncryptedFileWithoutKeyMetadata(t *testing.T) {
+	data := writeEncryptionTestFile(t, WithEncryption(testFooterKey, WithColumnKey(ColumnPath{"name"}, testColumnKey, nil)))
+
+	// the footer key and the column key both have empty key metadata, so they can only be told
+	// apart by the order in which they are retrieved.
+	var calls int
+	keys := KeyRetrieverFunc(func(keyMetadata []byte) ([]byte, error) {
+		calls++
+		if calls == 1 {
+			return testFooterKey, nil
+		}
+		return testColumnKey, nil
+	})
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(keys))
+	require.NoError(t, err)
+	requireEncryptionTestRows(t, r)
+	require.Equal(t, 2, calls)
+}
+
+func TestEncryptionAADPrefix(t *testing.T) {
+	prefix := []byte("file.parquet")
+
+	stored := writeEncryptionTestFile(t, WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer")), WithAADPrefix(prefix, false)))
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(stored), WithDecryption(testKeys))
+	require.NoError(t, err)
+	requireEncryptionTestRows(t, r)
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(stored), WithDecryption(testKeys), WithDecryptionAADPrefix([]byte("other.parquet")))
+	require.Error(t, err)
+
+	supplied := writeEncryptionTestFile(t, WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer")), WithAADPrefix(prefix, true)))
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(supplied), WithDecryption(testKeys))
+	require.Error(t, err)
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(supplied), WithDecryption(testKeys), WithDecryptionAADPrefix(prefix))
+	require.NoError(t, err)
+	requireEncryptionTestRows(t, r)
+}
+
+func TestEncryptedFileTampering(t *testing.T) {
+	data := writeEncryptionTestFile(t, WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer")), WithPlaintextFooter()))
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(testKeys))
+	require.NoError(t, err)
+	chunk, err := r.columnChunk(0, ColumnPath{"id"})
+	require.NoError(t, err)
+
+	// modify the last byte of the first page.
+	tampered := append([]byte{}, data...)
+	tampered[chunk.MetaData.DataPageOffset+chunk.MetaData.TotalCompressedSize-1] ^= 0xff
+	r, err = NewFileReaderWithOptions(bytes.NewReader(tampered), WithDecryption(testKeys))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Error(t, err)
+
+	// modify the last byte of the plaintext footer, right before its signature.
+	tampered = append([]byte{}, data...)
+	tampered[len(tampered)-8-encryptionNonceLength-encryptionTagLength-1] ^= 0xff
+	_, err = NewFileReaderWithOptions(bytes.NewReader(tampered), WithDecryption(testKeys))
+	require.Error(t, err)
+}
+
+func TestEncryptionInvalidKey(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	wr := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithEncryption([]byte("too short")))
+	require.NoError(t, wr.AddData(map[string]interface{}{"id": int64(1)}))
+	require.Error(t, wr.FlushRowGroup())
+
+	wr = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithEncryption(testFooterKey, WithColumnKey(ColumnPath{"foo"}, testColumnKey, nil)))
+	require.NoError(t, wr.AddData(map[string]interface{}{"id": int64(1)}))
+	require.Error(t, wr.FlushRowGroup())
+}
@@ -22,53 +22,87 @@ func ReadFileMetaData(r io.ReadSeeker, extraValidation bool) (*parquet.FileMetaD
 // ReadFileMetaDataWithContext reads and returns the meta data of a parquet file. You can use this function
 // to read and inspect the meta data before starting to read the whole parquet file.
 func ReadFileMetaDataWithContext(ctx context.Context, r io.ReadSeeker, extraValidation bool) (*parquet.FileMetaData, error) {
+	meta, _, err := readFileMetaData(ctx, r, extraValidation, nil, nil)
+	return meta, err
+}
+
+// readFileMetaData reads the meta data of a parquet file. If the file is encrypted and keys is not nil,
+// the footer is decrypted or its signature is verified, and a decryptor for the file is returned.
+func readFileMetaData(ctx context.Context, r io.ReadSeeker, extraValidation bool, keys KeyRetriever, aadPrefix []byte) (*parquet.FileMetaData, *fileDecryptor, error) {
+	// read footer length and file magic
+	if _, err := r.Seek(-8, io.SeekEnd); err != nil {
+		return nil, nil, fmt.Errorf("seek for the footer len failed: %w", err)
+	}
+	var fl int32
+	if err := binary.Read(r, binary.LittleEndian, &fl); err != nil {
+		return nil, nil, fmt.Errorf("read the footer len failed: %w", err)
+	}
+	if fl <= 0 {
+		return nil, nil, fmt.Errorf("invalid footer len %d", fl)
+	}
+
+	footerMagic := make([]byte, 4)
+	if _, err := io.ReadFull(r, footerMagic); err != nil {
+		return nil, nil, fmt.Errorf("read the file magic footer failed: %w", err)
+	}
+	encryptedFooter := bytes.Equal(footerMagic, magicEncrypted)
+
 	if extraValidation {
+		if !bytes.Equal(footerMagic, magic) && !encryptedFooter {
+			return nil, nil, errors.New("invalid parquet file footer")
+		}
+
 		if _, err := r.Seek(0, io.SeekStart); err != nil {
-			return nil, fmt.Errorf("seek for the file magic header failed: %w", err)
+			return nil, nil, fmt.Errorf("seek for the file magic header failed: %w", err)
 		}
 
 		buf := make([]byte, 4)
 		// read and validate header
 		if _, err := io.ReadFull(r, buf); err != nil {
-			return nil, fmt.Errorf("read the file magic header failed: %w", err)
+			return nil, nil, fmt.Errorf("read the file magic header failed: %w", err)
 		}
-		if !bytes.Equal(buf, magic) {
-			return nil, errors.New("invalid parquet file header")
+		if !bytes.Equal(buf, footerMagic) {
+			return nil, nil, errors.New("invalid parquet file header")
 		}
+	}
 
-		// read and validate footer
-		if _, err := r.Seek(-4, io.SeekEnd); err != nil {
-			return nil, fmt.Errorf("seek for the file magic footer failed: %w", err)
-		}
+	// read file metadata
+	if _, err := r.Seek(-8-int64(fl), io.SeekEnd); err != nil {
+		return nil, nil, fmt.Errorf("seek file meta data failed: %w", err)
+	}
+	footer := make([]byte, fl)
+	if _, err := io.ReadFull(r, footer); err != nil {
+		return nil, nil, fmt.Errorf("read file meta failed: %w", err)
+	}
 
-		if _, err := io.ReadFull(r, buf); err != nil {
-			return nil, fmt.Errorf("read the file magic header failed: %w", err)
+	if encryptedFooter {
+		meta, dec, err := readEncryptedFooter(ctx, footer, keys, aadPrefix)
+		if err != nil {
+			return nil, nil, err
 		}
-		if !bytes.Equal(buf, magic) {
-			return nil, errors.New("invalid parquet file footer")
+		if err := dec.decryptColumnMetaData(ctx, meta); err != nil {
+			return nil, nil, err
 		}
+		return meta, dec, nil
 	}
 
-	// read footer length
-	if _, err := r.Seek(-8, io.SeekEnd); err != nil {
-		return nil, fmt.Errorf("seek for the footer len failed: %w", err)
-	}
-	var fl int32
-	if err := binary.Read(r, binary.LittleEndian, &fl); err != nil {
-		return nil, fmt.Errorf("read the footer len failed: %w", err)
+	footerReader := bytes.NewReader(footer)
+	meta := &parquet.FileMetaData{}
+	if err := readThrift(ctx, meta, footerReader); err != nil {
+		return nil, nil, fmt.Errorf("read file meta failed: %w", err)
 	}
-	if fl <= 0 {
-		return nil, fmt.Errorf("invalid footer len %d", fl)
+
+	if meta.EncryptionAlgorithm == nil || keys == nil {
+		return meta, nil, nil
 	}
 
-	// read file metadata
-	if _, err := r.Seek(-8-int64(fl), io.SeekEnd); err != nil {
-		return nil, fmt.Errorf("seek file meta data failed: %w", err)
+	dec, err := verifyPlaintextFooter(meta, footer, len(footer)-footerReader.Len(), keys, aadPrefix)
+	if err != nil {
+		return nil, nil, err
 	}
-	meta := &parquet.FileMetaData{}
-	if err := readThrift(ctx, meta, io.LimitReader(r, int64(fl))); err != nil {
-		return nil, fmt.Errorf("read file meta failed: %w", err)
+	if err := dec.decryptColumnMetaData(ctx, meta); err != nil {
+		return nil, nil, err
 	}
 
-	return meta, nil
+	return meta, dec, nil
 }
@@ -2,6 +2,7 @@ package goparquet
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 
@@ -20,6 +21,24 @@ type FileReader struct {
 	currentRecord    int64
 	skipRowGroup     bool
 
+	// bloom filters that have already been read, identified by their column chunk.
+	bloomFilters map[*parquet.ColumnChunk]*bloomFilter
+
+	// readers for ReadColumnBatch, identified by the flat name of their column.
+	columnBatchReaders map[string]*columnBatchReader
+
+	rowGroupFilter boundPredicate
+	rowFilter      boundPredicate
+
+	// columns that are only read to evaluate the row filter, and are removed from the returned rows.
+	filterOnlyColumns []ColumnPath
+
+	// schema of the returned rows if it is different from the schema of the file.
+	readerSchema *parquetschema.SchemaDefinition
+	projection   *schemaProjection
+
+	readOptions readRowGroupOptions
+
 	ctx context.Context
 }
 
@@ -32,12 +51,27 @@ func NewFileReaderWithOptions(r io.ReadSeeker, readerOptions ...FileReaderOption
 		return nil, err
 	}
 
-	var err error
+	if _, ok := r.(io.ReaderAt); opts.concurrency > 1 && !ok {
+		return nil, errors.New("reading with concurrency requires a reader that implements io.ReaderAt")
+	}
+
+	var (
+		err       error
+		decryptor *fileDecryptor
+	)
 	if opts.metaData == nil {
-		opts.metaData, err = ReadFileMetaData(r, true)
+		opts.metaData, decryptor, err = readFileMetaData(opts.ctx, r, true, opts.keyRetriever, opts.aadPrefix)
 		if err != nil {
 			return nil, fmt.Errorf("reading file meta data failed: %w", err)
 		}
+	} else if opts.metaData.EncryptionAlgorithm != nil && opts.keyRetriever != nil {
+		decryptor, err = newFileDecryptor(opts.keyRetriever, opts.aadPrefix, opts.metaData.EncryptionAlgorithm, opts.metaData.FooterSigningKeyMetadata)
+		if err != nil {
+			return nil, err
+		}
+		if err := decryptor.decryptColumnMetaData(opts.ctx, opts.metaData); err != nil {
+			return nil, err
+		}
 	}
 
 	schema, err := makeSchema(opts.metaData, opts.validateCRC)
@@ -45,17 +79,68 @@ func NewFileReaderWithOptions(r io.ReadSeeker, readerOptions ...FileReaderOption
 		return nil, fmt.Errorf("creating schema failed: %w", err)
 	}
 
-	schema.SetSelectedColumns(opts.columns...)
+	fr := &FileReader{
+		meta:         opts.metaData,
+		schemaReader: schema,
+		reader:       r,
+		readOptions: readRowGroupOptions{
+			decryptor:          decryptor,
+			concurrency:        opts.concurrency,
+			maxReadGap:         opts.maxReadGap,
+			streamingThreshold: opts.streamingThreshold,
+		},
+		ctx: opts.ctx,
+	}
+
+	if opts.rowGroupFilter != nil {
+		if fr.rowGroupFilter, err = opts.rowGroupFilter.bind(schema); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.rowFilter != nil {
+		if fr.rowFilter, err = opts.rowFilter.bind(schema); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.readerSchema != nil {
+		if len(opts.columns) > 0 {
+			return nil, errors.New("a reader schema can't be combined with selected columns")
+		}
+		if fr.projection, err = newSchemaProjection(schema.GetSchemaDefinition(), opts.readerSchema); err != nil {
+			return nil, fmt.Errorf("reader schema is incompatible with the file schema: %w", err)
+		}
+		fr.readerSchema = opts.readerSchema
+		// if no column of the reader schema exists in the file, no column chunks are read.
+		fr.selectColumns(fr.projection.columns, false)
+	} else {
+		fr.SetSelectedColumnsByPath(opts.columns...)
+	}
+
 	// Reset the reader to the beginning of the file
 	if _, err := r.Seek(4, io.SeekStart); err != nil {
 		return nil, err
 	}
-	return &FileReader{
-		meta:         opts.metaData,
-		schemaReader: schema,
-		reader:       r,
-		ctx:          opts.ctx,
-	}, nil
+	return fr, nil
+}
+
+// NewFileReaderAt creates a new FileReader that reads a parquet file of the provided size from r.
+// Instead of seeking to every column chunk, the reader plans the byte ranges of all selected column
+// chunks of a row group and reads them using as few calls to ReadAt as possible. Byte ranges that
+// are at most 1 MiB apart are read at once, which can be configured using WithMaxReadGap. This
+// makes the reader well suited for files in object storage, where every read is a separate request.
+// You can provide a list of FileReaderOptions to configure the reader, see NewFileReaderWithOptions.
+func NewFileReaderAt(r io.ReaderAt, size int64, readerOptions ...FileReaderOption) (*FileReader, error) {
+	fr, err := NewFileReaderWithOptions(io.NewSectionReader(r, 0, size), readerOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	fr.readOptions.rangeReader = r
+	fr.readOptions.fileSize = size
+
+	return fr, nil
 }
 
 // FileReaderOption is an option that can be passed on to NewFileReaderWithOptions when
@@ -66,10 +151,23 @@ type fileReaderOptions struct {
 	ctx         context.Context
 	columns     []ColumnPath
 	validateCRC bool
+
+	rowGroupFilter Predicate
+	rowFilter      Predicate
+
+	keyRetriever KeyRetriever
+	aadPrefix    []byte
+
+	concurrency int
+	maxReadGap  int64
+
+	streamingThreshold int64
+
+	readerSchema *parquetschema.SchemaDefinition
 }
 
 func newFileReaderOptions() *fileReaderOptions {
-	return &fileReaderOptions{ctx: context.Background()}
+	return &fileReaderOptions{ctx: context.Background(), maxReadGap: defaultMaxReadGap, streamingThreshold: -1}
 }
 
 func (o *fileReaderOptions) apply(opts []FileReaderOption) error {
@@ -133,6 +231,118 @@ func WithCRC32Validation(enable bool) FileReaderOption {
 	}
 }
 
+// WithRowGroupFilter configures a predicate to skip row groups while reading. Row groups whose
+// column chunk statistics prove that none of their rows match the predicate are skipped when
+// reading rows sequentially, e.g. using NextRow. Row groups that are explicitly selected using
+// SeekToRowGroup are not skipped. Please note that the remaining row groups may still contain
+// rows that don't match the predicate.
+func WithRowGroupFilter(pred Predicate) FileReaderOption {
+	return func(opts *fileReaderOptions) error {
+		opts.rowGroupFilter = pred
+		return nil
+	}
+}
+
+// WithRowFilter configures a predicate that rows need to match to be returned by NextRow. Columns that
+// are referenced by the predicate are read even if they are not selected, but they are not included in
+// the returned rows. Row groups whose column chunk statistics prove that none of their rows match the
+// predicate are skipped, like with WithRowGroupFilter.
+func WithRowFilter(pred Predicate) FileReaderOption {
+	return func(opts *fileReaderOptions) error {
+		opts.rowFilter = pred
+		return nil
+	}
+}
+
+// WithDecryption enables reading encrypted files. The footer key and the column keys are retrieved
+// from keys, using the key metadata that was stored in the file. Columns whose key can't be retrieved
+// are not accessible, but all other columns can still be read. Files with a plaintext footer can be
+// read without decryption, as long as only plaintext columns are selected.
+//
+// If the file meta data is provided using WithFileMetaData, decryption is only supported for files with
+// a plaintext footer, and the footer signature is not verified.
+func WithDecryption(keys KeyRetriever) FileReaderOption {
+	return func(opts *fileReaderOptions) error {
+		opts.keyRetriever = keys
+		return nil
+	}
+}
+
+// WithDecryptionAADPrefix sets the AAD prefix that is required to decrypt files that were written
+// with an AAD prefix that is not stored in the file. See WithAADPrefix.
+func WithDecryptionAADPrefix(prefix []byte) FileReaderOption {
+	return func(opts *fileReaderOptions) error {
+		opts.aadPrefix = prefix
+		return nil
+	}
+}
+
+// WithReaderConcurrency sets the maximum number of column chunks of a row group that are read and
+// decoded in parallel. By default, column chunks are read one after another. Reading column chunks
+// in parallel requires independent reads, so the reader passed to NewFileReaderWithOptions needs to
+// implement io.ReaderAt, as e.g. *os.File and *bytes.Reader do.
+func WithReaderConcurrency(n int) FileReaderOption {
+	return func(opts *fileReaderOptions) error {
+		if n < 1 {
+			return fmt.Errorf("invalid reader concurrency %d", n)
+		}
+		opts.concurrency = n
+		return nil
+	}
+}
+
+// WithMaxReadGap sets the maximum number of bytes between the byte ranges of two column chunks
+// that are still read using a single read. Larger values reduce the number of reads at the cost
+// of reading data that isn't needed. It is only used by readers created with NewFileReaderAt.
+func WithMaxReadGap(n int64) FileReaderOption {
+	return func(opts *fileReaderOptions) error {
+		if n < 0 {
+			return fmt.Errorf("invalid maximum read gap %d", n)
+		}
+		opts.maxReadGap = n
+		return nil
+	}
+}
+
+// WithStreamingThreshold configures when the pages of a row group are read one at a time. By
+// default, all pages of the selected column chunks of a row group are read into memory when the
+// row group is loaded. If the selected column chunks of a row group are larger than n bytes when
+// uncompressed, only the current page of every column is kept in memory instead, and the next
+// page is read when it is needed by NextRow. If the reader implements io.ReaderAt, the pages of
+// every column are read in blocks of 64 KiB, so that small pages don't need a read each. With a
+// threshold of 0, all row groups are read page by page. n is not a limit of the memory used: it is
+// only compared to the size of the column chunks in the row group metadata, and the memory used to
+// read a row group page by page depends on the sizes of its pages. No memory budget is enforced.
+func WithStreamingThreshold(n int64) FileReaderOption {
+	return func(opts *fileReaderOptions) error {
+		if n < 0 {
+			return fmt.Errorf("invalid streaming threshold %d", n)
+		}
+		opts.streamingThreshold = n
+		return nil
+	}
+}
+
+// WithReaderSchema configures the schema of the rows returned by NextRow, which can be different
+// from the schema of the file, as long as it is compatible. Columns of the reader schema are matched
+// to the columns of the file by their field ID if it is set, and by their name otherwise. Columns
+// of the file that are not part of the reader schema are not read. Optional and repeated columns of
+// the reader schema that don't exist in the file are always null or empty, and required columns
+// need to exist in the file. Required columns of the file can be read as optional columns, INT32
+// values are promoted to INT64, and FLOAT values are promoted to DOUBLE. Creating the reader fails
+// if the reader schema isn't compatible with the schema of the file.
+//
+// The reader schema can't be combined with WithColumns or WithColumnPaths. Row filters and row group
+// filters still refer to the columns of the file. The columns of the file that are read are selected
+// from the reader schema, and must not be changed using SetSelectedColumns or SetSelectedColumnsByPath,
+// which would replace them.
+func WithReaderSchema(sd *parquetschema.SchemaDefinition) FileReaderOption {
+	return func(opts *fileReaderOptions) error {
+		opts.readerSchema = sd
+		return nil
+	}
+}
+
 // NewFileReader creates a new FileReader. You can limit the columns that are read by providing
 // the names of the specific columns to read using dotted notation. If no columns are provided,
 // then all columns are read.
@@ -173,11 +383,95 @@ func (f *FileReader) SeekToRowGroupWithContext(ctx context.Context, rowGroupPosi
 
 // readRowGroup read the next row group into memory
 func (f *FileReader) readRowGroup(ctx context.Context) error {
+	return f.readRowGroupFrom(ctx, 0)
+}
+
+// readRowGroupFrom reads the next row group into memory, skipping all rows before firstRow.
+func (f *FileReader) readRowGroupFrom(ctx context.Context, firstRow int64) error {
 	if len(f.meta.RowGroups) <= f.rowGroupPosition {
 		return io.EOF
 	}
 	f.rowGroupPosition++
-	return readRowGroup(ctx, f.reader, f.schemaReader, f.meta.RowGroups[f.rowGroupPosition-1])
+	return readRowGroup(ctx, f.reader, f.schemaReader, f.meta.RowGroups[f.rowGroupPosition-1], f.rowGroupPosition-1, firstRow, &f.readOptions)
+}
+
+// SeekToRow seeks to the row with index n, counting from 0 for the first row of the file, so that
+// the next call to NextRow returns this row. The row group containing the row is determined from
+// the number of rows of the row groups, and all pages before the row are skipped without decoding
+// them, using the offset index if the file has one, or the page headers otherwise. If n is equal
+// to the number of rows in the file, the next call to NextRow returns io.EOF.
+func (f *FileReader) SeekToRow(n int64) error {
+	return f.SeekToRowWithContext(f.ctx, n)
+}
+
+// SeekToRowWithContext seeks to the row with index n, counting from 0 for the first row of the file,
+// so that the next call to NextRow returns this row. See SeekToRow for details.
+func (f *FileReader) SeekToRowWithContext(ctx context.Context, n int64) error {
+	if n < 0 {
+		return fmt.Errorf("row %d out of range", n)
+	}
+
+	for i, rg := range f.meta.RowGroups {
+		if n < rg.NumRows {
+			f.rowGroupPosition = i
+			f.skipRowGroup = false
+			if err := f.readRowGroupFrom(ctx, n); err != nil {
+				return err
+			}
+			f.currentRecord = n
+			return nil
+		}
+		n -= rg.NumRows
+	}
+
+	if n > 0 {
+		return fmt.Errorf("row %d out of range", n+f.meta.NumRows)
+	}
+
+	f.rowGroupPosition = len(f.meta.RowGroups)
+	f.skipRowGroup = true
+	return nil
+}
+
+// ReadRows returns the rows with an index from from up to but not including to, counting from 0
+// for the first row of the file. It seeks to the row from using SeekToRow. If a row filter is
+// configured, only the rows in the range that match it are returned. After ReadRows, the next call
+// to NextRow returns the row with the index to.
+func (f *FileReader) ReadRows(from, to int64) ([]map[string]interface{}, error) {
+	return f.ReadRowsWithContext(f.ctx, from, to)
+}
+
+// ReadRowsWithContext returns the rows with an index from from up to but not including to, counting
+// from 0 for the first row of the file. See ReadRows for details.
+func (f *FileReader) ReadRowsWithContext(ctx context.Context, from, to int64) ([]map[string]interface{}, error) {
+	if from > to || to > f.meta.NumRows {
+		return nil, fmt.Errorf("invalid row range [%d, %d) for %d rows", from, to, f.meta.NumRows)
+	}
+
+	if err := f.SeekToRowWithContext(ctx, from); err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]interface{}, 0, to-from)
+	for i := from; i < to; i++ {
+		if f.currentRecord >= f.schemaReader.rowGroupNumRecords() || f.skipRowGroup {
+			if err := f.readRowGroup(ctx); err != nil {
+				return nil, err
+			}
+			f.currentRecord = 0
+			f.skipRowGroup = false
+		}
+
+		row, ok, err := f.readCurrentRow()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
 }
 
 // CurrentRowGroup returns information about the current row group.
@@ -201,6 +495,7 @@ func (f *FileReader) NumRows() int64 {
 
 func (f *FileReader) advanceIfNeeded(ctx context.Context) error {
 	if f.rowGroupPosition == 0 || f.currentRecord >= f.schemaReader.rowGroupNumRecords() || f.skipRowGroup {
+		f.skipFilteredRowGroups()
 		if err := f.readRowGroup(ctx); err != nil {
 			f.skipRowGroup = true
 			return err
@@ -212,6 +507,33 @@ func (f *FileReader) advanceIfNeeded(ctx context.Context) error {
 	return nil
 }
 
+// skipFilteredRowGroups advances the row group position past all row groups that can't match the
+// row group filter or the row filter.
+func (f *FileReader) skipFilteredRowGroups() {
+	for f.rowGroupPosition < len(f.meta.RowGroups) && f.canSkipRowGroup(f.rowGroupPosition) {
+		f.rowGroupPosition++
+	}
+}
+
+func (f *FileReader) canSkipRowGroup(rowGroup int) bool {
+	for _, pred := range []boundPredicate{f.rowGroupFilter, f.rowFilter} {
+		if pred != nil && pred.canSkip(f.chunkStats(rowGroup)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *FileReader) chunkStats(rowGroup int) chunkStatsFunc {
+	return func(path ColumnPath) *chunkStats {
+		chunk, err := f.columnChunk(rowGroup, path)
+		if err != nil {
+			return nil
+		}
+		return newChunkStats(f.meta, f.schemaReader, chunk)
+	}
+}
+
 // RowGroupNumRows returns the number of rows in the current RowGroup.
 func (f *FileReader) RowGroupNumRows() (int64, error) {
 	return f.RowGroupNumRowsWithContext(f.ctx)
@@ -227,18 +549,86 @@ func (f *FileReader) RowGroupNumRowsWithContext(ctx context.Context) (int64, err
 }
 
 // NextRow reads the next row from the parquet file. If required, it will load the next row group.
+// If a row filter is configured, rows that don't match it are skipped.
 func (f *FileReader) NextRow() (map[string]interface{}, error) {
 	return f.NextRowWithContext(f.ctx)
 }
 
 // NextRowWithContext reads the next row from the parquet file. If required, it will load the next row group.
+// If a row filter is configured, rows that don't match it are skipped.
 func (f *FileReader) NextRowWithContext(ctx context.Context) (map[string]interface{}, error) {
-	if err := f.advanceIfNeeded(ctx); err != nil {
-		return nil, err
+	for {
+		if err := f.advanceIfNeeded(ctx); err != nil {
+			return nil, err
+		}
+
+		row, ok, err := f.readCurrentRow()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return row, nil
+		}
 	}
+}
 
+// readCurrentRow reads the next row of the current row group. It returns false if the row doesn't
+// match the row filter.
+func (f *FileReader) readCurrentRow() (map[string]interface{}, bool, error) {
 	f.currentRecord++
-	return f.schemaReader.getData()
+
+	// the row filter is evaluated on the values of the filter columns first, so that the values of
+	// rows that don't match are skipped instead of being assembled into a row.
+	filtered := false
+	if f.rowFilter != nil {
+		matches, ok, err := f.peekRowFilter()
+		if err != nil {
+			return nil, false, err
+		}
+		if ok && !matches {
+			return nil, false, f.schemaReader.skipRow()
+		}
+		filtered = ok
+	}
+
+	row, err := f.schemaReader.getData()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if f.rowFilter != nil && !filtered && !f.rowFilter.matches(mapRowValues(row)) {
+		return nil, false, nil
+	}
+
+	for _, path := range f.filterOnlyColumns {
+		removeColumn(row, path)
+	}
+
+	if f.projection != nil {
+		row = f.projection.convert(row)
+	}
+
+	return row, true, nil
+}
+
+// peekRowFilter evaluates the row filter on the values of the next row without reading the row.
+// It returns false if the values of a filter column can't be determined from its current page.
+func (f *FileReader) peekRowFilter() (bool, bool, error) {
+	var (
+		conclusive = true
+		peekErr    error
+	)
+
+	matches := f.rowFilter.matches(func(path ColumnPath) []interface{} {
+		values, ok, err := f.schemaReader.peekRowValues(path)
+		if err != nil && peekErr == nil {
+			peekErr = err
+		}
+		conclusive = conclusive && ok
+		return values
+	})
+
+	return matches, conclusive, peekErr
 }
 
 // SkipRowGroup skips the currently loaded row group and advances to the next row group.
@@ -280,6 +670,133 @@ func (f *FileReader) ColumnMetaDataByPath(path ColumnPath) (map[string]string, e
 	return nil, fmt.Errorf("column %q not found", path.flatName())
 }
 
+// ReadColumnIndex reads the column index of the column identified by path in the provided
+// row group. If the column chunk has no column index, nil is returned.
+func (f *FileReader) ReadColumnIndex(rowGroup int, path ColumnPath) (*parquet.ColumnIndex, error) {
+	return f.ReadColumnIndexWithContext(f.ctx, rowGroup, path)
+}
+
+// ReadColumnIndexWithContext reads the column index of the column identified by path in the provided
+// row group. If the column chunk has no column index, nil is returned.
+func (f *FileReader) ReadColumnIndexWithContext(ctx context.Context, rowGroup int, path ColumnPath) (*parquet.ColumnIndex, error) {
+	chunk, err := f.columnChunk(rowGroup, path)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := f.chunkCipher(rowGroup, chunk)
+	if err != nil {
+		return nil, err
+	}
+	return readColumnIndex(ctx, f.reader, chunk, cc)
+}
+
+// ReadOffsetIndex reads the offset index of the column identified by path in the provided
+// row group. If the column chunk has no offset index, nil is returned.
+func (f *FileReader) ReadOffsetIndex(rowGroup int, path ColumnPath) (*parquet.OffsetIndex, error) {
+	return f.ReadOffsetIndexWithContext(f.ctx, rowGroup, path)
+}
+
+// ReadOffsetIndexWithContext reads the offset index of the column identified by path in the provided
+// row group. If the column chunk has no offset index, nil is returned.
+func (f *FileReader) ReadOffsetIndexWithContext(ctx context.Context, rowGroup int, path ColumnPath) (*parquet.OffsetIndex, error) {
+	chunk, err := f.columnChunk(rowGroup, path)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := f.chunkCipher(rowGroup, chunk)
+	if err != nil {
+		return nil, err
+	}
+	return readOffsetIndex(ctx, f.reader, chunk, cc)
+}
+
+// MightContain checks the bloom filter of the column identified by path in the current
+// row group whether it might contain value. If the method returns false, the value is definitely
+// not contained in the column chunk. If the column chunk has no bloom filter, true is returned.
+// The value needs to be of the same type as the values returned by NextRow for this column,
+// except for BYTE_ARRAY and FIXED_LEN_BYTE_ARRAY columns, which also accept strings.
+func (f *FileReader) MightContain(path ColumnPath, value interface{}) (bool, error) {
+	return f.MightContainWithContext(f.ctx, path, value)
+}
+
+// MightContainWithContext checks the bloom filter of the column identified by path in the current
+// row group whether it might contain value. See MightContain for details.
+func (f *FileReader) MightContainWithContext(ctx context.Context, path ColumnPath, value interface{}) (bool, error) {
+	if err := f.advanceIfNeeded(ctx); err != nil {
+		return false, err
+	}
+
+	return f.MightContainInRowGroupWithContext(ctx, f.rowGroupPosition-1, path, value)
+}
+
+// MightContainInRowGroup checks the bloom filter of the column identified by path in the provided
+// row group whether it might contain value. Unlike MightContain, it doesn't require the row group
+// to be loaded, so it can be used to decide which row groups to skip. See MightContain for details.
+func (f *FileReader) MightContainInRowGroup(rowGroup int, path ColumnPath, value interface{}) (bool, error) {
+	return f.MightContainInRowGroupWithContext(f.ctx, rowGroup, path, value)
+}
+
+// MightContainInRowGroupWithContext checks the bloom filter of the column identified by path in the
+// provided row group whether it might contain value. See MightContainInRowGroup for details.
+func (f *FileReader) MightContainInRowGroupWithContext(ctx context.Context, rowGroup int, path ColumnPath, value interface{}) (bool, error) {
+	chunk, err := f.columnChunk(rowGroup, path)
+	if err != nil {
+		return false, err
+	}
+
+	h, err := bloomFilterHash(chunk.MetaData.Type, value)
+	if err != nil {
+		return false, err
+	}
+
+	bf, ok := f.bloomFilters[chunk]
+	if !ok {
+		cc, err := f.chunkCipher(rowGroup, chunk)
+		if err != nil {
+			return false, err
+		}
+		bf, err = readBloomFilter(ctx, f.reader, chunk, cc)
+		if err != nil {
+			return false, err
+		}
+		if f.bloomFilters == nil {
+			f.bloomFilters = make(map[*parquet.ColumnChunk]*bloomFilter)
+		}
+		f.bloomFilters[chunk] = bf
+	}
+
+	if bf == nil {
+		return true, nil
+	}
+
+	return bf.check(h), nil
+}
+
+func (f *FileReader) columnChunk(rowGroup int, path ColumnPath) (*parquet.ColumnChunk, error) {
+	if rowGroup < 0 || rowGroup >= len(f.meta.RowGroups) {
+		return nil, fmt.Errorf("row group %d out of range", rowGroup)
+	}
+	for _, col := range f.meta.RowGroups[rowGroup].Columns {
+		if col.MetaData != nil && path.Equal(ColumnPath(col.MetaData.PathInSchema)) {
+			return col, nil
+		}
+	}
+	return nil, fmt.Errorf("column %q not found", path.flatName())
+}
+
+func (f *FileReader) chunkCipher(rowGroup int, chunk *parquet.ColumnChunk) (*chunkCipher, error) {
+	for i, col := range f.meta.RowGroups[rowGroup].Columns {
+		if col == chunk {
+			cc, err := f.readOptions.decryptor.chunkCipher(rowGroup, i, chunk)
+			if err != nil {
+				return nil, fmt.Errorf("column %q: %w", ColumnPath(chunk.MetaData.PathInSchema).flatName(), err)
+			}
+			return cc, nil
+		}
+	}
+	return nil, nil
+}
+
 // SetSelectedColumns sets the columns which are read. By default, all columns
 // will be read.
 //
@@ -289,13 +806,74 @@ func (f *FileReader) SetSelectedColumns(cols ...string) {
 	for _, c := range cols {
 		parsedCols = append(parsedCols, parseColumnPath(c))
 	}
-	f.schemaReader.SetSelectedColumns(parsedCols...)
+	f.SetSelectedColumnsByPath(parsedCols...)
 }
 
+// SetSelectedColumnsByPath sets the columns which are read. By default, all columns
+// will be read. Columns that are referenced by the row filter are read as well, but
+// are not included in the rows returned by NextRow. It must not be used with a reader
+// schema, see WithReaderSchema.
 func (f *FileReader) SetSelectedColumnsByPath(cols ...ColumnPath) {
+	f.selectColumns(cols, len(cols) == 0)
+}
+
+// selectColumns sets the columns which are read, or selects all columns if all is true. If cols is
+// empty and all is false, only the columns that are referenced by the row filter are read.
+func (f *FileReader) selectColumns(cols []ColumnPath, all bool) {
+	f.filterOnlyColumns = nil
+
+	if all {
+		f.schemaReader.SetSelectedColumns()
+		return
+	}
+	if f.rowFilter == nil {
+		f.setSchemaColumns(cols)
+		return
+	}
+
+	selected := append([]ColumnPath{}, cols...)
+
+	for _, path := range f.rowFilter.columns() {
+		if prefix := unselectedPrefix(cols, path); prefix != nil {
+			f.filterOnlyColumns = append(f.filterOnlyColumns, prefix)
+			selected = append(selected, path)
+		}
+	}
+
+	f.setSchemaColumns(selected)
+}
+
+// setSchemaColumns selects the columns cols of the schema reader, or no columns if cols is empty.
+func (f *FileReader) setSchemaColumns(cols []ColumnPath) {
+	if len(cols) == 0 {
+		f.schemaReader.selectNoColumns()
+		return
+	}
 	f.schemaReader.SetSelectedColumns(cols...)
 }
 
+// unselectedPrefix returns the shortest prefix of path that is neither selected nor the parent
+// of a selected column, or nil if path is selected.
+func unselectedPrefix(selected []ColumnPath, path ColumnPath) ColumnPath {
+	for i := 1; i <= len(path); i++ {
+		prefix := path[:i]
+
+		used := false
+		for _, s := range selected {
+			if s.HasPrefix(prefix) || prefix.HasPrefix(s) {
+				used = true
+				break
+			}
+		}
+
+		if !used {
+			return prefix
+		}
+	}
+
+	return nil
+}
+
 // Columns returns the list of columns.
 func (f *FileReader) Columns() []*Column {
 	return f.schemaReader.Columns()
@@ -313,8 +891,12 @@ func (f *FileReader) GetColumnByPath(path ColumnPath) *Column {
 	return f.schemaReader.GetColumnByPath(path)
 }
 
-// GetSchemaDefinition returns the current schema definition.
+// GetSchemaDefinition returns the current schema definition. If a reader schema was configured
+// using WithReaderSchema, the reader schema is returned.
 func (f *FileReader) GetSchemaDefinition() *parquetschema.SchemaDefinition {
+	if f.readerSchema != nil {
+		return f.readerSchema
+	}
 	return f.schemaReader.GetSchemaDefinition()
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/fraugster/parquet-go/parquet"
@@ -28,6 +29,12 @@ type FileWriter struct {
 
 	rowGroups []*parquet.RowGroup
 
+	writePageIndex bool
+	chunkIndexes   [][]*chunkIndexes
+
+	encryption *encryptionConfig
+	encryptor  *fileEncryptor
+
 	codec parquet.CompressionCodec
 
 	newPageFunc newDataPageFunc
@@ -35,6 +42,15 @@ type FileWriter struct {
 	ctx context.Context
 
 	schemaDef *parquetschema.SchemaDefinition
+
+	// columns the rows are sorted by, see WithSortingColumns and WithGlobalSort.
+	sortingColumns []SortingColumn
+	sorter         *rowSorter
+	globalSort     *globalSort
+
+	// err is an error of a FileWriterOption or of setting the schema definition in NewFileWriter.
+	// It is returned by all methods that write data.
+	err error
 }
 
 // FileWriterOption describes an option function that is applied to a FileWriter when it is created.
@@ -48,13 +64,14 @@ func NewFileWriter(w io.Writer, options ...FileWriterOption) *FileWriter {
 			w:   w,
 			pos: 0,
 		},
-		version:      1,
-		schemaWriter: &schema{},
-		kvStore:      make(map[string]string),
-		rowGroups:    []*parquet.RowGroup{},
-		createdBy:    "parquet-go",
-		newPageFunc:  newDataPageV1Writer,
-		ctx:          context.Background(),
+		version:        1,
+		schemaWriter:   &schema{},
+		kvStore:        make(map[string]string),
+		rowGroups:      []*parquet.RowGroup{},
+		writePageIndex: true,
+		createdBy:      "parquet-go",
+		newPageFunc:    newDataPageV1Writer,
+		ctx:            context.Background(),
 	}
 
 	for _, opt := range options {
@@ -63,10 +80,8 @@ func NewFileWriter(w io.Writer, options ...FileWriterOption) *FileWriter {
 
 	// if a WithSchemaDefinition option was provided, the schema needs to be set after everything else
 	// as other options can change settings on the schemaWriter (such as the maximum page size).
-	if fw.schemaDef != nil {
-		if err := fw.schemaWriter.SetSchemaDefinition(fw.schemaDef); err != nil {
-			panic(err) // TODO: this shouldn't happen, but still isn't great. We messed up the API design for options and NewFileWriter.
-		}
+	if fw.schemaDef != nil && fw.err == nil {
+		fw.err = fw.schemaWriter.SetSchemaDefinition(fw.schemaDef)
 	}
 
 	return fw
@@ -93,6 +108,14 @@ func WithCompressionCodec(codec parquet.CompressionCodec) FileWriterOption {
 	}
 }
 
+// WithColumnCompressionCodec sets the compression codec used for a particular column that is
+// identified by its ColumnPath, overriding the compression codec set using WithCompressionCodec.
+func WithColumnCompressionCodec(path ColumnPath, codec parquet.CompressionCodec) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.schemaWriter.setColumnCodec(path, codec)
+	}
+}
+
 // WithMetaData sets the key-value meta data on the file.
 func WithMetaData(data map[string]string) FileWriterOption {
 	return func(fw *FileWriter) {
@@ -127,6 +150,35 @@ func WithSchemaDefinition(sd *parquetschema.SchemaDefinition) FileWriterOption {
 	}
 }
 
+// WithColumnEncoding sets the encoding and whether to use a dictionary for a particular column
+// that is identified by its ColumnPath. The setting is only used for columns that are created from
+// a schema definition, i.e. when using WithSchemaDefinition or SetSchemaDefinition. If a dictionary
+// is used, enc is the encoding for the data pages if the dictionary grows too large. Using
+// parquet.Encoding_RLE_DICTIONARY or parquet.Encoding_PLAIN_DICTIONARY as encoding is equivalent
+// to PLAIN encoding with a dictionary.
+//
+// Columns without explicit configuration use PLAIN encoding, and a dictionary is used when it
+// reduces the size of the column chunk. Please be aware that setting an encoding that isn't
+// supported for the column's type makes setting the schema definition fail. If the schema
+// definition is set using WithSchemaDefinition, the error is returned when data is written
+// or the FileWriter is closed.
+func WithColumnEncoding(path ColumnPath, enc parquet.Encoding, useDict bool) FileWriterOption {
+	return func(fw *FileWriter) {
+		switch enc {
+		case parquet.Encoding_RLE_DICTIONARY, parquet.Encoding_PLAIN_DICTIONARY:
+			enc, useDict = parquet.Encoding_PLAIN, true
+		case parquet.Encoding_PLAIN, parquet.Encoding_RLE, parquet.Encoding_DELTA_BINARY_PACKED,
+			parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY, parquet.Encoding_DELTA_BYTE_ARRAY, parquet.Encoding_BYTE_STREAM_SPLIT:
+		default:
+			if fw.err == nil {
+				fw.err = fmt.Errorf("column %q: encoding %s is not supported for writing", path.flatName(), enc)
+			}
+			return
+		}
+		fw.schemaWriter.setColumnEncoding(path, enc, useDict)
+	}
+}
+
 // WithDataPageV2 enables the writer to write pages in the new V2 format. By default,
 // the library is using the V1 format. Please be aware that this may cause compatibility
 // issues with older implementations of parquet.
@@ -142,6 +194,42 @@ func WithCRC(enableCRC bool) FileWriterOption {
 	}
 }
 
+// WithPageIndex enables or disables writing the page index, i.e. the column index and the
+// offset index of all column chunks. The page index is written by default. The column index
+// contains the minimum and maximum value as well as the null count of every data page, while
+// the offset index contains the location of every data page. Column chunks of types that
+// don't provide page statistics (such as BOOLEAN and INT96) only get an offset index.
+func WithPageIndex(enable bool) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.writePageIndex = enable
+	}
+}
+
+// WithBloomFilter enables writing a split block bloom filter for the column identified by path.
+// The bloom filter of every column chunk is sized to hold ndv distinct values with a false-positive
+// probability of fpp. If ndv is 0 or less, the number of distinct values in the column chunk is
+// used. If fpp is not greater than 0 and less than 1, a false-positive probability of 0.01 is used.
+// Bloom filters are not supported for BOOLEAN columns.
+func WithBloomFilter(path ColumnPath, ndv int64, fpp float64) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.schemaWriter.setBloomFilter(path, ndv, fpp)
+	}
+}
+
+// WithEncryption enables parquet modular encryption. The footer key is used to encrypt the footer,
+// or to sign it if it is written in plaintext, as well as all columns that have no column key of
+// their own. The key needs to be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+// Invalid keys make writing the first row group fail. The encryption can be further configured
+// using EncryptionOptions. Files are read using the FileReaderOption WithDecryption.
+func WithEncryption(footerKey []byte, opts ...EncryptionOption) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.encryption = &encryptionConfig{footerKey: footerKey}
+		for _, opt := range opts {
+			opt(fw.encryption)
+		}
+	}
+}
+
 // WithWriterContext overrides the default context (which is a context.Background())
 // in the FileWriter with the provided context.Context object.
 func WithWriterContext(ctx context.Context) FileWriterOption {
@@ -229,29 +317,59 @@ func (fw *FileWriter) FlushRowGroup(opts ...FlushRowGroupOption) error {
 
 // FlushRowGroupWithContext writes the current row group to the parquet file.
 func (fw *FileWriter) FlushRowGroupWithContext(ctx context.Context, opts ...FlushRowGroupOption) error {
+	if fw.err != nil {
+		return fw.err
+	}
+	if fw.globalSort != nil {
+		return errors.New("row groups can't be flushed explicitly when sorting globally")
+	}
+
+	return fw.flushRowGroup(ctx, opts...)
+}
+
+func (fw *FileWriter) flushRowGroup(ctx context.Context, opts ...FlushRowGroupOption) error {
+	if err := fw.schemaWriter.finishColumnBatches(); err != nil {
+		return err
+	}
+
+	if fw.sorter.numRows() > 0 {
+		if err := fw.sortRowGroup(); err != nil {
+			return err
+		}
+	}
+
 	// Write the entire row group
 	if fw.schemaWriter.rowGroupNumRecords() == 0 {
 		return errors.New("nothing to write")
 	}
 
-	if fw.w.Pos() == 0 {
-		if err := writeFull(fw.w, magic); err != nil {
+	if fw.encryption != nil && fw.encryptor == nil {
+		var err error
+		if fw.encryptor, err = newFileEncryptor(fw.encryption, fw.schemaWriter); err != nil {
 			return err
 		}
 	}
 
+	if err := fw.writeMagic(); err != nil {
+		return err
+	}
+
 	h := newFlushRowGroupOptionHandle()
 
 	for _, o := range opts {
 		o(h)
 	}
 
-	cc, err := writeRowGroup(ctx, fw.w, fw.schemaWriter, fw.codec, fw.newPageFunc, h)
+	cc, indexes, err := writeRowGroup(ctx, fw.w, fw.schemaWriter, fw.codec, fw.newPageFunc, h, fw.encryptor, len(fw.rowGroups))
 	if err != nil {
 		return err
 	}
 
 	var totalCompressedSize, totalUncompressedSize int64
+	var sortingColumns []*parquet.SortingColumn
+	if fw.sorter != nil {
+		sortingColumns = fw.sorter.sortingColumns()
+	}
 
 	for _, c := range cc {
 		totalCompressedSize += c.MetaData.TotalCompressedSize
@@ -263,8 +381,9 @@ func (fw *FileWriter) FlushRowGroupWithContext(ctx context.Context, opts ...Flus
 		TotalByteSize:       totalUncompressedSize,
 		TotalCompressedSize: &totalCompressedSize,
 		NumRows:             fw.schemaWriter.rowGroupNumRecords(),
-		SortingColumns:      nil,
+		SortingColumns:      sortingColumns,
 	})
+	fw.chunkIndexes = append(fw.chunkIndexes, indexes)
 	fw.totalNumRecords += fw.schemaWriter.rowGroupNumRecords()
 	// flush the schema
 	fw.schemaWriter.resetData()
@@ -272,9 +391,29 @@ func (fw *FileWriter) FlushRowGroupWithContext(ctx context.Context, opts ...Flus
 	return nil
 }
 
+// writeMagic writes the magic bytes at the beginning of the file if nothing has been written yet.
+func (fw *FileWriter) writeMagic() error {
+	if fw.w.Pos() != 0 {
+		return nil
+	}
+
+	fileMagic := magic
+	if fw.encryption != nil && !fw.encryption.plaintextFooter {
+		fileMagic = magicEncrypted
+	}
+	return writeFull(fw.w, fileMagic)
+}
+
 // AddData adds a new record to the current row group and flushes it if auto-flush is enabled and the size
 // is equal to or greater than the configured maximum row group size.
 func (fw *FileWriter) AddData(m map[string]interface{}) error {
+	if fw.err != nil {
+		return fw.err
+	}
+	if len(fw.sortingColumns) > 0 || fw.globalSort != nil {
+		return fw.addSortedData(m)
+	}
+
 	if err := fw.schemaWriter.AddData(m); err != nil {
 		return err
 	}
@@ -301,12 +440,29 @@ func (fw *FileWriter) Close(opts ...FlushRowGroupOption) error {
 // provided a file as io.Writer when creating the FileWriter, you still need
 // to Close that file handle separately.
 func (fw *FileWriter) CloseWithContext(ctx context.Context, opts ...FlushRowGroupOption) error {
-	if len(fw.rowGroups) == 0 || fw.schemaWriter.rowGroupNumRecords() > 0 {
+	if fw.err != nil {
+		return fw.err
+	}
+	if fw.globalSort != nil {
+		if err := fw.writeGloballySorted(ctx, opts); err != nil {
+			return err
+		}
+	} else if len(fw.rowGroups) == 0 || fw.schemaWriter.rowGroupNumRecords() > 0 || fw.sorter.numRows() > 0 || fw.schemaWriter.columnBatches {
 		if err := fw.FlushRowGroup(opts...); err != nil {
 			return err
 		}
 	}
 
+	if err := writeBloomFilters(ctx, fw.w, fw.rowGroups, fw.chunkIndexes); err != nil {
+		return err
+	}
+
+	if fw.writePageIndex {
+		if err := writePageIndexes(ctx, fw.w, fw.rowGroups, fw.chunkIndexes); err != nil {
+			return err
+		}
+	}
+
 	kv := make([]*parquet.KeyValue, 0, len(fw.kvStore))
 	for i := range fw.kvStore {
 		v := fw.kvStore[i]
@@ -329,6 +485,13 @@ func (fw *FileWriter) CloseWithContext(ctx context.Context, opts ...FlushRowGrou
 		ColumnOrders:     nil,
 	}
 
+	if fw.encryptor != nil {
+		if err := fw.encryptor.encryptColumnMetaData(ctx, fw.rowGroups, fw.chunkIndexes); err != nil {
+			return err
+		}
+		return fw.encryptor.writeFooter(ctx, fw.w, meta)
+	}
+
 	pos := fw.w.Pos()
 	if err := writeThrift(ctx, meta, fw.w); err != nil {
 		return err
@@ -346,7 +509,7 @@ func (fw *FileWriter) CloseWithContext(ctx context.Context, opts ...FlushRowGrou
 // a compression format other than UNCOMPRESSED, the final size will most likely be smaller and will dpeend on how well
 // your data can be compressed.
 func (fw *FileWriter) CurrentRowGroupSize() int64 {
-	return fw.schemaWriter.DataSize()
+	return fw.schemaWriter.DataSize() + fw.sorter.dataSize()
 }
 
 // CurrentFileSize returns the amount of data written to the file so far. This does not include data that is in the
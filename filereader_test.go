@@ -2,10 +2,14 @@ package goparquet
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"math/rand"
+	"strings"
+	"sync/atomic"
 	"testing"
 
+	"github.com/fraugster/parquet-go/parquet"
 	"github.com/fraugster/parquet-go/parquetschema"
 	"github.com/stretchr/testify/require"
 )
@@ -245,3 +249,370 @@ func TestIssue60(t *testing.T) {
 
 	t.Logf("row = %#v", row)
 }
+
+// newTestFileWriter returns a FileWriter that writes a file with the schema definition schema to w.
+func newTestFileWriter(t *testing.T, w io.Writer, schema string, opts ...FileWriterOption) *FileWriter {
+	sd, err := parquetschema.ParseSchemaDefinition(schema)
+	require.NoError(t, err)
+
+	return NewFileWriter(w, append([]FileWriterOption{WithSchemaDefinition(sd)}, opts...)...)
+}
+
+// writeTestFile writes a file with the schema definition schema and numRowGroups row groups of
+// numRows rows each, which are returned by row for the index of the row group and the index of the
+// row in the row group. Row groups are not flushed explicitly if the rows are sorted globally.
+func writeTestFile(t *testing.T, schema string, numRowGroups, numRows int, row func(rg, i int) map[string]interface{}, opts ...FileWriterOption) []byte {
+	var buf bytes.Buffer
+	wr := newTestFileWriter(t, &buf, schema, opts...)
+	for rg := 0; rg < numRowGroups; rg++ {
+		for i := 0; i < numRows; i++ {
+			require.NoError(t, wr.AddData(row(rg, i)))
+		}
+		if wr.globalSort == nil {
+			require.NoError(t, wr.FlushRowGroup())
+		}
+	}
+	require.NoError(t, wr.Close())
+
+	return buf.Bytes()
+}
+
+func buildWideTestStream(t *testing.T, numColumns int, opts ...FileWriterOption) []byte {
+	var sb strings.Builder
+	sb.WriteString("message wide {\n")
+	for i := 0; i < numColumns; i++ {
+		fmt.Fprintf(&sb, "  optional binary col_%d (STRING);\n", i)
+	}
+	sb.WriteString("}\n")
+
+	row := func(rg, i int) map[string]interface{} {
+		data := map[string]interface{}{}
+		for c := 0; c < numColumns; c++ {
+			if (i+c)%5 != 0 {
+				data[fmt.Sprintf("col_%d", c)] = []byte(fmt.Sprintf("value-%d-%d-%d", rg, i, c))
+			}
+		}
+		return data
+	}
+
+	return writeTestFile(t, sb.String(), 3, 300, row, append([]FileWriterOption{WithMaxPageSize(1024)}, opts...)...)
+}
+
+func readAllRows(t *testing.T, r *FileReader) []map[string]interface{} {
+	var rows []map[string]interface{}
+	for {
+		row, err := r.NextRow()
+		if err == io.EOF {
+			return rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestReaderConcurrency(t *testing.T) {
+	data := buildWideTestStream(t, 50, WithCompressionCodec(parquet.CompressionCodec_SNAPPY))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	expected := readAllRows(t, r)
+	require.Len(t, expected, 900)
+
+	for _, n := range []int{1, 2, 8, 100} {
+		r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithReaderConcurrency(n))
+		require.NoError(t, err)
+		require.Equal(t, expected, readAllRows(t, r), "concurrency %d", n)
+	}
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithReaderConcurrency(4), WithColumns("col_3", "col_7", "col_42"))
+	require.NoError(t, err)
+	rows := readAllRows(t, r)
+	require.Len(t, rows, 900)
+	for i, row := range rows {
+		require.Equal(t, expected[i]["col_42"], row["col_42"])
+		require.NotContains(t, row, "col_4")
+	}
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(data), WithReaderConcurrency(0))
+	require.Error(t, err)
+
+	_, err = NewFileReaderWithOptions(struct{ io.ReadSeeker }{bytes.NewReader(data)}, WithReaderConcurrency(2))
+	require.Error(t, err, "concurrency requires io.ReaderAt")
+}
+
+func TestReaderConcurrencyError(t *testing.T) {
+	data := buildWideTestStream(t, 10, WithCRC(true))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	chunk, err := r.columnChunk(0, ColumnPath{"col_5"})
+	require.NoError(t, err)
+
+	corrupted := append([]byte{}, data...)
+	corrupted[chunk.MetaData.DataPageOffset+chunk.MetaData.TotalCompressedSize-1] ^= 0xff
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(corrupted), WithReaderConcurrency(4), WithCRC32Validation(true))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Error(t, err)
+}
+
+func TestStreamingThresholdCorruptedPage(t *testing.T) {
+	data := buildWideTestStream(t, 10, WithCRC(true))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	idx, err := r.ReadOffsetIndex(0, ColumnPath{"col_5"})
+	require.NoError(t, err)
+	require.Greater(t, len(idx.PageLocations), 1)
+
+	// corrupt the first page, which is read when the row group is loaded.
+	page := idx.PageLocations[0]
+	corrupted := append([]byte{}, data...)
+	corrupted[page.Offset+int64(page.CompressedPageSize)-1] ^= 0xff
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(corrupted), WithStreamingThreshold(0), WithCRC32Validation(true))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Error(t, err)
+}
+
+type countingReaderAt struct {
+	r     io.ReaderAt
+	reads int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	atomic.AddInt64(&c.reads, 1)
+	return c.r.ReadAt(p, off)
+}
+
+func TestCoalesceRanges(t *testing.T) {
+	ranges := []byteRange{
+		{offset: 100, length: 50},
+		{offset: 4, length: 10},
+		{offset: 150, length: 20},
+		{offset: 300, length: 10},
+		{offset: 120, length: 10},
+	}
+
+	require.Equal(t, []byteRange{{4, 10}, {100, 70}, {300, 10}}, coalesceRanges(ranges, 0))
+	require.Equal(t, []byteRange{{4, 166}, {300, 10}}, coalesceRanges(ranges, 86))
+	require.Equal(t, []byteRange{{4, 306}}, coalesceRanges(ranges, 1000))
+	require.Nil(t, coalesceRanges(nil, 1000))
+}
+
+func TestFileReaderAt(t *testing.T) {
+	data := buildWideTestStream(t, 20, WithCompressionCodec(parquet.CompressionCodec_SNAPPY))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	expected := readAllRows(t, r)
+
+	ra := &countingReaderAt{r: bytes.NewReader(data)}
+	r, err = NewFileReaderAt(ra, int64(len(data)))
+	require.NoError(t, err)
+	ra.reads = 0
+	require.Equal(t, expected, readAllRows(t, r))
+	require.Equal(t, int64(3), ra.reads, "expected one read per row group")
+
+	ra.reads = 0
+	r, err = NewFileReaderAt(ra, int64(len(data)), WithMaxReadGap(0), WithColumns("col_2", "col_5", "col_11"))
+	require.NoError(t, err)
+	ra.reads = 0
+	rows := readAllRows(t, r)
+	require.Len(t, rows, 900)
+	for i, row := range rows {
+		require.Equal(t, expected[i]["col_5"], row["col_5"])
+		require.NotContains(t, row, "col_3")
+	}
+	require.Equal(t, int64(9), ra.reads, "expected one read per selected column and row group")
+
+	r, err = NewFileReaderAt(ra, int64(len(data)), WithColumns("col_2", "col_3", "col_4"), WithMaxReadGap(0), WithReaderConcurrency(3))
+	require.NoError(t, err)
+	ra.reads = 0
+	rows = readAllRows(t, r)
+	require.Len(t, rows, 900)
+	require.Equal(t, int64(3), ra.reads, "expected adjacent column chunks to be read at once")
+
+	// the column chunks of row groups that are read page by page are read in blocks instead of
+	// reading every page header and page separately.
+	r, err = NewFileReaderAt(ra, int64(len(data)), WithStreamingThreshold(0))
+	require.NoError(t, err)
+	ra.reads = 0
+	require.Equal(t, expected, readAllRows(t, r))
+	require.Equal(t, int64(3*20), ra.reads, "expected one read per column chunk")
+
+	_, err = NewFileReaderAt(ra, int64(len(data)), WithMaxReadGap(-1))
+	require.Error(t, err)
+
+	_, err = NewFileReaderAt(ra, int64(len(data))-1)
+	require.Error(t, err)
+}
+
+type countingReadSeeker struct {
+	io.ReadSeeker
+	bytesRead int64
+}
+
+func (c *countingReadSeeker) Read(p []byte) (int, error) {
+	n, err := c.ReadSeeker.Read(p)
+	c.bytesRead += int64(n)
+	return n, err
+}
+
+func TestStreamingThreshold(t *testing.T) {
+	data := buildWideTestStream(t, 10, WithCompressionCodec(parquet.CompressionCodec_SNAPPY))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	expected := readAllRows(t, r)
+
+	var chunkSize int64
+	for _, chunk := range r.meta.RowGroups[0].Columns {
+		chunkSize += chunk.MetaData.TotalCompressedSize
+	}
+
+	rs := &countingReadSeeker{ReadSeeker: bytes.NewReader(data)}
+	r, err = NewFileReaderWithOptions(rs, WithStreamingThreshold(0))
+	require.NoError(t, err)
+	rs.bytesRead = 0
+	require.NoError(t, r.PreLoad())
+	require.Less(t, rs.bytesRead, chunkSize/2, "expected only the first page of every column chunk to be read")
+	require.Equal(t, expected, readAllRows(t, r))
+
+	rs = &countingReadSeeker{ReadSeeker: bytes.NewReader(data)}
+	r, err = NewFileReaderWithOptions(rs, WithStreamingThreshold(1<<30))
+	require.NoError(t, err)
+	rs.bytesRead = 0
+	require.NoError(t, r.PreLoad())
+	require.GreaterOrEqual(t, rs.bytesRead, chunkSize, "expected whole column chunks to be read")
+	require.Equal(t, expected, readAllRows(t, r))
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithStreamingThreshold(0), WithReaderConcurrency(4), WithColumns("col_1", "col_8"))
+	require.NoError(t, err)
+	rows := readAllRows(t, r)
+	require.Len(t, rows, 900)
+	for i, row := range rows {
+		require.Equal(t, expected[i]["col_8"], row["col_8"])
+		require.NotContains(t, row, "col_2")
+	}
+
+	r, err = NewFileReaderAt(bytes.NewReader(data), int64(len(data)), WithStreamingThreshold(0))
+	require.NoError(t, err)
+	require.Equal(t, expected, readAllRows(t, r))
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(data), WithStreamingThreshold(-1))
+	require.Error(t, err)
+}
+
+func TestSeekToRow(t *testing.T) {
+	testData := []struct {
+		name       string
+		writerOpts []FileWriterOption
+		readerOpts []FileReaderOption
+	}{
+		{"offset_index", nil, nil},
+		{"page_headers_v1", []FileWriterOption{WithPageIndex(false)}, nil},
+		{"page_headers_v2", []FileWriterOption{WithPageIndex(false), WithDataPageV2()}, nil},
+		{"compressed", []FileWriterOption{WithCompressionCodec(parquet.CompressionCodec_SNAPPY)}, nil},
+		{"encrypted", []FileWriterOption{WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer")), WithEncryptionAlgorithm(EncryptionAESGCMCTR))}, []FileReaderOption{WithDecryption(testKeys)}},
+		{"encrypted_v2", []FileWriterOption{WithPageIndex(false), WithDataPageV2(), WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer")))}, []FileReaderOption{WithDecryption(testKeys)}},
+		{"streaming_threshold", nil, []FileReaderOption{WithStreamingThreshold(0)}},
+		{"concurrency", nil, []FileReaderOption{WithReaderConcurrency(3)}},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			data := writeEncryptionTestFile(t, tt.writerOpts...)
+
+			r, err := NewFileReaderWithOptions(bytes.NewReader(data), tt.readerOpts...)
+			require.NoError(t, err)
+
+			for _, n := range []int64{0, 1, 257, 499, 500, 501, 998, 999, 42} {
+				require.NoError(t, r.SeekToRow(n))
+				row, err := r.NextRow()
+				require.NoError(t, err)
+				require.Equal(t, n, row["id"], "seek to row %d", n)
+				require.Equal(t, []int32{int32(n), int32(n % 7)}, row["values"], "seek to row %d", n)
+				if n%3 != 0 {
+					require.Equal(t, []byte(fmt.Sprintf("name-%d", n%50)), row["name"], "seek to row %d", n)
+				} else {
+					require.NotContains(t, row, "name")
+				}
+			}
+
+			require.NoError(t, r.SeekToRow(495))
+			for n := int64(495); n < 1000; n++ {
+				row, err := r.NextRow()
+				require.NoError(t, err)
+				require.Equal(t, n, row["id"])
+			}
+			_, err = r.NextRow()
+			require.Equal(t, io.EOF, err)
+
+			require.NoError(t, r.SeekToRow(1000))
+			_, err = r.NextRow()
+			require.Equal(t, io.EOF, err)
+
+			require.Error(t, r.SeekToRow(1001))
+			require.Error(t, r.SeekToRow(-1))
+		})
+	}
+}
+
+func TestSeekToRowSkipsPages(t *testing.T) {
+	data := writeEncryptionTestFile(t, WithPageIndex(false), WithDataPageV2())
+
+	rs := &countingReadSeeker{ReadSeeker: bytes.NewReader(data)}
+	r, err := NewFileReaderWithOptions(rs)
+	require.NoError(t, err)
+	rs.bytesRead = 0
+	require.NoError(t, r.SeekToRow(0))
+	fullRowGroup := rs.bytesRead
+
+	rs.bytesRead = 0
+	require.NoError(t, r.SeekToRow(495))
+	require.Less(t, rs.bytesRead, fullRowGroup/2, "expected pages before the row to be skipped")
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, int64(495), row["id"])
+}
+
+func TestReadRows(t *testing.T) {
+	data := writeEncryptionTestFile(t)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	rows, err := r.ReadRows(480, 520)
+	require.NoError(t, err)
+	require.Len(t, rows, 40)
+	for i, row := range rows {
+		require.Equal(t, int64(480+i), row["id"])
+	}
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, int64(520), row["id"])
+
+	rows, err = r.ReadRows(10, 10)
+	require.NoError(t, err)
+	require.Empty(t, rows)
+
+	rows, err = r.ReadRows(990, 1000)
+	require.NoError(t, err)
+	require.Len(t, rows, 10)
+
+	_, err = r.ReadRows(10, 5)
+	require.Error(t, err)
+	_, err = r.ReadRows(10, 1001)
+	require.Error(t, err)
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithRowFilter(Eq(ColumnPath{"name"}, []byte("name-1"))), WithColumns("id"))
+	require.NoError(t, err)
+	rows, err = r.ReadRows(0, 200)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{{"id": int64(1)}, {"id": int64(101)}, {"id": int64(151)}}, rows)
+}
@@ -0,0 +1,555 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// Predicate is a filter expression over the columns of a parquet file. Predicates are created
+// using the functions Eq, Lt, LtEq, Gt, GtEq, In, IsNull, And and Or, and can be used with
+// the FileReaderOptions WithRowGroupFilter and WithRowFilter.
+//
+// Values in predicates need to be of the same type as the values that NextRow returns for the
+// column, e.g. int32 for an INT32 column and []byte for a BYTE_ARRAY column. For BYTE_ARRAY
+// and FIXED_LEN_BYTE_ARRAY columns, strings are accepted as well. Values are compared according
+// to the sort order of the column's logical type, e.g. unsigned for UINT_32 columns. Comparisons
+// never match null values or NaN. For repeated columns, a comparison matches if it matches any
+// of the column's values.
+type Predicate interface {
+	// bind checks that all columns referenced by the predicate exist and that the values
+	// can be compared with the column's values, and returns the predicate bound to the schema.
+	bind(sch *schema) (boundPredicate, error)
+}
+
+// boundPredicate is a predicate that has been bound to the schema of a particular file.
+type boundPredicate interface {
+	// canSkip returns true if the column chunk statistics prove that no row of a row group
+	// matches the predicate.
+	canSkip(stats chunkStatsFunc) bool
+
+	// matches returns true if a row matches the predicate. values returns the values of a
+	// column of the row that are not null.
+	matches(values rowValuesFunc) bool
+
+	// columns returns the paths of all columns that are referenced by the predicate.
+	columns() []ColumnPath
+}
+
+// rowValuesFunc returns the values of the column identified by path in a row that are not null.
+type rowValuesFunc func(path ColumnPath) []interface{}
+
+// mapRowValues returns a rowValuesFunc for the values of row.
+func mapRowValues(row map[string]interface{}) rowValuesFunc {
+	return func(path ColumnPath) []interface{} {
+		return rowValues(row, path)
+	}
+}
+
+type filterOp int
+
+const (
+	filterOpEq filterOp = iota
+	filterOpLt
+	filterOpLtEq
+	filterOpGt
+	filterOpGtEq
+	filterOpIn
+	filterOpIsNull
+)
+
+var filterOpNames = map[filterOp]string{
+	filterOpEq:     "=",
+	filterOpLt:     "<",
+	filterOpLtEq:   "<=",
+	filterOpGt:     ">",
+	filterOpGtEq:   ">=",
+	filterOpIn:     "IN",
+	filterOpIsNull: "IS NULL",
+}
+
+type columnPredicate struct {
+	path   ColumnPath
+	op     filterOp
+	values []interface{}
+}
+
+// Eq returns a predicate that matches if the value of the column identified by path is equal to value.
+func Eq(path ColumnPath, value interface{}) Predicate {
+	return &columnPredicate{path: path, op: filterOpEq, values: []interface{}{value}}
+}
+
+// Lt returns a predicate that matches if the value of the column identified by path is less than value.
+func Lt(path ColumnPath, value interface{}) Predicate {
+	return &columnPredicate{path: path, op: filterOpLt, values: []interface{}{value}}
+}
+
+// LtEq returns a predicate that matches if the value of the column identified by path is less than
+// or equal to value.
+func LtEq(path ColumnPath, value interface{}) Predicate {
+	return &columnPredicate{path: path, op: filterOpLtEq, values: []interface{}{value}}
+}
+
+// Gt returns a predicate that matches if the value of the column identified by path is greater than value.
+func Gt(path ColumnPath, value interface{}) Predicate {
+	return &columnPredicate{path: path, op: filterOpGt, values: []interface{}{value}}
+}
+
+// GtEq returns a predicate that matches if the value of the column identified by path is greater than
+// or equal to value.
+func GtEq(path ColumnPath, value interface{}) Predicate {
+	return &columnPredicate{path: path, op: filterOpGtEq, values: []interface{}{value}}
+}
+
+// In returns a predicate that matches if the value of the column identified by path is equal to
+// one of the provided values.
+func In(path ColumnPath, values ...interface{}) Predicate {
+	return &columnPredicate{path: path, op: filterOpIn, values: values}
+}
+
+// IsNull returns a predicate that matches if the column identified by path is null. A repeated
+// column is null if it contains no values.
+func IsNull(path ColumnPath) Predicate {
+	return &columnPredicate{path: path, op: filterOpIsNull}
+}
+
+func (p *columnPredicate) bind(sch *schema) (boundPredicate, error) {
+	col := sch.GetColumnByPath(p.path)
+	if col == nil || col.data == nil {
+		return nil, fmt.Errorf("filter: column %s not found", p.path.flatName())
+	}
+
+	bp := &boundColumnPredicate{
+		path:    p.path,
+		op:      p.op,
+		typ:     *col.Type(),
+		compare: statsComparator(col.Element()),
+	}
+
+	for _, v := range p.values {
+		b, err := plainValueBytes(bp.typ, v)
+		if err != nil {
+			return nil, fmt.Errorf("filter: invalid value for column %s: %w", p.path.flatName(), err)
+		}
+		bp.values = append(bp.values, b)
+	}
+
+	switch p.op {
+	case filterOpLt, filterOpLtEq, filterOpGt, filterOpGtEq:
+		if bp.compare == nil {
+			return nil, fmt.Errorf("filter: operator %s is not supported for column %s because its sort order is undefined", filterOpNames[p.op], p.path.flatName())
+		}
+	}
+
+	return bp, nil
+}
+
+type boundColumnPredicate struct {
+	path ColumnPath
+	op   filterOp
+	typ  parquet.Type
+
+	// compare is nil if the column's sort order is undefined.
+	compare func(a, b []byte) int
+
+	// the PLAIN encoded values of the predicate.
+	values [][]byte
+}
+
+func (p *boundColumnPredicate) columns() []ColumnPath {
+	return []ColumnPath{p.path}
+}
+
+func (p *boundColumnPredicate) canSkip(statsFn chunkStatsFunc) bool {
+	stats := statsFn(p.path)
+	if stats == nil {
+		return false
+	}
+
+	if p.op == filterOpIsNull {
+		return stats.nullCount == 0
+	}
+
+	if stats.nullCount >= 0 && stats.nullCount == stats.numValues {
+		return true
+	}
+
+	if stats.compare == nil || stats.min == nil || stats.max == nil {
+		return false
+	}
+
+	for _, v := range p.values {
+		if isNaNValue(p.typ, v) || !p.outOfRange(stats, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// outOfRange returns true if the value v can't be matched by any value between the minimum and
+// the maximum value of the statistics.
+func (p *boundColumnPredicate) outOfRange(stats *chunkStats, v []byte) bool {
+	switch p.op {
+	case filterOpEq, filterOpIn:
+		return stats.compare(v, stats.min) < 0 || stats.compare(v, stats.max) > 0
+	case filterOpLt:
+		return stats.compare(stats.min, v) >= 0
+	case filterOpLtEq:
+		return stats.compare(stats.min, v) > 0
+	case filterOpGt:
+		return stats.compare(stats.max, v) <= 0
+	case filterOpGtEq:
+		return stats.compare(stats.max, v) < 0
+	default:
+		return false
+	}
+}
+
+func (p *boundColumnPredicate) matches(rowValues rowValuesFunc) bool {
+	values := rowValues(p.path)
+
+	if p.op == filterOpIsNull {
+		return len(values) == 0
+	}
+
+	for _, v := range values {
+		b, err := plainValueBytes(p.typ, v)
+		if err != nil || isNaNValue(p.typ, b) {
+			continue
+		}
+		for _, pv := range p.values {
+			if !isNaNValue(p.typ, pv) && p.matchValue(b, pv) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchValue returns true if the column value v matches the predicate value pv.
+func (p *boundColumnPredicate) matchValue(v, pv []byte) bool {
+	if p.compare == nil {
+		return (p.op == filterOpEq || p.op == filterOpIn) && bytes.Equal(v, pv)
+	}
+
+	c := p.compare(v, pv)
+
+	switch p.op {
+	case filterOpEq, filterOpIn:
+		return c == 0
+	case filterOpLt:
+		return c < 0
+	case filterOpLtEq:
+		return c <= 0
+	case filterOpGt:
+		return c > 0
+	case filterOpGtEq:
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+type andPredicate struct {
+	preds []Predicate
+}
+
+// And returns a predicate that matches if all of the provided predicates match.
+func And(preds ...Predicate) Predicate {
+	return &andPredicate{preds: preds}
+}
+
+func (p *andPredicate) bind(sch *schema) (boundPredicate, error) {
+	preds, err := bindPredicates(sch, p.preds)
+	if err != nil {
+		return nil, err
+	}
+	return &boundAndPredicate{preds: preds}, nil
+}
+
+type boundAndPredicate struct {
+	preds []boundPredicate
+}
+
+func (p *boundAndPredicate) canSkip(stats chunkStatsFunc) bool {
+	for _, pred := range p.preds {
+		if pred.canSkip(stats) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *boundAndPredicate) matches(values rowValuesFunc) bool {
+	for _, pred := range p.preds {
+		if !pred.matches(values) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *boundAndPredicate) columns() []ColumnPath {
+	return predicateColumns(p.preds)
+}
+
+type orPredicate struct {
+	preds []Predicate
+}
+
+// Or returns a predicate that matches if at least one of the provided predicates matches.
+func Or(preds ...Predicate) Predicate {
+	return &orPredicate{preds: preds}
+}
+
+func (p *orPredicate) bind(sch *schema) (boundPredicate, error) {
+	preds, err := bindPredicates(sch, p.preds)
+	if err != nil {
+		return nil, err
+	}
+	return &boundOrPredicate{preds: preds}, nil
+}
+
+type boundOrPredicate struct {
+	preds []boundPredicate
+}
+
+func (p *boundOrPredicate) canSkip(stats chunkStatsFunc) bool {
+	for _, pred := range p.preds {
+		if !pred.canSkip(stats) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *boundOrPredicate) matches(values rowValuesFunc) bool {
+	for _, pred := range p.preds {
+		if pred.matches(values) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *boundOrPredicate) columns() []ColumnPath {
+	return predicateColumns(p.preds)
+}
+
+func bindPredicates(sch *schema, preds []Predicate) ([]boundPredicate, error) {
+	bound := make([]boundPredicate, 0, len(preds))
+	for _, pred := range preds {
+		bp, err := pred.bind(sch)
+		if err != nil {
+			return nil, err
+		}
+		bound = append(bound, bp)
+	}
+	return bound, nil
+}
+
+func predicateColumns(preds []boundPredicate) []ColumnPath {
+	var cols []ColumnPath
+	for _, pred := range preds {
+		cols = append(cols, pred.columns()...)
+	}
+	return cols
+}
+
+// rowValues returns all values of the column identified by path in row. Null values are
+// not returned.
+func rowValues(v interface{}, path ColumnPath) []interface{} {
+	if len(path) == 0 {
+		return leafValues(v)
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		child, ok := t[path[0]]
+		if !ok {
+			return nil
+		}
+		return rowValues(child, path[1:])
+	case []map[string]interface{}:
+		var values []interface{}
+		for _, m := range t {
+			values = append(values, rowValues(m, path)...)
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func leafValues(v interface{}) []interface{} {
+	switch v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return []interface{}{v}
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return []interface{}{v}
+	}
+
+	values := make([]interface{}, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		values = append(values, rv.Index(i).Interface())
+	}
+	return values
+}
+
+// removeColumn removes the column identified by path from row. Groups that become
+// empty by removing the column are removed as well.
+func removeColumn(v interface{}, path ColumnPath) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			delete(t, path[0])
+			return
+		}
+
+		child, ok := t[path[0]]
+		if !ok {
+			return
+		}
+
+		if m, ok := child.(map[string]interface{}); ok && len(m) > 0 {
+			removeColumn(m, path[1:])
+			if len(m) == 0 {
+				delete(t, path[0])
+			}
+			return
+		}
+
+		removeColumn(child, path[1:])
+	case []map[string]interface{}:
+		for _, m := range t {
+			removeColumn(m, path)
+		}
+	}
+}
+
+// chunkStats contains the statistics of a column chunk that are relevant for filtering.
+type chunkStats struct {
+	typ parquet.Type
+
+	// compare compares min and max with other values. If it is nil, min and max can't be used.
+	compare func(a, b []byte) int
+
+	// min and max are nil if they are unknown.
+	min []byte
+	max []byte
+
+	// nullCount is negative if it is unknown.
+	nullCount int64
+	numValues int64
+}
+
+// chunkStatsFunc returns the statistics of the column chunk of the column identified by path,
+// or nil if no statistics are available.
+type chunkStatsFunc func(path ColumnPath) *chunkStats
+
+// newChunkStats returns the statistics of the provided column chunk. Minimum and maximum values are
+// only provided if they are known to follow the sort order of the column's logical type.
+func newChunkStats(meta *parquet.FileMetaData, sch *schema, chunk *parquet.ColumnChunk) *chunkStats {
+	if chunk.MetaData == nil || chunk.MetaData.Statistics == nil {
+		return nil
+	}
+
+	path := ColumnPath(chunk.MetaData.PathInSchema)
+
+	col := sch.GetColumnByPath(path)
+	if col == nil || col.data == nil {
+		return nil
+	}
+
+	elem := col.Element()
+	stats := chunk.MetaData.Statistics
+
+	cs := &chunkStats{
+		typ:       chunk.MetaData.Type,
+		nullCount: -1,
+		numValues: chunk.MetaData.NumValues,
+	}
+
+	if stats.NullCount != nil {
+		cs.nullCount = *stats.NullCount
+	}
+
+	// files without column orders may have been written with statistics in signed
+	// resp. byte-wise order for unsigned integers and decimals.
+	legacyOrder := isUnsignedInt(elem) || (isDecimal(elem) && (cs.typ == parquet.Type_BYTE_ARRAY || cs.typ == parquet.Type_FIXED_LEN_BYTE_ARRAY))
+
+	switch {
+	case stats.MinValue != nil && stats.MaxValue != nil && (!legacyOrder || hasTypeDefinedOrder(meta, sch, path)):
+		cs.min, cs.max = stats.MinValue, stats.MaxValue
+	case stats.Min != nil && stats.Max != nil && !legacyOrder && hasSignedLegacyOrder(cs.typ):
+		// the deprecated min and max values were written in signed order.
+		cs.min, cs.max = stats.Min, stats.Max
+	}
+
+	if !validStatsValue(cs.typ, cs.min) || !validStatsValue(cs.typ, cs.max) || isNaNValue(cs.typ, cs.min) || isNaNValue(cs.typ, cs.max) {
+		cs.min, cs.max = nil, nil
+	}
+
+	if cs.min != nil && cs.max != nil {
+		cs.compare = statsComparator(elem)
+	}
+
+	return cs
+}
+
+func hasTypeDefinedOrder(meta *parquet.FileMetaData, sch *schema, path ColumnPath) bool {
+	for idx, col := range sch.Columns() {
+		if col.Path().Equal(path) {
+			return idx < len(meta.ColumnOrders) && meta.ColumnOrders[idx] != nil && meta.ColumnOrders[idx].IsSetTYPE_ORDER()
+		}
+	}
+	return false
+}
+
+func hasSignedLegacyOrder(typ parquet.Type) bool {
+	switch typ {
+	case parquet.Type_BOOLEAN, parquet.Type_INT32, parquet.Type_INT64, parquet.Type_FLOAT, parquet.Type_DOUBLE:
+		return true
+	default:
+		return false
+	}
+}
+
+func validStatsValue(typ parquet.Type, b []byte) bool {
+	if b == nil {
+		return true
+	}
+
+	switch typ {
+	case parquet.Type_BOOLEAN:
+		return len(b) == 1
+	case parquet.Type_INT32, parquet.Type_FLOAT:
+		return len(b) == 4
+	case parquet.Type_INT64, parquet.Type_DOUBLE:
+		return len(b) == 8
+	default:
+		return true
+	}
+}
+
+func isNaNValue(typ parquet.Type, b []byte) bool {
+	switch {
+	case typ == parquet.Type_FLOAT && len(b) == 4:
+		return math.IsNaN(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+	case typ == parquet.Type_DOUBLE && len(b) == 8:
+		return math.IsNaN(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	default:
+		return false
+	}
+}
@@ -0,0 +1,390 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFilterTestFile(t *testing.T) []byte {
+	schema := `message test {
+		required int64 id;
+		required int32 day (DATE);
+		optional binary name (STRING);
+		required double score;
+		required int32 counter (UINT_32);
+		optional int96 ts;
+	}`
+
+	row := func(rg, i int) map[string]interface{} {
+		data := map[string]interface{}{
+			"id":      int64(rg*100 + i),
+			"day":     int32(18000 + rg),
+			"score":   float64(rg) + float64(i)/100,
+			"counter": int32(rg),
+		}
+		if rg != 2 {
+			data["name"] = []byte(fmt.Sprintf("name-%c", 'a'+rg))
+		}
+		return data
+	}
+
+	// 5 row groups of 100 rows each, the day column contains the row group index.
+	return writeTestFile(t, schema, 5, 100, row)
+}
+
+func readFilteredRowGroups(t *testing.T, r *FileReader) []int {
+	rowGroups := map[int]bool{}
+	for {
+		row, err := r.NextRow()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		rowGroups[int(row["day"].(int32)-18000)] = true
+	}
+
+	var res []int
+	for rg := range rowGroups {
+		res = append(res, rg)
+	}
+	sort.Ints(res)
+	return res
+}
+
+func TestRowGroupFilter(t *testing.T) {
+	data := writeFilterTestFile(t)
+
+	testData := []struct {
+		name      string
+		pred      Predicate
+		rowGroups []int
+	}{
+		{"eq", Eq(ColumnPath{"day"}, int32(18003)), []int{3}},
+		{"eq-none", Eq(ColumnPath{"day"}, int32(17000)), nil},
+		{"lt", Lt(ColumnPath{"id"}, int64(200)), []int{0, 1}},
+		{"lt-eq", LtEq(ColumnPath{"id"}, int64(200)), []int{0, 1, 2}},
+		{"gt", Gt(ColumnPath{"id"}, int64(399)), []int{4}},
+		{"gt-eq", GtEq(ColumnPath{"id"}, int64(399)), []int{3, 4}},
+		{"double", Gt(ColumnPath{"score"}, 3.5), []int{3, 4}},
+		{"in", In(ColumnPath{"day"}, int32(18001), int32(18004), int32(19000)), []int{1, 4}},
+		{"in-empty", In(ColumnPath{"day"}), nil},
+		{"string", Eq(ColumnPath{"name"}, "name-b"), []int{1}},
+		{"string-range", GtEq(ColumnPath{"name"}, []byte("name-d")), []int{3, 4}},
+		{"is-null", IsNull(ColumnPath{"name"}), []int{2}},
+		{"null-chunk-never-matches", Lt(ColumnPath{"name"}, "zzz"), []int{0, 1, 3, 4}},
+		{"and", And(GtEq(ColumnPath{"id"}, int64(100)), Lt(ColumnPath{"day"}, int32(18003))), []int{1, 2}},
+		{"or", Or(Eq(ColumnPath{"day"}, int32(18000)), Gt(ColumnPath{"id"}, int64(450))), []int{0, 4}},
+		{"nested", Or(And(IsNull(ColumnPath{"name"}), Gt(ColumnPath{"id"}, int64(0))), Eq(ColumnPath{"id"}, int64(42))), []int{0, 2}},
+		{"all", And(), []int{0, 1, 2, 3, 4}},
+		{"unsigned-without-column-order", Eq(ColumnPath{"counter"}, int32(3)), []int{0, 1, 2, 3, 4}},
+		{"all-null-chunks", Eq(ColumnPath{"ts"}, [12]byte{}), nil},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithRowGroupFilter(tt.pred))
+			require.NoError(t, err)
+			require.Equal(t, tt.rowGroups, readFilteredRowGroups(t, r))
+		})
+	}
+}
+
+func TestRowGroupFilterWithColumnSelection(t *testing.T) {
+	data := writeFilterTestFile(t)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithColumnPaths(ColumnPath{"day"}), WithRowGroupFilter(Gt(ColumnPath{"id"}, int64(250))))
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 3, 4}, readFilteredRowGroups(t, r))
+}
+
+func TestRowGroupFilterSeekToRowGroup(t *testing.T) {
+	data := writeFilterTestFile(t)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithRowGroupFilter(Eq(ColumnPath{"day"}, int32(18004))))
+	require.NoError(t, err)
+
+	// explicitly selected row groups are read even if they don't match.
+	require.NoError(t, r.SeekToRowGroup(1))
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.NotEqual(t, int32(18004), row["day"])
+}
+
+func TestRowGroupFilterUnsignedWithColumnOrder(t *testing.T) {
+	data := writeFilterTestFile(t)
+
+	meta, err := ReadFileMetaData(bytes.NewReader(data), true)
+	require.NoError(t, err)
+
+	uint32Bytes := func(v uint32) []byte {
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint32(b, v)
+		return b
+	}
+
+	// replace the statistics of the counter column with unsigned statistics where
+	// the minimum is smaller than the maximum only when compared as unsigned values.
+	for rg, rowGroup := range meta.RowGroups {
+		for _, chunk := range rowGroup.Columns {
+			if ColumnPath(chunk.MetaData.PathInSchema).Equal(ColumnPath{"counter"}) {
+				chunk.MetaData.Statistics.MinValue = uint32Bytes(uint32(rg) << 30)
+				chunk.MetaData.Statistics.MaxValue = uint32Bytes(uint32(rg)<<30 | 0xFFFF)
+			}
+		}
+	}
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithFileMetaData(meta), WithRowGroupFilter(GtEq(ColumnPath{"counter"}, int32(-1<<31))))
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3, 4}, readFilteredRowGroups(t, r), "statistics of unsigned columns without column order must be ignored")
+
+	meta.ColumnOrders = nil
+	for range meta.Schema[1:] {
+		meta.ColumnOrders = append(meta.ColumnOrders, &parquet.ColumnOrder{TYPE_ORDER: &parquet.TypeDefinedOrder{}})
+	}
+
+	// 1<<31 as unsigned, i.e. row groups 2 and 3 as well as row group 4 whose counter values overflow to 0.
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithFileMetaData(meta), WithRowGroupFilter(GtEq(ColumnPath{"counter"}, int32(-1<<31))))
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 3}, readFilteredRowGroups(t, r))
+}
+
+func TestRowGroupFilterValidation(t *testing.T) {
+	data := writeFilterTestFile(t)
+
+	testData := []struct {
+		name string
+		pred Predicate
+	}{
+		{"unknown-column", Eq(ColumnPath{"foo"}, int32(1))},
+		{"wrong-type", Eq(ColumnPath{"id"}, int32(1))},
+		{"wrong-type-in", In(ColumnPath{"id"}, int64(1), "2")},
+		{"nested-wrong-type", Or(Eq(ColumnPath{"id"}, int64(1)), And(Lt(ColumnPath{"day"}, 3)))},
+		{"undefined-order", Lt(ColumnPath{"ts"}, [12]byte{})},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFileReaderWithOptions(bytes.NewReader(data), WithRowGroupFilter(tt.pred))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestRowFilter(t *testing.T) {
+	data := writeFilterTestFile(t)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithRowFilter(And(Gt(ColumnPath{"id"}, int64(150)), Lt(ColumnPath{"id"}, int64(160)))))
+	require.NoError(t, err)
+
+	var ids []int64
+	for {
+		row, err := r.NextRow()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.Contains(t, row, "score")
+		ids = append(ids, row["id"].(int64))
+	}
+	require.Equal(t, []int64{151, 152, 153, 154, 155, 156, 157, 158, 159}, ids)
+}
+
+func TestRowFilterColumnsNotSelected(t *testing.T) {
+	data := writeFilterTestFile(t)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data),
+		WithColumnPaths(ColumnPath{"day"}),
+		WithRowFilter(Or(Eq(ColumnPath{"id"}, int64(3)), In(ColumnPath{"id"}, int64(250), int64(499)), IsNull(ColumnPath{"name"}))))
+	require.NoError(t, err)
+
+	var rows []map[string]interface{}
+	for {
+		row, err := r.NextRow()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+
+	// row 3, all 100 rows of row group 2 where name is null, and row 499.
+	require.Len(t, rows, 102)
+	require.Equal(t, map[string]interface{}{"day": int32(18000)}, rows[0])
+	require.Equal(t, map[string]interface{}{"day": int32(18002)}, rows[1])
+	require.Equal(t, map[string]interface{}{"day": int32(18004)}, rows[101])
+
+	// changing the selected columns keeps the columns referenced by the row filter.
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithRowFilter(Eq(ColumnPath{"id"}, int64(42))))
+	require.NoError(t, err)
+	r.SetSelectedColumnsByPath(ColumnPath{"score"})
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"score": 0.42}, row)
+
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestRowFilterNested(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional group info {
+			optional binary name (STRING);
+			required int32 age;
+		}
+		repeated int32 tags;
+		required double score;
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	wr := NewFileWriter(&buf, WithSchemaDefinition(sd))
+
+	rows := []map[string]interface{}{
+		{"id": int64(0), "info": map[string]interface{}{"name": []byte("alice"), "age": int32(30)}, "tags": []int32{1, 2}, "score": 1.0},
+		{"id": int64(1), "info": map[string]interface{}{"age": int32(40)}, "tags": []int32{3}, "score": math.NaN()},
+		{"id": int64(2), "score": 3.0},
+		{"id": int64(3), "info": map[string]interface{}{"name": []byte("bob"), "age": int32(50)}, "tags": []int32{2, 3, 4}, "score": 4.0},
+	}
+	for _, row := range rows {
+		require.NoError(t, wr.AddData(row))
+	}
+	require.NoError(t, wr.Close())
+
+	testData := []struct {
+		name     string
+		columns  []ColumnPath
+		pred     Predicate
+		expected []map[string]interface{}
+	}{
+		{
+			name:     "repeated-any",
+			columns:  []ColumnPath{{"id"}},
+			pred:     Eq(ColumnPath{"tags"}, int32(3)),
+			expected: []map[string]interface{}{{"id": int64(1)}, {"id": int64(3)}},
+		},
+		{
+			name:     "repeated-null",
+			columns:  []ColumnPath{{"id"}},
+			pred:     IsNull(ColumnPath{"tags"}),
+			expected: []map[string]interface{}{{"id": int64(2)}},
+		},
+		{
+			name:     "nested-null",
+			columns:  []ColumnPath{{"id"}},
+			pred:     IsNull(ColumnPath{"info", "name"}),
+			expected: []map[string]interface{}{{"id": int64(1)}, {"id": int64(2)}},
+		},
+		{
+			name:     "nested-partially-selected",
+			columns:  []ColumnPath{{"info", "age"}},
+			pred:     GtEq(ColumnPath{"info", "name"}, "b"),
+			expected: []map[string]interface{}{{"info": map[string]interface{}{"age": int32(50)}}},
+		},
+		{
+			name:     "nested-group-removed",
+			columns:  []ColumnPath{{"id"}},
+			pred:     Gt(ColumnPath{"info", "age"}, int32(35)),
+			expected: []map[string]interface{}{{"id": int64(1)}, {"id": int64(3)}},
+		},
+		{
+			name:     "nan-never-matches",
+			columns:  []ColumnPath{{"id"}},
+			pred:     Or(Lt(ColumnPath{"score"}, 2.0), Gt(ColumnPath{"score"}, 3.5), Eq(ColumnPath{"score"}, math.NaN())),
+			expected: []map[string]interface{}{{"id": int64(0)}, {"id": int64(3)}},
+		},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithColumnPaths(tt.columns...), WithRowFilter(tt.pred))
+			require.NoError(t, err)
+
+			var result []map[string]interface{}
+			for {
+				row, err := r.NextRow()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				result = append(result, row)
+			}
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRowFilterMultiplePages(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional group info {
+			optional binary name (STRING);
+		}
+		repeated int32 tags;
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	wr := NewFileWriter(&buf, WithSchemaDefinition(sd), WithMaxPageSize(64))
+
+	var expected []map[string]interface{}
+	for i := 0; i < 300; i++ {
+		row := map[string]interface{}{"id": int64(i)}
+		if i%3 != 0 {
+			row["info"] = map[string]interface{}{"name": []byte(fmt.Sprintf("name-%d", i))}
+		}
+		var (
+			tags    []int32
+			matches bool
+		)
+		for j := 0; j < i%4; j++ {
+			tags = append(tags, int32(i%7+j))
+			matches = matches || i%7+j == 5
+		}
+		if len(tags) > 0 {
+			row["tags"] = tags
+		}
+		require.NoError(t, wr.AddData(row))
+
+		if matches {
+			expected = append(expected, row)
+		}
+		if i%100 == 99 {
+			require.NoError(t, wr.FlushRowGroup())
+		}
+	}
+	require.NoError(t, wr.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithRowFilter(Eq(ColumnPath{"tags"}, int32(5))))
+	require.NoError(t, err)
+
+	// the filter is evaluated on the values of the first row without reading it.
+	require.NoError(t, r.PreLoad())
+	matches, ok, err := r.peekRowFilter()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.False(t, matches)
+
+	var result []map[string]interface{}
+	for {
+		row, err := r.NextRow()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		result = append(result, row)
+	}
+	require.Equal(t, expected, result)
+}
@@ -0,0 +1,172 @@
+package floor
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/fraugster/parquet-go/floor/interfaces"
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+var ratType = reflect.TypeOf(big.Rat{})
+
+// ratPointer returns a pointer to the big.Rat value. Values that aren't addressable, like the
+// fields of structs that are passed by value, are copied.
+func ratPointer(value reflect.Value) *big.Rat {
+	if value.CanAddr() {
+		return value.Addr().Interface().(*big.Rat)
+	}
+	r := value.Interface().(big.Rat)
+	return new(big.Rat).Set(&r)
+}
+
+// isDecimal returns true if elem is annotated as DECIMAL, either by its logical type
+// or its converted type.
+func isDecimal(elem *parquet.SchemaElement) bool {
+	if elem.LogicalType != nil {
+		return elem.GetLogicalType().IsSetDECIMAL()
+	}
+	return elem.GetConvertedType() == parquet.ConvertedType_DECIMAL
+}
+
+func decimalPrecisionAndScale(elem *parquet.SchemaElement) (precision, scale int32) {
+	if elem.LogicalType != nil && elem.GetLogicalType().IsSetDECIMAL() {
+		return elem.GetLogicalType().DECIMAL.Precision, elem.GetLogicalType().DECIMAL.Scale
+	}
+	return elem.GetPrecision(), elem.GetScale()
+}
+
+// encodeDecimal converts r to the unscaled value of the DECIMAL column elem. It returns
+// an int32 for INT32 columns, an int64 for INT64 columns, and the big-endian two's
+// complement of the unscaled value for FIXED_LEN_BYTE_ARRAY and BYTE_ARRAY columns.
+func encodeDecimal(elem *parquet.SchemaElement, r *big.Rat) (interface{}, error) {
+	precision, scale := decimalPrecisionAndScale(elem)
+
+	unscaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow10(scale)))
+	if !unscaled.IsInt() {
+		return nil, fmt.Errorf("value %s has more than %d decimal places", r.RatString(), scale)
+	}
+	i := unscaled.Num()
+
+	if new(big.Int).Abs(i).Cmp(pow10(precision)) >= 0 {
+		return nil, fmt.Errorf("value %s has more than %d digits", r.RatString(), precision)
+	}
+
+	switch elem.GetType() {
+	case parquet.Type_INT32:
+		return int32(i.Int64()), nil
+	case parquet.Type_INT64:
+		return i.Int64(), nil
+	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		return twosComplement(i, int(elem.GetTypeLength()))
+	case parquet.Type_BYTE_ARRAY:
+		return twosComplement(i, (i.BitLen()+8)/8)
+	}
+
+	return nil, fmt.Errorf("DECIMAL of type %s is unsupported", elem.GetType())
+}
+
+// setDecimal sets field to the unscaled value of r for the DECIMAL column elem.
+func setDecimal(field interfaces.MarshalElement, elem *parquet.SchemaElement, r *big.Rat) error {
+	v, err := encodeDecimal(elem, r)
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case int32:
+		field.SetInt32(v)
+	case int64:
+		field.SetInt64(v)
+	case []byte:
+		field.SetByteArray(v)
+	}
+	return nil
+}
+
+// MarshalDecimal sets field to the unscaled value of r for a DECIMAL column of the physical type
+// typ with the given precision and scale. typeLength is the length of FIXED_LEN_BYTE_ARRAY columns.
+// It is meant to be used by MarshalParquet methods, like the ones generated by
+// github.com/fraugster/parquet-go/parquetschema/codegen.
+func MarshalDecimal(field interfaces.MarshalElement, r *big.Rat, typ parquet.Type, typeLength, precision, scale int32) error {
+	return setDecimal(field, &parquet.SchemaElement{Type: &typ, TypeLength: &typeLength, Precision: &precision, Scale: &scale}, r)
+}
+
+// UnmarshalDecimal returns the value of field for a DECIMAL column with the given scale. It is
+// meant to be used by UnmarshalParquet methods, like the ones generated by
+// github.com/fraugster/parquet-go/parquetschema/codegen.
+func UnmarshalDecimal(field interfaces.UnmarshalElement, scale int32) (*big.Rat, error) {
+	v, err := decimalData(field)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDecimal(&parquet.SchemaElement{Scale: &scale}, v)
+}
+
+// decodeDecimal converts the unscaled value data of the DECIMAL column elem to a big.Rat.
+func decodeDecimal(elem *parquet.SchemaElement, data interface{}) (*big.Rat, error) {
+	_, scale := decimalPrecisionAndScale(elem)
+
+	var i *big.Int
+	switch v := data.(type) {
+	case int32:
+		i = big.NewInt(int64(v))
+	case int64:
+		i = big.NewInt(v)
+	case []byte:
+		i = fromTwosComplement(v)
+	default:
+		return nil, fmt.Errorf("expected int32, int64 or []byte for DECIMAL, found %T instead", data)
+	}
+
+	return new(big.Rat).SetFrac(i, pow10(scale)), nil
+}
+
+// decimalData returns the unscaled value of a DECIMAL from data.
+func decimalData(data interfaces.UnmarshalElement) (interface{}, error) {
+	if i, err := data.Int32(); err == nil {
+		return i, nil
+	}
+	if i, err := data.Int64(); err == nil {
+		return i, nil
+	}
+	return data.ByteArray()
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// twosComplement returns the big-endian two's complement of i with size bytes.
+func twosComplement(i *big.Int, size int) ([]byte, error) {
+	// a negative number i fits if -i-1, which is ^i, fits, e.g. -128 fits into a single byte.
+	bitLen := i.BitLen()
+	if i.Sign() < 0 {
+		bitLen = new(big.Int).Not(i).BitLen()
+	}
+	if bitLen >= 8*size {
+		return nil, errors.New("value doesn't fit into fixed length byte array")
+	}
+
+	buf := make([]byte, size)
+	if i.Sign() >= 0 {
+		i.FillBytes(buf)
+		return buf, nil
+	}
+
+	// the two's complement of a negative number is 2^(8*size) + i.
+	v := new(big.Int).Lsh(big.NewInt(1), uint(8*size))
+	v.Add(v, i)
+	v.FillBytes(buf)
+	return buf, nil
+}
+
+// fromTwosComplement returns the integer of the big-endian two's complement b.
+func fromTwosComplement(b []byte) *big.Int {
+	i := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		i.Sub(i, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	return i
+}
@@ -0,0 +1,223 @@
+package floor
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/fraugster/parquet-go/parquetschema/autoschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwosComplement(t *testing.T) {
+	testData := []struct {
+		value    int64
+		size     int
+		expected []byte
+	}{
+		{value: 0, size: 2, expected: []byte{0x00, 0x00}},
+		{value: 1, size: 2, expected: []byte{0x00, 0x01}},
+		{value: -1, size: 2, expected: []byte{0xff, 0xff}},
+		{value: 127, size: 1, expected: []byte{0x7f}},
+		{value: -127, size: 1, expected: []byte{0x81}},
+		{value: -128, size: 1, expected: []byte{0x80}},
+		{value: -32768, size: 2, expected: []byte{0x80, 0x00}},
+		{value: 256, size: 3, expected: []byte{0x00, 0x01, 0x00}},
+		{value: -256, size: 3, expected: []byte{0xff, 0xff, 0x00}},
+	}
+
+	for _, tt := range testData {
+		b, err := twosComplement(big.NewInt(tt.value), tt.size)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, b, "value %d", tt.value)
+		require.Equal(t, tt.value, fromTwosComplement(b).Int64())
+	}
+
+	for _, value := range []int64{128, -129} {
+		_, err := twosComplement(big.NewInt(value), 1)
+		require.Error(t, err, "value %d", value)
+	}
+}
+
+type decimalRecord struct {
+	Small    big.Rat
+	Medium   big.Rat
+	Large    big.Rat
+	Unscaled big.Rat
+	Optional *big.Rat
+	Raw      int64
+}
+
+func decimalRecords(t *testing.T) []decimalRecord {
+	rat := func(s string) big.Rat {
+		r, ok := new(big.Rat).SetString(s)
+		require.True(t, ok, s)
+		return *r
+	}
+	ptr := func(s string) *big.Rat {
+		r := rat(s)
+		return &r
+	}
+
+	return []decimalRecord{
+		{
+			Small:    rat("1234567.89"),
+			Medium:   rat("-12345678901234.5678"),
+			Large:    rat("1234567890123456789012345678.0123456789"),
+			Unscaled: rat("-98765432109876543.21"),
+			Optional: ptr("0.5"),
+			Raw:      12345,
+		},
+		{
+			Small:    rat("-0.01"),
+			Medium:   rat("0"),
+			Large:    rat("-1234567890123456789012345678.0123456789"),
+			Unscaled: rat("0.1"),
+			Raw:      -1,
+		},
+	}
+}
+
+const decimalSchema = `message test {
+	required int32 small (DECIMAL(9, 2));
+	required int64 medium (DECIMAL(18, 4));
+	required fixed_len_byte_array(16) large (DECIMAL(38, 10));
+	required binary unscaled (DECIMAL(20, 2));
+	optional int32 optional (DECIMAL(3, 1));
+	required int64 raw (DECIMAL(10, 2));
+}`
+
+func TestReadWriteDecimal(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(decimalSchema)
+	require.NoError(t, err)
+
+	records := decimalRecords(t)
+
+	var buf bytes.Buffer
+	w := NewWriter(goparquet.NewFileWriter(&buf, goparquet.WithSchemaDefinition(sd)))
+	for _, rec := range records {
+		require.NoError(t, w.Write(rec))
+	}
+	require.NoError(t, w.Close())
+
+	fr, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	row, err := fr.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, int32(123456789), row["small"])
+	require.Equal(t, int64(-123456789012345678), row["medium"])
+	require.Equal(t, int32(5), row["optional"])
+	require.Equal(t, int64(12345), row["raw"])
+
+	fr, err = goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	r := NewReader(fr)
+
+	var result []decimalRecord
+	for r.Next() {
+		var rec decimalRecord
+		require.NoError(t, r.Scan(&rec))
+		result = append(result, rec)
+	}
+	require.NoError(t, r.Err())
+	requireDecimalRecordsEqual(t, records, result)
+
+	fr, err = goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	tr, err := NewTypedReader[decimalRecord](fr)
+	require.NoError(t, err)
+
+	result, err = tr.ReadBatch(len(records))
+	require.NoError(t, err)
+	requireDecimalRecordsEqual(t, records, result)
+}
+
+func requireDecimalRecordsEqual(t *testing.T, expected, actual []decimalRecord) {
+	t.Helper()
+
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		e, a := expected[i], actual[i]
+		require.Equal(t, e.Small.RatString(), a.Small.RatString(), "%d. small", i)
+		require.Equal(t, e.Medium.RatString(), a.Medium.RatString(), "%d. medium", i)
+		require.Equal(t, e.Large.RatString(), a.Large.RatString(), "%d. large", i)
+		require.Equal(t, e.Unscaled.RatString(), a.Unscaled.RatString(), "%d. unscaled", i)
+		if e.Optional == nil {
+			require.Nil(t, a.Optional, "%d. optional", i)
+		} else {
+			require.Equal(t, e.Optional.RatString(), a.Optional.RatString(), "%d. optional", i)
+		}
+		require.Equal(t, e.Raw, a.Raw, "%d. raw", i)
+	}
+}
+
+func TestWriteDecimalErrors(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int32 value (DECIMAL(4, 2));
+	}`)
+	require.NoError(t, err)
+
+	type record struct {
+		Value big.Rat
+	}
+
+	testData := map[string]struct {
+		value       string
+		expectedErr string
+	}{
+		"too many decimal places": {value: "1.234", expectedErr: "field value: value 617/500 has more than 2 decimal places"},
+		"too many digits":         {value: "123.4", expectedErr: "field value: value 617/5 has more than 4 digits"},
+	}
+
+	for name, tt := range testData {
+		t.Run(name, func(t *testing.T) {
+			var rec record
+			_, ok := rec.Value.SetString(tt.value)
+			require.True(t, ok)
+
+			w := NewWriter(goparquet.NewFileWriter(&bytes.Buffer{}, goparquet.WithSchemaDefinition(sd)))
+			require.Error(t, w.Write(rec))
+
+			tw, err := NewTypedWriter[record](goparquet.NewFileWriter(&bytes.Buffer{}, goparquet.WithSchemaDefinition(sd)))
+			require.NoError(t, err)
+			require.EqualError(t, tw.Write(rec), tt.expectedErr)
+		})
+	}
+}
+
+func TestWriteReadDecimalWithAutoSchema(t *testing.T) {
+	type record struct {
+		Price    big.Rat  `parquet:"price,decimal(10,2)"`
+		Discount *big.Rat `parquet:"discount,decimal(4,4)"`
+		Total    big.Rat  `parquet:"total,decimal(30,2)"`
+	}
+
+	var rec record
+	rec.Price.SetString("99999999.99")
+	rec.Discount = big.NewRat(1, 8)
+	rec.Total.SetString("-1234567890123456789012345678.12")
+
+	sd, err := autoschema.GenerateSchema(rec)
+	require.NoError(t, err)
+	require.NoError(t, sd.Validate())
+
+	var buf bytes.Buffer
+	w, err := NewTypedWriter[record](goparquet.NewFileWriter(&buf, goparquet.WithSchemaDefinition(sd)))
+	require.NoError(t, err)
+	require.NoError(t, w.Write(rec))
+	require.NoError(t, w.Close())
+
+	fr, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	r := NewReader(fr)
+	require.True(t, r.Next())
+
+	var result record
+	require.NoError(t, r.Scan(&result))
+	require.Equal(t, rec.Price.RatString(), result.Price.RatString())
+	require.Equal(t, rec.Discount.RatString(), result.Discount.RatString())
+	require.Equal(t, rec.Total.RatString(), result.Total.RatString())
+}
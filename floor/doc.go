@@ -1,5 +1,4 @@
 /*
-
 Package floor provides a high-level interface to read from and write to parquet files. It works
 in conjunction with the goparquet package.
 
@@ -66,6 +65,11 @@ decode each field according to its data type.  Struct fields are matched up with
 to lowercase. If the struct field is equal to the parquet column name, it's a positive match. The exact mechanics of this may
 change in the future.
 
+The column name can also be set using the parquet struct tag, e.g. `parquet:"name"`. Fields with the struct tag `parquet:"-"`
+are skipped, and the zero value of fields with the optional option, e.g. `parquet:"name,optional"`, is written as null. The
+remaining options of the struct tag, like the logical type, physical type, encoding and compression codec of a column, are
+used by the autoschema package to generate the schema definition and writer options.
+
 Boolean types and numeric types will be mapped to their parquet equivalents.
 
 In particular, Go's int, int8, int16, int32, uint, uint8, and uint16 types will be mapped to parquet's int32 type, while
@@ -77,6 +81,10 @@ Go strings, byte slices and byte arrays will be mapped to parquet byte arrays. B
 in the schema, are also mapped to fixed length byte arrays, with additional check to ensure that the length of the slices
 resp. arrays matches up with the parquet schema definition.
 
+Go's big.Rat will be mapped to parquet's DECIMAL logical type, regardless of whether the column is an int32, int64,
+fixed length byte array or byte array. Writing a value that has more decimal places than the scale or more digits than
+the precision of the column fails. Integer types are written to DECIMAL columns as the unscaled value.
+
 Go slices of other data types will be mapped to parquet's LIST logical type. A strict adherence to a structure like this
 will be enforced:
 
@@ -201,5 +209,20 @@ needs to be a struct. Struct fields are matched up with parquet columns by conve
 to lowercase. If the struct field is equal to the parquet column name, it's a positive match. The exact
 mechanics of this may change in the future.
 
+If all objects that you read or write are of the same type, you can use the generic TypedReader and TypedWriter
+instead. They map the struct fields to the parquet columns once when they are created, and return an error right
+away if a field doesn't fit its column. Errors that occur while reading or writing objects are of type *FieldError
+and contain the path of the column.
+
+	w, err := floor.NewTypedFileWriter[yourRecord]("your-file.parquet", goparquet.WithSchemaDefinition(sd))
+	// ...
+	err = w.WriteBatch(records)
+	// ...
+	r, err := floor.NewTypedFileReader[yourRecord]("your-file.parquet")
+	// ...
+	for r.Next() {
+		record := r.Value()
+		// ...
+	}
 */
 package floor
@@ -15,5 +15,27 @@ func fieldNameToLower(field reflect.StructField) string {
 
 	parquetStructTagFields := strings.Split(parquetStructTag, ",")
 
-	return strings.TrimSpace(parquetStructTagFields[0])
+	if name := strings.TrimSpace(parquetStructTagFields[0]); name != "" {
+		return name
+	}
+
+	return strings.ToLower(field.Name)
+}
+
+// skipField returns true if the field has the struct tag `parquet:"-"`.
+func skipField(field reflect.StructField) bool {
+	return field.Tag.Get("parquet") == "-"
+}
+
+// optionalField returns true if the parquet struct tag of the field contains the optional
+// option. The zero value of such a field is written as null.
+func optionalField(field reflect.StructField) bool {
+	// options that contain commas are within parentheses, and are never equal to optional.
+	options := strings.Split(field.Tag.Get("parquet"), ",")
+	for _, opt := range options[1:] {
+		if strings.TrimSpace(opt) == "optional" {
+			return true
+		}
+	}
+	return false
 }
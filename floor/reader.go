@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"os"
 	"reflect"
 	"time"
@@ -122,6 +123,10 @@ func (um *reflectUnmarshaller) fillStruct(value reflect.Value, record interfaces
 
 	numFields := typ.NumField()
 	for i := 0; i < numFields; i++ {
+		if skipField(typ.Field(i)) {
+			continue
+		}
+
 		fieldValue := value.Field(i)
 
 		fieldName := fieldNameFunc(typ.Field(i))
@@ -211,6 +216,21 @@ func (um *reflectUnmarshaller) fillDateValue(value reflect.Value, data interface
 	return nil
 }
 
+func (um *reflectUnmarshaller) fillDecimalValue(elem *parquet.SchemaElement, value reflect.Value, data interfaces.UnmarshalElement) error {
+	v, err := decimalData(data)
+	if err != nil {
+		return err
+	}
+
+	r, err := decodeDecimal(elem, v)
+	if err != nil {
+		return err
+	}
+
+	value.Addr().Interface().(*big.Rat).Set(r)
+	return nil
+}
+
 func (um *reflectUnmarshaller) fillValue(value reflect.Value, data interfaces.UnmarshalElement, schemaDef *parquetschema.SchemaDefinition) error {
 	if value.Kind() == reflect.Ptr {
 		value.Set(reflect.New(value.Type().Elem()))
@@ -246,6 +266,12 @@ func (um *reflectUnmarshaller) fillValue(value reflect.Value, data interfaces.Un
 		}
 	}
 
+	if value.Type() == ratType {
+		if elem := schemaDef.SchemaElement(); isDecimal(elem) {
+			return um.fillDecimalValue(elem, value, data)
+		}
+	}
+
 	switch value.Kind() {
 	case reflect.Bool:
 		b, err := data.Bool()
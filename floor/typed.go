@@ -0,0 +1,82 @@
+package floor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// FieldError is returned by TypedReader and TypedWriter if the value of a field
+// couldn't be converted from or to its parquet representation.
+type FieldError struct {
+	// Path of the field in the parquet schema, with its elements separated by dots.
+	Path string
+
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func fieldErrorf(path string, format string, args ...interface{}) error {
+	return &FieldError{Path: path, Err: fmt.Errorf(format, args...)}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	floorTimeType = reflect.TypeOf(Time{})
+)
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// exportedFields returns the indexes, names and schema definitions of the exported
+// fields of the struct type typ that aren't skipped and have a matching column in schemaDef.
+func exportedFields(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition) (indexes []int, names []string, schemaDefs []*parquetschema.SchemaDefinition) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" || skipField(field) {
+			continue
+		}
+
+		name := fieldNameFunc(field)
+		fieldSchemaDef := schemaDef.SubSchema(name)
+		if fieldSchemaDef == nil {
+			continue
+		}
+
+		indexes = append(indexes, i)
+		names = append(names, name)
+		schemaDefs = append(schemaDefs, fieldSchemaDef)
+	}
+	return indexes, names, schemaDefs
+}
+
+// listElement returns the names of the repeated group and the element of a LIST, as
+// well as the schema definition of the element.
+func listElement(schemaDef *parquetschema.SchemaDefinition) (listName, elemName string, elemSchemaDef *parquetschema.SchemaDefinition, err error) {
+	if elemSchemaDef = schemaDef.SubSchema("list").SubSchema("element"); elemSchemaDef != nil {
+		return "list", "element", elemSchemaDef, nil
+	}
+	if elemSchemaDef = schemaDef.SubSchema("bag").SubSchema("array_element"); elemSchemaDef != nil {
+		return "bag", "array_element", elemSchemaDef, nil
+	}
+	return "", "", nil, errors.New("annotated as LIST but group structure seems invalid")
+}
+
+func isByteSequence(typ reflect.Type) bool {
+	return (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && typ.Elem().Kind() == reflect.Uint8
+}
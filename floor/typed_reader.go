@@ -0,0 +1,578 @@
+package floor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"reflect"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/floor/interfaces"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// TypedReader is a high-level reader for parquet files that returns objects of
+// type T. Unlike Reader, it maps the columns of the schema definition to the
+// fields of T once when it is created, instead of for every object that is read.
+type TypedReader[T any] struct {
+	r         *goparquet.FileReader
+	f         io.Closer
+	unmarshal func(data map[string]interface{}, obj *T) error
+
+	value T
+	err   error
+}
+
+// NewTypedReader returns a new high-level parquet file reader that returns objects
+// of type T, which needs to be a struct. If *T implements the floor.Unmarshaller
+// interface, it is used to unmarshal the objects. Otherwise, the columns of the
+// schema definition are mapped to the fields of T the same way Reader does it, and
+// an error is returned if a column can't be read into its field.
+func NewTypedReader[T any](r *goparquet.FileReader) (*TypedReader[T], error) {
+	unmarshal, err := newTypedUnmarshaller[T](r.GetSchemaDefinition())
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedReader[T]{
+		r:         r,
+		unmarshal: unmarshal,
+	}, nil
+}
+
+// NewTypedFileReader returns a new high-level parquet file reader that returns
+// objects of type T and directly reads from the provided file.
+func NewTypedFileReader[T any](file string) (*TypedReader[T], error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	fr, err := goparquet.NewFileReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	r, err := NewTypedReader[T](fr)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	r.f = f
+	return r, nil
+}
+
+// Close closes the reader.
+func (r *TypedReader[T]) Close() error {
+	if r.f != nil {
+		return r.f.Close()
+	}
+
+	return nil
+}
+
+// Next reads the next object so that it can be retrieved using Value.
+// Returns true if reading the next object was successful, false
+// otherwise, e.g. in case of an error or when EOF was reached.
+func (r *TypedReader[T]) Next() bool {
+	var value T
+	r.value = value
+
+	data, err := r.r.NextRow()
+	if err == io.EOF {
+		return false
+	}
+	if err != nil {
+		r.err = err
+		return false
+	}
+
+	if err := r.unmarshal(data, &r.value); err != nil {
+		r.err = err
+		return false
+	}
+
+	return true
+}
+
+// Value returns the object last read by Next.
+func (r *TypedReader[T]) Value() T {
+	return r.value
+}
+
+// ReadBatch reads up to n objects. It returns fewer objects if the end of the file
+// is reached, and io.EOF if there are no more objects to read.
+func (r *TypedReader[T]) ReadBatch(n int) ([]T, error) {
+	values := make([]T, 0, n)
+	for len(values) < n {
+		data, err := r.r.NextRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, *new(T))
+		if err := r.unmarshal(data, &values[len(values)-1]); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(values) == 0 && n > 0 {
+		return nil, io.EOF
+	}
+
+	return values, nil
+}
+
+// Err returns an error in case Next returned false due to an error.
+// If Next returned false due to EOF, Err returns nil.
+func (r *TypedReader[T]) Err() error {
+	return r.err
+}
+
+// GetSchemaDefinition returns the schema definition of the parquet
+// file.
+func (r *TypedReader[T]) GetSchemaDefinition() *parquetschema.SchemaDefinition {
+	return r.r.GetSchemaDefinition()
+}
+
+func newTypedUnmarshaller[T any](schemaDef *parquetschema.SchemaDefinition) (func(data map[string]interface{}, obj *T) error, error) {
+	if schemaDef == nil {
+		return nil, errors.New("no schema definition available")
+	}
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	if reflect.PtrTo(typ).Implements(reflect.TypeOf((*interfaces.Unmarshaller)(nil)).Elem()) {
+		return func(data map[string]interface{}, obj *T) error {
+			return interface{}(obj).(interfaces.Unmarshaller).UnmarshalParquet(interfaces.NewUnmarshallObject(data))
+		}, nil
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("type %s is not a struct", typ)
+	}
+
+	fields, err := newStructDecoder(typ, schemaDef, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return func(data map[string]interface{}, obj *T) error {
+		return decodeStruct(fields, data, reflect.ValueOf(obj).Elem())
+	}, nil
+}
+
+// decodeFunc sets value from its parquet representation data.
+type decodeFunc func(data interface{}, value reflect.Value) error
+
+type fieldDecoder struct {
+	index    int
+	name     string
+	path     string
+	required bool
+	decode   decodeFunc
+}
+
+func newStructDecoder(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition, path string) ([]fieldDecoder, error) {
+	indexes, names, schemaDefs := exportedFields(typ, schemaDef)
+
+	fields := make([]fieldDecoder, len(indexes))
+	for i := range indexes {
+		fieldPath := joinPath(path, names[i])
+		decode, err := newDecoder(typ.Field(indexes[i]).Type, schemaDefs[i], fieldPath)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = fieldDecoder{
+			index:    indexes[i],
+			name:     names[i],
+			path:     fieldPath,
+			required: schemaDefs[i].SchemaElement().GetRepetitionType() == parquet.FieldRepetitionType_REQUIRED,
+			decode:   decode,
+		}
+	}
+
+	return fields, nil
+}
+
+func decodeStruct(fields []fieldDecoder, data map[string]interface{}, value reflect.Value) error {
+	for _, f := range fields {
+		v, ok := data[f.name]
+		if !ok {
+			if f.required {
+				return fieldErrorf(f.path, "field is REQUIRED but couldn't be found in data")
+			}
+			continue
+		}
+
+		if err := f.decode(v, value.Field(f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newDecoder(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition, path string) (decodeFunc, error) {
+	elem := schemaDef.SchemaElement()
+
+	if typ.Kind() == reflect.Ptr {
+		decode, err := newDecoder(typ.Elem(), schemaDef, path)
+		if err != nil {
+			return nil, err
+		}
+		return func(data interface{}, value reflect.Value) error {
+			ptr := reflect.New(typ.Elem())
+			if err := decode(data, ptr.Elem()); err != nil {
+				return err
+			}
+			value.Set(ptr)
+			return nil
+		}, nil
+	}
+
+	if typ.ConvertibleTo(floorTimeType) && elem.LogicalType != nil && elem.GetLogicalType().IsSetTIME() {
+		return newTimeDecoder(typ, elem, path)
+	}
+
+	if typ.ConvertibleTo(timeType) {
+		switch {
+		case elem.LogicalType != nil && elem.GetLogicalType().IsSetDATE():
+			return func(data interface{}, value reflect.Value) error {
+				i, err := intValue(data, path)
+				if err != nil {
+					return err
+				}
+				date := time.Unix(0, 0).UTC().Add(24 * time.Hour * time.Duration(i))
+				value.Set(reflect.ValueOf(date).Convert(typ))
+				return nil
+			}, nil
+		case elem.LogicalType != nil && elem.GetLogicalType().IsSetTIMESTAMP():
+			return newTimestampDecoder(typ, elem, path)
+		case elem.LogicalType == nil && elem.GetType() == parquet.Type_INT96:
+			return func(data interface{}, value reflect.Value) error {
+				i96, ok := data.([12]byte)
+				if !ok {
+					return fieldErrorf(path, "expected [12]byte, found %T instead", data)
+				}
+				value.Set(reflect.ValueOf(goparquet.Int96ToTime(i96).UTC()).Convert(typ))
+				return nil
+			}, nil
+		}
+	}
+
+	if typ == ratType && isDecimal(elem) {
+		return func(data interface{}, value reflect.Value) error {
+			r, err := decodeDecimal(elem, data)
+			if err != nil {
+				return &FieldError{Path: path, Err: err}
+			}
+			value.Addr().Interface().(*big.Rat).Set(r)
+			return nil
+		}, nil
+	}
+
+	if elem.Type == nil {
+		return newGroupDecoder(typ, schemaDef, path)
+	}
+
+	unsupported := func() (decodeFunc, error) {
+		return nil, fieldErrorf(path, "can't read %s column into %s", elem.GetType(), typ)
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		if elem.GetType() != parquet.Type_BOOLEAN {
+			return unsupported()
+		}
+		return func(data interface{}, value reflect.Value) error {
+			b, ok := data.(bool)
+			if !ok {
+				return fieldErrorf(path, "expected bool, found %T instead", data)
+			}
+			value.SetBool(b)
+			return nil
+		}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if elem.GetType() != parquet.Type_INT32 && elem.GetType() != parquet.Type_INT64 {
+			return unsupported()
+		}
+		return func(data interface{}, value reflect.Value) error {
+			i, err := intValue(data, path)
+			if err != nil {
+				return err
+			}
+			value.SetInt(i)
+			return nil
+		}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if elem.GetType() != parquet.Type_INT32 && elem.GetType() != parquet.Type_INT64 {
+			return unsupported()
+		}
+		return func(data interface{}, value reflect.Value) error {
+			i, err := intValue(data, path)
+			if err != nil {
+				return err
+			}
+			value.SetUint(uint64(i))
+			return nil
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		if elem.GetType() != parquet.Type_FLOAT && elem.GetType() != parquet.Type_DOUBLE {
+			return unsupported()
+		}
+		return func(data interface{}, value reflect.Value) error {
+			switch f := data.(type) {
+			case float32:
+				value.SetFloat(float64(f))
+			case float64:
+				value.SetFloat(f)
+			default:
+				return fieldErrorf(path, "expected float32 or float64, found %T instead", data)
+			}
+			return nil
+		}, nil
+	case reflect.String:
+		if elem.GetType() != parquet.Type_BYTE_ARRAY && elem.GetType() != parquet.Type_FIXED_LEN_BYTE_ARRAY {
+			return unsupported()
+		}
+		return func(data interface{}, value reflect.Value) error {
+			b, ok := data.([]byte)
+			if !ok {
+				return fieldErrorf(path, "expected []byte, found %T instead", data)
+			}
+			value.SetString(string(b))
+			return nil
+		}, nil
+	case reflect.Slice, reflect.Array:
+		if !isByteSequence(typ) {
+			return unsupported()
+		}
+		if elem.GetType() != parquet.Type_BYTE_ARRAY && elem.GetType() != parquet.Type_FIXED_LEN_BYTE_ARRAY && elem.GetType() != parquet.Type_INT96 {
+			return unsupported()
+		}
+		return func(data interface{}, value reflect.Value) error {
+			var b []byte
+			switch x := data.(type) {
+			case []byte:
+				b = x
+			case [12]byte:
+				b = x[:]
+			default:
+				return fieldErrorf(path, "expected []byte, found %T instead", data)
+			}
+			if value.Kind() == reflect.Slice {
+				value.Set(reflect.MakeSlice(typ, len(b), len(b)))
+			}
+			for i := 0; i < len(b) && i < value.Len(); i++ {
+				value.Index(i).SetUint(uint64(b[i]))
+			}
+			return nil
+		}, nil
+	}
+
+	return unsupported()
+}
+
+func intValue(data interface{}, path string) (int64, error) {
+	switch i := data.(type) {
+	case int32:
+		return int64(i), nil
+	case int64:
+		return i, nil
+	}
+	return 0, fieldErrorf(path, "expected int32 or int64, found %T instead", data)
+}
+
+func newTimeDecoder(typ reflect.Type, elem *parquet.SchemaElement, path string) (decodeFunc, error) {
+	var fromInt func(int64) Time
+	unit := elem.GetLogicalType().TIME.Unit
+	switch {
+	case unit.IsSetNANOS():
+		fromInt = TimeFromNanoseconds
+	case unit.IsSetMICROS():
+		fromInt = TimeFromMicroseconds
+	case unit.IsSetMILLIS():
+		fromInt = func(i int64) Time { return TimeFromMilliseconds(int32(i)) }
+	default:
+		return nil, fieldErrorf(path, "invalid TIME unit")
+	}
+	utc := elem.GetLogicalType().TIME.GetIsAdjustedToUTC()
+
+	return func(data interface{}, value reflect.Value) error {
+		i, err := intValue(data, path)
+		if err != nil {
+			return err
+		}
+		t := fromInt(i)
+		if utc {
+			t = t.UTC()
+		}
+		value.Set(reflect.ValueOf(t).Convert(typ))
+		return nil
+	}, nil
+}
+
+func newTimestampDecoder(typ reflect.Type, elem *parquet.SchemaElement, path string) (decodeFunc, error) {
+	var factor int64
+	unit := elem.GetLogicalType().TIMESTAMP.Unit
+	switch {
+	case unit.IsSetNANOS():
+		factor = 1
+	case unit.IsSetMICROS():
+		factor = 1000
+	case unit.IsSetMILLIS():
+		factor = 1000000
+	default:
+		return nil, fieldErrorf(path, "invalid TIMESTAMP unit")
+	}
+	utc := elem.GetLogicalType().TIMESTAMP.GetIsAdjustedToUTC()
+	perSecond := int64(time.Second) / factor
+
+	return func(data interface{}, value reflect.Value) error {
+		i, err := intValue(data, path)
+		if err != nil {
+			return err
+		}
+		ts := time.Unix(i/perSecond, factor*(i%perSecond))
+		if utc {
+			ts = ts.UTC()
+		}
+		value.Set(reflect.ValueOf(ts).Convert(typ))
+		return nil
+	}, nil
+}
+
+func newGroupDecoder(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition, path string) (decodeFunc, error) {
+	elem := schemaDef.SchemaElement()
+
+	switch {
+	case elem.GetConvertedType() == parquet.ConvertedType_LIST:
+		if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array || isByteSequence(typ) {
+			return nil, fieldErrorf(path, "can't read LIST into %s", typ)
+		}
+		return newListDecoder(typ, schemaDef, path)
+	case elem.GetConvertedType() == parquet.ConvertedType_MAP:
+		if typ.Kind() != reflect.Map {
+			return nil, fieldErrorf(path, "can't read MAP into %s", typ)
+		}
+		return newMapDecoder(typ, schemaDef, path)
+	case typ.Kind() == reflect.Struct:
+		fields, err := newStructDecoder(typ, schemaDef, path)
+		if err != nil {
+			return nil, err
+		}
+		return func(data interface{}, value reflect.Value) error {
+			group, ok := data.(map[string]interface{})
+			if !ok {
+				return fieldErrorf(path, "expected group, found %T instead", data)
+			}
+			return decodeStruct(fields, group, value)
+		}, nil
+	}
+
+	return nil, fieldErrorf(path, "can't read group into %s", typ)
+}
+
+func newListDecoder(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition, path string) (decodeFunc, error) {
+	listName, elemName, elemSchemaDef, err := listElement(schemaDef)
+	if err != nil {
+		return nil, &FieldError{Path: path, Err: err}
+	}
+
+	decode, err := newDecoder(typ.Elem(), elemSchemaDef, joinPath(joinPath(path, listName), elemName))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(data interface{}, value reflect.Value) error {
+		group, ok := data.(map[string]interface{})
+		if !ok {
+			return fieldErrorf(path, "expected LIST group, found %T instead", data)
+		}
+		elems, ok := group[listName].([]map[string]interface{})
+		if !ok {
+			return fieldErrorf(path, "expected sub-group %s to be []map[string]interface{}, found %T instead", listName, group[listName])
+		}
+
+		if value.Kind() == reflect.Slice {
+			value.Set(reflect.MakeSlice(typ, len(elems), len(elems)))
+		}
+
+		for i := 0; i < len(elems) && i < value.Len(); i++ {
+			v, ok := elems[i][elemName]
+			if !ok {
+				continue
+			}
+			if err := decode(v, value.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, nil
+}
+
+func newMapDecoder(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition, path string) (decodeFunc, error) {
+	keyValueSchemaDef := schemaDef.SubSchema("key_value")
+	keySchemaDef := keyValueSchemaDef.SubSchema("key")
+	if keySchemaDef == nil {
+		return nil, fieldErrorf(path, "annotated as MAP but group structure seems invalid")
+	}
+
+	keyValuePath := joinPath(path, "key_value")
+
+	decodeKey, err := newDecoder(typ.Key(), keySchemaDef, joinPath(keyValuePath, "key"))
+	if err != nil {
+		return nil, err
+	}
+
+	var decodeValue decodeFunc
+	if valueSchemaDef := keyValueSchemaDef.SubSchema("value"); valueSchemaDef != nil {
+		decodeValue, err = newDecoder(typ.Elem(), valueSchemaDef, joinPath(keyValuePath, "value"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func(data interface{}, value reflect.Value) error {
+		group, ok := data.(map[string]interface{})
+		if !ok {
+			return fieldErrorf(path, "expected MAP group, found %T instead", data)
+		}
+		keyValues, ok := group["key_value"].([]map[string]interface{})
+		if !ok {
+			return fieldErrorf(path, "expected sub-group key_value to be []map[string]interface{}, found %T instead", group["key_value"])
+		}
+
+		value.Set(reflect.MakeMapWithSize(typ, len(keyValues)))
+
+		for _, kv := range keyValues {
+			k := reflect.New(typ.Key()).Elem()
+			if keyData, ok := kv["key"]; ok {
+				if err := decodeKey(keyData, k); err != nil {
+					return err
+				}
+			}
+
+			v := reflect.New(typ.Elem()).Elem()
+			if valueData, ok := kv["value"]; ok && decodeValue != nil {
+				if err := decodeValue(valueData, v); err != nil {
+					return err
+				}
+			}
+
+			value.SetMapIndex(k, v)
+		}
+
+		return nil
+	}, nil
+}
@@ -0,0 +1,275 @@
+package floor
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+const typedTestSchema = `message test {
+	required int64 id;
+	required binary name (STRING);
+	optional double score;
+	required boolean flag;
+	required int32 small;
+	optional group tags (LIST) {
+		repeated group list {
+			required binary element (STRING);
+		}
+	}
+	optional group attrs (MAP) {
+		repeated group key_value (MAP_KEY_VALUE) {
+			required binary key (STRING);
+			required int32 value;
+		}
+	}
+	optional group address {
+		required binary city (STRING);
+		optional binary zip (STRING);
+	}
+	optional group addresses (LIST) {
+		repeated group list {
+			required group element {
+				required binary city (STRING);
+				optional binary zip (STRING);
+			}
+		}
+	}
+	required int32 day (DATE);
+	required int64 created (TIMESTAMP(MICROS, true));
+	required int96 legacy;
+	required int64 at (TIME(NANOS, true));
+	required fixed_len_byte_array(16) uuid (UUID);
+	optional binary data;
+}`
+
+type typedTestAddress struct {
+	City string
+	Zip  *string
+}
+
+type typedTestRecord struct {
+	ID        int64
+	Name      string
+	Score     *float64
+	Flag      bool
+	Small     uint16
+	Tags      []string
+	Attrs     map[string]int32
+	Address   *typedTestAddress
+	Addresses []typedTestAddress
+	Day       time.Time
+	Created   time.Time
+	Legacy    time.Time
+	At        Time
+	UUID      [16]byte
+	Data      []byte
+	Ignored   string `parquet:"not_in_schema"`
+}
+
+func typedTestRecords() []typedTestRecord {
+	score := 0.5
+	zip := "10115"
+	created := time.Date(2022, 3, 4, 5, 6, 7, 8000, time.UTC)
+
+	return []typedTestRecord{
+		{
+			ID:        1,
+			Name:      "first",
+			Score:     &score,
+			Flag:      true,
+			Small:     65535,
+			Tags:      []string{"a", "b"},
+			Attrs:     map[string]int32{"x": 1, "y": 2},
+			Address:   &typedTestAddress{City: "Berlin", Zip: &zip},
+			Addresses: []typedTestAddress{{City: "Hamburg"}, {City: "Berlin", Zip: &zip}},
+			Day:       time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC),
+			Created:   created,
+			Legacy:    created.Add(123),
+			At:        MustTime(NewTime(12, 34, 56, 789)).UTC(),
+			UUID:      [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			Data:      []byte{0, 1, 2},
+		},
+		{
+			ID:      2,
+			Name:    "second",
+			Day:     time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC),
+			Created: created.Add(-time.Hour),
+			Legacy:  created,
+			At:      MustTime(NewTime(0, 0, 0, 0)).UTC(),
+		},
+		{
+			ID:      3,
+			Name:    "third",
+			Tags:    []string{"c"},
+			Address: &typedTestAddress{City: "Munich"},
+			Day:     time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			Created: created.Add(time.Hour),
+			Legacy:  created,
+			At:      MustTime(NewTime(23, 59, 59, 999999999)).UTC(),
+		},
+	}
+}
+
+func TestTypedWriterReader(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(typedTestSchema)
+	require.NoError(t, err)
+
+	records := typedTestRecords()
+
+	var buf bytes.Buffer
+	w, err := NewTypedWriter[typedTestRecord](goparquet.NewFileWriter(&buf, goparquet.WithSchemaDefinition(sd)))
+	require.NoError(t, err)
+	require.NoError(t, w.Write(records[0]))
+	require.NoError(t, w.WriteBatch(records[1:]))
+	require.NoError(t, w.Close())
+
+	fr, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	r, err := NewTypedReader[typedTestRecord](fr)
+	require.NoError(t, err)
+
+	var result []typedTestRecord
+	for r.Next() {
+		result = append(result, r.Value())
+	}
+	require.NoError(t, r.Err())
+	require.Equal(t, records, result)
+
+	// the reflection-based reader reads the same records.
+	fr, err = goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	reflectReader := NewReader(fr)
+
+	result = nil
+	for reflectReader.Next() {
+		var rec typedTestRecord
+		require.NoError(t, reflectReader.Scan(&rec))
+		result = append(result, rec)
+	}
+	require.NoError(t, reflectReader.Err())
+	require.Equal(t, records, result)
+}
+
+func TestTypedReaderReflectionWriter(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(typedTestSchema)
+	require.NoError(t, err)
+
+	records := typedTestRecords()
+
+	var buf bytes.Buffer
+	w := NewWriter(goparquet.NewFileWriter(&buf, goparquet.WithSchemaDefinition(sd)))
+	for _, rec := range records {
+		require.NoError(t, w.Write(rec))
+	}
+	require.NoError(t, w.Close())
+
+	fr, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	r, err := NewTypedReader[typedTestRecord](fr)
+	require.NoError(t, err)
+
+	batch, err := r.ReadBatch(2)
+	require.NoError(t, err)
+	require.Equal(t, records[:2], batch)
+
+	batch, err = r.ReadBatch(2)
+	require.NoError(t, err)
+	require.Equal(t, records[2:], batch)
+
+	_, err = r.ReadBatch(2)
+	require.Equal(t, io.EOF, err)
+}
+
+func TestTypedMarshaller(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test_msg {
+		required group emails (LIST) {
+			repeated group bag {
+				required binary array_element (STRING);
+			}
+		}
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := NewTypedWriter[emailList](goparquet.NewFileWriter(&buf, goparquet.WithSchemaDefinition(sd)))
+	require.NoError(t, err)
+	require.NoError(t, w.Write(emailList{emails: []string{"foo@example.com", "bar@example.com"}}))
+	require.NoError(t, w.Close())
+
+	fr, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	r, err := NewTypedReader[emailList](fr)
+	require.NoError(t, err)
+
+	require.True(t, r.Next())
+	require.Equal(t, []string{"foo@example.com", "bar@example.com"}, r.Value().emails)
+	require.False(t, r.Next())
+	require.NoError(t, r.Err())
+}
+
+func TestTypedFieldErrors(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required group address {
+			required binary city (STRING);
+		}
+		optional group tags (LIST) {
+			repeated group list {
+				required int32 element;
+			}
+		}
+	}`)
+	require.NoError(t, err)
+
+	type badCity struct {
+		Address struct{ City int64 }
+	}
+	type badTags struct {
+		Tags []string
+	}
+
+	_, err = NewTypedWriter[badCity](goparquet.NewFileWriter(io.Discard, goparquet.WithSchemaDefinition(sd)))
+	var fieldErr *FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	require.Equal(t, "address.city", fieldErr.Path)
+
+	_, err = NewTypedWriter[badTags](goparquet.NewFileWriter(io.Discard, goparquet.WithSchemaDefinition(sd)))
+	require.EqualError(t, err, "field tags.list.element: can't write string to INT32 column")
+
+	_, err = NewTypedWriter[int](goparquet.NewFileWriter(io.Discard, goparquet.WithSchemaDefinition(sd)))
+	require.Error(t, err)
+
+	var buf bytes.Buffer
+	fw := goparquet.NewFileWriter(&buf, goparquet.WithSchemaDefinition(sd))
+	require.NoError(t, fw.AddData(map[string]interface{}{
+		"id":      int64(1),
+		"address": map[string]interface{}{"city": []byte("Berlin")},
+	}))
+	require.NoError(t, fw.Close())
+
+	fr, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	_, err = NewTypedReader[badCity](fr)
+	require.EqualError(t, err, "field address.city: can't read BYTE_ARRAY column into int64")
+
+	type record struct {
+		ID      int64
+		Address struct{ City string }
+	}
+
+	fr, err = goparquet.NewFileReader(bytes.NewReader(buf.Bytes()), "id")
+	require.NoError(t, err)
+
+	r, err := NewTypedReader[record](fr)
+	require.NoError(t, err)
+	require.False(t, r.Next())
+	require.EqualError(t, r.Err(), "field address.city: field is REQUIRED but couldn't be found in data")
+}
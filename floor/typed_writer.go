@@ -0,0 +1,484 @@
+package floor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/floor/interfaces"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// TypedWriter is a high-level writer for parquet files that writes objects of
+// type T. Unlike Writer, it maps the fields of T to the columns of the schema
+// definition once when it is created, instead of for every object that is written.
+type TypedWriter[T any] struct {
+	w       *goparquet.FileWriter
+	f       io.Closer
+	marshal func(obj *T) (map[string]interface{}, error)
+}
+
+// NewTypedWriter creates a new high-level writer for parquet that writes objects
+// of type T, which needs to be a struct. If *T implements the floor.Marshaller
+// interface, it is used to marshal the objects. Otherwise, the fields of T are
+// mapped to the columns of the schema definition the same way Writer does it, and
+// an error is returned if a field can't be written to its column.
+// NOTE: We assume the schema definition is constant.
+func NewTypedWriter[T any](w *goparquet.FileWriter) (*TypedWriter[T], error) {
+	marshal, err := newTypedMarshaller[T](w.GetSchemaDefinition())
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedWriter[T]{
+		w:       w,
+		marshal: marshal,
+	}, nil
+}
+
+// NewTypedFileWriter creates a new high-level writer for parquet that writes objects
+// of type T to a particular file.
+// NOTE: We assume the schema definition is constant.
+func NewTypedFileWriter[T any](file string, opts ...goparquet.FileWriterOption) (*TypedWriter[T], error) {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := NewTypedWriter[T](goparquet.NewFileWriter(f, opts...))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	w.f = f
+	return w, nil
+}
+
+// Write adds a new object to be written to the parquet file.
+func (w *TypedWriter[T]) Write(obj T) error {
+	data, err := w.marshal(&obj)
+	if err != nil {
+		return err
+	}
+
+	return w.w.AddData(data)
+}
+
+// WriteBatch adds all objects of objs to be written to the parquet file.
+func (w *TypedWriter[T]) WriteBatch(objs []T) error {
+	for i := range objs {
+		data, err := w.marshal(&objs[i])
+		if err != nil {
+			return err
+		}
+
+		if err := w.w.AddData(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close flushes outstanding data and closes the underlying
+// parquet writer.
+func (w *TypedWriter[T]) Close() error {
+	if w.f != nil {
+		defer w.f.Close()
+	}
+
+	return w.w.Close()
+}
+
+func newTypedMarshaller[T any](schemaDef *parquetschema.SchemaDefinition) (func(obj *T) (map[string]interface{}, error), error) {
+	if schemaDef == nil {
+		return nil, errors.New("no schema definition available")
+	}
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	if reflect.PtrTo(typ).Implements(reflect.TypeOf((*interfaces.Marshaller)(nil)).Elem()) {
+		return func(obj *T) (map[string]interface{}, error) {
+			data := interfaces.NewMarshallObjectWithSchema(nil, schemaDef)
+			if err := interface{}(obj).(interfaces.Marshaller).MarshalParquet(data); err != nil {
+				return nil, err
+			}
+			return data.GetData(), nil
+		}, nil
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("type %s is not a struct", typ)
+	}
+
+	fields, err := newStructEncoder(typ, schemaDef, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return func(obj *T) (map[string]interface{}, error) {
+		return encodeStruct(fields, reflect.ValueOf(obj).Elem())
+	}, nil
+}
+
+// encodeFunc returns the parquet representation of value, or nil if the value is
+// null.
+type encodeFunc func(value reflect.Value) (interface{}, error)
+
+type fieldEncoder struct {
+	index  int
+	name   string
+	encode encodeFunc
+}
+
+func newStructEncoder(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition, path string) ([]fieldEncoder, error) {
+	indexes, names, schemaDefs := exportedFields(typ, schemaDef)
+
+	fields := make([]fieldEncoder, len(indexes))
+	for i := range indexes {
+		encode, err := newEncoder(typ.Field(indexes[i]).Type, schemaDefs[i], joinPath(path, names[i]))
+		if err != nil {
+			return nil, err
+		}
+		if optionalField(typ.Field(indexes[i])) {
+			encode = omitZero(encode)
+		}
+		fields[i] = fieldEncoder{index: indexes[i], name: names[i], encode: encode}
+	}
+
+	return fields, nil
+}
+
+// omitZero returns an encodeFunc that encodes the zero value as null.
+func omitZero(encode encodeFunc) encodeFunc {
+	return func(value reflect.Value) (interface{}, error) {
+		if value.IsZero() {
+			return nil, nil
+		}
+		return encode(value)
+	}
+}
+
+func encodeStruct(fields []fieldEncoder, value reflect.Value) (map[string]interface{}, error) {
+	data := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		v, err := f.encode(value.Field(f.index))
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			data[f.name] = v
+		}
+	}
+	return data, nil
+}
+
+func newEncoder(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition, path string) (encodeFunc, error) {
+	elem := schemaDef.SchemaElement()
+
+	if typ.Kind() == reflect.Ptr {
+		encode, err := newEncoder(typ.Elem(), schemaDef, path)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) (interface{}, error) {
+			if value.IsNil() {
+				return nil, nil
+			}
+			return encode(value.Elem())
+		}, nil
+	}
+
+	if typ.ConvertibleTo(floorTimeType) && elem.LogicalType != nil && elem.GetLogicalType().IsSetTIME() {
+		return newTimeEncoder(elem, path)
+	}
+
+	if typ.ConvertibleTo(timeType) {
+		switch {
+		case elem.LogicalType != nil && elem.GetLogicalType().IsSetDATE():
+			return func(value reflect.Value) (interface{}, error) {
+				t := value.Convert(timeType).Interface().(time.Time)
+				return int32(t.Sub(time.Unix(0, 0).UTC()).Hours() / 24), nil
+			}, nil
+		case elem.LogicalType != nil && elem.GetLogicalType().IsSetTIMESTAMP():
+			return newTimestampEncoder(elem, path)
+		case elem.LogicalType == nil && elem.GetType() == parquet.Type_INT96:
+			return func(value reflect.Value) (interface{}, error) {
+				return goparquet.TimeToInt96(value.Convert(timeType).Interface().(time.Time)), nil
+			}, nil
+		}
+	}
+
+	if typ == ratType && isDecimal(elem) {
+		return func(value reflect.Value) (interface{}, error) {
+			v, err := encodeDecimal(elem, ratPointer(value))
+			if err != nil {
+				return nil, &FieldError{Path: path, Err: err}
+			}
+			return v, nil
+		}, nil
+	}
+
+	if elem.Type == nil {
+		return newGroupEncoder(typ, schemaDef, path)
+	}
+
+	unsupported := func() (encodeFunc, error) {
+		return nil, fieldErrorf(path, "can't write %s to %s column", typ, elem.GetType())
+	}
+
+	switch elem.GetType() {
+	case parquet.Type_BOOLEAN:
+		if typ.Kind() != reflect.Bool {
+			return unsupported()
+		}
+		return func(value reflect.Value) (interface{}, error) {
+			return value.Bool(), nil
+		}, nil
+	case parquet.Type_INT32:
+		switch typ.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return func(value reflect.Value) (interface{}, error) {
+				return int32(value.Int()), nil
+			}, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return func(value reflect.Value) (interface{}, error) {
+				return int32(value.Uint()), nil
+			}, nil
+		}
+		return unsupported()
+	case parquet.Type_INT64:
+		switch typ.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return func(value reflect.Value) (interface{}, error) {
+				return value.Int(), nil
+			}, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return func(value reflect.Value) (interface{}, error) {
+				return int64(value.Uint()), nil
+			}, nil
+		}
+		return unsupported()
+	case parquet.Type_INT96:
+		if !isByteSequence(typ) {
+			return unsupported()
+		}
+		return func(value reflect.Value) (interface{}, error) {
+			if value.Kind() == reflect.Slice && value.IsNil() {
+				return nil, nil
+			}
+			if value.Len() != 12 {
+				return nil, fieldErrorf(path, "field is of type INT96 but length is %d", value.Len())
+			}
+			var data [12]byte
+			for i := range data {
+				data[i] = byte(value.Index(i).Uint())
+			}
+			return data, nil
+		}, nil
+	case parquet.Type_FLOAT:
+		if typ.Kind() != reflect.Float32 && typ.Kind() != reflect.Float64 {
+			return unsupported()
+		}
+		return func(value reflect.Value) (interface{}, error) {
+			return float32(value.Float()), nil
+		}, nil
+	case parquet.Type_DOUBLE:
+		if typ.Kind() != reflect.Float32 && typ.Kind() != reflect.Float64 {
+			return unsupported()
+		}
+		return func(value reflect.Value) (interface{}, error) {
+			return value.Float(), nil
+		}, nil
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		if typ.Kind() == reflect.String {
+			return func(value reflect.Value) (interface{}, error) {
+				return []byte(value.String()), nil
+			}, nil
+		}
+		if !isByteSequence(typ) {
+			return unsupported()
+		}
+		isUUID := elem.LogicalType != nil && elem.GetLogicalType().IsSetUUID()
+		return func(value reflect.Value) (interface{}, error) {
+			var data []byte
+			switch value.Kind() {
+			case reflect.Slice:
+				if value.IsNil() {
+					return nil, nil
+				}
+				data = value.Bytes()
+			case reflect.Array:
+				data = make([]byte, value.Len())
+				for i := range data {
+					data[i] = byte(value.Index(i).Uint())
+				}
+			}
+			if isUUID && len(data) != 16 {
+				return nil, fieldErrorf(path, "field is annotated as UUID but length is %d", len(data))
+			}
+			return data, nil
+		}, nil
+	}
+
+	return unsupported()
+}
+
+func newTimeEncoder(elem *parquet.SchemaElement, path string) (encodeFunc, error) {
+	unit := elem.GetLogicalType().TIME.Unit
+	switch {
+	case unit.IsSetNANOS():
+		return func(value reflect.Value) (interface{}, error) {
+			return value.Convert(floorTimeType).Interface().(Time).Nanoseconds(), nil
+		}, nil
+	case unit.IsSetMICROS():
+		return func(value reflect.Value) (interface{}, error) {
+			return value.Convert(floorTimeType).Interface().(Time).Microseconds(), nil
+		}, nil
+	case unit.IsSetMILLIS():
+		return func(value reflect.Value) (interface{}, error) {
+			return value.Convert(floorTimeType).Interface().(Time).Milliseconds(), nil
+		}, nil
+	}
+	return nil, fieldErrorf(path, "invalid TIME unit")
+}
+
+func newTimestampEncoder(elem *parquet.SchemaElement, path string) (encodeFunc, error) {
+	var factor int64
+	unit := elem.GetLogicalType().TIMESTAMP.Unit
+	switch {
+	case unit.IsSetNANOS():
+		factor = 1
+	case unit.IsSetMICROS():
+		factor = 1000
+	case unit.IsSetMILLIS():
+		factor = 1000000
+	default:
+		return nil, fieldErrorf(path, "invalid TIMESTAMP unit")
+	}
+	return func(value reflect.Value) (interface{}, error) {
+		return value.Convert(timeType).Interface().(time.Time).UnixNano() / factor, nil
+	}, nil
+}
+
+func newGroupEncoder(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition, path string) (encodeFunc, error) {
+	elem := schemaDef.SchemaElement()
+
+	switch {
+	case elem.GetConvertedType() == parquet.ConvertedType_LIST:
+		if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array || isByteSequence(typ) {
+			return nil, fieldErrorf(path, "can't write %s to LIST", typ)
+		}
+		return newListEncoder(typ, schemaDef, path)
+	case elem.GetConvertedType() == parquet.ConvertedType_MAP:
+		if typ.Kind() != reflect.Map {
+			return nil, fieldErrorf(path, "can't write %s to MAP", typ)
+		}
+		return newMapEncoder(typ, schemaDef, path)
+	case typ.Kind() == reflect.Struct:
+		fields, err := newStructEncoder(typ, schemaDef, path)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) (interface{}, error) {
+			return encodeStruct(fields, value)
+		}, nil
+	}
+
+	return nil, fieldErrorf(path, "can't write %s to group", typ)
+}
+
+func newListEncoder(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition, path string) (encodeFunc, error) {
+	listName, elemName, elemSchemaDef, err := listElement(schemaDef)
+	if err != nil {
+		return nil, &FieldError{Path: path, Err: err}
+	}
+
+	encode, err := newEncoder(typ.Elem(), elemSchemaDef, joinPath(joinPath(path, listName), elemName))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(value reflect.Value) (interface{}, error) {
+		if value.Len() == 0 {
+			return nil, nil
+		}
+
+		elems := make([]map[string]interface{}, value.Len())
+		for i := range elems {
+			v, err := encode(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = map[string]interface{}{}
+			if v != nil {
+				elems[i][elemName] = v
+			}
+		}
+
+		return map[string]interface{}{listName: elems}, nil
+	}, nil
+}
+
+func newMapEncoder(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition, path string) (encodeFunc, error) {
+	keyValueSchemaDef := schemaDef.SubSchema("key_value")
+	keySchemaDef := keyValueSchemaDef.SubSchema("key")
+	if keySchemaDef == nil {
+		return nil, fieldErrorf(path, "annotated as MAP but group structure seems invalid")
+	}
+
+	keyValuePath := joinPath(path, "key_value")
+
+	encodeKey, err := newEncoder(typ.Key(), keySchemaDef, joinPath(keyValuePath, "key"))
+	if err != nil {
+		return nil, err
+	}
+
+	var encodeValue encodeFunc
+	if valueSchemaDef := keyValueSchemaDef.SubSchema("value"); valueSchemaDef != nil {
+		encodeValue, err = newEncoder(typ.Elem(), valueSchemaDef, joinPath(keyValuePath, "value"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func(value reflect.Value) (interface{}, error) {
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		keyValues := make([]map[string]interface{}, 0, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			keyValue := map[string]interface{}{}
+
+			k, err := encodeKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			if k != nil {
+				keyValue["key"] = k
+			}
+
+			if encodeValue != nil {
+				v, err := encodeValue(iter.Value())
+				if err != nil {
+					return nil, err
+				}
+				if v != nil {
+					keyValue["value"] = v
+				}
+			}
+
+			keyValues = append(keyValues, keyValue)
+		}
+
+		return map[string]interface{}{"key_value": keyValues}, nil
+	}, nil
+}
@@ -127,8 +127,16 @@ func (m *reflectMarshaller) decodeStruct(record interfaces.MarshalObject, value
 
 	numFields := typ.NumField()
 	for i := 0; i < numFields; i++ {
+		if skipField(typ.Field(i)) {
+			continue
+		}
+
 		fieldValue := value.Field(i)
 
+		if optionalField(typ.Field(i)) && fieldValue.IsZero() {
+			continue
+		}
+
 		fieldName := fieldNameFunc(typ.Field(i))
 
 		subSchemaDef := schemaDef.SubSchema(fieldName)
@@ -176,6 +184,10 @@ func (m *reflectMarshaller) decodeTimestampValue(elem *parquet.SchemaElement, fi
 	return nil
 }
 
+func (m *reflectMarshaller) decodeDecimalValue(elem *parquet.SchemaElement, field interfaces.MarshalElement, value reflect.Value) error {
+	return setDecimal(field, elem, ratPointer(value))
+}
+
 func (m *reflectMarshaller) decodeValue(field interfaces.MarshalElement, value reflect.Value, schemaDef *parquetschema.SchemaDefinition) error {
 	elem := schemaDef.SchemaElement()
 	if elem == nil {
@@ -211,6 +223,10 @@ func (m *reflectMarshaller) decodeValue(field interfaces.MarshalElement, value r
 		}
 	}
 
+	if value.Type() == ratType && isDecimal(elem) {
+		return m.decodeDecimalValue(elem, field, value)
+	}
+
 	if !elem.IsSetType() && !elem.IsSetConvertedType() && elem.GetNumChildren() > 0 && value.Kind() == reflect.Map {
 		group := field.Group()
 		iter := value.MapRange()
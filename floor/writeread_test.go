@@ -782,3 +782,80 @@ func TestReflectMarshallerPanicIssue13(t *testing.T) {
 	write("files/issue13_bool.parquet", struct{ Bar bool }{Bar: true})
 	write("files/issue13_byteslice.parquet", struct{ Bar []byte }{Bar: []byte{0xFF, 0x0A}})
 }
+
+func TestWriteReadWithTagOptions(t *testing.T) {
+	type record struct {
+		ID       int64     `parquet:"id,fieldid=1,encoding=delta"`
+		Name     string    `parquet:",optional,dict,compression=zstd"`
+		Created  time.Time `parquet:"created,logical=timestamp(micros)"`
+		Legacy   time.Time `parquet:"legacy,type=int96"`
+		Day      time.Time `parquet:"day,logical=date"`
+		Tags     []string  `parquet:"tags,encoding=delta"`
+		Internal string    `parquet:"-"`
+	}
+
+	created := time.Date(2022, 3, 4, 5, 6, 7, 8000, time.UTC)
+	records := []record{
+		{ID: 1, Name: "foo", Created: created, Legacy: created.Add(123), Day: time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC), Tags: []string{"a", "b"}},
+		{ID: 2, Created: created, Legacy: created, Day: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), Tags: []string{"c"}},
+	}
+
+	opts, err := autoschema.GenerateWriterOptions(record{})
+	require.NoError(t, err)
+
+	writeAll := func(t *testing.T, typed bool) []byte {
+		var buf bytes.Buffer
+		fw := goparquet.NewFileWriter(&buf, opts...)
+		if typed {
+			w, err := NewTypedWriter[record](fw)
+			require.NoError(t, err)
+			require.NoError(t, w.WriteBatch(records))
+			require.NoError(t, w.Close())
+		} else {
+			w := NewWriter(fw)
+			for _, rec := range records {
+				require.NoError(t, w.Write(rec))
+			}
+			require.NoError(t, w.Close())
+		}
+		return buf.Bytes()
+	}
+
+	for _, typed := range []bool{false, true} {
+		data := writeAll(t, typed)
+
+		fr, err := goparquet.NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		row, err := fr.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, created.UnixMicro(), row["created"])
+		require.NotContains(t, row, "internal")
+
+		row, err = fr.NextRow()
+		require.NoError(t, err)
+		require.NotContains(t, row, "name")
+
+		fr, err = goparquet.NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		r := NewReader(fr)
+
+		var result []record
+		for r.Next() {
+			var rec record
+			require.NoError(t, r.Scan(&rec))
+			result = append(result, rec)
+		}
+		require.NoError(t, r.Err())
+		require.Equal(t, records, result, "typed writer: %t", typed)
+
+		fr, err = goparquet.NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		tr, err := NewTypedReader[record](fr)
+		require.NoError(t, err)
+
+		result, err = tr.ReadBatch(len(records))
+		require.NoError(t, err)
+		require.Equal(t, records, result, "typed writer: %t", typed)
+	}
+}
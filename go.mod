@@ -1,15 +1,23 @@
 module github.com/fraugster/parquet-go
 
-go 1.13
+go 1.18
 
 require (
+	github.com/andybalholm/brotli v1.0.5
 	github.com/apache/thrift v0.16.0
 	github.com/araddon/dateparse v0.0.0-20210429162001-6b43995a97de
 	github.com/davecgh/go-spew v1.1.1
 	github.com/golang/snappy v0.0.4
-	github.com/kr/pretty v0.1.0 // indirect
+	github.com/klauspost/compress v1.15.15
+	github.com/pierrec/lz4/v4 v4.1.17
 	github.com/spf13/cobra v0.0.5
-	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/stretchr/testify v1.7.0
+)
+
+require (
+	github.com/kr/pretty v0.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/spf13/pflag v1.0.5 // indirect
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
@@ -257,6 +257,19 @@ func encodeValue(w io.Writer, enc valuesEncoder, all []interface{}) error {
 	return enc.Close()
 }
 
+// encodeInt32Values encodes all values using enc, like encodeValue.
+func encodeInt32Values(w io.Writer, enc *int32DeltaBPEncoder, all []int32) error {
+	if err := enc.init(w); err != nil {
+		return err
+	}
+
+	if err := enc.encodeInt32s(all); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
 // In PageV1 the rle stream for rep/def level has the size in stream , but in V2 the size is inside the header not the
 // stream
 func encodeLevelsV1(w io.Writer, max uint16, values *packedArray) error {
@@ -13,10 +13,18 @@ type pageReader interface {
 	read(r io.Reader, ph *parquet.PageHeader, codec parquet.CompressionCodec, validateCRC bool) error
 
 	readValues(size int) (values []interface{}, dLevel *packedArray, rLevel *packedArray, err error)
+	// readBatch reads values like readValues, but appends the values that are not null to the
+	// slice of batch matching the physical type typ instead of returning them.
+	readBatch(size int, typ parquet.Type, batch *ColumnBatch) (dLevel *packedArray, rLevel *packedArray, err error)
 
 	numValues() int32
 }
 
+// pageIterator is an internal interface to read the data pages of a column chunk one after another.
+type pageIterator interface {
+	nextPage() (pageReader, error)
+}
+
 // pageReader is an internal interface used only internally to read the pages
 type pageWriter interface {
 	init(col *Column, codec parquet.CompressionCodec) error
@@ -32,6 +40,37 @@ type valuesDecoder interface {
 	decodeValues([]interface{}) (int, error)
 }
 
+// The following interfaces are implemented by values decoders that can decode values directly
+// into a slice of their physical type, without storing every value in an interface{}.
+
+type booleanValuesDecoder interface {
+	decodeBooleans([]bool) (int, error)
+}
+
+type int32ValuesDecoder interface {
+	decodeInt32s([]int32) (int, error)
+}
+
+type int64ValuesDecoder interface {
+	decodeInt64s([]int64) (int, error)
+}
+
+type int96ValuesDecoder interface {
+	decodeInt96s([][12]byte) (int, error)
+}
+
+type floatValuesDecoder interface {
+	decodeFloats([]float32) (int, error)
+}
+
+type doubleValuesDecoder interface {
+	decodeDoubles([]float64) (int, error)
+}
+
+type byteArrayValuesDecoder interface {
+	decodeByteArrays([][]byte) (int, error)
+}
+
 type dictValuesDecoder interface {
 	valuesDecoder
 
@@ -45,6 +84,37 @@ type valuesEncoder interface {
 	io.Closer
 }
 
+// The following interfaces are implemented by values encoders that can encode a slice of values
+// of their physical type, without storing every value in an interface{}.
+
+type booleanValuesEncoder interface {
+	encodeBooleans([]bool) error
+}
+
+type int32ValuesEncoder interface {
+	encodeInt32s([]int32) error
+}
+
+type int64ValuesEncoder interface {
+	encodeInt64s([]int64) error
+}
+
+type int96ValuesEncoder interface {
+	encodeInt96s([][12]byte) error
+}
+
+type floatValuesEncoder interface {
+	encodeFloats([]float32) error
+}
+
+type doubleValuesEncoder interface {
+	encodeDoubles([]float64) error
+}
+
+type byteArrayValuesEncoder interface {
+	encodeByteArrays([][]byte) error
+}
+
 type dictValuesEncoder interface {
 	valuesEncoder
 
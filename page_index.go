@@ -0,0 +1,209 @@
+package goparquet
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// pageIndexBuilder collects the information about the data pages of a column chunk that is
+// required to create the column index and offset index of the column chunk.
+type pageIndexBuilder struct {
+	compare func(a, b []byte) int
+	// ordered is false if the page statistics are not computed in the sort order of compare, see
+	// newPageIndexBuilder.
+	ordered bool
+
+	locations []*parquet.PageLocation
+
+	nullPages  []bool
+	minValues  [][]byte
+	maxValues  [][]byte
+	nullCounts []int64
+
+	// if a page with values has no statistics, no column index can be created.
+	missingStats bool
+
+	firstRowIndex int64
+}
+
+func newPageIndexBuilder(elem *parquet.SchemaElement) *pageIndexBuilder {
+	// the statistics of unsigned integers and decimals are computed using signed integer and
+	// byte-wise comparison, and no column order is written, so their pages aren't ordered.
+	return &pageIndexBuilder{
+		compare: statsComparator(elem),
+		ordered: elem != nil && !isUnsignedInt(elem) && !isDecimal(elem),
+	}
+}
+
+func (b *pageIndexBuilder) addPage(page *dataPage, offset int64, compressedPageSize int) {
+	b.locations = append(b.locations, &parquet.PageLocation{
+		Offset:             offset,
+		CompressedPageSize: int32(compressedPageSize),
+		FirstRowIndex:      b.firstRowIndex,
+	})
+	b.firstRowIndex += page.numRows
+
+	nullPage := page.numValues == 0
+
+	var minValue, maxValue []byte
+	if page.stats != nil {
+		minValue, maxValue = page.stats.MinValue, page.stats.MaxValue
+	}
+
+	if nullPage {
+		// the spec requires empty min and max values for pages that only contain null values.
+		minValue, maxValue = []byte{}, []byte{}
+	} else if minValue == nil || maxValue == nil {
+		b.missingStats = true
+	}
+
+	b.nullPages = append(b.nullPages, nullPage)
+	b.minValues = append(b.minValues, minValue)
+	b.maxValues = append(b.maxValues, maxValue)
+	b.nullCounts = append(b.nullCounts, page.nullValues)
+}
+
+// columnIndex returns the column index for the column chunk. If no column index can be
+// created for the column chunk, nil is returned.
+func (b *pageIndexBuilder) columnIndex() *parquet.ColumnIndex {
+	if b.missingStats || b.compare == nil || len(b.nullPages) == 0 {
+		return nil
+	}
+
+	return &parquet.ColumnIndex{
+		NullPages:     b.nullPages,
+		MinValues:     b.minValues,
+		MaxValues:     b.maxValues,
+		BoundaryOrder: b.boundaryOrder(),
+		NullCounts:    b.nullCounts,
+	}
+}
+
+func (b *pageIndexBuilder) boundaryOrder() parquet.BoundaryOrder {
+	if !b.ordered {
+		return parquet.BoundaryOrder_UNORDERED
+	}
+
+	ascending, descending := true, true
+
+	prev := -1
+	for i := range b.nullPages {
+		if b.nullPages[i] {
+			continue
+		}
+
+		if prev >= 0 {
+			if b.compare(b.minValues[prev], b.minValues[i]) > 0 || b.compare(b.maxValues[prev], b.maxValues[i]) > 0 {
+				ascending = false
+			}
+			if b.compare(b.minValues[prev], b.minValues[i]) < 0 || b.compare(b.maxValues[prev], b.maxValues[i]) < 0 {
+				descending = false
+			}
+		}
+		prev = i
+	}
+
+	switch {
+	case ascending:
+		return parquet.BoundaryOrder_ASCENDING
+	case descending:
+		return parquet.BoundaryOrder_DESCENDING
+	default:
+		return parquet.BoundaryOrder_UNORDERED
+	}
+}
+
+func (b *pageIndexBuilder) offsetIndex() *parquet.OffsetIndex {
+	return &parquet.OffsetIndex{
+		PageLocations: b.locations,
+	}
+}
+
+// chunkIndexes holds the column index, offset index and bloom filter of a column chunk until
+// they are written to the file.
+type chunkIndexes struct {
+	columnIndex *parquet.ColumnIndex
+	offsetIndex *parquet.OffsetIndex
+	bloomFilter *bloomFilter
+	// cipher is used to encrypt the indexes and the column meta data, nil if the column chunk is not encrypted.
+	cipher *chunkCipher
+}
+
+// writePageIndexes writes all column indexes, followed by all offset indexes of all row groups,
+// and sets their offsets and lengths in the column chunks.
+func writePageIndexes(ctx context.Context, w writePos, rowGroups []*parquet.RowGroup, indexes [][]*chunkIndexes) error {
+	for i, rg := range rowGroups {
+		for j, chunk := range rg.Columns {
+			idx := indexes[i][j]
+			if idx == nil || idx.columnIndex == nil {
+				continue
+			}
+
+			pos := w.Pos()
+			if err := idx.cipher.writeThrift(ctx, idx.columnIndex, w, moduleColumnIndex, -1); err != nil {
+				return err
+			}
+			length := int32(w.Pos() - pos)
+
+			chunk.ColumnIndexOffset = &pos
+			chunk.ColumnIndexLength = &length
+		}
+	}
+
+	for i, rg := range rowGroups {
+		for j, chunk := range rg.Columns {
+			idx := indexes[i][j]
+			if idx == nil || idx.offsetIndex == nil {
+				continue
+			}
+
+			pos := w.Pos()
+			if err := idx.cipher.writeThrift(ctx, idx.offsetIndex, w, moduleOffsetIndex, -1); err != nil {
+				return err
+			}
+			length := int32(w.Pos() - pos)
+
+			chunk.OffsetIndexOffset = &pos
+			chunk.OffsetIndexLength = &length
+		}
+	}
+
+	return nil
+}
+
+func readColumnIndex(ctx context.Context, r io.ReadSeeker, chunk *parquet.ColumnChunk, cc *chunkCipher) (*parquet.ColumnIndex, error) {
+	if chunk.ColumnIndexOffset == nil || chunk.ColumnIndexLength == nil {
+		return nil, nil
+	}
+
+	if _, err := r.Seek(*chunk.ColumnIndexOffset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seek to column index failed: %w", err)
+	}
+
+	idx := &parquet.ColumnIndex{}
+	if err := cc.readThrift(ctx, idx, io.LimitReader(r, int64(*chunk.ColumnIndexLength)), moduleColumnIndex, -1); err != nil {
+		return nil, fmt.Errorf("read column index failed: %w", err)
+	}
+
+	return idx, nil
+}
+
+func readOffsetIndex(ctx context.Context, r io.ReadSeeker, chunk *parquet.ColumnChunk, cc *chunkCipher) (*parquet.OffsetIndex, error) {
+	if chunk.OffsetIndexOffset == nil || chunk.OffsetIndexLength == nil {
+		return nil, nil
+	}
+
+	if _, err := r.Seek(*chunk.OffsetIndexOffset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seek to offset index failed: %w", err)
+	}
+
+	idx := &parquet.OffsetIndex{}
+	if err := cc.readThrift(ctx, idx, io.LimitReader(r, int64(*chunk.OffsetIndexLength)), moduleOffsetIndex, -1); err != nil {
+		return nil, fmt.Errorf("read offset index failed: %w", err)
+	}
+
+	return idx, nil
+}
@@ -0,0 +1,216 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func writePageIndexTestFile(t *testing.T, opts ...FileWriterOption) []byte {
+	schema := `message test {
+		required int64 id;
+		optional int32 value;
+		required boolean flag;
+	}`
+
+	row := func(rg, i int) map[string]interface{} {
+		data := map[string]interface{}{
+			"id":   int64(rg*1000 + i),
+			"flag": i%2 == 0,
+		}
+		if i >= 500 {
+			data["value"] = int32(2000 - i)
+		}
+		return data
+	}
+
+	return writeTestFile(t, schema, 2, 1000, row, append([]FileWriterOption{WithMaxPageSize(256)}, opts...)...)
+}
+
+func TestWriteThenReadPageIndex(t *testing.T) {
+	data := writePageIndexTestFile(t)
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, 2, r.RowGroupCount())
+
+	for rg := 0; rg < r.RowGroupCount(); rg++ {
+		for _, path := range []ColumnPath{{"id"}, {"value"}, {"flag"}} {
+			offsetIdx, err := r.ReadOffsetIndex(rg, path)
+			require.NoError(t, err)
+			require.NotNil(t, offsetIdx)
+			require.NotEmpty(t, offsetIdx.PageLocations)
+
+			chunk, err := r.columnChunk(rg, path)
+			require.NoError(t, err)
+
+			require.Equal(t, int64(0), offsetIdx.PageLocations[0].FirstRowIndex)
+			for i, loc := range offsetIdx.PageLocations {
+				if i > 0 {
+					prev := offsetIdx.PageLocations[i-1]
+					require.Equal(t, prev.Offset+int64(prev.CompressedPageSize), loc.Offset)
+					require.True(t, loc.FirstRowIndex > prev.FirstRowIndex)
+				}
+				require.True(t, loc.Offset >= chunk.MetaData.DataPageOffset)
+			}
+			last := offsetIdx.PageLocations[len(offsetIdx.PageLocations)-1]
+			require.Equal(t, chunk.FileOffset+chunk.MetaData.TotalCompressedSize, last.Offset+int64(last.CompressedPageSize))
+
+			colIdx, err := r.ReadColumnIndex(rg, path)
+			require.NoError(t, err)
+
+			if path.Equal(ColumnPath{"flag"}) {
+				require.Nil(t, colIdx, "boolean columns have no page statistics")
+				continue
+			}
+
+			require.NotNil(t, colIdx)
+			require.Len(t, colIdx.NullPages, len(offsetIdx.PageLocations))
+			require.Len(t, colIdx.MinValues, len(offsetIdx.PageLocations))
+			require.Len(t, colIdx.MaxValues, len(offsetIdx.PageLocations))
+			require.Len(t, colIdx.NullCounts, len(offsetIdx.PageLocations))
+
+			var nullCount int64
+			for _, n := range colIdx.NullCounts {
+				nullCount += n
+			}
+
+			switch {
+			case path.Equal(ColumnPath{"id"}):
+				require.True(t, len(colIdx.NullPages) > 1)
+				require.Equal(t, parquet.BoundaryOrder_ASCENDING, colIdx.BoundaryOrder)
+				require.Equal(t, int64(0), nullCount)
+				require.Equal(t, int64(rg*1000), int64(binary.LittleEndian.Uint64(colIdx.MinValues[0])))
+				require.Equal(t, int64(rg*1000+999), int64(binary.LittleEndian.Uint64(colIdx.MaxValues[len(colIdx.MaxValues)-1])))
+			case path.Equal(ColumnPath{"value"}):
+				require.True(t, len(colIdx.NullPages) > 1)
+				require.Equal(t, parquet.BoundaryOrder_DESCENDING, colIdx.BoundaryOrder)
+				require.Equal(t, int64(500), nullCount)
+				require.Equal(t, int64(500), colIdx.NullCounts[0])
+				require.Equal(t, int32(1500), int32(binary.LittleEndian.Uint32(colIdx.MaxValues[0])))
+			}
+		}
+	}
+
+	for i := 0; i < 2000; i++ {
+		_, err := r.NextRow()
+		require.NoError(t, err)
+	}
+
+	_, err = r.ReadColumnIndex(2, ColumnPath{"id"})
+	require.Error(t, err)
+
+	_, err = r.ReadOffsetIndex(0, ColumnPath{"foo"})
+	require.Error(t, err)
+}
+
+func TestPageIndexFirstRowIndex(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	wr := NewFileWriter(&buf, WithSchemaDefinition(sd), WithMaxPageSize(100))
+	for i := 0; i < 100; i++ {
+		require.NoError(t, wr.AddData(map[string]interface{}{"id": int64(i)}))
+	}
+	require.NoError(t, wr.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	offsetIdx, err := r.ReadOffsetIndex(0, ColumnPath{"id"})
+	require.NoError(t, err)
+	colIdx, err := r.ReadColumnIndex(0, ColumnPath{"id"})
+	require.NoError(t, err)
+	require.True(t, len(offsetIdx.PageLocations) > 2)
+
+	// the id of every row is its index, so the first row of a page contains the page's minimum value.
+	for i, loc := range offsetIdx.PageLocations {
+		minValue := int64(binary.LittleEndian.Uint64(colIdx.MinValues[i]))
+		require.Equal(t, minValue, loc.FirstRowIndex, "page %d", i)
+		if i > 0 {
+			require.Equal(t, int64(binary.LittleEndian.Uint64(colIdx.MaxValues[i-1]))+1, loc.FirstRowIndex, "page %d", i)
+		}
+	}
+}
+
+func TestWriteWithoutPageIndex(t *testing.T) {
+	data := writePageIndexTestFile(t, WithPageIndex(false))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	for _, path := range []ColumnPath{{"id"}, {"value"}, {"flag"}} {
+		colIdx, err := r.ReadColumnIndex(0, path)
+		require.NoError(t, err)
+		require.Nil(t, colIdx)
+
+		offsetIdx, err := r.ReadOffsetIndex(0, path)
+		require.NoError(t, err)
+		require.Nil(t, offsetIdx)
+	}
+
+	for i := 0; i < 2000; i++ {
+		_, err := r.NextRow()
+		require.NoError(t, err)
+	}
+}
+
+func TestPageIndexBoundaryOrder(t *testing.T) {
+	elem := &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_BYTE_ARRAY)}
+
+	testData := []struct {
+		minValues []string
+		maxValues []string
+		nullPages []bool
+		expected  parquet.BoundaryOrder
+	}{
+		{[]string{"a", "c", "e"}, []string{"b", "d", "f"}, []bool{false, false, false}, parquet.BoundaryOrder_ASCENDING},
+		{[]string{"e", "c", "a"}, []string{"f", "d", "b"}, []bool{false, false, false}, parquet.BoundaryOrder_DESCENDING},
+		{[]string{"a", "e", "c"}, []string{"b", "f", "d"}, []bool{false, false, false}, parquet.BoundaryOrder_UNORDERED},
+		{[]string{"e", "", "a"}, []string{"f", "", "b"}, []bool{false, true, false}, parquet.BoundaryOrder_DESCENDING},
+		{[]string{"a", "a"}, []string{"z", "b"}, []bool{false, false}, parquet.BoundaryOrder_DESCENDING},
+	}
+
+	for idx, tt := range testData {
+		b := newPageIndexBuilder(elem)
+		for i := range tt.nullPages {
+			page := &dataPage{numRows: 1}
+			if tt.nullPages[i] {
+				page.nullValues = 1
+			} else {
+				page.values = []interface{}{[]byte(tt.minValues[i]), []byte(tt.maxValues[i])}
+				page.numValues = 2
+				page.stats = &parquet.Statistics{MinValue: []byte(tt.minValues[i]), MaxValue: []byte(tt.maxValues[i])}
+			}
+			b.addPage(page, int64(i*100), 100)
+		}
+
+		colIdx := b.columnIndex()
+		require.NotNil(t, colIdx, "%d", idx)
+		require.Equal(t, tt.expected, colIdx.BoundaryOrder, "%d", idx)
+	}
+
+	// the statistics of unsigned integers and decimals don't use their sort order.
+	for _, elem := range []*parquet.SchemaElement{
+		{Type: parquet.TypePtr(parquet.Type_INT32), ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_32)},
+		{Type: parquet.TypePtr(parquet.Type_BYTE_ARRAY), ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL)},
+	} {
+		b := newPageIndexBuilder(elem)
+		for i := 0; i < 2; i++ {
+			v := []byte{byte(i), 0, 0, 0}
+			b.addPage(&dataPage{numRows: 1, numValues: 1, stats: &parquet.Statistics{MinValue: v, MaxValue: v}}, int64(i*100), 100)
+		}
+
+		colIdx := b.columnIndex()
+		require.NotNil(t, colIdx)
+		require.Equal(t, parquet.BoundaryOrder_UNORDERED, colIdx.BoundaryOrder, "%s", elem.GetConvertedType())
+	}
+}
@@ -28,35 +28,62 @@ func (dp *dataPageReaderV1) numValues() int32 {
 }
 
 func (dp *dataPageReaderV1) readValues(size int) (values []interface{}, dLevel *packedArray, rLevel *packedArray, err error) {
+	size, notNull, dLevel, rLevel, err := dp.readLevels(size)
+	if err != nil || size == 0 {
+		return nil, nil, nil, err
+	}
+
+	val := make([]interface{}, notNull)
+
+	if notNull != 0 {
+		if n, err := dp.valuesDecoder.decodeValues(val); err != nil {
+			return nil, nil, nil, fmt.Errorf("read values from page failed, need %d value read %d: %w", notNull, n, err)
+		}
+	}
+	dp.position += size
+
+	return val, dLevel, rLevel, nil
+}
+
+func (dp *dataPageReaderV1) readBatch(size int, typ parquet.Type, batch *ColumnBatch) (dLevel *packedArray, rLevel *packedArray, err error) {
+	size, notNull, dLevel, rLevel, err := dp.readLevels(size)
+	if err != nil || size == 0 {
+		return nil, nil, err
+	}
+
+	if notNull != 0 {
+		if n, err := batch.decodeValues(dp.valuesDecoder, typ, notNull); err != nil {
+			return nil, nil, fmt.Errorf("read values from page failed, need %d value read %d: %w", notNull, n, err)
+		}
+	}
+	dp.position += size
+
+	return dLevel, rLevel, nil
+}
+
+// readLevels reads the repetition and definition levels of the next size values of the page, and
+// returns the number of values that are read, which is less than size at the end of the page, and
+// the number of values that are not null.
+func (dp *dataPageReaderV1) readLevels(size int) (int, int, *packedArray, *packedArray, error) {
 	if rem := int(dp.valuesCount) - dp.position; rem < size {
 		size = rem
 	}
 
 	if size == 0 {
-		return nil, nil, nil, nil
+		return 0, 0, nil, nil, nil
 	}
 
-	rLevel, _, err = decodePackedArray(dp.rDecoder, size)
+	rLevel, _, err := decodePackedArray(dp.rDecoder, size)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("read repetition levels failed: %w", err)
+		return 0, 0, nil, nil, fmt.Errorf("read repetition levels failed: %w", err)
 	}
 
-	var notNull int
-	dLevel, notNull, err = decodePackedArray(dp.dDecoder, size)
+	dLevel, notNull, err := decodePackedArray(dp.dDecoder, size)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("read definition levels failed: %w", err)
-	}
-
-	val := make([]interface{}, notNull)
-
-	if notNull != 0 {
-		if n, err := dp.valuesDecoder.decodeValues(val); err != nil {
-			return nil, nil, nil, fmt.Errorf("read values from page failed, need %d value read %d: %w", notNull, n, err)
-		}
+		return 0, 0, nil, nil, fmt.Errorf("read definition levels failed: %w", err)
 	}
-	dp.position += size
 
-	return val, dLevel, rLevel, nil
+	return size, notNull, dLevel, rLevel, nil
 }
 
 func (dp *dataPageReaderV1) init(dDecoder, rDecoder getLevelDecoder, values getValueDecoderFn) error {
@@ -183,7 +210,7 @@ func (dp *dataPageWriterV1) write(ctx context.Context, w io.Writer) (int, int, e
 		return 0, 0, err
 	}
 
-	err = encodeValue(dataBuf, encoder, dp.page.values)
+	err = encodePageValues(dataBuf, encoder, dp.page)
 	if err != nil {
 		return 0, 0, err
 	}
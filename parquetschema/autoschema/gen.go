@@ -3,10 +3,12 @@ package autoschema
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
-	"strings"
 	"time"
 
+	goparquet "github.com/fraugster/parquet-go"
 	"github.com/fraugster/parquet-go/parquet"
 	"github.com/fraugster/parquet-go/parquetschema"
 )
@@ -14,24 +16,92 @@ import (
 // GenerateSchema auto-generates a schema definition for a provided object's type
 // using reflection. The generated schema is meant to be compatible with
 // github.com/fraugster/parquet-go/floor's reflection-based marshalling/unmarshalling.
+//
+// The column name and options of a field can be set using the parquet struct tag,
+// e.g. `parquet:"name,optional,logical=timestamp(micros)"`. A field with the struct tag
+// `parquet:"-"` is skipped. The following options are supported:
+//
+//	optional               the column is optional even if the field isn't a pointer
+//	fieldid=N              the field ID of the column
+//	type=T                 the physical type of the column, e.g. int96 or fixed_len_byte_array(16)
+//	logical=L              the logical type of the column, e.g. timestamp(micros), date or string
+//	decimal(P,S)           a DECIMAL column with precision P and scale S, which can be combined with type=T
+//	encoding=E             the encoding of the column, e.g. delta or byte_stream_split
+//	dict                   use a dictionary for the column
+//	compression=C          the compression codec of the column, e.g. zstd
+//
+// The options encoding, dict and compression aren't part of the schema definition; use
+// GenerateWriterOptions to apply them to a goparquet.FileWriter.
 func GenerateSchema(obj interface{}) (*parquetschema.SchemaDefinition, error) {
+	sd, _, err := generate(obj)
+	return sd, err
+}
+
+// GenerateWriterOptions auto-generates a schema definition for a provided object's type
+// like GenerateSchema, and returns it as goparquet.WithSchemaDefinition option together
+// with options for the column encodings and compression codecs set in the struct tags.
+func GenerateWriterOptions(obj interface{}) ([]goparquet.FileWriterOption, error) {
+	sd, g, err := generate(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []goparquet.FileWriterOption{goparquet.WithSchemaDefinition(sd)}
+
+	for _, col := range g.columns {
+		if col.tag.encoding != "" || col.tag.dict {
+			enc, err := col.tag.columnEncoding(col.typ)
+			if err != nil {
+				return nil, fmt.Errorf("can't generate writer options: %w", err)
+			}
+			opts = append(opts, goparquet.WithColumnEncoding(col.path, enc, col.tag.dict))
+		}
+		if col.tag.compression != nil {
+			opts = append(opts, goparquet.WithColumnCompressionCodec(col.path, *col.tag.compression))
+		}
+	}
+
+	return opts, nil
+}
+
+func generate(obj interface{}) (*parquetschema.SchemaDefinition, *generator, error) {
+	g := &generator{}
+
 	valueObj := reflect.ValueOf(obj)
-	columns, err := generateSchema(valueObj.Type())
+	columns, err := g.generateSchema(valueObj.Type(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("can't generate schema: %w", err)
+		return nil, nil, fmt.Errorf("can't generate schema: %w", err)
 	}
 
-	return &parquetschema.SchemaDefinition{
+	sd := &parquetschema.SchemaDefinition{
 		RootColumn: &parquetschema.ColumnDefinition{
 			SchemaElement: &parquet.SchemaElement{
 				Name: "autogen_schema",
 			},
 			Children: columns,
 		},
-	}, nil
+	}
+
+	if err := sd.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("can't generate schema: %w", err)
+	}
+
+	return sd, g, nil
 }
 
-func generateSchema(objType reflect.Type) ([]*parquetschema.ColumnDefinition, error) {
+// generator generates the columns for a struct type and collects the columns that have
+// writer options set in their struct tags.
+type generator struct {
+	columns []columnOptions
+}
+
+type columnOptions struct {
+	path goparquet.ColumnPath
+	typ  parquet.Type
+	tag  *fieldTag
+}
+
+func (g *generator) generateSchema(objType reflect.Type, path []string) ([]*parquetschema.ColumnDefinition, error) {
 	if objType.Kind() == reflect.Ptr {
 		objType = objType.Elem()
 	}
@@ -44,20 +114,49 @@ func generateSchema(objType reflect.Type) ([]*parquetschema.ColumnDefinition, er
 
 	for i := 0; i < objType.NumField(); i++ {
 		fieldType := objType.Field(i)
-		fieldName := fieldNameToLower(fieldType)
 
-		column, err := generateField(fieldType.Type, fieldName)
+		tag, err := parseFieldTag(fieldType)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", fieldType.Name, err)
+		}
+		if tag.skip {
+			continue
+		}
+
+		fieldPath := append(append([]string(nil), path...), tag.name)
+
+		var column *parquetschema.ColumnDefinition
+		if tag.isDecimal {
+			column, err = generateDecimalField(fieldType.Type, tag.name, tag.precision, tag.scale)
+		} else {
+			column, err = g.generateField(fieldType.Type, tag.name, fieldPath)
+		}
 		if err != nil {
 			return nil, err
 		}
 
+		if err := tag.apply(column); err != nil {
+			return nil, err
+		}
+
+		if tag.encoding != "" || tag.dict || tag.compression != nil {
+			leaf := leafColumn(column)
+			if leaf.SchemaElement.Type == nil {
+				return nil, fmt.Errorf("field %s: encoding, dict and compression options are not supported for groups", tag.name)
+			}
+			if leaf != column {
+				fieldPath = append(fieldPath, column.Children[0].SchemaElement.Name, leaf.SchemaElement.Name)
+			}
+			g.columns = append(g.columns, columnOptions{path: fieldPath, typ: leaf.SchemaElement.GetType(), tag: tag})
+		}
+
 		columns = append(columns, column)
 	}
 
 	return columns, nil
 }
 
-func generateField(fieldType reflect.Type, fieldName string) (*parquetschema.ColumnDefinition, error) {
+func (g *generator) generateField(fieldType reflect.Type, fieldName string, path []string) (*parquetschema.ColumnDefinition, error) {
 	switch fieldType.Kind() {
 	case reflect.Bool:
 		return &parquetschema.ColumnDefinition{
@@ -246,11 +345,11 @@ func generateField(fieldType reflect.Type, fieldName string) (*parquetschema.Col
 	case reflect.Interface:
 		return nil, errors.New("unsupported type interface")
 	case reflect.Map:
-		keyType, err := generateField(fieldType.Key(), "key")
+		keyType, err := g.generateField(fieldType.Key(), "key", childPath(path, "key_value", "key"))
 		if err != nil {
 			return nil, err
 		}
-		valueType, err := generateField(fieldType.Elem(), "value")
+		valueType, err := g.generateField(fieldType.Elem(), "value", childPath(path, "key_value", "value"))
 		if err != nil {
 			return nil, err
 		}
@@ -278,7 +377,7 @@ func generateField(fieldType reflect.Type, fieldName string) (*parquetschema.Col
 			},
 		}, nil
 	case reflect.Ptr:
-		colDef, err := generateField(fieldType.Elem(), fieldName)
+		colDef, err := g.generateField(fieldType.Elem(), fieldName, path)
 		if err != nil {
 			return nil, err
 		}
@@ -309,33 +408,11 @@ func generateField(fieldType reflect.Type, fieldName string) (*parquetschema.Col
 				}, nil
 			}
 		}
-		elementType, err := generateField(fieldType.Elem(), "element")
+		elementType, err := g.generateField(fieldType.Elem(), "element", childPath(path, "list", "element"))
 		if err != nil {
 			return nil, err
 		}
-		repType := elementType.SchemaElement.RepetitionType
-		elementType.SchemaElement.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED)
-		return &parquetschema.ColumnDefinition{
-			SchemaElement: &parquet.SchemaElement{
-				Name:           fieldName,
-				RepetitionType: repType,
-				ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_LIST),
-				LogicalType: &parquet.LogicalType{
-					LIST: &parquet.ListType{},
-				},
-			},
-			Children: []*parquetschema.ColumnDefinition{
-				{
-					SchemaElement: &parquet.SchemaElement{
-						Name:           "list",
-						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
-					},
-					Children: []*parquetschema.ColumnDefinition{
-						elementType,
-					},
-				},
-			},
-		}, nil
+		return listColumn(fieldName, elementType), nil
 	case reflect.String:
 		return &parquetschema.ColumnDefinition{
 			SchemaElement: &parquet.SchemaElement{
@@ -350,6 +427,8 @@ func generateField(fieldType reflect.Type, fieldName string) (*parquetschema.Col
 		}, nil
 	case reflect.Struct:
 		switch {
+		case fieldType == ratType:
+			return nil, fmt.Errorf("field %s of type big.Rat needs a decimal(precision,scale) option", fieldName)
 		case fieldType.ConvertibleTo(reflect.TypeOf(time.Time{})):
 			return &parquetschema.ColumnDefinition{
 				SchemaElement: &parquet.SchemaElement{
@@ -366,8 +445,24 @@ func generateField(fieldType reflect.Type, fieldName string) (*parquetschema.Col
 					},
 				},
 			}, nil
+		case fieldType.PkgPath() == floorPkgPath && fieldType.Name() == "Time":
+			return &parquetschema.ColumnDefinition{
+				SchemaElement: &parquet.SchemaElement{
+					Type:           parquet.TypePtr(parquet.Type_INT64),
+					Name:           fieldName,
+					RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+					LogicalType: &parquet.LogicalType{
+						TIME: &parquet.TimeType{
+							IsAdjustedToUTC: true,
+							Unit: &parquet.TimeUnit{
+								NANOS: parquet.NewNanoSeconds(),
+							},
+						},
+					},
+				},
+			}, nil
 		default:
-			children, err := generateSchema(fieldType)
+			children, err := g.generateSchema(fieldType, path)
 			if err != nil {
 				return nil, err
 			}
@@ -386,13 +481,106 @@ func generateField(fieldType reflect.Type, fieldName string) (*parquetschema.Col
 	}
 }
 
-func fieldNameToLower(field reflect.StructField) string {
-	parquetStructTag, ok := field.Tag.Lookup("parquet")
-	if !ok {
-		return strings.ToLower(field.Name)
+func childPath(path []string, names ...string) []string {
+	return append(append([]string(nil), path...), names...)
+}
+
+var ratType = reflect.TypeOf(big.Rat{})
+
+// floorPkgPath is the import path of the package of floor.Time, which can't be imported here
+// as floor uses this package in its tests.
+const floorPkgPath = "github.com/fraugster/parquet-go/floor"
+
+// listColumn returns a LIST column with the element column elementType. The repetition type of the
+// element column becomes the repetition type of the LIST column.
+func listColumn(fieldName string, elementType *parquetschema.ColumnDefinition) *parquetschema.ColumnDefinition {
+	repType := elementType.SchemaElement.RepetitionType
+	elementType.SchemaElement.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED)
+	return &parquetschema.ColumnDefinition{
+		SchemaElement: &parquet.SchemaElement{
+			Name:           fieldName,
+			RepetitionType: repType,
+			ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_LIST),
+			LogicalType: &parquet.LogicalType{
+				LIST: &parquet.ListType{},
+			},
+		},
+		Children: []*parquetschema.ColumnDefinition{
+			{
+				SchemaElement: &parquet.SchemaElement{
+					Name:           "list",
+					RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+				},
+				Children: []*parquetschema.ColumnDefinition{
+					elementType,
+				},
+			},
+		},
+	}
+}
+
+// generateDecimalField generates a DECIMAL column for a big.Rat or integer type, or a LIST of
+// DECIMAL elements for a slice of them. The physical type is INT32 for a precision of up to 9,
+// INT64 for a precision of up to 18, and a FIXED_LEN_BYTE_ARRAY of the minimum length for the
+// precision otherwise.
+func generateDecimalField(fieldType reflect.Type, fieldName string, precision, scale int32) (*parquetschema.ColumnDefinition, error) {
+	if fieldType.Kind() == reflect.Slice {
+		elementType, err := generateDecimalField(fieldType.Elem(), "element", precision, scale)
+		if err != nil {
+			return nil, err
+		}
+		return listColumn(fieldName, elementType), nil
+	}
+
+	if fieldType.Kind() == reflect.Ptr {
+		colDef, err := generateDecimalField(fieldType.Elem(), fieldName, precision, scale)
+		if err != nil {
+			return nil, err
+		}
+		colDef.SchemaElement.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+		return colDef, nil
+	}
+
+	elem := &parquet.SchemaElement{
+		Name:           fieldName,
+		RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+		ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL),
+		Scale:          &scale,
+		Precision:      &precision,
+		LogicalType: &parquet.LogicalType{
+			DECIMAL: &parquet.DecimalType{
+				Scale:     scale,
+				Precision: precision,
+			},
+		},
 	}
 
-	parquetStructTagFields := strings.Split(parquetStructTag, ",")
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if precision > 18 || precision > 9 && fieldType.Bits() < 64 {
+			return nil, fmt.Errorf("field %s: precision %d is too large for type %s", fieldName, precision, fieldType)
+		}
+	case reflect.Struct:
+		if fieldType != ratType {
+			return nil, fmt.Errorf("field %s: type %s can't be a DECIMAL", fieldName, fieldType)
+		}
+	default:
+		return nil, fmt.Errorf("field %s: type %s can't be a DECIMAL", fieldName, fieldType)
+	}
+
+	switch {
+	case precision <= 9:
+		elem.Type = parquet.TypePtr(parquet.Type_INT32)
+	case precision <= 18:
+		elem.Type = parquet.TypePtr(parquet.Type_INT64)
+	default:
+		typeLen := int32(1)
+		for int32(math.Floor(math.Log10(math.Exp2(8*float64(typeLen)-1)-1))) < precision {
+			typeLen++
+		}
+		elem.Type = parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY)
+		elem.TypeLength = &typeLen
+	}
 
-	return strings.TrimSpace(parquetStructTagFields[0])
+	return &parquetschema.ColumnDefinition{SchemaElement: elem}, nil
 }
@@ -1,10 +1,15 @@
 package autoschema
 
 import (
+	"bytes"
+	"math/big"
 	"testing"
 	"time"
 	"unsafe"
 
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/floor"
+	"github.com/fraugster/parquet-go/parquet"
 	"github.com/stretchr/testify/require"
 )
 
@@ -204,6 +209,106 @@ func TestGenerateSchema(t *testing.T) {
 			})(nil),
 			ExpectedOutput: "message autogen_schema {\n  required int64 foo (TIMESTAMP(NANOS, true));\n}\n",
 		},
+		"floor.Time": {
+			Input: (*struct {
+				Foo floor.Time
+				Bar *floor.Time `parquet:"bar,logical=time(millis,false)"`
+			})(nil),
+			ExpectedOutput: "message autogen_schema {\n  required int64 foo (TIME(NANOS, true));\n  optional int32 bar (TIME(MILLIS, false));\n}\n",
+		},
+		"decimals": {
+			Input: (*struct {
+				Foo big.Rat  `parquet:"foo,decimal(9,2)"`
+				Bar *big.Rat `parquet:"bar, decimal(18, 4)"`
+				Baz big.Rat  `parquet:"baz,decimal(38,10)"`
+				Bla int64    `parquet:"bla,decimal(12,3)"`
+				Fob int32    `parquet:"fob,decimal(5,0)"`
+			})(nil),
+			ExpectedOutput: "message autogen_schema {\n  required int32 foo (DECIMAL(9, 2));\n  optional int64 bar (DECIMAL(18, 4));\n  required fixed_len_byte_array(16) baz (DECIMAL(38, 10));\n  required int64 bla (DECIMAL(12, 3));\n  required int32 fob (DECIMAL(5, 0));\n}\n",
+		},
+		"decimals with physical types": {
+			Input: (*struct {
+				Foo big.Rat   `parquet:"foo,decimal(18,2),type=fixed_len_byte_array(8)"`
+				Bar *big.Rat  `parquet:"bar,decimal(10,2),type=binary"`
+				Baz big.Rat   `parquet:"baz,type=int64,decimal(5,1)"`
+				Bla []big.Rat `parquet:"bla,optional,decimal(20,2),type=binary"`
+			})(nil),
+			ExpectedOutput: "message autogen_schema {\n  required fixed_len_byte_array(8) foo (DECIMAL(18, 2));\n  optional binary bar (DECIMAL(10, 2));\n  required int64 baz (DECIMAL(5, 1));\n  optional group bla (LIST) {\n    repeated group list {\n      required binary element (DECIMAL(20, 2));\n    }\n  }\n}\n",
+		},
+		"big.Rat without decimal option": {
+			Input: (*struct {
+				Foo big.Rat
+			})(nil),
+			ExpectErr: true,
+		},
+		"invalid decimal option": {
+			Input: (*struct {
+				Foo big.Rat `parquet:"foo,decimal(2,3)"`
+			})(nil),
+			ExpectErr: true,
+		},
+		"decimal precision too large": {
+			Input: (*struct {
+				Foo int32 `parquet:"foo,decimal(10,2)"`
+			})(nil),
+			ExpectErr: true,
+		},
+		"decimal of unsupported type": {
+			Input: (*struct {
+				Foo string `parquet:"foo,decimal(10,2)"`
+			})(nil),
+			ExpectErr: true,
+		},
+		"skipped fields": {
+			Input: (*struct {
+				Foo int64 `parquet:"-"`
+				Bar int64 `parquet:",optional"`
+			})(nil),
+			ExpectedOutput: "message autogen_schema {\n  optional int64 bar (INT(64, true));\n}\n",
+		},
+		"tag options": {
+			Input: (*struct {
+				Foo time.Time   `parquet:"foo,logical=timestamp(micros)"`
+				Bar time.Time   `parquet:"bar,type=int96"`
+				Baz int64       `parquet:"baz,fieldid=3,encoding=delta,dict,compression=zstd"`
+				Bla string      `parquet:"bla,optional,logical=json"`
+				Fob time.Time   `parquet:"fob,logical=date"`
+				Sub []time.Time `parquet:"sub,logical=time(millis,false)"`
+				Uid [16]byte    `parquet:"uid,logical=uuid"`
+				Dec int64       `parquet:"dec,logical=decimal(12,3)"`
+			})(nil),
+			ExpectedOutput: "message autogen_schema {\n  required int64 foo (TIMESTAMP(MICROS, true));\n  required int96 bar;\n  required int64 baz (INT(64, true)) = 3;\n  optional binary bla (JSON);\n  required int32 fob (DATE);\n  required group sub (LIST) {\n    repeated group list {\n      required int32 element (TIME(MILLIS, false));\n    }\n  }\n  required fixed_len_byte_array(16) uid (UUID);\n  required int64 dec (DECIMAL(12, 3));\n}\n",
+		},
+		"unknown option": {
+			Input: (*struct {
+				Foo int64 `parquet:"foo,bar"`
+			})(nil),
+			ExpectErr: true,
+		},
+		"unknown type": {
+			Input: (*struct {
+				Foo int64 `parquet:"foo,type=int128"`
+			})(nil),
+			ExpectErr: true,
+		},
+		"invalid logical type for type": {
+			Input: (*struct {
+				Foo int64 `parquet:"foo,type=double,logical=date"`
+			})(nil),
+			ExpectErr: true,
+		},
+		"logical type for group": {
+			Input: (*struct {
+				Foo struct{ Bar int64 } `parquet:"foo,logical=string"`
+			})(nil),
+			ExpectErr: true,
+		},
+		"unknown compression": {
+			Input: (*struct {
+				Foo int64 `parquet:"foo,compression=foo"`
+			})(nil),
+			ExpectErr: true,
+		},
 	}
 
 	for testName, testData := range tests {
@@ -218,3 +323,50 @@ func TestGenerateSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateWriterOptions(t *testing.T) {
+	type record struct {
+		ID     int64    `parquet:"id,encoding=delta"`
+		Name   string   `parquet:"name,dict,compression=zstd"`
+		Tags   []string `parquet:"tags,encoding=delta"`
+		Ignore string   `parquet:"-"`
+	}
+
+	opts, err := GenerateWriterOptions(record{})
+	require.NoError(t, err)
+	require.Len(t, opts, 5)
+
+	var buf bytes.Buffer
+	w := goparquet.NewFileWriter(&buf, append(opts, goparquet.WithCompressionCodec(parquet.CompressionCodec_SNAPPY))...)
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id":   int64(1),
+		"name": []byte("foo"),
+		"tags": map[string]interface{}{
+			"list": []map[string]interface{}{{"element": []byte("bar")}},
+		},
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	require.NoError(t, r.PreLoad())
+	chunks := r.CurrentRowGroup().Columns
+	require.Len(t, chunks, 3)
+	require.Contains(t, chunks[0].MetaData.Encodings, parquet.Encoding_DELTA_BINARY_PACKED)
+	require.Equal(t, parquet.CompressionCodec_SNAPPY, chunks[0].MetaData.Codec)
+	require.Contains(t, chunks[1].MetaData.Encodings, parquet.Encoding_RLE_DICTIONARY)
+	require.Equal(t, parquet.CompressionCodec_ZSTD, chunks[1].MetaData.Codec)
+	require.Equal(t, []string{"tags", "list", "element"}, chunks[2].MetaData.PathInSchema)
+	require.Contains(t, chunks[2].MetaData.Encodings, parquet.Encoding_DELTA_BYTE_ARRAY)
+
+	_, err = GenerateWriterOptions(struct {
+		Foo bool `parquet:"foo,encoding=delta"`
+	}{})
+	require.NoError(t, err)
+
+	_, err = GenerateWriterOptions(struct {
+		Foo bool `parquet:"foo,encoding=foo"`
+	}{})
+	require.Error(t, err)
+}
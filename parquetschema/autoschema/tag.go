@@ -0,0 +1,376 @@
+package autoschema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// fieldTag contains the options of the parquet struct tag of a struct field.
+type fieldTag struct {
+	name     string
+	skip     bool
+	optional bool
+	fieldID  *int32
+
+	// physical type and logical type overriding the ones derived from the Go type.
+	physicalType string
+	logicalType  string
+
+	isDecimal bool
+	precision int32
+	scale     int32
+
+	encoding    string
+	dict        bool
+	compression *parquet.CompressionCodec
+}
+
+// parseFieldTag parses the parquet struct tag of a struct field. The first element of the
+// tag is the column name; if it is empty or there is no struct tag, the lowercase field name
+// is used. A tag of "-" skips the field. The remaining elements are the options described
+// in GenerateSchema.
+func parseFieldTag(field reflect.StructField) (*fieldTag, error) {
+	tag := &fieldTag{name: strings.ToLower(field.Name)}
+
+	parquetStructTag, ok := field.Tag.Lookup("parquet")
+	if !ok {
+		return tag, nil
+	}
+
+	if parquetStructTag == "-" {
+		tag.skip = true
+		return tag, nil
+	}
+
+	parts := splitTag(parquetStructTag)
+	if parts[0] != "" {
+		tag.name = parts[0]
+	}
+
+	for _, opt := range parts[1:] {
+		key, value := opt, ""
+		if idx := strings.Index(opt, "="); idx >= 0 {
+			key, value = strings.TrimSpace(opt[:idx]), strings.TrimSpace(opt[idx+1:])
+		}
+
+		switch {
+		case key == "optional" && value == "":
+			tag.optional = true
+		case key == "dict" && value == "":
+			tag.dict = true
+		case strings.HasPrefix(key, "decimal(") && value == "":
+			if err := tag.parseDecimal(key); err != nil {
+				return nil, err
+			}
+		case key == "fieldid":
+			id, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid option %s: %w", opt, err)
+			}
+			fieldID := int32(id)
+			tag.fieldID = &fieldID
+		case key == "type":
+			tag.physicalType = strings.ToLower(value)
+		case key == "logical":
+			if strings.HasPrefix(strings.ToLower(value), "decimal(") {
+				if err := tag.parseDecimal(strings.ToLower(value)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			tag.logicalType = strings.ToLower(value)
+		case key == "encoding":
+			tag.encoding = strings.ToLower(value)
+		case key == "compression":
+			codec, err := parquet.CompressionCodecFromString(strings.ToUpper(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid option %s: %w", opt, err)
+			}
+			tag.compression = &codec
+		default:
+			return nil, fmt.Errorf("unknown option %s", opt)
+		}
+	}
+
+	return tag, nil
+}
+
+// splitTag splits a struct tag at commas that are not within parentheses.
+func splitTag(tag string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, c := range tag {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(tag[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(tag[start:]))
+}
+
+// splitArgs splits an option like timestamp(micros,true) into its name and arguments.
+func splitArgs(opt string) (name string, args []string, err error) {
+	idx := strings.Index(opt, "(")
+	if idx < 0 {
+		return opt, nil, nil
+	}
+	if !strings.HasSuffix(opt, ")") {
+		return "", nil, fmt.Errorf("invalid option %s, missing closing parenthesis", opt)
+	}
+
+	for _, arg := range strings.Split(opt[idx+1:len(opt)-1], ",") {
+		args = append(args, strings.TrimSpace(arg))
+	}
+	return opt[:idx], args, nil
+}
+
+func (tag *fieldTag) parseDecimal(opt string) error {
+	_, args, err := splitArgs(opt)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("invalid option %s, expected decimal(precision,scale)", opt)
+	}
+
+	p, err := strconv.ParseInt(args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid precision in option %s: %w", opt, err)
+	}
+	s, err := strconv.ParseInt(args[1], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid scale in option %s: %w", opt, err)
+	}
+	if p < 1 || s < 0 || s > p {
+		return fmt.Errorf("invalid option %s, needs to be 1 <= precision and 0 <= scale <= precision", opt)
+	}
+
+	tag.isDecimal, tag.precision, tag.scale = true, int32(p), int32(s)
+	return nil
+}
+
+// apply applies the options of the tag to the column generated for the field. The physical
+// and logical type are applied to the element of a LIST column.
+func (tag *fieldTag) apply(col *parquetschema.ColumnDefinition) error {
+	if tag.optional {
+		col.SchemaElement.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+	}
+	if tag.fieldID != nil {
+		col.SchemaElement.FieldID = tag.fieldID
+	}
+
+	if tag.physicalType == "" && tag.logicalType == "" {
+		return nil
+	}
+
+	elem := leafColumn(col).SchemaElement
+	if elem.Type == nil {
+		return fmt.Errorf("field %s: type and logical options are not supported for groups", tag.name)
+	}
+
+	if tag.physicalType != "" {
+		lt, ct := elem.LogicalType, elem.ConvertedType
+		if err := setPhysicalType(elem, tag.physicalType); err != nil {
+			return fmt.Errorf("field %s: %w", tag.name, err)
+		}
+		// the physical type of a DECIMAL can be set, e.g. to store it in a BYTE_ARRAY.
+		if tag.isDecimal {
+			elem.LogicalType, elem.ConvertedType = lt, ct
+		}
+	}
+
+	if tag.logicalType != "" {
+		if err := setLogicalType(elem, tag.logicalType, tag.physicalType == ""); err != nil {
+			return fmt.Errorf("field %s: %w", tag.name, err)
+		}
+	}
+
+	return nil
+}
+
+// leafColumn returns the element column of a LIST column, and col itself otherwise.
+func leafColumn(col *parquetschema.ColumnDefinition) *parquetschema.ColumnDefinition {
+	if col.SchemaElement.GetConvertedType() == parquet.ConvertedType_LIST && len(col.Children) == 1 && len(col.Children[0].Children) == 1 {
+		return col.Children[0].Children[0]
+	}
+	return col
+}
+
+// setPhysicalType sets the physical type of elem and removes the logical type and converted
+// type that were derived from the Go type.
+func setPhysicalType(elem *parquet.SchemaElement, typ string) error {
+	name, args, err := splitArgs(typ)
+	if err != nil {
+		return err
+	}
+
+	elem.LogicalType, elem.ConvertedType, elem.TypeLength = nil, nil, nil
+
+	switch name {
+	case "boolean":
+		elem.Type = parquet.TypePtr(parquet.Type_BOOLEAN)
+	case "int32":
+		elem.Type = parquet.TypePtr(parquet.Type_INT32)
+	case "int64":
+		elem.Type = parquet.TypePtr(parquet.Type_INT64)
+	case "int96":
+		elem.Type = parquet.TypePtr(parquet.Type_INT96)
+	case "float":
+		elem.Type = parquet.TypePtr(parquet.Type_FLOAT)
+	case "double":
+		elem.Type = parquet.TypePtr(parquet.Type_DOUBLE)
+	case "binary", "byte_array":
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+	case "fixed_len_byte_array":
+		if len(args) != 1 {
+			return fmt.Errorf("invalid type %s, expected fixed_len_byte_array(length)", typ)
+		}
+		n, err := strconv.ParseInt(args[0], 10, 32)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid length in type %s", typ)
+		}
+		typeLen := int32(n)
+		elem.Type = parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY)
+		elem.TypeLength = &typeLen
+	default:
+		return fmt.Errorf("unknown type %s", typ)
+	}
+
+	if args != nil && name != "fixed_len_byte_array" {
+		return fmt.Errorf("invalid type %s", typ)
+	}
+
+	return nil
+}
+
+// setLogicalType sets the logical type and converted type of elem. If setType is true,
+// the physical type is set to the type required by the logical type.
+func setLogicalType(elem *parquet.SchemaElement, lt string, setType bool) error {
+	name, args, err := splitArgs(lt)
+	if err != nil {
+		return err
+	}
+
+	var (
+		logicalType   = parquet.NewLogicalType()
+		convertedType *parquet.ConvertedType
+		physicalType  = parquet.Type_BYTE_ARRAY
+		typeLen       *int32
+	)
+
+	switch name {
+	case "string":
+		logicalType.STRING = parquet.NewStringType()
+		convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+	case "json":
+		logicalType.JSON = parquet.NewJsonType()
+		convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_JSON)
+	case "bson":
+		logicalType.BSON = parquet.NewBsonType()
+		convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_BSON)
+	case "enum":
+		logicalType.ENUM = parquet.NewEnumType()
+		convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_ENUM)
+	case "uuid":
+		logicalType.UUID = parquet.NewUUIDType()
+		physicalType = parquet.Type_FIXED_LEN_BYTE_ARRAY
+		n := int32(16)
+		typeLen = &n
+	case "date":
+		logicalType.DATE = parquet.NewDateType()
+		convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DATE)
+		physicalType = parquet.Type_INT32
+	case "time", "timestamp":
+		if len(args) < 1 || len(args) > 2 {
+			return fmt.Errorf("invalid logical type %s, expected %s(unit) or %s(unit,isAdjustedToUTC)", lt, name, name)
+		}
+
+		utc := true
+		if len(args) == 2 {
+			if utc, err = strconv.ParseBool(args[1]); err != nil {
+				return fmt.Errorf("invalid isAdjustedToUTC in logical type %s", lt)
+			}
+		}
+
+		unit := parquet.NewTimeUnit()
+		physicalType = parquet.Type_INT64
+		switch args[0] {
+		case "millis":
+			unit.MILLIS = parquet.NewMilliSeconds()
+		case "micros":
+			unit.MICROS = parquet.NewMicroSeconds()
+		case "nanos":
+			unit.NANOS = parquet.NewNanoSeconds()
+		default:
+			return fmt.Errorf("unknown unit in logical type %s", lt)
+		}
+
+		if name == "time" {
+			logicalType.TIME = &parquet.TimeType{IsAdjustedToUTC: utc, Unit: unit}
+			switch {
+			case unit.IsSetMILLIS():
+				physicalType = parquet.Type_INT32
+				convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MILLIS)
+			case unit.IsSetMICROS():
+				convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MICROS)
+			}
+		} else {
+			logicalType.TIMESTAMP = &parquet.TimestampType{IsAdjustedToUTC: utc, Unit: unit}
+			switch {
+			case unit.IsSetMILLIS():
+				convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS)
+			case unit.IsSetMICROS():
+				convertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS)
+			}
+		}
+		args = nil
+	default:
+		return fmt.Errorf("unknown logical type %s", lt)
+	}
+
+	if args != nil {
+		return fmt.Errorf("invalid logical type %s", lt)
+	}
+
+	elem.LogicalType, elem.ConvertedType = logicalType, convertedType
+	if setType {
+		elem.Type, elem.TypeLength = parquet.TypePtr(physicalType), typeLen
+	}
+
+	return nil
+}
+
+// columnEncoding returns the encoding of the encoding option for a column of type typ. The encoding
+// delta is DELTA_BINARY_PACKED for integer columns, and DELTA_BYTE_ARRAY otherwise.
+func (tag *fieldTag) columnEncoding(typ parquet.Type) (parquet.Encoding, error) {
+	switch tag.encoding {
+	case "":
+		return parquet.Encoding_PLAIN, nil
+	case "delta":
+		if typ == parquet.Type_INT32 || typ == parquet.Type_INT64 {
+			return parquet.Encoding_DELTA_BINARY_PACKED, nil
+		}
+		return parquet.Encoding_DELTA_BYTE_ARRAY, nil
+	}
+
+	enc, err := parquet.EncodingFromString(strings.ToUpper(tag.encoding))
+	if err != nil {
+		return 0, fmt.Errorf("field %s: unknown encoding %s", tag.name, tag.encoding)
+	}
+	return enc, nil
+}
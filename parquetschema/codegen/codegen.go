@@ -0,0 +1,421 @@
+// Package codegen generates Go struct types from parquet schema definitions. The generated
+// types can be read and written using the reflection-based marshalling and unmarshalling of
+// github.com/fraugster/parquet-go/floor. Their parquet struct tags contain the options that
+// github.com/fraugster/parquet-go/parquetschema/autoschema needs to generate the columns,
+// as far as they can be expressed in struct tags.
+//
+// Optional columns are mapped to pointers, except for LISTs, MAPs and byte slices which are
+// null if they are nil. LISTs are mapped to slices, MAPs to maps, and groups to nested struct
+// types. Columns with the DATE, TIMESTAMP logical type or of type INT96 are mapped to time.Time,
+// columns with the TIME logical type are mapped to floor.Time. DECIMAL columns are mapped to
+// big.Rat, with the physical type in the struct tag if autoschema wouldn't choose it for the
+// precision.
+//
+// Optionally, MarshalParquet and UnmarshalParquet methods are generated for all struct types,
+// so that no reflection is needed at runtime. GenerateMethods generates these methods for
+// existing struct types of a Go package instead.
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// Options configures the code generated by Generate.
+type Options struct {
+	// PackageName is the name of the package of the generated code. The default is main.
+	PackageName string
+
+	// TypeName is the name of the struct type generated for the root of the schema definition.
+	// The default is the name of the schema definition converted to an exported identifier.
+	// Nested struct types are named after their parent type and their field.
+	TypeName string
+
+	// Methods enables generating MarshalParquet and UnmarshalParquet methods for all struct types.
+	Methods bool
+}
+
+// Generate generates the source code of a Go file containing struct types for the schema
+// definition sd. The source code is formatted using gofmt.
+func Generate(sd *parquetschema.SchemaDefinition, opts Options) ([]byte, error) {
+	if sd == nil || sd.RootColumn == nil {
+		return nil, errors.New("schema definition is empty")
+	}
+
+	if opts.PackageName == "" {
+		opts.PackageName = "main"
+	}
+	if opts.TypeName == "" {
+		opts.TypeName = exportedName(sd.RootColumn.SchemaElement.GetName())
+	}
+
+	g := &generator{}
+	if _, err := g.structType(opts.TypeName, sd.RootColumn); err != nil {
+		return nil, err
+	}
+
+	return g.source(opts.PackageName, opts.Methods)
+}
+
+// generator collects the struct types generated from a schema definition or from Go source.
+type generator struct {
+	structs   []*structType
+	typeNames map[string]bool
+}
+
+func (g *generator) structType(name string, col *parquetschema.ColumnDefinition) (*structType, error) {
+	if g.typeNames == nil {
+		g.typeNames = map[string]bool{}
+	}
+
+	st := &structType{name: uniqueName(name, g.typeNames)}
+	g.structs = append(g.structs, st)
+
+	fieldNames := map[string]bool{}
+	for _, child := range col.Children {
+		f := &field{
+			name:   uniqueName(exportedName(child.SchemaElement.GetName()), fieldNames),
+			column: child.SchemaElement.GetName(),
+		}
+
+		typ, err := g.fieldType(st.name+f.name, child)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", f.column, err)
+		}
+		f.typ = typ
+		f.options = tagOptions(child, typ)
+
+		st.fields = append(st.fields, f)
+	}
+
+	return st, nil
+}
+
+// fieldType returns the Go type of the column col. Struct types for groups are named typeName.
+func (g *generator) fieldType(typeName string, col *parquetschema.ColumnDefinition) (*fieldType, error) {
+	if col.SchemaElement.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED {
+		return nil, errors.New("repeated columns outside of LIST and MAP groups are unsupported")
+	}
+
+	typ, err := g.valueType(typeName, col)
+	if err != nil {
+		return nil, err
+	}
+
+	if col.SchemaElement.GetRepetitionType() == parquet.FieldRepetitionType_OPTIONAL {
+		typ.optional = true
+		typ.pointer = typ.kind == kindPrimitive && typ.goType != "[]byte" || typ.kind == kindStruct
+	}
+
+	return typ, nil
+}
+
+func (g *generator) valueType(typeName string, col *parquetschema.ColumnDefinition) (*fieldType, error) {
+	elem := col.SchemaElement
+
+	if elem.Type == nil {
+		switch {
+		case elem.GetConvertedType() == parquet.ConvertedType_LIST || elem.LogicalType != nil && elem.GetLogicalType().IsSetLIST():
+			return g.listType(typeName, col)
+		case elem.GetConvertedType() == parquet.ConvertedType_MAP || elem.LogicalType != nil && elem.GetLogicalType().IsSetMAP():
+			return g.mapType(typeName, col)
+		}
+
+		st, err := g.structType(typeName, col)
+		if err != nil {
+			return nil, err
+		}
+		return &fieldType{kind: kindStruct, structType: st}, nil
+	}
+
+	goType, err := primitiveType(elem)
+	if err != nil {
+		return nil, err
+	}
+	return &fieldType{kind: kindPrimitive, goType: goType, elem: elem}, nil
+}
+
+func (g *generator) listType(typeName string, col *parquetschema.ColumnDefinition) (*fieldType, error) {
+	if len(col.Children) != 1 || len(col.Children[0].Children) != 1 || col.Children[0].SchemaElement.GetRepetitionType() != parquet.FieldRepetitionType_REPEATED {
+		return nil, errors.New("LIST group needs to contain a repeated group with a single element column")
+	}
+
+	listName, elemName := col.Children[0].SchemaElement.GetName(), col.Children[0].Children[0].SchemaElement.GetName()
+	if !(listName == "list" && elemName == "element") && !(listName == "bag" && elemName == "array_element") {
+		return nil, fmt.Errorf("unsupported LIST structure %s.%s", listName, elemName)
+	}
+
+	elemType, err := g.fieldType(typeName, col.Children[0].Children[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &fieldType{kind: kindList, elemType: elemType}, nil
+}
+
+func (g *generator) mapType(typeName string, col *parquetschema.ColumnDefinition) (*fieldType, error) {
+	kv := childColumn(col, "key_value")
+	if len(col.Children) != 1 || kv == nil || childColumn(kv, "key") == nil || childColumn(kv, "value") == nil {
+		return nil, errors.New("MAP group needs to contain a repeated group key_value with columns key and value")
+	}
+
+	keyCol, valueCol := childColumn(kv, "key"), childColumn(kv, "value")
+	if keyCol.SchemaElement.Type == nil || keyCol.SchemaElement.GetRepetitionType() != parquet.FieldRepetitionType_REQUIRED {
+		return nil, errors.New("MAP key needs to be a required primitive column")
+	}
+
+	keyType, err := g.valueType(typeName, keyCol)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(keyType.goType, "[]") || keyType.goType == "big.Rat" {
+		return nil, fmt.Errorf("MAP key of type %s is unsupported", keyType.goType)
+	}
+
+	valueType, err := g.fieldType(typeName, valueCol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fieldType{kind: kindMap, keyType: keyType, valueType: valueType}, nil
+}
+
+func childColumn(col *parquetschema.ColumnDefinition, name string) *parquetschema.ColumnDefinition {
+	for _, child := range col.Children {
+		if child.SchemaElement.GetName() == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// primitiveType returns the Go type of a primitive column, following the mapping of floor.
+func primitiveType(elem *parquet.SchemaElement) (string, error) {
+	if isDecimal(elem) {
+		switch elem.GetType() {
+		case parquet.Type_INT32, parquet.Type_INT64, parquet.Type_FIXED_LEN_BYTE_ARRAY, parquet.Type_BYTE_ARRAY:
+			return "big.Rat", nil
+		}
+		return "", fmt.Errorf("DECIMAL of type %s is unsupported", elem.GetType())
+	}
+
+	switch elem.GetType() {
+	case parquet.Type_BOOLEAN:
+		return "bool", nil
+	case parquet.Type_INT32:
+		switch {
+		case isDate(elem):
+			return "time.Time", nil
+		case isTime(elem):
+			return "floor.Time", nil
+		}
+		return intType(elem, 32), nil
+	case parquet.Type_INT64:
+		switch {
+		case isTimestamp(elem):
+			return "time.Time", nil
+		case isTime(elem):
+			return "floor.Time", nil
+		}
+		return intType(elem, 64), nil
+	case parquet.Type_INT96:
+		return "time.Time", nil
+	case parquet.Type_FLOAT:
+		return "float32", nil
+	case parquet.Type_DOUBLE:
+		return "float64", nil
+	case parquet.Type_BYTE_ARRAY:
+		if elem.ConvertedType != nil {
+			switch elem.GetConvertedType() {
+			case parquet.ConvertedType_UTF8, parquet.ConvertedType_ENUM, parquet.ConvertedType_JSON:
+				return "string", nil
+			}
+		}
+		if lt := elem.GetLogicalType(); lt != nil && (lt.IsSetSTRING() || lt.IsSetENUM() || lt.IsSetJSON()) {
+			return "string", nil
+		}
+		return "[]byte", nil
+	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		if elem.GetTypeLength() < 1 {
+			return "", fmt.Errorf("invalid length %d of FIXED_LEN_BYTE_ARRAY", elem.GetTypeLength())
+		}
+		return fmt.Sprintf("[%d]byte", elem.GetTypeLength()), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", elem.GetType())
+}
+
+// intType returns the Go integer type for an INT32 or INT64 column with the given bit width,
+// taking the INTEGER logical type or the INT_* and UINT_* converted types into account.
+func intType(elem *parquet.SchemaElement, bitWidth int) string {
+	signed := true
+	if lt := elem.GetLogicalType(); lt != nil && lt.IsSetINTEGER() {
+		bitWidth, signed = int(lt.INTEGER.GetBitWidth()), lt.INTEGER.GetIsSigned()
+	} else if elem.ConvertedType != nil {
+		switch elem.GetConvertedType() {
+		case parquet.ConvertedType_INT_8:
+			bitWidth = 8
+		case parquet.ConvertedType_INT_16:
+			bitWidth = 16
+		case parquet.ConvertedType_UINT_8:
+			bitWidth, signed = 8, false
+		case parquet.ConvertedType_UINT_16:
+			bitWidth, signed = 16, false
+		case parquet.ConvertedType_UINT_32:
+			bitWidth, signed = 32, false
+		case parquet.ConvertedType_UINT_64:
+			bitWidth, signed = 64, false
+		}
+	}
+
+	if signed {
+		return "int" + strconv.Itoa(bitWidth)
+	}
+	return "uint" + strconv.Itoa(bitWidth)
+}
+
+// tagOptions returns the options of the parquet struct tag for a field of type typ, so that
+// autoschema generates the column col for it. Options that can't be expressed in a struct tag
+// are omitted.
+func tagOptions(col *parquetschema.ColumnDefinition, typ *fieldType) []string {
+	var options []string
+
+	if typ.optional && !typ.pointer {
+		options = append(options, "optional")
+	}
+	if col.SchemaElement.FieldID != nil {
+		options = append(options, fmt.Sprintf("fieldid=%d", col.SchemaElement.GetFieldID()))
+	}
+
+	// the physical and logical type of a list are the ones of its element.
+	if typ.kind == kindList {
+		typ = typ.elemType
+	}
+	if typ.kind != kindPrimitive {
+		return options
+	}
+
+	elem := typ.elem
+	switch {
+	case elem.GetType() == parquet.Type_INT96:
+		options = append(options, "type=int96")
+	case isDate(elem):
+		options = append(options, "logical=date")
+	case isTimestamp(elem):
+		if unit, utc := timeUnit(elem); unit != "nanos" || !utc {
+			options = append(options, "logical="+timeLogicalType("timestamp", unit, utc))
+		}
+	case isDecimal(elem):
+		precision, scale := decimalParams(elem)
+		options = append(options, fmt.Sprintf("decimal(%d,%d)", precision, scale))
+		// autoschema uses INT32 up to a precision of 9, INT64 up to 18, and FIXED_LEN_BYTE_ARRAY
+		// otherwise, with a length that may differ from the one of the column.
+		switch t := elem.GetType(); {
+		case t == parquet.Type_INT32 && precision > 9, t == parquet.Type_INT64 && (precision <= 9 || precision > 18):
+			options = append(options, "type="+strings.ToLower(t.String()))
+		case t == parquet.Type_FIXED_LEN_BYTE_ARRAY:
+			options = append(options, fmt.Sprintf("type=fixed_len_byte_array(%d)", elem.GetTypeLength()))
+		case t == parquet.Type_BYTE_ARRAY:
+			options = append(options, "type=binary")
+		}
+	case elem.LogicalType != nil && elem.GetLogicalType().IsSetJSON() || elem.GetConvertedType() == parquet.ConvertedType_JSON:
+		options = append(options, "logical=json")
+	case elem.LogicalType != nil && elem.GetLogicalType().IsSetENUM() || elem.GetConvertedType() == parquet.ConvertedType_ENUM:
+		options = append(options, "logical=enum")
+	case elem.LogicalType != nil && elem.GetLogicalType().IsSetBSON() || elem.GetConvertedType() == parquet.ConvertedType_BSON:
+		options = append(options, "logical=bson")
+	case elem.LogicalType != nil && elem.GetLogicalType().IsSetUUID():
+		options = append(options, "logical=uuid")
+	}
+
+	return options
+}
+
+func timeLogicalType(name, unit string, utc bool) string {
+	if utc {
+		return name + "(" + unit + ")"
+	}
+	return name + "(" + unit + ",false)"
+}
+
+// source returns the formatted source code of the struct types, and their MarshalParquet and
+// UnmarshalParquet methods if methods is true.
+func (g *generator) source(packageName string, methods bool) ([]byte, error) {
+	w := &codeWriter{imports: map[string]bool{}}
+
+	for _, st := range g.structs {
+		w.printf("\ntype %s struct {\n", st.name)
+		for _, f := range st.fields {
+			w.useTypeImports(f.typ)
+			w.printf("%s %s %s\n", f.name, f.typ.expr(), f.tag())
+		}
+		w.printf("}\n")
+	}
+
+	if methods {
+		for _, st := range g.structs {
+			w.marshalMethod(st)
+			w.unmarshalMethod(st)
+		}
+	}
+
+	return w.source(packageName)
+}
+
+// source returns the formatted source code of a Go file of the package packageName that
+// contains the code written to w.
+func (w *codeWriter) source(packageName string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by parquet-go codegen. DO NOT EDIT.\n\npackage %s\n", packageName)
+	if len(w.imports) > 0 {
+		var paths []string
+		for imp := range w.imports {
+			paths = append(paths, imp)
+		}
+		sort.Strings(paths)
+
+		var stdImports, imports []string
+		for _, path := range paths {
+			if strings.Contains(path, ".") {
+				imports = append(imports, importSpecs[path])
+			} else {
+				stdImports = append(stdImports, importSpecs[path])
+			}
+		}
+
+		fmt.Fprintf(&buf, "\nimport (\n%s\n", strings.Join(stdImports, "\n"))
+		if len(stdImports) > 0 && len(imports) > 0 {
+			fmt.Fprintf(&buf, "\n")
+		}
+		fmt.Fprintf(&buf, "%s\n)\n", strings.Join(imports, "\n"))
+	}
+	buf.Write(w.buf.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code failed: %w", err)
+	}
+	return src, nil
+}
+
+// importSpecs contains the import specs of the packages that generated code can use.
+var importSpecs = map[string]string{
+	"bytes":                                 `"bytes"`,
+	"fmt":                                   `"fmt"`,
+	"math/big":                              `"math/big"`,
+	"reflect":                               `"reflect"`,
+	"testing":                               `"testing"`,
+	"time":                                  `"time"`,
+	"github.com/fraugster/parquet-go":       `goparquet "github.com/fraugster/parquet-go"`,
+	"github.com/fraugster/parquet-go/floor": `"github.com/fraugster/parquet-go/floor"`,
+	"github.com/fraugster/parquet-go/parquet":                  `"github.com/fraugster/parquet-go/parquet"`,
+	"github.com/fraugster/parquet-go/floor/interfaces":         `"github.com/fraugster/parquet-go/floor/interfaces"`,
+	"github.com/fraugster/parquet-go/parquetschema/autoschema": `"github.com/fraugster/parquet-go/parquetschema/autoschema"`,
+}
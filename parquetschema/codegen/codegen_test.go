@@ -0,0 +1,134 @@
+package codegen
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportedName(t *testing.T) {
+	testData := map[string]string{
+		"id":         "ID",
+		"user_id":    "UserID",
+		"userId":     "UserID",
+		"created-at": "CreatedAt",
+		"HTTPStatus": "HTTPStatus",
+		"url":        "URL",
+		"2fa":        "F2fa",
+		"_":          "F",
+	}
+
+	for name, expected := range testData {
+		require.Equal(t, expected, exportedName(name), name)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	testData := map[string]struct {
+		schema   string
+		opts     Options
+		expected string
+	}{
+		"simple": {
+			schema: `message test {
+				required int64 id;
+				optional binary name (STRING);
+			}`,
+			expected: "// Code generated by parquet-go codegen. DO NOT EDIT.\n\npackage main\n\ntype Test struct {\n\tID   int64   `parquet:\"id\"`\n\tName *string `parquet:\"name\"`\n}\n",
+		},
+		"type name and package": {
+			schema: `message test {
+				required int32 day (DATE);
+				required group inner {
+					required float value;
+				}
+			}`,
+			opts:     Options{PackageName: "foo", TypeName: "Bar"},
+			expected: "// Code generated by parquet-go codegen. DO NOT EDIT.\n\npackage foo\n\nimport (\n\t\"time\"\n)\n\ntype Bar struct {\n\tDay   time.Time `parquet:\"day,logical=date\"`\n\tInner BarInner  `parquet:\"inner\"`\n}\n\ntype BarInner struct {\n\tValue float32 `parquet:\"value\"`\n}\n",
+		},
+		"legacy list and duplicate names": {
+			schema: `message test {
+				required group emails (LIST) {
+					repeated group bag {
+						required binary array_element (STRING);
+					}
+				}
+				required int64 a_b;
+				required int64 a__b;
+				required int32 t (TIME(MILLIS, true));
+			}`,
+			expected: "// Code generated by parquet-go codegen. DO NOT EDIT.\n\npackage main\n\nimport (\n\t\"github.com/fraugster/parquet-go/floor\"\n)\n\ntype Test struct {\n\tEmails []string   `parquet:\"emails\"`\n\tAB     int64      `parquet:\"a_b\"`\n\tAB2    int64      `parquet:\"a__b\"`\n\tT      floor.Time `parquet:\"t\"`\n}\n",
+		},
+		"decimals": {
+			schema: `message test {
+				required int32 a (DECIMAL(9, 2));
+				required int64 b (DECIMAL(5, 2));
+				optional fixed_len_byte_array(8) c (DECIMAL(18, 2));
+				required binary d (DECIMAL(20, 0));
+			}`,
+			expected: "// Code generated by parquet-go codegen. DO NOT EDIT.\n\npackage main\n\nimport (\n\t\"math/big\"\n)\n\ntype Test struct {\n\tA big.Rat  `parquet:\"a,decimal(9,2)\"`\n\tB big.Rat  `parquet:\"b,decimal(5,2),type=int64\"`\n\tC *big.Rat `parquet:\"c,decimal(18,2),type=fixed_len_byte_array(8)\"`\n\tD big.Rat  `parquet:\"d,decimal(20,0),type=binary\"`\n}\n",
+		},
+	}
+
+	for name, tt := range testData {
+		t.Run(name, func(t *testing.T) {
+			sd, err := parquetschema.ParseSchemaDefinition(tt.schema)
+			require.NoError(t, err)
+
+			src, err := Generate(sd, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(src))
+		})
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	testData := map[string]string{
+		"repeated column": `message test {
+			repeated int64 ids;
+		}`,
+		"invalid list": `message test {
+			required group ids (LIST) {
+				repeated int64 id;
+			}
+		}`,
+		"group map key": `message test {
+			required group m (MAP) {
+				repeated group key_value (MAP_KEY_VALUE) {
+					required group key {
+						required int64 id;
+					}
+					required int64 value;
+				}
+			}
+		}`,
+	}
+
+	for name, schema := range testData {
+		t.Run(name, func(t *testing.T) {
+			sd, err := parquetschema.ParseSchemaDefinition(schema)
+			require.NoError(t, err)
+
+			_, err = Generate(sd, Options{})
+			require.Error(t, err)
+		})
+	}
+}
+
+// TestGenerateExample checks that the generated code in internal/example, which is tested against
+// the reflection-based marshalling and unmarshalling of floor, is up to date.
+func TestGenerateExample(t *testing.T) {
+	schema, err := os.ReadFile("internal/example/record.schema")
+	require.NoError(t, err)
+	sd, err := parquetschema.ParseSchemaDefinition(string(schema))
+	require.NoError(t, err)
+
+	src, err := Generate(sd, Options{PackageName: "example", Methods: true})
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("internal/example/record_gen.go")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(src))
+}
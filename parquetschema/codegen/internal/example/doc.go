@@ -0,0 +1,6 @@
+// Package example contains the code generated for record.schema. It is used to test that the
+// generated code compiles, and that the generated methods marshal and unmarshal the same data as
+// the reflection-based marshalling and unmarshalling of floor.
+package example
+
+//go:generate go run ../../../../cmd/parquet-tool gen-go --package example --methods --output record_gen.go record.schema
@@ -0,0 +1,438 @@
+// Code generated by parquet-go codegen. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/floor"
+	"github.com/fraugster/parquet-go/floor/interfaces"
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+type Record struct {
+	ID        int64             `parquet:"id,fieldid=1"`
+	Name      string            `parquet:"name"`
+	Score     *float64          `parquet:"score"`
+	Flag      bool              `parquet:"flag"`
+	Small     uint16            `parquet:"small"`
+	Tags      []*string         `parquet:"tags,optional"`
+	Attrs     map[string]int32  `parquet:"attrs,optional"`
+	Address   *RecordAddress    `parquet:"address"`
+	Addresses []RecordAddresses `parquet:"addresses"`
+	Day       time.Time         `parquet:"day,logical=date"`
+	Created   time.Time         `parquet:"created,logical=timestamp(micros)"`
+	Legacy    time.Time         `parquet:"legacy,type=int96"`
+	At        floor.Time        `parquet:"at"`
+	AtMs      floor.Time        `parquet:"at_ms"`
+	UUID      [16]byte          `parquet:"uuid,logical=uuid"`
+	Data      []byte            `parquet:"data,optional"`
+	Price     big.Rat           `parquet:"price,decimal(12,2)"`
+	Amount    big.Rat           `parquet:"amount,decimal(18,2),type=fixed_len_byte_array(8)"`
+	Total     *big.Rat          `parquet:"total,decimal(30,4),type=binary"`
+	Rates     []big.Rat         `parquet:"rates,optional,decimal(5,3)"`
+	Doc       string            `parquet:"doc,logical=json"`
+}
+
+type RecordAddress struct {
+	City string  `parquet:"city"`
+	Zip  *string `parquet:"zip"`
+}
+
+type RecordAddresses struct {
+	City string  `parquet:"city"`
+	Zip  *string `parquet:"zip"`
+}
+
+// MarshalParquet marshals r into obj.
+func (r *Record) MarshalParquet(obj interfaces.MarshalObject) error {
+	obj.AddField("id").SetInt64(r.ID)
+	obj.AddField("name").SetByteArray([]byte(r.Name))
+	if r.Score != nil {
+		obj.AddField("score").SetFloat64(*r.Score)
+	}
+	obj.AddField("flag").SetBool(r.Flag)
+	obj.AddField("small").SetInt32(int32(r.Small))
+	if len(r.Tags) > 0 {
+		list1 := obj.AddField("tags").List()
+		for _, v3 := range r.Tags {
+			elem2 := list1.Add()
+			if v3 != nil {
+				elem2.SetByteArray([]byte(*v3))
+			}
+		}
+	}
+	if r.Attrs != nil {
+		m4 := obj.AddField("attrs").Map()
+		for k6, v7 := range r.Attrs {
+			kv5 := m4.Add()
+			kv5.Key().SetByteArray([]byte(k6))
+			kv5.Value().SetInt32(v7)
+		}
+	}
+	if r.Address != nil {
+		if err := r.Address.MarshalParquet(obj.AddField("address").Group()); err != nil {
+			return err
+		}
+	}
+	if len(r.Addresses) > 0 {
+		list8 := obj.AddField("addresses").List()
+		for _, v10 := range r.Addresses {
+			elem9 := list8.Add()
+			if err := v10.MarshalParquet(elem9.Group()); err != nil {
+				return err
+			}
+		}
+	}
+	obj.AddField("day").SetInt32(int32(r.Day.Sub(time.Unix(0, 0).UTC()).Hours() / 24))
+	obj.AddField("created").SetInt64(r.Created.UnixNano() / 1000)
+	obj.AddField("legacy").SetInt96(goparquet.TimeToInt96(r.Legacy))
+	obj.AddField("at").SetInt64(r.At.Nanoseconds())
+	obj.AddField("at_ms").SetInt32(r.AtMs.Milliseconds())
+	obj.AddField("uuid").SetByteArray(append([]byte(nil), r.UUID[:]...))
+	if r.Data != nil {
+		obj.AddField("data").SetByteArray(r.Data)
+	}
+	if err := floor.MarshalDecimal(obj.AddField("price"), &r.Price, parquet.Type_INT64, 0, 12, 2); err != nil {
+		return err
+	}
+	if err := floor.MarshalDecimal(obj.AddField("amount"), &r.Amount, parquet.Type_FIXED_LEN_BYTE_ARRAY, 8, 18, 2); err != nil {
+		return err
+	}
+	if r.Total != nil {
+		if err := floor.MarshalDecimal(obj.AddField("total"), r.Total, parquet.Type_BYTE_ARRAY, 0, 30, 4); err != nil {
+			return err
+		}
+	}
+	if len(r.Rates) > 0 {
+		list11 := obj.AddField("rates").List()
+		for i14 := range r.Rates {
+			elem12 := list11.Add()
+			if err := floor.MarshalDecimal(elem12, &r.Rates[i14], parquet.Type_INT32, 0, 5, 3); err != nil {
+				return err
+			}
+		}
+	}
+	obj.AddField("doc").SetByteArray([]byte(r.Doc))
+	return nil
+}
+
+// UnmarshalParquet unmarshals obj into r.
+func (r *Record) UnmarshalParquet(obj interfaces.UnmarshalObject) error {
+	if field1 := obj.GetField("id"); field1.Error() == nil {
+		x2, err := field1.Int64()
+		if err != nil {
+			return fmt.Errorf("field id: %w", err)
+		}
+		r.ID = x2
+	} else {
+		return fmt.Errorf("field id: %w", field1.Error())
+	}
+	if field3 := obj.GetField("name"); field3.Error() == nil {
+		x4, err := field3.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field name: %w", err)
+		}
+		r.Name = string(x4)
+	} else {
+		return fmt.Errorf("field name: %w", field3.Error())
+	}
+	if field5 := obj.GetField("score"); field5.Error() == nil {
+		var p6 float64
+		x7, err := field5.Float64()
+		if err != nil {
+			return fmt.Errorf("field score: %w", err)
+		}
+		p6 = x7
+		r.Score = &p6
+	}
+	if field8 := obj.GetField("flag"); field8.Error() == nil {
+		x9, err := field8.Bool()
+		if err != nil {
+			return fmt.Errorf("field flag: %w", err)
+		}
+		r.Flag = x9
+	} else {
+		return fmt.Errorf("field flag: %w", field8.Error())
+	}
+	if field10 := obj.GetField("small"); field10.Error() == nil {
+		x11, err := field10.Int32()
+		if err != nil {
+			return fmt.Errorf("field small: %w", err)
+		}
+		r.Small = uint16(x11)
+	} else {
+		return fmt.Errorf("field small: %w", field10.Error())
+	}
+	if field12 := obj.GetField("tags"); field12.Error() == nil {
+		list13, err := field12.List()
+		if err != nil {
+			return fmt.Errorf("field tags: %w", err)
+		}
+		for list13.Next() {
+			elem14, err := list13.Value()
+			var v15 *string
+			if err == nil {
+				var p16 string
+				x17, err := elem14.ByteArray()
+				if err != nil {
+					return fmt.Errorf("field tags.element: %w", err)
+				}
+				p16 = string(x17)
+				v15 = &p16
+			}
+			r.Tags = append(r.Tags, v15)
+		}
+	}
+	if field18 := obj.GetField("attrs"); field18.Error() == nil {
+		m19, err := field18.Map()
+		if err != nil {
+			return fmt.Errorf("field attrs: %w", err)
+		}
+		r.Attrs = make(map[string]int32)
+		for m19.Next() {
+			key20, err := m19.Key()
+			var k22 string
+			if err != nil {
+				return fmt.Errorf("field attrs.key: %w", err)
+			}
+			x24, err := key20.ByteArray()
+			if err != nil {
+				return fmt.Errorf("field attrs.key: %w", err)
+			}
+			k22 = string(x24)
+			value21, err := m19.Value()
+			var v23 int32
+			if err != nil {
+				return fmt.Errorf("field attrs.value: %w", err)
+			}
+			x25, err := value21.Int32()
+			if err != nil {
+				return fmt.Errorf("field attrs.value: %w", err)
+			}
+			v23 = x25
+			r.Attrs[k22] = v23
+		}
+	}
+	if field26 := obj.GetField("address"); field26.Error() == nil {
+		var p27 RecordAddress
+		group28, err := field26.Group()
+		if err != nil {
+			return fmt.Errorf("field address: %w", err)
+		}
+		if err := p27.UnmarshalParquet(group28); err != nil {
+			return fmt.Errorf("field address: %w", err)
+		}
+		r.Address = &p27
+	}
+	if field29 := obj.GetField("addresses"); field29.Error() == nil {
+		list30, err := field29.List()
+		if err != nil {
+			return fmt.Errorf("field addresses: %w", err)
+		}
+		for list30.Next() {
+			elem31, err := list30.Value()
+			var v32 RecordAddresses
+			if err != nil {
+				return fmt.Errorf("field addresses.element: %w", err)
+			}
+			group33, err := elem31.Group()
+			if err != nil {
+				return fmt.Errorf("field addresses.element: %w", err)
+			}
+			if err := v32.UnmarshalParquet(group33); err != nil {
+				return fmt.Errorf("field addresses.element: %w", err)
+			}
+			r.Addresses = append(r.Addresses, v32)
+		}
+	} else {
+		return fmt.Errorf("field addresses: %w", field29.Error())
+	}
+	if field34 := obj.GetField("day"); field34.Error() == nil {
+		x35, err := field34.Int32()
+		if err != nil {
+			return fmt.Errorf("field day: %w", err)
+		}
+		r.Day = time.Unix(0, 0).UTC().Add(24 * time.Hour * time.Duration(x35))
+	} else {
+		return fmt.Errorf("field day: %w", field34.Error())
+	}
+	if field36 := obj.GetField("created"); field36.Error() == nil {
+		x37, err := field36.Int64()
+		if err != nil {
+			return fmt.Errorf("field created: %w", err)
+		}
+		r.Created = time.Unix(x37/1000000, 1000*(x37%1000000)).UTC()
+	} else {
+		return fmt.Errorf("field created: %w", field36.Error())
+	}
+	if field38 := obj.GetField("legacy"); field38.Error() == nil {
+		x39, err := field38.Int96()
+		if err != nil {
+			return fmt.Errorf("field legacy: %w", err)
+		}
+		r.Legacy = goparquet.Int96ToTime(x39).UTC()
+	} else {
+		return fmt.Errorf("field legacy: %w", field38.Error())
+	}
+	if field40 := obj.GetField("at"); field40.Error() == nil {
+		x41, err := field40.Int64()
+		if err != nil {
+			return fmt.Errorf("field at: %w", err)
+		}
+		r.At = floor.TimeFromNanoseconds(x41).UTC()
+	} else {
+		return fmt.Errorf("field at: %w", field40.Error())
+	}
+	if field42 := obj.GetField("at_ms"); field42.Error() == nil {
+		x43, err := field42.Int32()
+		if err != nil {
+			return fmt.Errorf("field at_ms: %w", err)
+		}
+		r.AtMs = floor.TimeFromMilliseconds(x43)
+	} else {
+		return fmt.Errorf("field at_ms: %w", field42.Error())
+	}
+	if field44 := obj.GetField("uuid"); field44.Error() == nil {
+		x45, err := field44.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field uuid: %w", err)
+		}
+		if len(x45) != 16 {
+			return fmt.Errorf("field uuid: expected 16 bytes, got %d", len(x45))
+		}
+		copy(r.UUID[:], x45)
+	} else {
+		return fmt.Errorf("field uuid: %w", field44.Error())
+	}
+	if field46 := obj.GetField("data"); field46.Error() == nil {
+		x47, err := field46.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field data: %w", err)
+		}
+		r.Data = x47
+	}
+	if field48 := obj.GetField("price"); field48.Error() == nil {
+		x49, err := floor.UnmarshalDecimal(field48, 2)
+		if err != nil {
+			return fmt.Errorf("field price: %w", err)
+		}
+		r.Price.Set(x49)
+	} else {
+		return fmt.Errorf("field price: %w", field48.Error())
+	}
+	if field50 := obj.GetField("amount"); field50.Error() == nil {
+		x51, err := floor.UnmarshalDecimal(field50, 2)
+		if err != nil {
+			return fmt.Errorf("field amount: %w", err)
+		}
+		r.Amount.Set(x51)
+	} else {
+		return fmt.Errorf("field amount: %w", field50.Error())
+	}
+	if field52 := obj.GetField("total"); field52.Error() == nil {
+		var p53 big.Rat
+		x54, err := floor.UnmarshalDecimal(field52, 4)
+		if err != nil {
+			return fmt.Errorf("field total: %w", err)
+		}
+		p53.Set(x54)
+		r.Total = &p53
+	}
+	if field55 := obj.GetField("rates"); field55.Error() == nil {
+		list56, err := field55.List()
+		if err != nil {
+			return fmt.Errorf("field rates: %w", err)
+		}
+		for list56.Next() {
+			elem57, err := list56.Value()
+			var v58 big.Rat
+			if err != nil {
+				return fmt.Errorf("field rates.element: %w", err)
+			}
+			x59, err := floor.UnmarshalDecimal(elem57, 3)
+			if err != nil {
+				return fmt.Errorf("field rates.element: %w", err)
+			}
+			v58.Set(x59)
+			r.Rates = append(r.Rates, v58)
+		}
+	}
+	if field60 := obj.GetField("doc"); field60.Error() == nil {
+		x61, err := field60.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field doc: %w", err)
+		}
+		r.Doc = string(x61)
+	} else {
+		return fmt.Errorf("field doc: %w", field60.Error())
+	}
+	return nil
+}
+
+// MarshalParquet marshals r into obj.
+func (r *RecordAddress) MarshalParquet(obj interfaces.MarshalObject) error {
+	obj.AddField("city").SetByteArray([]byte(r.City))
+	if r.Zip != nil {
+		obj.AddField("zip").SetByteArray([]byte(*r.Zip))
+	}
+	return nil
+}
+
+// UnmarshalParquet unmarshals obj into r.
+func (r *RecordAddress) UnmarshalParquet(obj interfaces.UnmarshalObject) error {
+	if field1 := obj.GetField("city"); field1.Error() == nil {
+		x2, err := field1.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field city: %w", err)
+		}
+		r.City = string(x2)
+	} else {
+		return fmt.Errorf("field city: %w", field1.Error())
+	}
+	if field3 := obj.GetField("zip"); field3.Error() == nil {
+		var p4 string
+		x5, err := field3.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field zip: %w", err)
+		}
+		p4 = string(x5)
+		r.Zip = &p4
+	}
+	return nil
+}
+
+// MarshalParquet marshals r into obj.
+func (r *RecordAddresses) MarshalParquet(obj interfaces.MarshalObject) error {
+	obj.AddField("city").SetByteArray([]byte(r.City))
+	if r.Zip != nil {
+		obj.AddField("zip").SetByteArray([]byte(*r.Zip))
+	}
+	return nil
+}
+
+// UnmarshalParquet unmarshals obj into r.
+func (r *RecordAddresses) UnmarshalParquet(obj interfaces.UnmarshalObject) error {
+	if field1 := obj.GetField("city"); field1.Error() == nil {
+		x2, err := field1.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field city: %w", err)
+		}
+		r.City = string(x2)
+	} else {
+		return fmt.Errorf("field city: %w", field1.Error())
+	}
+	if field3 := obj.GetField("zip"); field3.Error() == nil {
+		var p4 string
+		x5, err := field3.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field zip: %w", err)
+		}
+		p4 = string(x5)
+		r.Zip = &p4
+	}
+	return nil
+}
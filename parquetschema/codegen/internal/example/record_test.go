@@ -0,0 +1,148 @@
+package example
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/floor"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+// reflectRecord has the same fields as Record, but no methods, so floor uses reflection for it.
+type reflectRecord Record
+
+func testRecords() []Record {
+	score := 0.5
+	tag := "b"
+	zip := "10115"
+	created := time.Date(2022, 3, 4, 5, 6, 7, 8000, time.UTC)
+	total := big.NewRat(-123456789012345678, 10000)
+
+	return []Record{
+		{
+			ID:        1,
+			Name:      "first",
+			Score:     &score,
+			Flag:      true,
+			Small:     65535,
+			Tags:      []*string{&tag},
+			Attrs:     map[string]int32{"x": 1},
+			Address:   &RecordAddress{City: "Berlin", Zip: &zip},
+			Addresses: []RecordAddresses{{City: "Hamburg"}, {City: "Berlin", Zip: &zip}},
+			Day:       time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC),
+			Created:   created,
+			Legacy:    created.Add(123),
+			At:        floor.MustTime(floor.NewTime(12, 34, 56, 789)).UTC(),
+			AtMs:      floor.MustTime(floor.NewTime(1, 2, 3, 4000000)),
+			UUID:      [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			Data:      []byte{0, 1, 2},
+			Price:     *big.NewRat(12345, 100),
+			Amount:    *big.NewRat(999999999999999999, 100),
+			Total:     total,
+			Rates:     []big.Rat{*big.NewRat(1, 8), *big.NewRat(-99999, 1000)},
+			Doc:       `{"a":1}`,
+		},
+		{
+			ID:        2,
+			Name:      "second",
+			Addresses: []RecordAddresses{{City: "Munich"}},
+			Day:       time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC),
+			Created:   created.Add(-time.Hour),
+			Legacy:    created,
+			At:        floor.MustTime(floor.NewTime(0, 0, 0, 0)).UTC(),
+			AtMs:      floor.MustTime(floor.NewTime(23, 59, 59, 999000000)),
+			Price:     *big.NewRat(-1, 100),
+			Amount:    *big.NewRat(-999999999999999999, 100),
+			Doc:       "[]",
+		},
+	}
+}
+
+func readSchema(t *testing.T) *parquetschema.SchemaDefinition {
+	data, err := os.ReadFile("record.schema")
+	require.NoError(t, err)
+	sd, err := parquetschema.ParseSchemaDefinition(string(data))
+	require.NoError(t, err)
+	return sd
+}
+
+func write(t *testing.T, sd *parquetschema.SchemaDefinition, records []interface{}) []byte {
+	var buf bytes.Buffer
+	w := floor.NewWriter(goparquet.NewFileWriter(&buf, goparquet.WithSchemaDefinition(sd)))
+	for _, rec := range records {
+		require.NoError(t, w.Write(rec))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func readRows(t *testing.T, data []byte) []map[string]interface{} {
+	fr, err := goparquet.NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	var rows []map[string]interface{}
+	for {
+		row, err := fr.NextRow()
+		if err != nil {
+			break
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestMarshalParquet(t *testing.T) {
+	sd := readSchema(t)
+
+	var generated, reflected []interface{}
+	for _, rec := range testRecords() {
+		rec := rec
+		generated = append(generated, &rec)
+		reflected = append(reflected, (*reflectRecord)(&rec))
+	}
+
+	require.Equal(t, readRows(t, write(t, sd, reflected)), readRows(t, write(t, sd, generated)))
+}
+
+func TestUnmarshalParquet(t *testing.T) {
+	sd := readSchema(t)
+	records := testRecords()
+
+	var objs []interface{}
+	for i := range records {
+		objs = append(objs, (*reflectRecord)(&records[i]))
+	}
+	data := write(t, sd, objs)
+
+	fr, err := goparquet.NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	r := floor.NewReader(fr)
+
+	var generated []Record
+	for r.Next() {
+		var rec Record
+		require.NoError(t, r.Scan(&rec))
+		generated = append(generated, rec)
+	}
+	require.NoError(t, r.Err())
+
+	fr, err = goparquet.NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	r = floor.NewReader(fr)
+
+	var reflected []Record
+	for r.Next() {
+		var rec reflectRecord
+		require.NoError(t, r.Scan(&rec))
+		reflected = append(reflected, Record(rec))
+	}
+	require.NoError(t, r.Err())
+
+	require.Equal(t, records, generated)
+	require.Equal(t, reflected, generated)
+}
@@ -0,0 +1,7 @@
+// Package tagged contains struct types with parquet struct tags and the methods generated for
+// them by cmd/parquetgen. It is used to test that the generated code compiles, and that the
+// generated methods marshal and unmarshal the same data as the reflection-based marshalling and
+// unmarshalling of floor.
+package tagged
+
+//go:generate go run ../../../../cmd/parquetgen -type Order
@@ -0,0 +1,69 @@
+package tagged
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/fraugster/parquet-go/floor"
+)
+
+// Status is the status of an order.
+type Status int32
+
+// Currency is an ISO 4217 currency code.
+type Currency string
+
+// Order is an order with nested groups, lists, maps and columns with logical types.
+type Order struct {
+	ID       int64    `parquet:"id,fieldid=1"`
+	Customer string   `parquet:"customer"`
+	Status   Status   `parquet:"status"`
+	Currency Currency `parquet:"currency,logical=enum"`
+	Priority int8
+	Quantity uint16   `parquet:"quantity"`
+	Discount float32  `parquet:"discount,optional"`
+	Total    float64  `parquet:"total"`
+	Paid     bool     `parquet:"paid"`
+	Note     *string  `parquet:"note"`
+	Price    int64    `parquet:"price,decimal(12,2)"`
+	Amount   big.Rat  `parquet:"amount,decimal(20,4)"`
+	Fee      *big.Rat `parquet:"fee,decimal(9,2),type=binary"`
+	Metadata string   `parquet:"metadata,logical=json"`
+	Token    [16]byte `parquet:"token,logical=uuid"`
+	Raw      []byte   `parquet:"raw"`
+
+	Created   time.Time  `parquet:"created,logical=timestamp(micros)"`
+	Shipped   *time.Time `parquet:"shipped,logical=timestamp(millis,false)"`
+	Day       time.Time  `parquet:"day,logical=date"`
+	Legacy    time.Time  `parquet:"legacy,type=int96"`
+	Updated   time.Time  `parquet:"updated"`
+	Delivery  floor.Time `parquet:"delivery,logical=time(millis,false)"`
+	Cutoff    floor.Time `parquet:"cutoff"`
+	CutoffUTC floor.Time `parquet:"cutoff_utc,logical=time(micros)"`
+
+	Address   Address            `parquet:"address"`
+	Billing   *Address           `parquet:"billing"`
+	Items     []Item             `parquet:"items"`
+	Tags      []string           `parquet:"tags"`
+	Scores    []*int32           `parquet:"scores"`
+	Dates     []time.Time        `parquet:"dates,logical=date"`
+	Rates     []big.Rat          `parquet:"rates,decimal(5,3)"`
+	Attrs     map[string]int64   `parquet:"attrs"`
+	Shipments map[int32]*Address `parquet:"shipments"`
+
+	cache []byte `parquet:"-"`
+}
+
+// Address is a postal address.
+type Address struct {
+	Street string  `parquet:"street"`
+	Zip    *string `parquet:"zip"`
+}
+
+// Item is an item of an order.
+type Item struct {
+	SKU      string            `parquet:"sku"`
+	Quantity int               `parquet:"quantity"`
+	Options  map[string]string `parquet:"options"`
+	Parts    [][]byte          `parquet:"parts"`
+}
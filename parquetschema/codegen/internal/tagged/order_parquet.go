@@ -0,0 +1,666 @@
+// Code generated by parquet-go codegen. DO NOT EDIT.
+
+package tagged
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/floor"
+	"github.com/fraugster/parquet-go/floor/interfaces"
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// MarshalParquet marshals r into obj.
+func (r *Order) MarshalParquet(obj interfaces.MarshalObject) error {
+	obj.AddField("id").SetInt64(r.ID)
+	obj.AddField("customer").SetByteArray([]byte(r.Customer))
+	obj.AddField("status").SetInt32(int32(r.Status))
+	obj.AddField("currency").SetByteArray([]byte(r.Currency))
+	obj.AddField("priority").SetInt32(int32(r.Priority))
+	obj.AddField("quantity").SetInt32(int32(r.Quantity))
+	if r.Discount != 0 {
+		obj.AddField("discount").SetFloat32(r.Discount)
+	}
+	obj.AddField("total").SetFloat64(r.Total)
+	obj.AddField("paid").SetBool(r.Paid)
+	if r.Note != nil {
+		obj.AddField("note").SetByteArray([]byte(*r.Note))
+	}
+	obj.AddField("price").SetInt64(r.Price)
+	if err := floor.MarshalDecimal(obj.AddField("amount"), &r.Amount, parquet.Type_FIXED_LEN_BYTE_ARRAY, 9, 20, 4); err != nil {
+		return err
+	}
+	if r.Fee != nil {
+		if err := floor.MarshalDecimal(obj.AddField("fee"), r.Fee, parquet.Type_BYTE_ARRAY, 0, 9, 2); err != nil {
+			return err
+		}
+	}
+	obj.AddField("metadata").SetByteArray([]byte(r.Metadata))
+	obj.AddField("token").SetByteArray(append([]byte(nil), r.Token[:]...))
+	obj.AddField("raw").SetByteArray(r.Raw)
+	obj.AddField("created").SetInt64(r.Created.UnixNano() / 1000)
+	if r.Shipped != nil {
+		obj.AddField("shipped").SetInt64((*r.Shipped).UnixNano() / 1000000)
+	}
+	obj.AddField("day").SetInt32(int32(r.Day.Sub(time.Unix(0, 0).UTC()).Hours() / 24))
+	obj.AddField("legacy").SetInt96(goparquet.TimeToInt96(r.Legacy))
+	obj.AddField("updated").SetInt64(r.Updated.UnixNano())
+	obj.AddField("delivery").SetInt32(r.Delivery.Milliseconds())
+	obj.AddField("cutoff").SetInt64(r.Cutoff.Nanoseconds())
+	obj.AddField("cutoff_utc").SetInt64(r.CutoffUTC.Microseconds())
+	if err := r.Address.MarshalParquet(obj.AddField("address").Group()); err != nil {
+		return err
+	}
+	if r.Billing != nil {
+		if err := r.Billing.MarshalParquet(obj.AddField("billing").Group()); err != nil {
+			return err
+		}
+	}
+	if len(r.Items) > 0 {
+		list1 := obj.AddField("items").List()
+		for _, v3 := range r.Items {
+			elem2 := list1.Add()
+			if err := v3.MarshalParquet(elem2.Group()); err != nil {
+				return err
+			}
+		}
+	}
+	if len(r.Tags) > 0 {
+		list4 := obj.AddField("tags").List()
+		for _, v6 := range r.Tags {
+			elem5 := list4.Add()
+			elem5.SetByteArray([]byte(v6))
+		}
+	}
+	if len(r.Scores) > 0 {
+		list7 := obj.AddField("scores").List()
+		for _, v9 := range r.Scores {
+			elem8 := list7.Add()
+			if v9 != nil {
+				elem8.SetInt32(*v9)
+			}
+		}
+	}
+	if len(r.Dates) > 0 {
+		list10 := obj.AddField("dates").List()
+		for _, v12 := range r.Dates {
+			elem11 := list10.Add()
+			elem11.SetInt32(int32(v12.Sub(time.Unix(0, 0).UTC()).Hours() / 24))
+		}
+	}
+	if len(r.Rates) > 0 {
+		list13 := obj.AddField("rates").List()
+		for i16 := range r.Rates {
+			elem14 := list13.Add()
+			if err := floor.MarshalDecimal(elem14, &r.Rates[i16], parquet.Type_INT32, 0, 5, 3); err != nil {
+				return err
+			}
+		}
+	}
+	if r.Attrs != nil {
+		m17 := obj.AddField("attrs").Map()
+		for k19, v20 := range r.Attrs {
+			kv18 := m17.Add()
+			kv18.Key().SetByteArray([]byte(k19))
+			kv18.Value().SetInt64(v20)
+		}
+	}
+	if r.Shipments != nil {
+		m21 := obj.AddField("shipments").Map()
+		for k23, v24 := range r.Shipments {
+			kv22 := m21.Add()
+			kv22.Key().SetInt32(k23)
+			if v24 != nil {
+				if err := v24.MarshalParquet(kv22.Value().Group()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// UnmarshalParquet unmarshals obj into r.
+func (r *Order) UnmarshalParquet(obj interfaces.UnmarshalObject) error {
+	if field1 := obj.GetField("id"); field1.Error() == nil {
+		x2, err := field1.Int64()
+		if err != nil {
+			return fmt.Errorf("field id: %w", err)
+		}
+		r.ID = x2
+	} else {
+		return fmt.Errorf("field id: %w", field1.Error())
+	}
+	if field3 := obj.GetField("customer"); field3.Error() == nil {
+		x4, err := field3.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field customer: %w", err)
+		}
+		r.Customer = string(x4)
+	} else {
+		return fmt.Errorf("field customer: %w", field3.Error())
+	}
+	if field5 := obj.GetField("status"); field5.Error() == nil {
+		x6, err := field5.Int32()
+		if err != nil {
+			return fmt.Errorf("field status: %w", err)
+		}
+		r.Status = Status(x6)
+	} else {
+		return fmt.Errorf("field status: %w", field5.Error())
+	}
+	if field7 := obj.GetField("currency"); field7.Error() == nil {
+		x8, err := field7.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field currency: %w", err)
+		}
+		r.Currency = Currency(x8)
+	} else {
+		return fmt.Errorf("field currency: %w", field7.Error())
+	}
+	if field9 := obj.GetField("priority"); field9.Error() == nil {
+		x10, err := field9.Int32()
+		if err != nil {
+			return fmt.Errorf("field priority: %w", err)
+		}
+		r.Priority = int8(x10)
+	} else {
+		return fmt.Errorf("field priority: %w", field9.Error())
+	}
+	if field11 := obj.GetField("quantity"); field11.Error() == nil {
+		x12, err := field11.Int32()
+		if err != nil {
+			return fmt.Errorf("field quantity: %w", err)
+		}
+		r.Quantity = uint16(x12)
+	} else {
+		return fmt.Errorf("field quantity: %w", field11.Error())
+	}
+	if field13 := obj.GetField("discount"); field13.Error() == nil {
+		x14, err := field13.Float32()
+		if err != nil {
+			return fmt.Errorf("field discount: %w", err)
+		}
+		r.Discount = x14
+	}
+	if field15 := obj.GetField("total"); field15.Error() == nil {
+		x16, err := field15.Float64()
+		if err != nil {
+			return fmt.Errorf("field total: %w", err)
+		}
+		r.Total = x16
+	} else {
+		return fmt.Errorf("field total: %w", field15.Error())
+	}
+	if field17 := obj.GetField("paid"); field17.Error() == nil {
+		x18, err := field17.Bool()
+		if err != nil {
+			return fmt.Errorf("field paid: %w", err)
+		}
+		r.Paid = x18
+	} else {
+		return fmt.Errorf("field paid: %w", field17.Error())
+	}
+	if field19 := obj.GetField("note"); field19.Error() == nil {
+		var p20 string
+		x21, err := field19.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field note: %w", err)
+		}
+		p20 = string(x21)
+		r.Note = &p20
+	}
+	if field22 := obj.GetField("price"); field22.Error() == nil {
+		x23, err := field22.Int64()
+		if err != nil {
+			return fmt.Errorf("field price: %w", err)
+		}
+		r.Price = x23
+	} else {
+		return fmt.Errorf("field price: %w", field22.Error())
+	}
+	if field24 := obj.GetField("amount"); field24.Error() == nil {
+		x25, err := floor.UnmarshalDecimal(field24, 4)
+		if err != nil {
+			return fmt.Errorf("field amount: %w", err)
+		}
+		r.Amount.Set(x25)
+	} else {
+		return fmt.Errorf("field amount: %w", field24.Error())
+	}
+	if field26 := obj.GetField("fee"); field26.Error() == nil {
+		var p27 big.Rat
+		x28, err := floor.UnmarshalDecimal(field26, 2)
+		if err != nil {
+			return fmt.Errorf("field fee: %w", err)
+		}
+		p27.Set(x28)
+		r.Fee = &p27
+	}
+	if field29 := obj.GetField("metadata"); field29.Error() == nil {
+		x30, err := field29.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field metadata: %w", err)
+		}
+		r.Metadata = string(x30)
+	} else {
+		return fmt.Errorf("field metadata: %w", field29.Error())
+	}
+	if field31 := obj.GetField("token"); field31.Error() == nil {
+		x32, err := field31.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field token: %w", err)
+		}
+		if len(x32) != 16 {
+			return fmt.Errorf("field token: expected 16 bytes, got %d", len(x32))
+		}
+		copy(r.Token[:], x32)
+	} else {
+		return fmt.Errorf("field token: %w", field31.Error())
+	}
+	if field33 := obj.GetField("raw"); field33.Error() == nil {
+		x34, err := field33.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field raw: %w", err)
+		}
+		r.Raw = x34
+	} else {
+		return fmt.Errorf("field raw: %w", field33.Error())
+	}
+	if field35 := obj.GetField("created"); field35.Error() == nil {
+		x36, err := field35.Int64()
+		if err != nil {
+			return fmt.Errorf("field created: %w", err)
+		}
+		r.Created = time.Unix(x36/1000000, 1000*(x36%1000000)).UTC()
+	} else {
+		return fmt.Errorf("field created: %w", field35.Error())
+	}
+	if field37 := obj.GetField("shipped"); field37.Error() == nil {
+		var p38 time.Time
+		x39, err := field37.Int64()
+		if err != nil {
+			return fmt.Errorf("field shipped: %w", err)
+		}
+		p38 = time.Unix(x39/1000, 1000000*(x39%1000))
+		r.Shipped = &p38
+	}
+	if field40 := obj.GetField("day"); field40.Error() == nil {
+		x41, err := field40.Int32()
+		if err != nil {
+			return fmt.Errorf("field day: %w", err)
+		}
+		r.Day = time.Unix(0, 0).UTC().Add(24 * time.Hour * time.Duration(x41))
+	} else {
+		return fmt.Errorf("field day: %w", field40.Error())
+	}
+	if field42 := obj.GetField("legacy"); field42.Error() == nil {
+		x43, err := field42.Int96()
+		if err != nil {
+			return fmt.Errorf("field legacy: %w", err)
+		}
+		r.Legacy = goparquet.Int96ToTime(x43).UTC()
+	} else {
+		return fmt.Errorf("field legacy: %w", field42.Error())
+	}
+	if field44 := obj.GetField("updated"); field44.Error() == nil {
+		x45, err := field44.Int64()
+		if err != nil {
+			return fmt.Errorf("field updated: %w", err)
+		}
+		r.Updated = time.Unix(0, x45).UTC()
+	} else {
+		return fmt.Errorf("field updated: %w", field44.Error())
+	}
+	if field46 := obj.GetField("delivery"); field46.Error() == nil {
+		x47, err := field46.Int32()
+		if err != nil {
+			return fmt.Errorf("field delivery: %w", err)
+		}
+		r.Delivery = floor.TimeFromMilliseconds(x47)
+	} else {
+		return fmt.Errorf("field delivery: %w", field46.Error())
+	}
+	if field48 := obj.GetField("cutoff"); field48.Error() == nil {
+		x49, err := field48.Int64()
+		if err != nil {
+			return fmt.Errorf("field cutoff: %w", err)
+		}
+		r.Cutoff = floor.TimeFromNanoseconds(x49).UTC()
+	} else {
+		return fmt.Errorf("field cutoff: %w", field48.Error())
+	}
+	if field50 := obj.GetField("cutoff_utc"); field50.Error() == nil {
+		x51, err := field50.Int64()
+		if err != nil {
+			return fmt.Errorf("field cutoff_utc: %w", err)
+		}
+		r.CutoffUTC = floor.TimeFromMicroseconds(x51).UTC()
+	} else {
+		return fmt.Errorf("field cutoff_utc: %w", field50.Error())
+	}
+	if field52 := obj.GetField("address"); field52.Error() == nil {
+		group53, err := field52.Group()
+		if err != nil {
+			return fmt.Errorf("field address: %w", err)
+		}
+		if err := r.Address.UnmarshalParquet(group53); err != nil {
+			return fmt.Errorf("field address: %w", err)
+		}
+	} else {
+		return fmt.Errorf("field address: %w", field52.Error())
+	}
+	if field54 := obj.GetField("billing"); field54.Error() == nil {
+		var p55 Address
+		group56, err := field54.Group()
+		if err != nil {
+			return fmt.Errorf("field billing: %w", err)
+		}
+		if err := p55.UnmarshalParquet(group56); err != nil {
+			return fmt.Errorf("field billing: %w", err)
+		}
+		r.Billing = &p55
+	}
+	if field57 := obj.GetField("items"); field57.Error() == nil {
+		list58, err := field57.List()
+		if err != nil {
+			return fmt.Errorf("field items: %w", err)
+		}
+		for list58.Next() {
+			elem59, err := list58.Value()
+			var v60 Item
+			if err != nil {
+				return fmt.Errorf("field items.element: %w", err)
+			}
+			group61, err := elem59.Group()
+			if err != nil {
+				return fmt.Errorf("field items.element: %w", err)
+			}
+			if err := v60.UnmarshalParquet(group61); err != nil {
+				return fmt.Errorf("field items.element: %w", err)
+			}
+			r.Items = append(r.Items, v60)
+		}
+	} else {
+		return fmt.Errorf("field items: %w", field57.Error())
+	}
+	if field62 := obj.GetField("tags"); field62.Error() == nil {
+		list63, err := field62.List()
+		if err != nil {
+			return fmt.Errorf("field tags: %w", err)
+		}
+		for list63.Next() {
+			elem64, err := list63.Value()
+			var v65 string
+			if err != nil {
+				return fmt.Errorf("field tags.element: %w", err)
+			}
+			x66, err := elem64.ByteArray()
+			if err != nil {
+				return fmt.Errorf("field tags.element: %w", err)
+			}
+			v65 = string(x66)
+			r.Tags = append(r.Tags, v65)
+		}
+	} else {
+		return fmt.Errorf("field tags: %w", field62.Error())
+	}
+	if field67 := obj.GetField("scores"); field67.Error() == nil {
+		list68, err := field67.List()
+		if err != nil {
+			return fmt.Errorf("field scores: %w", err)
+		}
+		for list68.Next() {
+			elem69, err := list68.Value()
+			var v70 *int32
+			if err != nil {
+				return fmt.Errorf("field scores.element: %w", err)
+			}
+			var p71 int32
+			x72, err := elem69.Int32()
+			if err != nil {
+				return fmt.Errorf("field scores.element: %w", err)
+			}
+			p71 = x72
+			v70 = &p71
+			r.Scores = append(r.Scores, v70)
+		}
+	}
+	if field73 := obj.GetField("dates"); field73.Error() == nil {
+		list74, err := field73.List()
+		if err != nil {
+			return fmt.Errorf("field dates: %w", err)
+		}
+		for list74.Next() {
+			elem75, err := list74.Value()
+			var v76 time.Time
+			if err != nil {
+				return fmt.Errorf("field dates.element: %w", err)
+			}
+			x77, err := elem75.Int32()
+			if err != nil {
+				return fmt.Errorf("field dates.element: %w", err)
+			}
+			v76 = time.Unix(0, 0).UTC().Add(24 * time.Hour * time.Duration(x77))
+			r.Dates = append(r.Dates, v76)
+		}
+	} else {
+		return fmt.Errorf("field dates: %w", field73.Error())
+	}
+	if field78 := obj.GetField("rates"); field78.Error() == nil {
+		list79, err := field78.List()
+		if err != nil {
+			return fmt.Errorf("field rates: %w", err)
+		}
+		for list79.Next() {
+			elem80, err := list79.Value()
+			var v81 big.Rat
+			if err != nil {
+				return fmt.Errorf("field rates.element: %w", err)
+			}
+			x82, err := floor.UnmarshalDecimal(elem80, 3)
+			if err != nil {
+				return fmt.Errorf("field rates.element: %w", err)
+			}
+			v81.Set(x82)
+			r.Rates = append(r.Rates, v81)
+		}
+	} else {
+		return fmt.Errorf("field rates: %w", field78.Error())
+	}
+	if field83 := obj.GetField("attrs"); field83.Error() == nil {
+		m84, err := field83.Map()
+		if err != nil {
+			return fmt.Errorf("field attrs: %w", err)
+		}
+		r.Attrs = make(map[string]int64)
+		for m84.Next() {
+			key85, err := m84.Key()
+			var k87 string
+			if err != nil {
+				return fmt.Errorf("field attrs.key: %w", err)
+			}
+			x89, err := key85.ByteArray()
+			if err != nil {
+				return fmt.Errorf("field attrs.key: %w", err)
+			}
+			k87 = string(x89)
+			value86, err := m84.Value()
+			var v88 int64
+			if err != nil {
+				return fmt.Errorf("field attrs.value: %w", err)
+			}
+			x90, err := value86.Int64()
+			if err != nil {
+				return fmt.Errorf("field attrs.value: %w", err)
+			}
+			v88 = x90
+			r.Attrs[k87] = v88
+		}
+	}
+	if field91 := obj.GetField("shipments"); field91.Error() == nil {
+		m92, err := field91.Map()
+		if err != nil {
+			return fmt.Errorf("field shipments: %w", err)
+		}
+		r.Shipments = make(map[int32]*Address)
+		for m92.Next() {
+			key93, err := m92.Key()
+			var k95 int32
+			if err != nil {
+				return fmt.Errorf("field shipments.key: %w", err)
+			}
+			x97, err := key93.Int32()
+			if err != nil {
+				return fmt.Errorf("field shipments.key: %w", err)
+			}
+			k95 = x97
+			value94, err := m92.Value()
+			var v96 *Address
+			if err == nil {
+				var p98 Address
+				group99, err := value94.Group()
+				if err != nil {
+					return fmt.Errorf("field shipments.value: %w", err)
+				}
+				if err := p98.UnmarshalParquet(group99); err != nil {
+					return fmt.Errorf("field shipments.value: %w", err)
+				}
+				v96 = &p98
+			}
+			r.Shipments[k95] = v96
+		}
+	}
+	return nil
+}
+
+// MarshalParquet marshals r into obj.
+func (r *Address) MarshalParquet(obj interfaces.MarshalObject) error {
+	obj.AddField("street").SetByteArray([]byte(r.Street))
+	if r.Zip != nil {
+		obj.AddField("zip").SetByteArray([]byte(*r.Zip))
+	}
+	return nil
+}
+
+// UnmarshalParquet unmarshals obj into r.
+func (r *Address) UnmarshalParquet(obj interfaces.UnmarshalObject) error {
+	if field1 := obj.GetField("street"); field1.Error() == nil {
+		x2, err := field1.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field street: %w", err)
+		}
+		r.Street = string(x2)
+	} else {
+		return fmt.Errorf("field street: %w", field1.Error())
+	}
+	if field3 := obj.GetField("zip"); field3.Error() == nil {
+		var p4 string
+		x5, err := field3.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field zip: %w", err)
+		}
+		p4 = string(x5)
+		r.Zip = &p4
+	}
+	return nil
+}
+
+// MarshalParquet marshals r into obj.
+func (r *Item) MarshalParquet(obj interfaces.MarshalObject) error {
+	obj.AddField("sku").SetByteArray([]byte(r.SKU))
+	obj.AddField("quantity").SetInt64(int64(r.Quantity))
+	if r.Options != nil {
+		m1 := obj.AddField("options").Map()
+		for k3, v4 := range r.Options {
+			kv2 := m1.Add()
+			kv2.Key().SetByteArray([]byte(k3))
+			kv2.Value().SetByteArray([]byte(v4))
+		}
+	}
+	if len(r.Parts) > 0 {
+		list5 := obj.AddField("parts").List()
+		for _, v7 := range r.Parts {
+			elem6 := list5.Add()
+			elem6.SetByteArray(v7)
+		}
+	}
+	return nil
+}
+
+// UnmarshalParquet unmarshals obj into r.
+func (r *Item) UnmarshalParquet(obj interfaces.UnmarshalObject) error {
+	if field1 := obj.GetField("sku"); field1.Error() == nil {
+		x2, err := field1.ByteArray()
+		if err != nil {
+			return fmt.Errorf("field sku: %w", err)
+		}
+		r.SKU = string(x2)
+	} else {
+		return fmt.Errorf("field sku: %w", field1.Error())
+	}
+	if field3 := obj.GetField("quantity"); field3.Error() == nil {
+		x4, err := field3.Int64()
+		if err != nil {
+			return fmt.Errorf("field quantity: %w", err)
+		}
+		r.Quantity = int(x4)
+	} else {
+		return fmt.Errorf("field quantity: %w", field3.Error())
+	}
+	if field5 := obj.GetField("options"); field5.Error() == nil {
+		m6, err := field5.Map()
+		if err != nil {
+			return fmt.Errorf("field options: %w", err)
+		}
+		r.Options = make(map[string]string)
+		for m6.Next() {
+			key7, err := m6.Key()
+			var k9 string
+			if err != nil {
+				return fmt.Errorf("field options.key: %w", err)
+			}
+			x11, err := key7.ByteArray()
+			if err != nil {
+				return fmt.Errorf("field options.key: %w", err)
+			}
+			k9 = string(x11)
+			value8, err := m6.Value()
+			var v10 string
+			if err != nil {
+				return fmt.Errorf("field options.value: %w", err)
+			}
+			x12, err := value8.ByteArray()
+			if err != nil {
+				return fmt.Errorf("field options.value: %w", err)
+			}
+			v10 = string(x12)
+			r.Options[k9] = v10
+		}
+	}
+	if field13 := obj.GetField("parts"); field13.Error() == nil {
+		list14, err := field13.List()
+		if err != nil {
+			return fmt.Errorf("field parts: %w", err)
+		}
+		for list14.Next() {
+			elem15, err := list14.Value()
+			var v16 []byte
+			if err != nil {
+				return fmt.Errorf("field parts.element: %w", err)
+			}
+			x17, err := elem15.ByteArray()
+			if err != nil {
+				return fmt.Errorf("field parts.element: %w", err)
+			}
+			v16 = x17
+			r.Parts = append(r.Parts, v16)
+		}
+	} else {
+		return fmt.Errorf("field parts: %w", field13.Error())
+	}
+	return nil
+}
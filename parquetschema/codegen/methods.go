@@ -0,0 +1,358 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// codeWriter writes generated code and keeps track of the imported packages and the names
+// of local variables.
+type codeWriter struct {
+	buf     bytes.Buffer
+	imports map[string]bool
+	vars    int
+}
+
+func (w *codeWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.buf, format, args...)
+}
+
+func (w *codeWriter) use(pkg string) {
+	w.imports[pkg] = true
+}
+
+// newVar returns a new name for a local variable.
+func (w *codeWriter) newVar(prefix string) string {
+	w.vars++
+	return fmt.Sprintf("%s%d", prefix, w.vars)
+}
+
+func (w *codeWriter) useTypeImports(t *fieldType) {
+	switch t.kind {
+	case kindPrimitive:
+		switch t.goType {
+		case "time.Time":
+			w.use("time")
+		case "floor.Time":
+			w.use("github.com/fraugster/parquet-go/floor")
+		case "big.Rat":
+			w.use("math/big")
+		}
+	case kindList:
+		w.useTypeImports(t.elemType)
+	case kindMap:
+		w.useTypeImports(t.keyType)
+		w.useTypeImports(t.valueType)
+	}
+}
+
+// operand returns value so that it can be used as operand of a selector or slice expression.
+func operand(value string) string {
+	if strings.HasPrefix(value, "*") {
+		return "(" + value + ")"
+	}
+	return value
+}
+
+func (w *codeWriter) marshalMethod(st *structType) {
+	w.use("github.com/fraugster/parquet-go/floor/interfaces")
+	w.vars = 0
+
+	w.printf("\n// MarshalParquet marshals r into obj.\n")
+	w.printf("func (r *%s) MarshalParquet(obj interfaces.MarshalObject) error {\n", st.name)
+	for _, f := range st.fields {
+		w.marshalValue(f.typ, "r."+f.name, fmt.Sprintf("obj.AddField(%q)", f.column))
+	}
+	w.printf("return nil\n}\n")
+}
+
+// marshalValue writes the code to marshal value of type t into the interfaces.MarshalElement target.
+func (w *codeWriter) marshalValue(t *fieldType, value, target string) {
+	if t.pointer {
+		elemType := *t
+		elemType.pointer, elemType.optional = false, false
+
+		w.printf("if %s != nil {\n", value)
+		w.marshalValue(&elemType, "*"+value, target)
+		w.printf("}\n")
+		return
+	}
+
+	switch t.kind {
+	case kindPrimitive:
+		switch {
+		case t.base() == "big.Rat":
+			w.marshalDecimal(t, value, target)
+			return
+		case t.optional && t.base() == "[]byte":
+			w.printf("if %s != nil {\n%s.%s\n}\n", value, target, w.setter(t, value))
+			return
+		case t.optional:
+			// like floor, optional values that aren't pointers are null if they are zero.
+			w.printf("if %s != %s {\n%s.%s\n}\n", value, t.zero(), target, w.setter(t, value))
+			return
+		}
+		w.printf("%s.%s\n", target, w.setter(t, value))
+	case kindStruct:
+		w.printf("if err := %s.MarshalParquet(%s.Group()); err != nil {\nreturn err\n}\n", strings.TrimPrefix(value, "*"), target)
+	case kindList:
+		list, elem, v := w.newVar("list"), w.newVar("elem"), w.newVar("v")
+		if t.elemType.base() == "big.Rat" && !t.elemType.pointer {
+			// big.Rat elements are marshalled by index, so that they aren't copied.
+			i := w.newVar("i")
+			w.printf("if len(%s) > 0 {\n%s := %s.List()\nfor %s := range %s {\n", value, list, target, i, value)
+			v = fmt.Sprintf("%s[%s]", value, i)
+		} else {
+			w.printf("if len(%s) > 0 {\n%s := %s.List()\nfor _, %s := range %s {\n", value, list, target, v, value)
+		}
+		w.printf("%s := %s.Add()\n", elem, list)
+		w.marshalValue(t.elemType, v, elem)
+		w.printf("}\n}\n")
+	case kindMap:
+		m, kv, k, v := w.newVar("m"), w.newVar("kv"), w.newVar("k"), w.newVar("v")
+		w.printf("if %s != nil {\n%s := %s.Map()\nfor %s, %s := range %s {\n", value, m, target, k, v, value)
+		w.printf("%s := %s.Add()\n", kv, m)
+		w.marshalValue(t.keyType, k, kv+".Key()")
+		w.marshalValue(t.valueType, v, kv+".Value()")
+		w.printf("}\n}\n")
+	}
+}
+
+// marshalDecimal writes the code to marshal the big.Rat value into target.
+func (w *codeWriter) marshalDecimal(t *fieldType, value, target string) {
+	w.use("github.com/fraugster/parquet-go/floor")
+	w.use("github.com/fraugster/parquet-go/parquet")
+
+	// MarshalDecimal takes a pointer, so that big.Rat values aren't copied.
+	ptr := "&" + value
+	if strings.HasPrefix(value, "*") {
+		ptr = strings.TrimPrefix(value, "*")
+	}
+
+	elem := t.elem
+	precision, scale := decimalParams(elem)
+	w.printf("if err := floor.MarshalDecimal(%s, %s, parquet.Type_%s, %d, %d, %d); err != nil {\nreturn err\n}\n", target, ptr, elem.GetType(), elem.GetTypeLength(), precision, scale)
+}
+
+// setter returns the call of the interfaces.MarshalElement method to set value of the primitive type t.
+func (w *codeWriter) setter(t *fieldType, value string) string {
+	elem := t.elem
+
+	switch t.base() {
+	case "time.Time":
+		switch {
+		case elem.GetType() == parquet.Type_INT96:
+			w.use("github.com/fraugster/parquet-go")
+			return fmt.Sprintf("SetInt96(goparquet.TimeToInt96(%s))", value)
+		case isDate(elem):
+			w.use("time")
+			return fmt.Sprintf("SetInt32(int32(%s.Sub(time.Unix(0, 0).UTC()).Hours() / 24))", operand(value))
+		}
+		switch unit, _ := timeUnit(elem); unit {
+		case "millis":
+			return fmt.Sprintf("SetInt64(%s.UnixNano() / 1000000)", operand(value))
+		case "micros":
+			return fmt.Sprintf("SetInt64(%s.UnixNano() / 1000)", operand(value))
+		}
+		return fmt.Sprintf("SetInt64(%s.UnixNano())", operand(value))
+	case "floor.Time":
+		switch unit, _ := timeUnit(elem); unit {
+		case "millis":
+			return fmt.Sprintf("SetInt32(%s.Milliseconds())", operand(value))
+		case "micros":
+			return fmt.Sprintf("SetInt64(%s.Microseconds())", operand(value))
+		}
+		return fmt.Sprintf("SetInt64(%s.Nanoseconds())", operand(value))
+	case "string":
+		return fmt.Sprintf("SetByteArray([]byte(%s))", value)
+	case "[]byte":
+		return fmt.Sprintf("SetByteArray(%s)", value)
+	}
+
+	switch elem.GetType() {
+	case parquet.Type_BOOLEAN:
+		return fmt.Sprintf("SetBool(%s)", convert("bool", t.goType, value))
+	case parquet.Type_INT32:
+		return fmt.Sprintf("SetInt32(%s)", convert("int32", t.goType, value))
+	case parquet.Type_INT64:
+		return fmt.Sprintf("SetInt64(%s)", convert("int64", t.goType, value))
+	case parquet.Type_FLOAT:
+		return fmt.Sprintf("SetFloat32(%s)", convert("float32", t.goType, value))
+	case parquet.Type_DOUBLE:
+		return fmt.Sprintf("SetFloat64(%s)", convert("float64", t.goType, value))
+	}
+
+	// [N]byte is copied, as the array may be a loop variable that is reused for the next element.
+	return fmt.Sprintf("SetByteArray(append([]byte(nil), %s[:]...))", operand(value))
+}
+
+// convert returns the conversion of value from type from to type to.
+func convert(to, from, value string) string {
+	if to == from {
+		return value
+	}
+	return to + "(" + value + ")"
+}
+
+func (w *codeWriter) unmarshalMethod(st *structType) {
+	w.use("github.com/fraugster/parquet-go/floor/interfaces")
+	w.vars = 0
+
+	w.printf("\n// UnmarshalParquet unmarshals obj into r.\n")
+	w.printf("func (r *%s) UnmarshalParquet(obj interfaces.UnmarshalObject) error {\n", st.name)
+	for _, f := range st.fields {
+		field := w.newVar("field")
+		w.printf("if %s := obj.GetField(%q); %s.Error() == nil {\n", field, f.column, field)
+		w.unmarshalValue(f.typ, field, "r."+f.name, f.column)
+		if !f.typ.optional {
+			w.use("fmt")
+			w.printf("} else {\nreturn fmt.Errorf(\"field %s: %%w\", %s.Error())\n", f.column, field)
+		}
+		w.printf("}\n")
+	}
+	w.printf("return nil\n}\n")
+}
+
+// unmarshalValue writes the code to unmarshal the interfaces.UnmarshalElement elem into target of
+// type t. path is the path of the column used in error messages.
+func (w *codeWriter) unmarshalValue(t *fieldType, elem, target, path string) {
+	if t.pointer {
+		elemType := *t
+		elemType.pointer = false
+
+		p := w.newVar("p")
+		w.useTypeImports(&elemType)
+		w.printf("var %s %s\n", p, elemType.expr())
+		w.unmarshalValue(&elemType, elem, p, path)
+		w.printf("%s = &%s\n", target, p)
+		return
+	}
+
+	w.use("fmt")
+	returnErr := fmt.Sprintf("if err != nil {\nreturn fmt.Errorf(\"field %s: %%w\", err)\n}\n", path)
+
+	switch t.kind {
+	case kindPrimitive:
+		x := w.newVar("x")
+		if t.base() == "big.Rat" {
+			w.use("github.com/fraugster/parquet-go/floor")
+			_, scale := decimalParams(t.elem)
+			w.printf("%s, err := floor.UnmarshalDecimal(%s, %d)\n%s", x, elem, scale, returnErr)
+			w.printf("%s.Set(%s)\n", target, x)
+			return
+		}
+		getter, value := w.getter(t, x)
+		w.printf("%s, err := %s.%s()\n%s", x, elem, getter, returnErr)
+		if base := t.base(); strings.HasPrefix(base, "[") && base != "[]byte" {
+			size := strings.TrimSuffix(strings.TrimPrefix(base, "["), "]byte")
+			w.printf("if len(%s) != %s {\nreturn fmt.Errorf(\"field %s: expected %s bytes, got %%d\", len(%s))\n}\n", x, size, path, size, x)
+			w.printf("copy(%s[:], %s)\n", target, x)
+			return
+		}
+		w.printf("%s = %s\n", target, value)
+	case kindStruct:
+		group := w.newVar("group")
+		w.printf("%s, err := %s.Group()\n%s", group, elem, returnErr)
+		w.printf("if err := %s.UnmarshalParquet(%s); err != nil {\nreturn fmt.Errorf(\"field %s: %%w\", err)\n}\n", target, group, path)
+	case kindList:
+		list, listElem, v := w.newVar("list"), w.newVar("elem"), w.newVar("v")
+		w.printf("%s, err := %s.List()\n%s", list, elem, returnErr)
+		w.printf("for %s.Next() {\n%s, err := %s.Value()\n", list, listElem, list)
+		w.unmarshalElement(t.elemType, listElem, v, path+".element")
+		w.printf("%s = append(%s, %s)\n}\n", target, target, v)
+	case kindMap:
+		m, key, value, k, v := w.newVar("m"), w.newVar("key"), w.newVar("value"), w.newVar("k"), w.newVar("v")
+		w.printf("%s, err := %s.Map()\n%s", m, elem, returnErr)
+		w.useTypeImports(t)
+		w.printf("%s = make(%s)\n", target, t.expr())
+		w.printf("for %s.Next() {\n%s, err := %s.Key()\n", m, key, m)
+		w.unmarshalElement(t.keyType, key, k, path+".key")
+		w.printf("%s, err := %s.Value()\n", value, m)
+		w.unmarshalElement(t.valueType, value, v, path+".value")
+		w.printf("%s[%s] = %s\n}\n", target, k, v)
+	}
+}
+
+// unmarshalElement writes the code to declare the variable v of type t and unmarshal the list
+// element, map key or map value elem into it. elem and err were returned by the list or map.
+// Missing optional elements are null.
+func (w *codeWriter) unmarshalElement(t *fieldType, elem, v, path string) {
+	w.useTypeImports(t)
+	w.printf("var %s %s\n", v, t.expr())
+	if t.optional {
+		w.printf("if err == nil {\n")
+		w.unmarshalValue(t, elem, v, path)
+		w.printf("}\n")
+		return
+	}
+
+	w.printf("if err != nil {\nreturn fmt.Errorf(\"field %s: %%w\", err)\n}\n", path)
+	w.unmarshalValue(t, elem, v, path)
+}
+
+// getter returns the interfaces.UnmarshalElement method to get a value of the primitive type
+// t, and the expression to convert the value x returned by it to t.
+func (w *codeWriter) getter(t *fieldType, x string) (getter, value string) {
+	elem := t.elem
+
+	switch t.base() {
+	case "time.Time":
+		switch {
+		case elem.GetType() == parquet.Type_INT96:
+			w.use("github.com/fraugster/parquet-go")
+			return "Int96", fmt.Sprintf("goparquet.Int96ToTime(%s).UTC()", x)
+		case isDate(elem):
+			w.use("time")
+			return "Int32", fmt.Sprintf("time.Unix(0, 0).UTC().Add(24 * time.Hour * time.Duration(%s))", x)
+		}
+
+		w.use("time")
+		unit, utc := timeUnit(elem)
+		switch unit {
+		case "millis":
+			value = fmt.Sprintf("time.Unix(%s/1000, 1000000*(%s%%1000))", x, x)
+		case "micros":
+			value = fmt.Sprintf("time.Unix(%s/1000000, 1000*(%s%%1000000))", x, x)
+		default:
+			value = fmt.Sprintf("time.Unix(0, %s)", x)
+		}
+		if utc {
+			value += ".UTC()"
+		}
+		return "Int64", value
+	case "floor.Time":
+		w.use("github.com/fraugster/parquet-go/floor")
+		unit, utc := timeUnit(elem)
+		switch unit {
+		case "millis":
+			getter, value = "Int32", fmt.Sprintf("floor.TimeFromMilliseconds(%s)", x)
+		case "micros":
+			getter, value = "Int64", fmt.Sprintf("floor.TimeFromMicroseconds(%s)", x)
+		default:
+			getter, value = "Int64", fmt.Sprintf("floor.TimeFromNanoseconds(%s)", x)
+		}
+		if utc {
+			value += ".UTC()"
+		}
+		return getter, value
+	case "string":
+		return "ByteArray", fmt.Sprintf("%s(%s)", t.goType, x)
+	}
+
+	switch elem.GetType() {
+	case parquet.Type_BOOLEAN:
+		return "Bool", convert(t.goType, "bool", x)
+	case parquet.Type_INT32:
+		return "Int32", convert(t.goType, "int32", x)
+	case parquet.Type_INT64:
+		return "Int64", convert(t.goType, "int64", x)
+	case parquet.Type_FLOAT:
+		return "Float32", convert(t.goType, "float32", x)
+	case parquet.Type_DOUBLE:
+		return "Float64", convert(t.goType, "float64", x)
+	}
+	return "ByteArray", x
+}
@@ -0,0 +1,153 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// structType is a Go struct type that is mapped to a parquet group.
+type structType struct {
+	name   string
+	fields []*field
+}
+
+// field is a field of a struct type that is mapped to a column of a parquet group.
+type field struct {
+	name   string
+	column string
+	// options of the parquet struct tag of the field, without the column name.
+	options []string
+	typ     *fieldType
+}
+
+type typeKind int
+
+const (
+	kindPrimitive typeKind = iota
+	kindStruct
+	kindList
+	kindMap
+)
+
+// fieldType describes the Go type of a field, list element, map key or map value, and how
+// it is mapped to parquet.
+type fieldType struct {
+	kind typeKind
+
+	// pointer is true if the value is optional and represented as pointer.
+	pointer bool
+	// optional is true if the value is optional. Optional slices, maps and byte slices are not
+	// represented as pointer, but are null if they are nil.
+	optional bool
+
+	// goType is the Go type of a primitive value, e.g. int64, time.Time or [16]byte.
+	goType string
+	// baseType is the underlying type of goType if goType is a defined type like
+	// `type Status int32`, and empty otherwise.
+	baseType string
+	// elem is the schema element of a primitive value.
+	elem *parquet.SchemaElement
+
+	structType *structType
+
+	// elemType is the type of the elements of a list.
+	elemType *fieldType
+
+	keyType   *fieldType
+	valueType *fieldType
+}
+
+// expr returns the Go type expression of t.
+func (t *fieldType) expr() string {
+	var expr string
+	switch t.kind {
+	case kindPrimitive:
+		expr = t.goType
+	case kindStruct:
+		expr = t.structType.name
+	case kindList:
+		expr = "[]" + t.elemType.expr()
+	case kindMap:
+		expr = "map[" + t.keyType.expr() + "]" + t.valueType.expr()
+	}
+
+	if t.pointer {
+		return "*" + expr
+	}
+	return expr
+}
+
+// base returns the underlying type of a primitive value.
+func (t *fieldType) base() string {
+	if t.baseType != "" {
+		return t.baseType
+	}
+	return t.goType
+}
+
+// zero returns the zero value of a primitive value.
+func (t *fieldType) zero() string {
+	switch base := t.base(); {
+	case base == "bool":
+		return "false"
+	case base == "string":
+		return `""`
+	case strings.HasPrefix(base, "int") || strings.HasPrefix(base, "uint") || strings.HasPrefix(base, "float"):
+		return "0"
+	}
+	return "(" + t.goType + "{})"
+}
+
+// tag returns the struct tag of a field.
+func (f *field) tag() string {
+	return fmt.Sprintf("`parquet:%q`", strings.Join(append([]string{f.column}, f.options...), ","))
+}
+
+// isTimestamp returns true if elem is annotated with the TIMESTAMP logical type. Like in floor,
+// converted types are not considered for temporal types.
+func isTimestamp(elem *parquet.SchemaElement) bool {
+	return elem.LogicalType != nil && elem.GetLogicalType().IsSetTIMESTAMP()
+}
+
+func isTime(elem *parquet.SchemaElement) bool {
+	return elem.LogicalType != nil && elem.GetLogicalType().IsSetTIME()
+}
+
+func isDate(elem *parquet.SchemaElement) bool {
+	return elem.LogicalType != nil && elem.GetLogicalType().IsSetDATE()
+}
+
+func isDecimal(elem *parquet.SchemaElement) bool {
+	if elem.LogicalType != nil {
+		return elem.GetLogicalType().IsSetDECIMAL()
+	}
+	return elem.GetConvertedType() == parquet.ConvertedType_DECIMAL
+}
+
+// decimalParams returns the precision and scale of a DECIMAL column.
+func decimalParams(elem *parquet.SchemaElement) (precision, scale int32) {
+	if lt := elem.GetLogicalType(); lt != nil && lt.IsSetDECIMAL() {
+		return lt.DECIMAL.Precision, lt.DECIMAL.Scale
+	}
+	return elem.GetPrecision(), elem.GetScale()
+}
+
+// timeUnit returns the unit of a TIME or TIMESTAMP logical type, and whether it is adjusted to UTC.
+func timeUnit(elem *parquet.SchemaElement) (unit string, utc bool) {
+	var tu *parquet.TimeUnit
+	if isTime(elem) {
+		tu, utc = elem.GetLogicalType().TIME.GetUnit(), elem.GetLogicalType().TIME.GetIsAdjustedToUTC()
+	} else {
+		tu, utc = elem.GetLogicalType().TIMESTAMP.GetUnit(), elem.GetLogicalType().TIMESTAMP.GetIsAdjustedToUTC()
+	}
+
+	switch {
+	case tu.IsSetMILLIS():
+		return "millis", utc
+	case tu.IsSetMICROS():
+		return "micros", utc
+	}
+	return "nanos", utc
+}
@@ -0,0 +1,64 @@
+package codegen
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// initialisms are written in upper case when they are part of an identifier.
+var initialisms = map[string]bool{
+	"API": true, "CSV": true, "DNS": true, "HTML": true, "HTTP": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "TCP": true, "UID": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// exportedName converts a column name like user_id or userId to an exported Go identifier like UserID.
+func exportedName(name string) string {
+	var words []string
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		words = append(words, splitCamelCase(word)...)
+	}
+
+	var sb strings.Builder
+	for _, word := range words {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		sb.WriteRune(unicode.ToUpper(runes[0]))
+		sb.WriteString(string(runes[1:]))
+	}
+
+	ident := sb.String()
+	if ident == "" || !unicode.IsLetter([]rune(ident)[0]) {
+		ident = "F" + ident
+	}
+	return ident
+}
+
+// splitCamelCase splits a word like userId into user and Id.
+func splitCamelCase(word string) []string {
+	var (
+		words []string
+		start int
+	)
+	runes := []rune(word)
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1]) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+// uniqueName returns name, or name with a numeric suffix if name is already used.
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
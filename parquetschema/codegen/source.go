@@ -0,0 +1,594 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fraugster/parquet-go/floor"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/fraugster/parquet-go/parquetschema/autoschema"
+)
+
+// MethodOptions configures the code generated by GenerateMethods.
+type MethodOptions struct {
+	// TypeNames are the names of the struct types to generate methods for. Methods are also
+	// generated for the struct types of the package that are used by their fields. The default
+	// is all struct types of the package that have fields with parquet struct tags.
+	TypeNames []string
+}
+
+// GenerateMethods parses the Go package in the directory dir, and generates MarshalParquet and
+// UnmarshalParquet methods for its struct types. The methods marshal and unmarshal the same data
+// as the reflection-based marshalling and unmarshalling of floor, using the columns that
+// autoschema generates for the struct types.
+//
+// It returns the source code of a Go file containing the methods, and of a Go test file that
+// checks that the methods round-trip a value like the reflection-based marshalling and
+// unmarshalling. Both are formatted using gofmt.
+//
+// Fields of type time.Time, floor.Time, big.Rat and integers with the decimal option, nested
+// structs, slices, maps and pointers to them are supported, as are defined types of the package
+// whose underlying type is a basic type. Unexported fields need the struct tag `parquet:"-"`.
+func GenerateMethods(dir string, opts MethodOptions) (src, testSrc []byte, err error) {
+	pkg, err := parsePackage(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	typeNames := opts.TypeNames
+	if len(typeNames) == 0 {
+		typeNames = pkg.taggedStructs()
+		if len(typeNames) == 0 {
+			return nil, nil, fmt.Errorf("no struct types with parquet struct tags found in package %s", pkg.name)
+		}
+	}
+
+	g := &sourceGenerator{
+		pkg:         pkg,
+		structTypes: map[string]*structType{},
+		mirrors:     map[string]reflect.Type{},
+		mirroring:   map[string]bool{},
+	}
+
+	var roots []*structType
+	for _, name := range typeNames {
+		st, err := g.rootStruct(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("type %s: %w", name, err)
+		}
+		roots = append(roots, st)
+	}
+
+	w := &codeWriter{imports: map[string]bool{}}
+	for _, st := range g.structs {
+		w.marshalMethod(st)
+		w.unmarshalMethod(st)
+	}
+	if src, err = w.source(pkg.name); err != nil {
+		return nil, nil, err
+	}
+
+	w = &codeWriter{imports: map[string]bool{}}
+	for _, st := range roots {
+		w.roundTripTest(st)
+	}
+	if testSrc, err = w.source(pkg.name); err != nil {
+		return nil, nil, err
+	}
+
+	return src, testSrc, nil
+}
+
+// goPackage contains the type declarations of a parsed Go package.
+type goPackage struct {
+	name  string
+	types map[string]*typeDecl
+	// names are the names of the declared types in the order of their declaration.
+	names []string
+}
+
+type typeDecl struct {
+	spec *ast.TypeSpec
+	file *ast.File
+}
+
+// parsePackage parses the Go files of the package in dir, except for test files.
+func parsePackage(dir string) (*goPackage, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parsing package failed: %w", err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	pkg := &goPackage{types: map[string]*typeDecl{}}
+	for name, p := range pkgs {
+		pkg.name = name
+
+		var fileNames []string
+		for fileName := range p.Files {
+			fileNames = append(fileNames, fileName)
+		}
+		sort.Strings(fileNames)
+
+		for _, fileName := range fileNames {
+			file := p.Files[fileName]
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range genDecl.Specs {
+					spec := spec.(*ast.TypeSpec)
+					pkg.types[spec.Name.Name] = &typeDecl{spec: spec, file: file}
+					pkg.names = append(pkg.names, spec.Name.Name)
+				}
+			}
+		}
+	}
+
+	return pkg, nil
+}
+
+// taggedStructs returns the names of the struct types that have fields with parquet struct tags.
+func (pkg *goPackage) taggedStructs() []string {
+	var names []string
+	for _, name := range pkg.names {
+		st, ok := pkg.types[name].spec.Type.(*ast.StructType)
+		if !ok {
+			continue
+		}
+		for _, f := range st.Fields.List {
+			if _, ok := fieldTag(f).Lookup("parquet"); ok {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+func fieldTag(f *ast.Field) reflect.StructTag {
+	if f.Tag == nil {
+		return ""
+	}
+	tag, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(tag)
+}
+
+// sourceGenerator maps the struct types of a Go package to the columns that autoschema
+// generates for them.
+type sourceGenerator struct {
+	pkg *goPackage
+
+	// structs are the struct types to generate methods for, in the order they were found.
+	structs     []*structType
+	structTypes map[string]*structType
+
+	// mirrors are struct types created at runtime that have the same fields as the struct types
+	// of the package, so that autoschema can generate their columns.
+	mirrors   map[string]reflect.Type
+	mirroring map[string]bool
+}
+
+// rootStruct generates the struct type name and the struct types used by its fields.
+func (g *sourceGenerator) rootStruct(name string) (*structType, error) {
+	decl := g.pkg.types[name]
+	if decl == nil {
+		return nil, errors.New("type not found")
+	}
+	if _, ok := decl.spec.Type.(*ast.StructType); !ok {
+		return nil, errors.New("type is not a struct type")
+	}
+
+	mirror, err := g.mirrorType(decl.spec.Name, decl.file)
+	if err != nil {
+		return nil, err
+	}
+
+	sd, err := autoschema.GenerateSchema(reflect.New(mirror).Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	return g.structType(name, sd.RootColumn)
+}
+
+func (g *sourceGenerator) structType(name string, col *parquetschema.ColumnDefinition) (*structType, error) {
+	if st := g.structTypes[name]; st != nil {
+		return st, nil
+	}
+
+	st := &structType{name: name}
+	g.structTypes[name] = st
+	g.structs = append(g.structs, st)
+
+	decl := g.pkg.types[name]
+	children := col.Children
+	err := forEachField(decl.spec.Type.(*ast.StructType), func(fieldName string, f *ast.Field) error {
+		child := children[0]
+		children = children[1:]
+
+		typ, err := g.fieldType(f.Type, decl.file, child)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", fieldName, err)
+		}
+
+		st.fields = append(st.fields, &field{name: fieldName, column: child.SchemaElement.GetName(), typ: typ})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return st, nil
+}
+
+// forEachField calls fn for all fields of st that are not skipped with the struct tag
+// `parquet:"-"`. It returns an error for embedded and unexported fields.
+func forEachField(st *ast.StructType, fn func(name string, f *ast.Field) error) error {
+	for _, f := range st.Fields.List {
+		if fieldTag(f).Get("parquet") == "-" {
+			continue
+		}
+		if len(f.Names) == 0 {
+			return fmt.Errorf("embedded field %s is unsupported", typeString(f.Type))
+		}
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				return fmt.Errorf("unexported field %s needs the struct tag `parquet:\"-\"`", ident.Name)
+			}
+			if err := fn(ident.Name, f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldType returns the type of a field, list element, map key or map value with the type
+// expression expr, which is stored in the column col.
+func (g *sourceGenerator) fieldType(expr ast.Expr, file *ast.File, col *parquetschema.ColumnDefinition) (*fieldType, error) {
+	pointer := false
+	if star, ok := expr.(*ast.StarExpr); ok {
+		if _, ok := star.X.(*ast.StarExpr); ok {
+			return nil, errors.New("pointers to pointers are unsupported")
+		}
+		expr, pointer = star.X, true
+	}
+
+	var (
+		typ *fieldType
+		err error
+	)
+
+	switch expr := expr.(type) {
+	case *ast.ArrayType:
+		typ, err = g.arrayType(expr, file, col)
+	case *ast.MapType:
+		typ, err = g.mapType(expr, file, col)
+	case *ast.Ident:
+		typ, err = g.identType(expr, file, col)
+	case *ast.SelectorExpr:
+		var goType string
+		if goType, err = selectorType(expr, file); err == nil {
+			typ = &fieldType{kind: kindPrimitive, goType: goType, elem: col.SchemaElement}
+		}
+	default:
+		err = fmt.Errorf("type %s is unsupported", typeString(expr))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	typ.pointer = pointer
+	typ.optional = col.SchemaElement.GetRepetitionType() == parquet.FieldRepetitionType_OPTIONAL
+	if typ.kind == kindStruct && typ.optional && !typ.pointer {
+		return nil, errors.New("the optional option is unsupported for structs, use a pointer instead")
+	}
+	if typ.kind == kindPrimitive {
+		if err := checkPrimitive(typ); err != nil {
+			return nil, err
+		}
+	}
+
+	return typ, nil
+}
+
+func (g *sourceGenerator) arrayType(expr *ast.ArrayType, file *ast.File, col *parquetschema.ColumnDefinition) (*fieldType, error) {
+	isByte := false
+	if ident, ok := expr.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") {
+		isByte = true
+	}
+
+	if expr.Len != nil {
+		lit, ok := expr.Len.(*ast.BasicLit)
+		if !isByte || !ok || lit.Kind != token.INT {
+			return nil, fmt.Errorf("type %s is unsupported, only arrays of the form [N]byte are", typeString(expr))
+		}
+		return &fieldType{kind: kindPrimitive, goType: "[" + lit.Value + "]byte", elem: col.SchemaElement}, nil
+	}
+
+	if isByte {
+		return &fieldType{kind: kindPrimitive, goType: "[]byte", elem: col.SchemaElement}, nil
+	}
+
+	elemType, err := g.fieldType(expr.Elt, file, col.Children[0].Children[0])
+	if err != nil {
+		return nil, err
+	}
+	return &fieldType{kind: kindList, elemType: elemType}, nil
+}
+
+func (g *sourceGenerator) mapType(expr *ast.MapType, file *ast.File, col *parquetschema.ColumnDefinition) (*fieldType, error) {
+	kv := col.Children[0]
+
+	keyType, err := g.fieldType(expr.Key, file, kv.Children[0])
+	if err != nil {
+		return nil, err
+	}
+	if keyType.kind != kindPrimitive || keyType.pointer {
+		return nil, fmt.Errorf("map key of type %s is unsupported", keyType.expr())
+	}
+
+	valueType, err := g.fieldType(expr.Value, file, kv.Children[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &fieldType{kind: kindMap, keyType: keyType, valueType: valueType}, nil
+}
+
+// identType returns the type of a basic type or of a type declared in the package.
+func (g *sourceGenerator) identType(ident *ast.Ident, file *ast.File, col *parquetschema.ColumnDefinition) (*fieldType, error) {
+	if basicTypes[ident.Name] != nil {
+		return &fieldType{kind: kindPrimitive, goType: ident.Name, elem: col.SchemaElement}, nil
+	}
+
+	decl := g.pkg.types[ident.Name]
+	if decl == nil {
+		return nil, fmt.Errorf("type %s is unsupported", ident.Name)
+	}
+
+	if _, ok := decl.spec.Type.(*ast.StructType); ok {
+		st, err := g.structType(ident.Name, col)
+		if err != nil {
+			return nil, err
+		}
+		return &fieldType{kind: kindStruct, structType: st}, nil
+	}
+
+	if underlying, ok := decl.spec.Type.(*ast.Ident); ok && basicTypes[underlying.Name] != nil {
+		return &fieldType{kind: kindPrimitive, goType: ident.Name, baseType: underlying.Name, elem: col.SchemaElement}, nil
+	}
+
+	return nil, fmt.Errorf("type %s is unsupported, only defined types of basic types and structs are", ident.Name)
+}
+
+// selectorType returns time.Time, floor.Time or big.Rat for the qualified identifier expr.
+func selectorType(expr *ast.SelectorExpr, file *ast.File) (string, error) {
+	if pkgIdent, ok := expr.X.(*ast.Ident); ok {
+		switch path := importPath(file, pkgIdent.Name); {
+		case path == "time" && expr.Sel.Name == "Time":
+			return "time.Time", nil
+		case path == "github.com/fraugster/parquet-go/floor" && expr.Sel.Name == "Time":
+			return "floor.Time", nil
+		case path == "math/big" && expr.Sel.Name == "Rat":
+			return "big.Rat", nil
+		}
+	}
+	return "", fmt.Errorf("type %s is unsupported", typeString(expr))
+}
+
+// importPath returns the path of the package imported with the name pkgName in file.
+func importPath(file *ast.File, pkgName string) string {
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == pkgName {
+			return path
+		}
+	}
+	return ""
+}
+
+// checkPrimitive returns an error if the primitive type t can't be stored in its column.
+func checkPrimitive(t *fieldType) error {
+	var ok bool
+
+	switch typ, base := t.elem.GetType(), t.base(); {
+	case base == "time.Time":
+		ok = typ == parquet.Type_INT96 || typ == parquet.Type_INT32 && isDate(t.elem) || typ == parquet.Type_INT64 && isTimestamp(t.elem)
+	case base == "floor.Time":
+		ok = isTime(t.elem)
+	case base == "big.Rat":
+		ok = isDecimal(t.elem) && (typ == parquet.Type_INT32 || typ == parquet.Type_INT64 || typ == parquet.Type_FIXED_LEN_BYTE_ARRAY || typ == parquet.Type_BYTE_ARRAY)
+	case base == "bool":
+		ok = typ == parquet.Type_BOOLEAN
+	case base == "string" || strings.HasPrefix(base, "["):
+		ok = typ == parquet.Type_BYTE_ARRAY || typ == parquet.Type_FIXED_LEN_BYTE_ARRAY
+	case strings.HasPrefix(base, "float"):
+		ok = typ == parquet.Type_FLOAT || typ == parquet.Type_DOUBLE
+	default:
+		ok = typ == parquet.Type_INT32 || typ == parquet.Type_INT64
+	}
+
+	if !ok {
+		return fmt.Errorf("type %s can't be stored in a column of type %s", t.goType, t.elem.GetType())
+	}
+	return nil
+}
+
+// basicTypes are the basic types supported in struct fields.
+var basicTypes = map[string]reflect.Type{
+	"bool":    reflect.TypeOf(false),
+	"int":     reflect.TypeOf(int(0)),
+	"int8":    reflect.TypeOf(int8(0)),
+	"int16":   reflect.TypeOf(int16(0)),
+	"int32":   reflect.TypeOf(int32(0)),
+	"int64":   reflect.TypeOf(int64(0)),
+	"uint":    reflect.TypeOf(uint(0)),
+	"uint8":   reflect.TypeOf(uint8(0)),
+	"uint16":  reflect.TypeOf(uint16(0)),
+	"uint32":  reflect.TypeOf(uint32(0)),
+	"uint64":  reflect.TypeOf(uint64(0)),
+	"byte":    reflect.TypeOf(byte(0)),
+	"rune":    reflect.TypeOf(rune(0)),
+	"float32": reflect.TypeOf(float32(0)),
+	"float64": reflect.TypeOf(float64(0)),
+	"string":  reflect.TypeOf(""),
+}
+
+// mirrorType returns a type created at runtime for the type expression expr, which autoschema
+// maps to the same columns as the type of the package.
+func (g *sourceGenerator) mirrorType(expr ast.Expr, file *ast.File) (reflect.Type, error) {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		elem, err := g.mirrorType(expr.X, file)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.PtrTo(elem), nil
+	case *ast.ArrayType:
+		elem, err := g.mirrorType(expr.Elt, file)
+		if err != nil {
+			return nil, err
+		}
+		if expr.Len == nil {
+			return reflect.SliceOf(elem), nil
+		}
+		lit, ok := expr.Len.(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			return nil, fmt.Errorf("array length %s is unsupported, only integer literals are", typeString(expr.Len))
+		}
+		n, err := strconv.Atoi(lit.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid array length %s: %w", lit.Value, err)
+		}
+		return reflect.ArrayOf(n, elem), nil
+	case *ast.MapType:
+		key, err := g.mirrorType(expr.Key, file)
+		if err != nil {
+			return nil, err
+		}
+		if !key.Comparable() {
+			return nil, fmt.Errorf("map key of type %s is unsupported", typeString(expr.Key))
+		}
+		value, err := g.mirrorType(expr.Value, file)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(key, value), nil
+	case *ast.Ident:
+		if typ := basicTypes[expr.Name]; typ != nil {
+			return typ, nil
+		}
+		return g.mirrorDecl(expr.Name)
+	case *ast.SelectorExpr:
+		goType, err := selectorType(expr, file)
+		if err != nil {
+			return nil, err
+		}
+		switch goType {
+		case "floor.Time":
+			return reflect.TypeOf(floor.Time{}), nil
+		case "big.Rat":
+			return reflect.TypeOf(big.Rat{}), nil
+		}
+		return reflect.TypeOf(time.Time{}), nil
+	}
+	return nil, fmt.Errorf("type %s is unsupported", typeString(expr))
+}
+
+// mirrorDecl returns the mirror of the type name declared in the package.
+func (g *sourceGenerator) mirrorDecl(name string) (reflect.Type, error) {
+	if typ := g.mirrors[name]; typ != nil {
+		return typ, nil
+	}
+
+	decl := g.pkg.types[name]
+	if decl == nil {
+		return nil, fmt.Errorf("type %s is unsupported", name)
+	}
+	if decl.spec.TypeParams != nil {
+		return nil, fmt.Errorf("generic type %s is unsupported", name)
+	}
+	if g.mirroring[name] {
+		return nil, fmt.Errorf("recursive type %s is unsupported", name)
+	}
+	g.mirroring[name] = true
+	defer delete(g.mirroring, name)
+
+	st, ok := decl.spec.Type.(*ast.StructType)
+	if !ok {
+		return g.mirrorType(decl.spec.Type, decl.file)
+	}
+
+	var fields []reflect.StructField
+	err := forEachField(st, func(fieldName string, f *ast.Field) error {
+		typ, err := g.mirrorType(f.Type, decl.file)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", fieldName, err)
+		}
+		fields = append(fields, reflect.StructField{Name: fieldName, Type: typ, Tag: fieldTag(f)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g.mirrors[name] = reflect.StructOf(fields)
+	return g.mirrors[name], nil
+}
+
+// typeString returns the source code of the type expression expr.
+func typeString(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.BasicLit:
+		return expr.Value
+	case *ast.StarExpr:
+		return "*" + typeString(expr.X)
+	case *ast.SelectorExpr:
+		return typeString(expr.X) + "." + expr.Sel.Name
+	case *ast.ArrayType:
+		if expr.Len == nil {
+			return "[]" + typeString(expr.Elt)
+		}
+		return "[" + typeString(expr.Len) + "]" + typeString(expr.Elt)
+	case *ast.MapType:
+		return "map[" + typeString(expr.Key) + "]" + typeString(expr.Value)
+	case *ast.InterfaceType:
+		return "interface{}"
+	case *ast.ChanType:
+		return "chan " + typeString(expr.Value)
+	case *ast.FuncType:
+		return "func"
+	}
+	return fmt.Sprintf("%T", expr)
+}
@@ -0,0 +1,85 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateMethodsErrors(t *testing.T) {
+	testData := map[string]struct {
+		src       string
+		typeNames []string
+	}{
+		"unknown type": {
+			src:       "type Foo struct { ID int64 }",
+			typeNames: []string{"Bar"},
+		},
+		"not a struct": {
+			src:       "type Foo int64",
+			typeNames: []string{"Foo"},
+		},
+		"no tagged structs": {
+			src: "type Foo struct { ID int64 }",
+		},
+		"unexported field": {
+			src:       "type Foo struct { id int64 }",
+			typeNames: []string{"Foo"},
+		},
+		"embedded field": {
+			src:       "type Bar struct { ID int64 }\ntype Foo struct { Bar }",
+			typeNames: []string{"Foo"},
+		},
+		"unsupported type": {
+			src:       "type Foo struct { C chan int }",
+			typeNames: []string{"Foo"},
+		},
+		"unsupported array": {
+			src:       "type Foo struct { IDs [3]int64 }",
+			typeNames: []string{"Foo"},
+		},
+		"optional struct": {
+			src:       "type Bar struct { ID int64 }\ntype Foo struct { Bar Bar `parquet:\"bar,optional\"` }",
+			typeNames: []string{"Foo"},
+		},
+		"recursive type": {
+			src:       "type Foo struct { Next *Foo }",
+			typeNames: []string{"Foo"},
+		},
+		"type mismatch": {
+			src:       "type Foo struct { Name string `parquet:\"name,type=int64\"` }",
+			typeNames: []string{"Foo"},
+		},
+		"invalid tag": {
+			src:       "type Foo struct { ID int64 `parquet:\"id,bar\"` }",
+			typeNames: []string{"Foo"},
+		},
+	}
+
+	for name, tt := range testData {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "foo.go"), []byte("package foo\n\n"+tt.src+"\n"), 0644))
+
+			_, _, err := GenerateMethods(dir, MethodOptions{TypeNames: tt.typeNames})
+			require.Error(t, err)
+		})
+	}
+}
+
+// TestGenerateMethodsTagged checks that the generated code in internal/tagged, which is tested
+// against the reflection-based marshalling and unmarshalling of floor, is up to date.
+func TestGenerateMethodsTagged(t *testing.T) {
+	src, testSrc, err := GenerateMethods("internal/tagged", MethodOptions{TypeNames: []string{"Order"}})
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("internal/tagged/order_parquet.go")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(src))
+
+	expected, err = os.ReadFile("internal/tagged/order_parquet_test.go")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(testSrc))
+}
@@ -0,0 +1,198 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// roundTripTest writes a test that marshals and unmarshals a value of the struct type st using
+// the generated methods and the reflection-based marshalling and unmarshalling of floor, and
+// checks that they produce the same data.
+func (w *codeWriter) roundTripTest(st *structType) {
+	for _, pkg := range []string{"bytes", "reflect", "testing", "github.com/fraugster/parquet-go", "github.com/fraugster/parquet-go/floor", "github.com/fraugster/parquet-go/parquetschema/autoschema"} {
+		w.use(pkg)
+	}
+
+	seed := 0
+	value := w.literal(&fieldType{kind: kindStruct, structType: st}, &seed)
+
+	name := []rune(st.name)
+	name[0] = unicode.ToUpper(name[0])
+	reflectType := "reflect" + string(name)
+
+	w.printf(roundTripTestFormat, string(name), st.name, reflectType, value)
+}
+
+const roundTripTestFormat = `
+func Test%[1]sParquetMethods(t *testing.T) {
+	// %[3]s has no methods, so floor uses reflection for it.
+	type %[3]s %[2]s
+
+	value := %[4]s
+
+	sd, err := autoschema.GenerateSchema(&value)
+	if err != nil {
+		t.Fatalf("generating schema failed: %%v", err)
+	}
+
+	write := func(obj interface{}) []byte {
+		var buf bytes.Buffer
+		w := floor.NewWriter(goparquet.NewFileWriter(&buf, goparquet.WithSchemaDefinition(sd)))
+		if err := w.Write(obj); err != nil {
+			t.Fatalf("writing failed: %%v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("closing writer failed: %%v", err)
+		}
+		return buf.Bytes()
+	}
+
+	readRow := func(data []byte) map[string]interface{} {
+		fr, err := goparquet.NewFileReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("opening file failed: %%v", err)
+		}
+		row, err := fr.NextRow()
+		if err != nil {
+			t.Fatalf("reading row failed: %%v", err)
+		}
+		return row
+	}
+
+	read := func(data []byte, obj interface{}) {
+		fr, err := goparquet.NewFileReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("opening file failed: %%v", err)
+		}
+		r := floor.NewReader(fr)
+		if !r.Next() {
+			t.Fatalf("reading failed: %%v", r.Err())
+		}
+		if err := r.Scan(obj); err != nil {
+			t.Fatalf("scanning failed: %%v", err)
+		}
+	}
+
+	generated, reflected := write(&value), write((*%[3]s)(&value))
+	if got, want := readRow(generated), readRow(reflected); !reflect.DeepEqual(got, want) {
+		t.Errorf("MarshalParquet wrote %%v, expected %%v", got, want)
+	}
+
+	var got %[2]s
+	read(reflected, &got)
+	if !reflect.DeepEqual(got, value) {
+		t.Errorf("UnmarshalParquet read %%+v, expected %%+v", got, value)
+	}
+
+	var gotReflect %[3]s
+	read(generated, &gotReflect)
+	if !reflect.DeepEqual(%[2]s(gotReflect), value) {
+		t.Errorf("reading data written by MarshalParquet returned %%+v, expected %%+v", gotReflect, value)
+	}
+}
+`
+
+// literal returns an expression of a non-zero test value of type t. seed is used to generate
+// different values for different fields.
+func (w *codeWriter) literal(t *fieldType, seed *int) string {
+	if t.pointer {
+		elemType := *t
+		elemType.pointer = false
+		if t.kind == kindStruct {
+			return "&" + w.literal(&elemType, seed)
+		}
+		if t.base() == "big.Rat" {
+			return strings.TrimPrefix(w.literal(&elemType, seed), "*")
+		}
+		return fmt.Sprintf("func() *%s { v := %s; return &v }()", elemType.expr(), w.literal(&elemType, seed))
+	}
+
+	switch t.kind {
+	case kindStruct:
+		var sb strings.Builder
+		sb.WriteString(t.structType.name + "{\n")
+		for _, f := range t.structType.fields {
+			fmt.Fprintf(&sb, "%s: %s,\n", f.name, w.literal(f.typ, seed))
+		}
+		sb.WriteString("}")
+		return sb.String()
+	case kindList:
+		return fmt.Sprintf("%s{%s, %s}", t.expr(), w.literal(t.elemType, seed), w.literal(t.elemType, seed))
+	case kindMap:
+		return fmt.Sprintf("%s{%s: %s}", t.expr(), w.literal(t.keyType, seed), w.literal(t.valueType, seed))
+	}
+
+	*seed++
+	n := *seed%100 + 1
+	elem := t.elem
+
+	switch base := t.base(); {
+	case base == "time.Time":
+		w.use("time")
+		switch {
+		case elem.GetType() == parquet.Type_INT96:
+			return fmt.Sprintf("time.Unix(%d, 123).UTC()", 1600000000+n)
+		case isDate(elem):
+			return fmt.Sprintf("time.Date(2022, 1, %d, 0, 0, 0, 0, time.UTC)", n%28+1)
+		}
+		nsec := 123
+		unit, utc := timeUnit(elem)
+		switch unit {
+		case "millis":
+			nsec *= 1000000
+		case "micros":
+			nsec *= 1000
+		}
+		value := fmt.Sprintf("time.Unix(%d, %d)", 1600000000+n, nsec)
+		if utc {
+			value += ".UTC()"
+		}
+		return value
+	case base == "floor.Time":
+		value := fmt.Sprintf("floor.MustTime(floor.NewTime(%d, %d, %d, 0))", n%24, n%60, n%60)
+		if _, utc := timeUnit(elem); utc {
+			value += ".UTC()"
+		}
+		return value
+	case base == "big.Rat":
+		w.use("math/big")
+		// the value has at most two decimal places, and fewer digits than the precision allows.
+		precision, scale := decimalParams(elem)
+		places := scale
+		if places > 2 {
+			places = 2
+		}
+		limit, denom := 1, 1
+		for i := int32(0); i < precision-scale+places && limit < 1000; i++ {
+			limit *= 10
+		}
+		for i := int32(0); i < places; i++ {
+			denom *= 10
+		}
+		num := n % limit
+		if num == 0 {
+			num = 1
+		}
+		return fmt.Sprintf("*big.NewRat(%d, %d)", num, denom)
+	case base == "bool":
+		return convert(t.goType, "bool", "true")
+	case base == "string" || base == "[]byte":
+		s := fmt.Sprintf("value %d", n)
+		if elem.GetType() == parquet.Type_FIXED_LEN_BYTE_ARRAY {
+			s = strings.Repeat(string(rune('a'+n%26)), int(elem.GetTypeLength()))
+		}
+		if t.goType == "string" {
+			return strconv.Quote(s)
+		}
+		return fmt.Sprintf("%s(%q)", t.goType, s)
+	case strings.HasPrefix(base, "["):
+		return fmt.Sprintf("%s{%d}", t.goType, n)
+	case strings.HasPrefix(base, "float"):
+		return fmt.Sprintf("%s(%d.5)", t.goType, n)
+	}
+	return fmt.Sprintf("%s(%d)", t.goType, n)
+}
@@ -0,0 +1,387 @@
+package parquetschema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// ChangeKind is the kind of a change between two schema definitions.
+type ChangeKind int
+
+const (
+	// ColumnAdded means that the column only exists in the new schema definition.
+	ColumnAdded ChangeKind = iota + 1
+	// ColumnRemoved means that the column only exists in the old schema definition.
+	ColumnRemoved
+	// ColumnRenamed means that the column has the same field ID but a different name.
+	ColumnRenamed
+	// RepetitionChanged means that the repetition type of the column changed.
+	RepetitionChanged
+	// TypeChanged means that the physical type of the column changed, or that a group
+	// became a primitive column or vice versa.
+	TypeChanged
+	// LogicalTypeChanged means that the logical type or converted type of the column changed.
+	LogicalTypeChanged
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ColumnAdded:
+		return "ColumnAdded"
+	case ColumnRemoved:
+		return "ColumnRemoved"
+	case ColumnRenamed:
+		return "ColumnRenamed"
+	case RepetitionChanged:
+		return "RepetitionChanged"
+	case TypeChanged:
+		return "TypeChanged"
+	case LogicalTypeChanged:
+		return "LogicalTypeChanged"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change describes a difference between two schema definitions.
+type Change struct {
+	Kind ChangeKind
+
+	// Path of the column. For removed columns, this is the path in the old schema definition,
+	// otherwise it is the path in the new schema definition.
+	Path []string
+
+	// Old is the schema element of the column in the old schema definition, or nil if the
+	// column was added.
+	Old *parquet.SchemaElement
+
+	// New is the schema element of the column in the new schema definition, or nil if the
+	// column was removed.
+	New *parquet.SchemaElement
+}
+
+func (c Change) String() string {
+	path := strings.Join(c.Path, ".")
+
+	switch c.Kind {
+	case ColumnAdded:
+		return fmt.Sprintf("column %s added: %s %s", path, repetitionString(c.New), typeString(c.New))
+	case ColumnRemoved:
+		return fmt.Sprintf("column %s removed", path)
+	case ColumnRenamed:
+		return fmt.Sprintf("column %s renamed from %s", path, c.Old.GetName())
+	case RepetitionChanged:
+		return fmt.Sprintf("repetition of column %s changed from %s to %s", path, repetitionString(c.Old), repetitionString(c.New))
+	case TypeChanged:
+		return fmt.Sprintf("type of column %s changed from %s to %s", path, typeString(c.Old), typeString(c.New))
+	case LogicalTypeChanged:
+		return fmt.Sprintf("logical type of column %s changed from %s to %s", path, annotationString(c.Old), annotationString(c.New))
+	}
+	return fmt.Sprintf("%s of column %s", c.Kind, path)
+}
+
+func repetitionString(elem *parquet.SchemaElement) string {
+	return strings.ToLower(elem.GetRepetitionType().String())
+}
+
+func typeString(elem *parquet.SchemaElement) string {
+	if elem.Type == nil {
+		return "group"
+	}
+	return getSchemaType(elem)
+}
+
+func annotationString(elem *parquet.SchemaElement) string {
+	switch {
+	case elem.LogicalType != nil:
+		return getSchemaLogicalType(elem.LogicalType)
+	case elem.ConvertedType != nil:
+		return elem.GetConvertedType().String()
+	}
+	return "none"
+}
+
+// Diff returns the changes between the schema definitions old and new. Columns are matched by their
+// field ID if the column of the new schema definition has one, and by their name otherwise.
+// Columns that were added or removed are reported, but not their children.
+func Diff(old, new *SchemaDefinition) []Change {
+	var changes []Change
+	diffColumns(nil, rootChildren(old), rootChildren(new), &changes)
+	return changes
+}
+
+func rootChildren(sd *SchemaDefinition) []*ColumnDefinition {
+	if sd == nil || sd.RootColumn == nil {
+		return nil
+	}
+	return sd.RootColumn.Children
+}
+
+func diffColumns(path []string, oldCols, newCols []*ColumnDefinition, changes *[]Change) {
+	matched := make(map[*ColumnDefinition]bool)
+
+	for _, nc := range newCols {
+		ne := nc.SchemaElement
+		colPath := append(path[:len(path):len(path)], ne.GetName())
+
+		oc := FindMatchingColumn(oldCols, ne)
+		if oc == nil {
+			*changes = append(*changes, Change{Kind: ColumnAdded, Path: colPath, New: ne})
+			continue
+		}
+		matched[oc] = true
+		oe := oc.SchemaElement
+
+		change := Change{Path: colPath, Old: oe, New: ne}
+		if oe.GetName() != ne.GetName() {
+			change.Kind = ColumnRenamed
+			*changes = append(*changes, change)
+		}
+		if oe.GetRepetitionType() != ne.GetRepetitionType() {
+			change.Kind = RepetitionChanged
+			*changes = append(*changes, change)
+		}
+		if (oe.Type == nil) != (ne.Type == nil) || oe.GetType() != ne.GetType() || oe.GetTypeLength() != ne.GetTypeLength() {
+			change.Kind = TypeChanged
+			*changes = append(*changes, change)
+		}
+		if !equalAnnotations(oe, ne) {
+			change.Kind = LogicalTypeChanged
+			*changes = append(*changes, change)
+		}
+
+		if oe.Type == nil && ne.Type == nil {
+			diffColumns(colPath, oc.Children, nc.Children, changes)
+		}
+	}
+
+	for _, oc := range oldCols {
+		if !matched[oc] {
+			*changes = append(*changes, Change{Kind: ColumnRemoved, Path: append(path[:len(path):len(path)], oc.SchemaElement.GetName()), Old: oc.SchemaElement})
+		}
+	}
+}
+
+// equalAnnotations compares the logical types of two elements if both have one, and their converted
+// types otherwise.
+func equalAnnotations(a, b *parquet.SchemaElement) bool {
+	if a.LogicalType != nil && b.LogicalType != nil {
+		return a.LogicalType.Equals(b.LogicalType)
+	}
+	if a.ConvertedType != nil && b.ConvertedType != nil {
+		return *a.ConvertedType == *b.ConvertedType
+	}
+	return !hasAnnotation(a) && !hasAnnotation(b)
+}
+
+// FindMatchingColumn returns the column of cols that matches elem. Columns are matched by their
+// field ID if elem has one, and by their name otherwise. Columns without a field ID are matched by
+// their name as well, but a column with the same name and a different field ID doesn't match.
+// If no column matches, nil is returned.
+func FindMatchingColumn(cols []*ColumnDefinition, elem *parquet.SchemaElement) *ColumnDefinition {
+	if elem.FieldID != nil {
+		for _, c := range cols {
+			if c.SchemaElement.FieldID != nil && *c.SchemaElement.FieldID == *elem.FieldID {
+				return c
+			}
+		}
+	}
+
+	for _, c := range cols {
+		if c.SchemaElement.GetName() != elem.GetName() {
+			continue
+		}
+		if elem.FieldID != nil && c.SchemaElement.FieldID != nil {
+			return nil
+		}
+		return c
+	}
+
+	return nil
+}
+
+// CompatibilityMode determines in which direction CheckCompatibility checks two schema definitions.
+type CompatibilityMode int
+
+const (
+	// BackwardCompatible checks that data written using the writer schema definition can be read
+	// using the reader schema definition.
+	BackwardCompatible CompatibilityMode = iota + 1
+	// ForwardCompatible checks that data written using the reader schema definition can be read
+	// using the writer schema definition.
+	ForwardCompatible
+	// FullCompatible checks that the schema definitions are both backward and forward compatible.
+	FullCompatible
+)
+
+func (m CompatibilityMode) String() string {
+	switch m {
+	case BackwardCompatible:
+		return "backward"
+	case ForwardCompatible:
+		return "forward"
+	case FullCompatible:
+		return "full"
+	}
+	return fmt.Sprintf("CompatibilityMode(%d)", int(m))
+}
+
+// CompatibilityError is returned by CheckCompatibility if two schema definitions are not compatible.
+type CompatibilityError struct {
+	Mode CompatibilityMode
+
+	// Changes that break compatibility.
+	Changes []Change
+}
+
+func (e *CompatibilityError) Error() string {
+	msgs := make([]string, 0, len(e.Changes))
+	for _, c := range e.Changes {
+		msgs = append(msgs, c.String())
+	}
+	return fmt.Sprintf("schemas are not %s compatible: %s", e.Mode, strings.Join(msgs, "; "))
+}
+
+// CheckCompatibility checks whether the reader and writer schema definitions are compatible
+// according to mode, and returns a *CompatibilityError with all changes that break compatibility
+// if they are not. Data can be read using a different schema definition if
+//
+//   - all required columns of the reading schema definition exist in the written data,
+//   - no optional column of the written data is read as a required column,
+//   - no repeated column is read as a non-repeated column or vice versa,
+//   - all columns have the same type, except for INT32 that is read as INT64, and FLOAT that
+//     is read as DOUBLE,
+//   - and all columns have the same logical type, except for integers that are read as integers
+//     of the same signedness and a larger bit width. Adding or removing a logical type, for example
+//     STRING or DECIMAL, breaks compatibility as well.
+//
+// Columns are matched by their field ID if the reading schema definition has one, and by their
+// name otherwise. Columns that are only part of the written data are ignored.
+func CheckCompatibility(reader, writer *SchemaDefinition, mode CompatibilityMode) error {
+	var changes []Change
+
+	switch mode {
+	case BackwardCompatible:
+		changes = incompatibleChanges(writer, reader)
+	case ForwardCompatible:
+		changes = incompatibleChanges(reader, writer)
+	case FullCompatible:
+		changes = append(incompatibleChanges(writer, reader), incompatibleChanges(reader, writer)...)
+	default:
+		return fmt.Errorf("invalid compatibility mode %d", int(mode))
+	}
+
+	if len(changes) > 0 {
+		return &CompatibilityError{Mode: mode, Changes: changes}
+	}
+
+	return nil
+}
+
+// incompatibleChanges returns the changes that prevent data written using the schema definition
+// written from being read using the schema definition read.
+func incompatibleChanges(written, read *SchemaDefinition) []Change {
+	var incompatible []Change
+	for _, c := range Diff(written, read) {
+		if !isCompatibleChange(c) {
+			incompatible = append(incompatible, c)
+		}
+	}
+	return incompatible
+}
+
+func isCompatibleChange(c Change) bool {
+	switch c.Kind {
+	case ColumnAdded:
+		return c.New.GetRepetitionType() != parquet.FieldRepetitionType_REQUIRED
+	case ColumnRemoved, ColumnRenamed:
+		return true
+	case RepetitionChanged:
+		return c.Old.GetRepetitionType() == parquet.FieldRepetitionType_REQUIRED && c.New.GetRepetitionType() == parquet.FieldRepetitionType_OPTIONAL
+	case TypeChanged:
+		if c.Old.Type == nil || c.New.Type == nil {
+			return false
+		}
+		switch {
+		case c.Old.GetType() == parquet.Type_INT32 && c.New.GetType() == parquet.Type_INT64:
+			return isSignedInteger(c.Old) && isSignedInteger(c.New)
+		case c.Old.GetType() == parquet.Type_FLOAT && c.New.GetType() == parquet.Type_DOUBLE:
+			return true
+		}
+		return false
+	case LogicalTypeChanged:
+		oldWidth, oldSigned, ok := integerType(c.Old)
+		if !ok {
+			return false
+		}
+		newWidth, newSigned, ok := integerType(c.New)
+		return ok && oldSigned == newSigned && oldWidth <= newWidth
+	}
+	return false
+}
+
+// integerType returns the bit width and signedness of an INT32 or INT64 element that has no logical
+// type or an integer logical type. If the element isn't an integer, ok is false.
+func integerType(elem *parquet.SchemaElement) (bitWidth int, signed bool, ok bool) {
+	switch {
+	case elem.Type == nil:
+		return 0, false, false
+	case elem.GetType() == parquet.Type_INT32:
+		bitWidth = 32
+	case elem.GetType() == parquet.Type_INT64:
+		bitWidth = 64
+	default:
+		return 0, false, false
+	}
+
+	if elem.LogicalType != nil {
+		if !elem.LogicalType.IsSetINTEGER() {
+			return 0, false, false
+		}
+		return int(elem.LogicalType.INTEGER.BitWidth), elem.LogicalType.INTEGER.IsSigned, true
+	}
+
+	if elem.ConvertedType != nil {
+		switch elem.GetConvertedType() {
+		case parquet.ConvertedType_INT_8:
+			return 8, true, true
+		case parquet.ConvertedType_INT_16:
+			return 16, true, true
+		case parquet.ConvertedType_INT_32:
+			return 32, true, true
+		case parquet.ConvertedType_INT_64:
+			return 64, true, true
+		case parquet.ConvertedType_UINT_8:
+			return 8, false, true
+		case parquet.ConvertedType_UINT_16:
+			return 16, false, true
+		case parquet.ConvertedType_UINT_32:
+			return 32, false, true
+		case parquet.ConvertedType_UINT_64:
+			return 64, false, true
+		}
+		return 0, false, false
+	}
+
+	return bitWidth, true, true
+}
+
+func hasAnnotation(elem *parquet.SchemaElement) bool {
+	return elem.LogicalType != nil || elem.ConvertedType != nil
+}
+
+// isSignedInteger returns true if the element has no logical type or a signed integer logical type.
+func isSignedInteger(elem *parquet.SchemaElement) bool {
+	if elem.LogicalType != nil {
+		return elem.LogicalType.IsSetINTEGER() && elem.LogicalType.INTEGER.IsSigned
+	}
+	if elem.ConvertedType != nil {
+		switch elem.GetConvertedType() {
+		case parquet.ConvertedType_INT_8, parquet.ConvertedType_INT_16, parquet.ConvertedType_INT_32, parquet.ConvertedType_INT_64:
+			return true
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,246 @@
+package parquetschema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func changeStrings(changes []Change) []string {
+	var ret []string
+	for _, c := range changes {
+		ret = append(ret, c.String())
+	}
+	return ret
+}
+
+func TestDiff(t *testing.T) {
+	oldSchema, err := ParseSchemaDefinition(`message test {
+		required int64 id = 1;
+		required binary name (STRING);
+		optional int32 count;
+		optional group address {
+			required binary city (STRING);
+			optional binary zip;
+		}
+		optional float score;
+		repeated int32 values;
+		optional binary removed;
+	}`)
+	require.NoError(t, err)
+
+	newSchema, err := ParseSchemaDefinition(`message test {
+		required int64 ident = 1;
+		optional binary name (STRING);
+		optional int64 count;
+		optional group address {
+			required binary city (STRING);
+			optional binary zip (STRING);
+			optional binary country (STRING);
+		}
+		optional binary score;
+		optional int32 values;
+		optional group added {
+			required int32 a;
+		}
+	}`)
+	require.NoError(t, err)
+
+	changes := Diff(oldSchema, newSchema)
+	require.Equal(t, []string{
+		"column ident renamed from id",
+		"repetition of column name changed from required to optional",
+		"type of column count changed from int32 to int64",
+		"logical type of column address.zip changed from none to STRING",
+		"column address.country added: optional binary",
+		"type of column score changed from float to binary",
+		"repetition of column values changed from repeated to optional",
+		"column added added: optional group",
+		"column removed removed",
+	}, changeStrings(changes))
+
+	require.Equal(t, ColumnRenamed, changes[0].Kind)
+	require.Equal(t, []string{"ident"}, changes[0].Path)
+	require.Equal(t, "id", changes[0].Old.Name)
+	require.Equal(t, []string{"address", "country"}, changes[4].Path)
+	require.Nil(t, changes[4].Old)
+	require.Nil(t, changes[8].New)
+
+	require.Empty(t, Diff(oldSchema, oldSchema))
+	require.Len(t, Diff(nil, oldSchema), 7)
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	testData := []struct {
+		name     string
+		old      string
+		new      string
+		backward bool
+		forward  bool
+	}{
+		{
+			name:     "identical",
+			old:      `message test { required int64 id; }`,
+			new:      `message test { required int64 id; }`,
+			backward: true,
+			forward:  true,
+		},
+		{
+			name:     "optional column added",
+			old:      `message test { required int64 id; }`,
+			new:      `message test { required int64 id; optional binary name; }`,
+			backward: true,
+			forward:  true,
+		},
+		{
+			name:     "required column added",
+			old:      `message test { required int64 id; }`,
+			new:      `message test { required int64 id; required binary name; }`,
+			backward: false,
+			forward:  true,
+		},
+		{
+			name:     "required column made optional",
+			old:      `message test { required int64 id; }`,
+			new:      `message test { optional int64 id; }`,
+			backward: true,
+			forward:  false,
+		},
+		{
+			name:     "int32 promoted to int64",
+			old:      `message test { required int32 id (INT(32, true)); }`,
+			new:      `message test { required int64 id (INT(64, true)); }`,
+			backward: true,
+			forward:  false,
+		},
+		{
+			name:     "float promoted to double",
+			old:      `message test { repeated float values; }`,
+			new:      `message test { repeated double values; }`,
+			backward: true,
+			forward:  false,
+		},
+		{
+			name:     "date changed to int64",
+			old:      `message test { required int32 day (DATE); }`,
+			new:      `message test { required int64 day; }`,
+			backward: false,
+			forward:  false,
+		},
+		{
+			name:     "logical type changed",
+			old:      `message test { required binary data (JSON); }`,
+			new:      `message test { required binary data (BSON); }`,
+			backward: false,
+			forward:  false,
+		},
+		{
+			name:     "logical type added",
+			old:      `message test { required binary data; }`,
+			new:      `message test { required binary data (STRING); }`,
+			backward: false,
+			forward:  false,
+		},
+		{
+			name:     "logical type removed",
+			old:      `message test { required binary data (STRING); }`,
+			new:      `message test { required binary data; }`,
+			backward: false,
+			forward:  false,
+		},
+		{
+			name:     "integer narrowed",
+			old:      `message test { required int32 id (INT(32, true)); }`,
+			new:      `message test { required int32 id (INT(8, true)); }`,
+			backward: false,
+			forward:  true,
+		},
+		{
+			name:     "integer without logical type widened",
+			old:      `message test { required int32 id (INT(16, true)); }`,
+			new:      `message test { required int32 id; }`,
+			backward: true,
+			forward:  false,
+		},
+		{
+			name:     "integer signedness changed",
+			old:      `message test { required int32 id (INT(8, false)); }`,
+			new:      `message test { required int32 id (INT(16, true)); }`,
+			backward: false,
+			forward:  false,
+		},
+		{
+			name:     "decimal added",
+			old:      `message test { required int32 price; }`,
+			new:      `message test { required int32 price (DECIMAL(9, 2)); }`,
+			backward: false,
+			forward:  false,
+		},
+		{
+			name:     "timestamp added",
+			old:      `message test { required int64 ts; }`,
+			new:      `message test { required int64 ts (TIMESTAMP(MILLIS, true)); }`,
+			backward: false,
+			forward:  false,
+		},
+		{
+			name:     "repeated column made optional",
+			old:      `message test { repeated int64 id; }`,
+			new:      `message test { optional int64 id; }`,
+			backward: false,
+			forward:  false,
+		},
+		{
+			name:     "column renamed using field ID",
+			old:      `message test { required int64 id = 1; }`,
+			new:      `message test { required int64 ident = 1; }`,
+			backward: true,
+			forward:  true,
+		},
+		{
+			name:     "nested required column added",
+			old:      `message test { optional group a { required int64 b; } }`,
+			new:      `message test { optional group a { required int64 b; required int64 c; } }`,
+			backward: false,
+			forward:  true,
+		},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			oldSchema, err := ParseSchemaDefinition(tt.old)
+			require.NoError(t, err)
+			newSchema, err := ParseSchemaDefinition(tt.new)
+			require.NoError(t, err)
+
+			err = CheckCompatibility(newSchema, oldSchema, BackwardCompatible)
+			require.Equal(t, tt.backward, err == nil, "backward: %v", err)
+
+			err = CheckCompatibility(newSchema, oldSchema, ForwardCompatible)
+			require.Equal(t, tt.forward, err == nil, "forward: %v", err)
+
+			err = CheckCompatibility(newSchema, oldSchema, FullCompatible)
+			require.Equal(t, tt.backward && tt.forward, err == nil, "full: %v", err)
+		})
+	}
+}
+
+func TestCompatibilityError(t *testing.T) {
+	oldSchema, err := ParseSchemaDefinition(`message test { optional int64 id; required binary name; }`)
+	require.NoError(t, err)
+	newSchema, err := ParseSchemaDefinition(`message test { required int64 id; required binary name; required int32 added; }`)
+	require.NoError(t, err)
+
+	err = CheckCompatibility(newSchema, oldSchema, BackwardCompatible)
+	var compatErr *CompatibilityError
+	require.True(t, errors.As(err, &compatErr))
+	require.Equal(t, BackwardCompatible, compatErr.Mode)
+	require.Equal(t, []string{
+		"repetition of column id changed from optional to required",
+		"column added added: required int32",
+	}, changeStrings(compatErr.Changes))
+	require.Equal(t, "schemas are not backward compatible: repetition of column id changed from optional to required; column added added: required int32", err.Error())
+
+	require.Error(t, CheckCompatibility(newSchema, oldSchema, CompatibilityMode(0)))
+}
@@ -0,0 +1,260 @@
+package parquetschema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// MergeError is returned by Merge if a column of a schema definition conflicts with the columns
+// of the schema definitions before it.
+type MergeError struct {
+	// Index of the schema definition that contains the conflicting column.
+	Index int
+
+	// Path of the conflicting column.
+	Path []string
+
+	// Reason describes the conflict.
+	Reason string
+}
+
+func (e *MergeError) Error() string {
+	return fmt.Sprintf("schema definition %d: column %s: %s", e.Index, strings.Join(e.Path, "."), e.Reason)
+}
+
+// Merge merges the schema definitions defs into a single schema definition that contains the
+// columns of all of them. Groups, including LISTs and MAPs, are merged recursively, and columns are
+// matched by their field ID if it is set, and by their name otherwise. As their names differ between
+// writers, the repeated groups of LISTs and MAPs, the elements of LISTs and the keys and values of
+// MAPs are matched by their position, and keep the names of the first schema definition. The schema
+// definition returned can be used to read data that was written using any of the merged schema
+// definitions:
+//
+//   - columns that are not part of all schema definitions, and required columns that are
+//     optional in any of them, become optional,
+//   - INT32 columns become INT64 columns and FLOAT columns become DOUBLE columns if any of the
+//     schema definitions uses the wider type,
+//   - integer columns get the largest bit width of all schema definitions, if they all have the
+//     same signedness.
+//
+// The name of the root column is the name of the root column of the first schema definition.
+// If the schema definitions contain conflicting columns, for example columns of different types or
+// a repeated and a non-repeated column, columns with different logical types, or columns of the same
+// group with the same field ID, a *MergeError is returned.
+func Merge(defs ...*SchemaDefinition) (*SchemaDefinition, error) {
+	if len(defs) == 0 {
+		return nil, errors.New("no schema definitions to merge")
+	}
+
+	var merged *ColumnDefinition
+	for i, sd := range defs {
+		if sd == nil || sd.RootColumn == nil {
+			return nil, fmt.Errorf("schema definition %d is empty", i)
+		}
+
+		if merged == nil {
+			if err := checkFieldIDs(i, nil, sd.RootColumn.Children); err != nil {
+				return nil, err
+			}
+			merged = copyColumnDefinition(sd.RootColumn)
+			continue
+		}
+
+		children, err := mergeColumns(i, nil, merged.Children, sd.RootColumn.Children)
+		if err != nil {
+			return nil, err
+		}
+		merged.Children = children
+		merged.SchemaElement.NumChildren = int32Ptr(int32(len(children)))
+	}
+
+	return &SchemaDefinition{RootColumn: merged}, nil
+}
+
+func copyColumnDefinition(col *ColumnDefinition) *ColumnDefinition {
+	elem := *col.SchemaElement
+	c := &ColumnDefinition{SchemaElement: &elem}
+	for _, child := range col.Children {
+		c.Children = append(c.Children, copyColumnDefinition(child))
+	}
+	return c
+}
+
+func mergeColumns(idx int, path []string, merged, cols []*ColumnDefinition) ([]*ColumnDefinition, error) {
+	found := make(map[*ColumnDefinition]bool)
+
+	for _, col := range cols {
+		name := col.SchemaElement.GetName()
+		colPath := append(path[:len(path):len(path)], name)
+
+		m := FindMatchingColumn(merged, col.SchemaElement)
+		if m == nil {
+			// no column matches if a column with the same name has a different field ID.
+			for _, c := range merged {
+				if c.SchemaElement.GetName() == name {
+					return nil, &MergeError{Index: idx, Path: colPath, Reason: fmt.Sprintf("conflicting field IDs %d and %d", c.SchemaElement.GetFieldID(), col.SchemaElement.GetFieldID())}
+				}
+			}
+		}
+
+		if m != nil && found[m] {
+			if id := col.SchemaElement.FieldID; id != nil && m.SchemaElement.FieldID != nil && *id == *m.SchemaElement.FieldID {
+				return nil, &MergeError{Index: idx, Path: colPath, Reason: fmt.Sprintf("duplicate field ID %d", *id)}
+			}
+			return nil, &MergeError{Index: idx, Path: colPath, Reason: "duplicate column name"}
+		}
+
+		if m == nil {
+			c := copyColumnDefinition(col)
+			widenRepetition(c.SchemaElement)
+			merged = append(merged, c)
+			found[c] = true
+			continue
+		}
+		found[m] = true
+
+		if err := mergeColumn(idx, colPath, m, col, false); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range merged {
+		if !found[c] {
+			widenRepetition(c.SchemaElement)
+		}
+	}
+
+	return merged, nil
+}
+
+// mergeColumn merges the column col into the column merged. If byPosition is true, the children of
+// the groups are matched by their position instead of their field ID or name.
+func mergeColumn(idx int, path []string, merged, col *ColumnDefinition, byPosition bool) error {
+	me, ce := merged.SchemaElement, col.SchemaElement
+
+	conflict := func(format string, args ...interface{}) error {
+		return &MergeError{Index: idx, Path: path, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if me.FieldID != nil && ce.FieldID != nil && *me.FieldID != *ce.FieldID {
+		return conflict("conflicting field IDs %d and %d", *me.FieldID, *ce.FieldID)
+	}
+	if me.FieldID == nil {
+		me.FieldID = ce.FieldID
+	}
+
+	mr, cr := me.GetRepetitionType(), ce.GetRepetitionType()
+	switch {
+	case mr == cr:
+	case mr == parquet.FieldRepetitionType_REPEATED || cr == parquet.FieldRepetitionType_REPEATED:
+		return conflict("conflicting repetition types %s and %s", repetitionString(me), repetitionString(ce))
+	default:
+		widenRepetition(me)
+	}
+
+	if (me.Type == nil) != (ce.Type == nil) {
+		return conflict("conflicting types %s and %s", typeString(me), typeString(ce))
+	}
+
+	if me.Type != nil && (me.GetType() != ce.GetType() || me.GetTypeLength() != ce.GetTypeLength()) {
+		switch {
+		case me.GetType() == parquet.Type_INT32 && ce.GetType() == parquet.Type_INT64 && isSignedInteger(me) && isSignedInteger(ce),
+			me.GetType() == parquet.Type_FLOAT && ce.GetType() == parquet.Type_DOUBLE:
+			me.Type = ce.Type
+		case me.GetType() == parquet.Type_INT64 && ce.GetType() == parquet.Type_INT32 && isSignedInteger(me) && isSignedInteger(ce),
+			me.GetType() == parquet.Type_DOUBLE && ce.GetType() == parquet.Type_FLOAT:
+		default:
+			return conflict("conflicting types %s and %s", typeString(me), typeString(ce))
+		}
+	}
+
+	if !equalAnnotations(me, ce) {
+		mw, ms, mok := integerType(me)
+		cw, cs, cok := integerType(ce)
+		if !mok || !cok || ms != cs {
+			return conflict("conflicting logical types %s and %s", annotationString(me), annotationString(ce))
+		}
+		if mw < cw {
+			me.LogicalType, me.ConvertedType = ce.LogicalType, ce.ConvertedType
+		}
+	}
+
+	if me.Type != nil {
+		return nil
+	}
+
+	switch {
+	case isList(me) || isMap(me):
+		if len(merged.Children) != 1 || len(col.Children) != 1 {
+			return conflict("%s groups need to contain exactly one repeated field", annotationString(me))
+		}
+		// the element of a LIST is only matched by its position if both repeated groups contain
+		// nothing else. If both contain several fields, the repeated groups are the elements, and
+		// their fields are matched by name.
+		m, c := merged.Children[0], col.Children[0]
+		if isList(me) && (len(m.Children) == 1) != (len(c.Children) == 1) {
+			return conflict("conflicting LIST layouts")
+		}
+		elementsByPosition := isMap(me) || len(m.Children) == 1
+		return mergeColumn(idx, append(path[:len(path):len(path)], c.SchemaElement.GetName()), m, c, elementsByPosition)
+	case byPosition:
+		if len(merged.Children) != len(col.Children) {
+			return conflict("conflicting number of fields %d and %d", len(merged.Children), len(col.Children))
+		}
+		for i, c := range col.Children {
+			if err := mergeColumn(idx, append(path[:len(path):len(path)], c.SchemaElement.GetName()), merged.Children[i], c, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	children, err := mergeColumns(idx, path, merged.Children, col.Children)
+	if err != nil {
+		return err
+	}
+	merged.Children = children
+	me.NumChildren = int32Ptr(int32(len(children)))
+
+	return nil
+}
+
+// checkFieldIDs returns a *MergeError if two columns of the same group have the same field ID.
+func checkFieldIDs(idx int, path []string, cols []*ColumnDefinition) error {
+	ids := make(map[int32]bool)
+	for _, col := range cols {
+		colPath := append(path[:len(path):len(path)], col.SchemaElement.GetName())
+		if id := col.SchemaElement.FieldID; id != nil {
+			if ids[*id] {
+				return &MergeError{Index: idx, Path: colPath, Reason: fmt.Sprintf("duplicate field ID %d", *id)}
+			}
+			ids[*id] = true
+		}
+		if err := checkFieldIDs(idx, colPath, col.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isList(elem *parquet.SchemaElement) bool {
+	return (elem.LogicalType != nil && elem.GetLogicalType().IsSetLIST()) || elem.GetConvertedType() == parquet.ConvertedType_LIST
+}
+
+func isMap(elem *parquet.SchemaElement) bool {
+	return (elem.LogicalType != nil && elem.GetLogicalType().IsSetMAP()) || elem.GetConvertedType() == parquet.ConvertedType_MAP || elem.GetConvertedType() == parquet.ConvertedType_MAP_KEY_VALUE
+}
+
+// widenRepetition makes a required column optional.
+func widenRepetition(elem *parquet.SchemaElement) {
+	if elem.GetRepetitionType() == parquet.FieldRepetitionType_REQUIRED {
+		elem.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+	}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
@@ -0,0 +1,268 @@
+package parquetschema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	testData := []struct {
+		name     string
+		defs     []string
+		expected string
+	}{
+		{
+			name: "added and removed columns",
+			defs: []string{
+				`message test { required int64 id; required binary name (STRING); repeated int32 values; }`,
+				`message test { required int64 id; required double score; }`,
+			},
+			expected: `message test {
+  required int64 id;
+  optional binary name (STRING);
+  repeated int32 values;
+  optional double score;
+}
+`,
+		},
+		{
+			name: "widened repetition and types",
+			defs: []string{
+				`message test { required int32 id (INT(32, true)); optional float score; required int32 small (INT(8, true)); }`,
+				`message test { optional int64 id (INT(64, true)); required double score; required int32 small (INT(16, true)); }`,
+				`message test { required int32 id; required float score; required int32 small (INT(8, true)); }`,
+			},
+			expected: `message test {
+  optional int64 id (INT(64, true));
+  optional double score;
+  required int32 small (INT(16, true));
+}
+`,
+		},
+		{
+			name: "nested groups",
+			defs: []string{
+				`message test { optional group address { required binary city (STRING); } }`,
+				`message test { required group address { required binary city (STRING); required binary zip (STRING); } }`,
+			},
+			expected: `message test {
+  optional group address {
+    required binary city (STRING);
+    optional binary zip (STRING);
+  }
+}
+`,
+		},
+		{
+			name: "lists and maps",
+			defs: []string{
+				`message test {
+					optional group tags (LIST) { repeated group list { required binary element (STRING); } }
+					optional group attrs (MAP) { repeated group key_value { required binary key (STRING); required int32 value; } }
+				}`,
+				`message test {
+					required group tags (LIST) { repeated group list { optional binary element (STRING); } }
+					optional group attrs (MAP) { repeated group key_value { required binary key (STRING); optional int64 value; } }
+					optional group points (LIST) { repeated group list { required group element { required double x; required double y; } } }
+				}`,
+				`message test {
+					optional group points (LIST) { repeated group list { required group element { required double x; required double z; } } }
+				}`,
+			},
+			expected: `message test {
+  optional group tags (LIST) {
+    repeated group list {
+      optional binary element (STRING);
+    }
+  }
+  optional group attrs (MAP) {
+    repeated group key_value {
+      required binary key (STRING);
+      optional int64 value;
+    }
+  }
+  optional group points (LIST) {
+    repeated group list {
+      required group element {
+        required double x;
+        optional double y;
+        optional double z;
+      }
+    }
+  }
+}
+`,
+		},
+		{
+			name: "legacy list and map layouts",
+			defs: []string{
+				`message test {
+					optional group tags (LIST) { repeated group list { required binary element (STRING); } }
+					optional group attrs (MAP) { repeated group key_value { required binary key (STRING); required int32 value; } }
+				}`,
+				`message test {
+					optional group tags (LIST) { repeated group bag { optional binary array_element (STRING); } }
+					optional group attrs (MAP) { repeated group map { required binary k (STRING); optional int32 v; } }
+				}`,
+			},
+			expected: `message test {
+  optional group tags (LIST) {
+    repeated group list {
+      optional binary element (STRING);
+    }
+  }
+  optional group attrs (MAP) {
+    repeated group key_value {
+      required binary key (STRING);
+      optional int32 value;
+    }
+  }
+}
+`,
+		},
+		{
+			name: "field IDs",
+			defs: []string{
+				`message test { required int64 id = 1; optional binary name (STRING) = 2; }`,
+				`message test { required int64 ident = 1; optional binary label (STRING) = 3; }`,
+			},
+			expected: `message test {
+  required int64 id = 1;
+  optional binary name (STRING) = 2;
+  optional binary label (STRING) = 3;
+}
+`,
+		},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			var defs []*SchemaDefinition
+			for _, d := range tt.defs {
+				sd, err := ParseSchemaDefinition(d)
+				require.NoError(t, err)
+				defs = append(defs, sd)
+			}
+			original := defs[0].String()
+
+			merged, err := Merge(defs...)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, merged.String())
+			require.NoError(t, merged.ValidateStrict())
+			require.Equal(t, original, defs[0].String(), "merging modified the first schema definition")
+
+			for _, sd := range defs {
+				require.NoError(t, CheckCompatibility(merged, sd, BackwardCompatible))
+			}
+		})
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	testData := []struct {
+		name   string
+		defs   []string
+		path   []string
+		reason string
+	}{
+		{
+			name:   "different types",
+			defs:   []string{`message test { required int64 id; }`, `message test { required binary id; }`},
+			path:   []string{"id"},
+			reason: "conflicting types int64 and binary",
+		},
+		{
+			name:   "group and primitive",
+			defs:   []string{`message test { optional group a { required int64 b; } }`, `message test { optional int64 a; }`},
+			path:   []string{"a"},
+			reason: "conflicting types group and int64",
+		},
+		{
+			name:   "repeated and optional",
+			defs:   []string{`message test { repeated int64 id; }`, `message test { optional int64 id; }`},
+			path:   []string{"id"},
+			reason: "conflicting repetition types repeated and optional",
+		},
+		{
+			name:   "logical types",
+			defs:   []string{`message test { required binary data (JSON); }`, `message test { required binary data (BSON); }`},
+			path:   []string{"data"},
+			reason: "conflicting logical types JSON and BSON",
+		},
+		{
+			name:   "logical type added",
+			defs:   []string{`message test { required binary data; }`, `message test { required binary data (STRING); }`},
+			path:   []string{"data"},
+			reason: "conflicting logical types none and STRING",
+		},
+		{
+			name:   "integer signedness",
+			defs:   []string{`message test { required int32 id (INT(8, false)); }`, `message test { required int32 id (INT(16, true)); }`},
+			path:   []string{"id"},
+			reason: "conflicting logical types INT(8, false) and INT(16, true)",
+		},
+		{
+			name: "nested in list",
+			defs: []string{
+				`message test { optional group l (LIST) { repeated group list { required int32 element (INT(32, true)); } } }`,
+				`message test { optional int64 other; }`,
+				`message test { optional group l (LIST) { repeated group list { required int32 element (DATE); } } }`,
+			},
+			path:   []string{"l", "list", "element"},
+			reason: "conflicting logical types INT(32, true) and DATE",
+		},
+		{
+			name:   "field IDs",
+			defs:   []string{`message test { required int64 id = 1; }`, `message test { required int64 id = 2; }`},
+			path:   []string{"id"},
+			reason: "conflicting field IDs 1 and 2",
+		},
+		{
+			name:   "duplicate field IDs",
+			defs:   []string{`message test { required int64 id = 1; }`, `message test { required int64 a = 1; required int64 b = 1; }`},
+			path:   []string{"b"},
+			reason: "duplicate field ID 1",
+		},
+		{
+			name:   "duplicate field IDs in the first schema definition",
+			defs:   []string{`message test { required int64 a = 1; required int64 b = 1; }`},
+			path:   []string{"b"},
+			reason: "duplicate field ID 1",
+		},
+		{
+			name: "list layouts",
+			defs: []string{
+				`message test { optional group l (LIST) { repeated group list { required group element { required int32 a; required int32 b; } } } }`,
+				`message test { optional group l (LIST) { repeated group array { required int32 a; required int32 b; } } }`,
+			},
+			path:   []string{"l"},
+			reason: "conflicting LIST layouts",
+		},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			var defs []*SchemaDefinition
+			for _, d := range tt.defs {
+				sd, err := ParseSchemaDefinition(d)
+				require.NoError(t, err)
+				defs = append(defs, sd)
+			}
+
+			_, err := Merge(defs...)
+			var mergeErr *MergeError
+			require.True(t, errors.As(err, &mergeErr), "unexpected error %v", err)
+			require.Equal(t, len(defs)-1, mergeErr.Index)
+			require.Equal(t, tt.path, mergeErr.Path)
+			require.Equal(t, tt.reason, mergeErr.Reason)
+		})
+	}
+
+	_, err := Merge()
+	require.Error(t, err)
+
+	_, err = Merge(&SchemaDefinition{}, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,243 @@
+package goparquet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// defaultMaxReadGap is the default maximum gap between the byte ranges of two column chunks that
+// are still read at once by readers created with NewFileReaderAt.
+const defaultMaxReadGap = 1 << 20
+
+// byteRange is a range of bytes in a file.
+type byteRange struct {
+	offset int64
+	length int64
+}
+
+func (r byteRange) end() int64 {
+	return r.offset + r.length
+}
+
+// chunkByteRange returns the byte range of all pages of a column chunk.
+func chunkByteRange(chunk *parquet.ColumnChunk) (byteRange, error) {
+	if chunk.MetaData == nil {
+		return byteRange{}, errors.New("missing meta data for column chunk")
+	}
+
+	offset := chunk.MetaData.DataPageOffset
+	if chunk.MetaData.DictionaryPageOffset != nil {
+		offset = *chunk.MetaData.DictionaryPageOffset
+	}
+
+	return byteRange{offset: offset, length: chunk.MetaData.TotalCompressedSize}, nil
+}
+
+// coalesceRanges sorts the byte ranges and merges all ranges that overlap or are at most maxGap
+// bytes apart, so that fewer but larger reads are required to read all ranges.
+func coalesceRanges(ranges []byteRange, maxGap int64) []byteRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sorted := make([]byteRange, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].offset < sorted[j].offset
+	})
+
+	merged := []byteRange{sorted[0]}
+	for _, r := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if r.offset-last.end() > maxGap {
+			merged = append(merged, r)
+			continue
+		}
+		if r.end() > last.end() {
+			last.length = r.end() - last.offset
+		}
+	}
+
+	return merged
+}
+
+// fetchChunkRanges reads the byte ranges of the column chunks of all tasks from ra, coalescing
+// ranges that are at most maxGap bytes apart, and sets the reader of every task to the data
+// that was read.
+func fetchChunkRanges(ra io.ReaderAt, size int64, maxGap int64, tasks []chunkReadTask) error {
+	ranges := make([]byteRange, len(tasks))
+	for i, t := range tasks {
+		r, err := chunkByteRange(t.chunk)
+		if err != nil {
+			return fmt.Errorf("column %q: %w", t.col.FlatName(), err)
+		}
+		if r.offset < 0 || r.length < 0 || r.end() > size {
+			return fmt.Errorf("column %q: column chunk at offset %d with size %d is out of file bounds", t.col.FlatName(), r.offset, r.length)
+		}
+		ranges[i] = r
+	}
+
+	merged := coalesceRanges(ranges, maxGap)
+	buffers := make([][]byte, len(merged))
+	for i, r := range merged {
+		buf := make([]byte, r.length)
+		if n, err := ra.ReadAt(buf, r.offset); n < len(buf) {
+			return fmt.Errorf("reading %d bytes at offset %d failed: %w", r.length, r.offset, err)
+		}
+		buffers[i] = buf
+	}
+
+	for i, r := range ranges {
+		j := sort.Search(len(merged), func(j int) bool {
+			return merged[j].end() >= r.end()
+		})
+		tasks[i].r = &rangeBuffer{offset: merged[j].offset, data: buffers[j]}
+	}
+
+	return nil
+}
+
+// rangeBuffer is an io.ReadSeeker on a byte range of a file that has been read into memory.
+// All offsets are absolute offsets in the file.
+type rangeBuffer struct {
+	offset int64
+	data   []byte
+	pos    int64
+}
+
+func (b *rangeBuffer) Read(p []byte) (int, error) {
+	if b.pos < b.offset {
+		return 0, fmt.Errorf("offset %d is before the buffered range starting at %d", b.pos, b.offset)
+	}
+
+	i := b.pos - b.offset
+	if i >= int64(len(b.data)) {
+		return 0, io.EOF
+	}
+
+	n := copy(p, b.data[i:])
+	b.pos += int64(n)
+	return n, nil
+}
+
+func (b *rangeBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.pos
+	case io.SeekEnd:
+		offset += b.offset + int64(len(b.data))
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	b.pos = offset
+	return offset, nil
+}
+
+// streamingReadSize is the number of bytes that are read at once from the column chunks of row
+// groups that are read page by page, see WithStreamingThreshold.
+const streamingReadSize = 64 << 10
+
+// bufferedRangeReader is an io.ReadSeeker on the byte range of a column chunk that reads the
+// range in blocks of up to size bytes, so that page headers and small pages don't need a read
+// each. Reads of at least size bytes bypass the buffer. All offsets are absolute offsets in the file.
+type bufferedRangeReader struct {
+	ra    io.ReaderAt
+	start int64
+	end   int64
+	size  int
+
+	buf       []byte
+	bufOffset int64
+	pos       int64
+}
+
+func newBufferedRangeReader(ra io.ReaderAt, chunk *parquet.ColumnChunk, size int) (*bufferedRangeReader, error) {
+	r, err := chunkByteRange(chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bufferedRangeReader{ra: ra, start: r.offset, end: r.end(), size: size, pos: r.offset}, nil
+}
+
+func (b *bufferedRangeReader) Read(p []byte) (int, error) {
+	if b.pos < b.start {
+		return 0, fmt.Errorf("offset %d is before the column chunk starting at %d", b.pos, b.start)
+	}
+	if b.pos >= b.end {
+		return 0, io.EOF
+	}
+	if rem := b.end - b.pos; int64(len(p)) > rem {
+		p = p[:rem]
+	}
+
+	if b.pos < b.bufOffset || b.pos >= b.bufOffset+int64(len(b.buf)) {
+		if len(p) >= b.size {
+			n, err := b.ra.ReadAt(p, b.pos)
+			b.pos += int64(n)
+			if n == len(p) {
+				err = nil
+			}
+			return n, err
+		}
+
+		if err := b.fill(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, b.buf[b.pos-b.bufOffset:])
+	b.pos += int64(n)
+	return n, nil
+}
+
+// fill reads the next block of the column chunk, starting at the current position.
+func (b *bufferedRangeReader) fill() error {
+	n := int64(b.size)
+	if rem := b.end - b.pos; rem < n {
+		n = rem
+	}
+	if b.buf == nil {
+		b.buf = make([]byte, b.size)
+	}
+
+	m, err := b.ra.ReadAt(b.buf[:n], b.pos)
+	if m == 0 {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	b.buf, b.bufOffset = b.buf[:m], b.pos
+	return nil
+}
+
+func (b *bufferedRangeReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.pos
+	case io.SeekEnd:
+		offset += b.end
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	b.pos = offset
+	return offset, nil
+}
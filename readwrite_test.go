@@ -132,6 +132,43 @@ func TestWriteThenReadFile(t *testing.T) {
 			},
 			ReadOpts: []FileReaderOption{WithCRC32Validation(true)},
 		},
+		{
+			Name: "datapagev1_zstd",
+			WriteOpts: []FileWriterOption{
+				WithCompressionCodec(parquet.CompressionCodec_ZSTD),
+			},
+			ReadOpts: []FileReaderOption{},
+		},
+		{
+			Name: "datapagev2_zstd",
+			WriteOpts: []FileWriterOption{
+				WithCompressionCodec(parquet.CompressionCodec_ZSTD),
+				WithDataPageV2(),
+			},
+			ReadOpts: []FileReaderOption{},
+		},
+		{
+			Name: "datapagev1_brotli",
+			WriteOpts: []FileWriterOption{
+				WithCompressionCodec(parquet.CompressionCodec_BROTLI),
+			},
+			ReadOpts: []FileReaderOption{},
+		},
+		{
+			Name: "datapagev1_lz4raw",
+			WriteOpts: []FileWriterOption{
+				WithCompressionCodec(parquet.CompressionCodec_LZ4_RAW),
+			},
+			ReadOpts: []FileReaderOption{},
+		},
+		{
+			Name: "datapagev2_lz4",
+			WriteOpts: []FileWriterOption{
+				WithCompressionCodec(parquet.CompressionCodec_LZ4),
+				WithDataPageV2(),
+			},
+			ReadOpts: []FileReaderOption{},
+		},
 	}
 
 	for _, tt := range tests {
@@ -992,6 +1029,7 @@ func TestReadWriteFixedLenByteArrayEncodings(t *testing.T) {
 		{name: "delta_byte_array_with_dict", enc: parquet.Encoding_DELTA_BYTE_ARRAY, useDict: true, input: []byte{1, 3, 2, 14, 99, 42}},
 		{name: "delta_byte_array_no_dict", enc: parquet.Encoding_DELTA_BYTE_ARRAY, useDict: false, input: []byte{7, 5, 254, 127, 42, 23}},
 		{name: "plain_no_dict", enc: parquet.Encoding_PLAIN, useDict: false, input: []byte{9, 8, 7, 6, 5, 4}},
+		{name: "byte_stream_split_no_dict", enc: parquet.Encoding_BYTE_STREAM_SPLIT, useDict: false, input: []byte{1, 2, 3, 4, 5, 6}},
 	}
 
 	for _, tt := range testData {
@@ -1223,6 +1261,8 @@ func TestReadWriteFloatEncodings(t *testing.T) {
 	}{
 		{name: "plain_no_dict", enc: parquet.Encoding_PLAIN, useDict: false, input: 1.1111},
 		{name: "plain_with_dict", enc: parquet.Encoding_PLAIN, useDict: true, input: 2.2222},
+		{name: "byte_stream_split_no_dict", enc: parquet.Encoding_BYTE_STREAM_SPLIT, useDict: false, input: 3.3333},
+		{name: "byte_stream_split_with_dict", enc: parquet.Encoding_BYTE_STREAM_SPLIT, useDict: true, input: 4.4444},
 	}
 
 	for _, tt := range testData {
@@ -1270,6 +1310,8 @@ func TestReadWriteDoubleEncodings(t *testing.T) {
 	}{
 		{name: "plain_no_dict", enc: parquet.Encoding_PLAIN, useDict: false, input: 42.123456},
 		{name: "plain_with_dict", enc: parquet.Encoding_PLAIN, useDict: true, input: 32.98765},
+		{name: "byte_stream_split_no_dict", enc: parquet.Encoding_BYTE_STREAM_SPLIT, useDict: false, input: 12.3456789},
+		{name: "byte_stream_split_with_dict", enc: parquet.Encoding_BYTE_STREAM_SPLIT, useDict: true, input: 98.7654321},
 	}
 
 	for _, tt := range testData {
@@ -1487,3 +1529,203 @@ func TestWriteThenReadSetSchemaDefinition(t *testing.T) {
 	_, err = r.NextRow()
 	require.True(t, errors.Is(err, io.EOF))
 }
+
+func TestWriteThenReadWithColumnEncoding(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message msg {
+		required int64 ts (TIMESTAMP(MILLIS, true));
+		required binary category (STRING);
+		required binary id (STRING);
+		required binary label (STRING);
+		required int32 flags;
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	wr := NewFileWriter(&buf,
+		WithSchemaDefinition(sd),
+		WithColumnEncoding(ColumnPath{"ts"}, parquet.Encoding_DELTA_BINARY_PACKED, false),
+		WithColumnEncoding(ColumnPath{"category"}, parquet.Encoding_RLE_DICTIONARY, false),
+		WithColumnEncoding(ColumnPath{"flags"}, parquet.Encoding_DELTA_BINARY_PACKED, true),
+	)
+
+	const numRecords = 1000
+
+	for i := 0; i < numRecords; i++ {
+		require.NoError(t, wr.AddData(map[string]interface{}{
+			"ts":       int64(1600000000000 + i),
+			"category": []byte(fmt.Sprintf("category-%d", i%5)),
+			"id":       []byte(fmt.Sprintf("id-%d", i)),
+			"label":    []byte(fmt.Sprintf("label-%d", i%3)),
+			"flags":    int32(i),
+		}))
+	}
+
+	require.NoError(t, wr.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	require.NoError(t, r.PreLoad())
+
+	expectedEncodings := map[string][]parquet.Encoding{
+		"ts":       {parquet.Encoding_RLE, parquet.Encoding_DELTA_BINARY_PACKED},
+		"category": {parquet.Encoding_RLE, parquet.Encoding_PLAIN, parquet.Encoding_RLE_DICTIONARY},
+		"id":       {parquet.Encoding_RLE, parquet.Encoding_PLAIN},
+		"label":    {parquet.Encoding_RLE, parquet.Encoding_PLAIN, parquet.Encoding_RLE_DICTIONARY},
+		"flags":    {parquet.Encoding_RLE, parquet.Encoding_PLAIN, parquet.Encoding_RLE_DICTIONARY},
+	}
+
+	for _, col := range r.CurrentRowGroup().Columns {
+		name := ColumnPath(col.MetaData.PathInSchema).flatName()
+		assert.Equal(t, expectedEncodings[name], col.MetaData.Encodings, "column %s", name)
+	}
+
+	for i := 0; i < numRecords; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"ts":       int64(1600000000000 + i),
+			"category": []byte(fmt.Sprintf("category-%d", i%5)),
+			"id":       []byte(fmt.Sprintf("id-%d", i)),
+			"label":    []byte(fmt.Sprintf("label-%d", i%3)),
+			"flags":    int32(i),
+		}, row)
+	}
+
+	_, err = r.NextRow()
+	require.True(t, errors.Is(err, io.EOF))
+}
+
+func TestWriteThenReadWithColumnCompressionCodec(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message msg {
+		required int64 id;
+		required binary data (STRING);
+		optional group attrs {
+			required binary value (STRING);
+		}
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	wr := NewFileWriter(&buf,
+		WithSchemaDefinition(sd),
+		WithCompressionCodec(parquet.CompressionCodec_SNAPPY),
+		WithColumnCompressionCodec(ColumnPath{"data"}, parquet.CompressionCodec_ZSTD),
+		WithColumnCompressionCodec(ColumnPath{"attrs", "value"}, parquet.CompressionCodec_UNCOMPRESSED),
+	)
+
+	const numRecords = 100
+
+	for i := 0; i < numRecords; i++ {
+		require.NoError(t, wr.AddData(map[string]interface{}{
+			"id":    int64(i),
+			"data":  []byte(fmt.Sprintf("data-%d", i)),
+			"attrs": map[string]interface{}{"value": []byte(fmt.Sprintf("value-%d", i))},
+		}))
+	}
+
+	require.NoError(t, wr.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	require.NoError(t, r.PreLoad())
+
+	expectedCodecs := map[string]parquet.CompressionCodec{
+		"id":          parquet.CompressionCodec_SNAPPY,
+		"data":        parquet.CompressionCodec_ZSTD,
+		"attrs.value": parquet.CompressionCodec_UNCOMPRESSED,
+	}
+
+	for _, col := range r.CurrentRowGroup().Columns {
+		name := ColumnPath(col.MetaData.PathInSchema).flatName()
+		assert.Equal(t, expectedCodecs[name], col.MetaData.Codec, "column %s", name)
+	}
+
+	for i := 0; i < numRecords; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"id":    int64(i),
+			"data":  []byte(fmt.Sprintf("data-%d", i)),
+			"attrs": map[string]interface{}{"value": []byte(fmt.Sprintf("value-%d", i))},
+		}, row)
+	}
+}
+
+func TestSetSchemaDefinitionWithUnsupportedColumnEncoding(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message msg { required double value; }`)
+	require.NoError(t, err)
+
+	wr := NewFileWriter(&bytes.Buffer{}, WithColumnEncoding(ColumnPath{"value"}, parquet.Encoding_DELTA_BINARY_PACKED, false))
+
+	require.Error(t, wr.SetSchemaDefinition(sd))
+}
+
+func TestNewFileWriterWithUnsupportedColumnEncoding(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message msg { required double value; }`)
+	require.NoError(t, err)
+
+	tests := map[string]parquet.Encoding{
+		"unsupported for type": parquet.Encoding_DELTA_BINARY_PACKED,
+		"unsupported encoding": parquet.Encoding_BIT_PACKED,
+	}
+
+	for name, enc := range tests {
+		t.Run(name, func(t *testing.T) {
+			wr := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithColumnEncoding(ColumnPath{"value"}, enc, false))
+
+			require.Error(t, wr.AddData(map[string]interface{}{"value": 1.5}))
+			require.Error(t, wr.WriteColumnBatch(ColumnPath{"value"}, []float64{1.5}, nil, nil))
+			require.Error(t, wr.Close())
+		})
+	}
+}
+
+func TestWriteThenReadByteStreamSplit(t *testing.T) {
+	for _, v2 := range []bool{false, true} {
+		t.Run(fmt.Sprintf("v2=%t", v2), func(t *testing.T) {
+			var buf bytes.Buffer
+
+			opts := []FileWriterOption{WithMaxPageSize(512)}
+			if v2 {
+				opts = append(opts, WithDataPageV2())
+			}
+
+			wr := NewFileWriter(&buf, opts...)
+
+			store, err := NewDoubleStore(parquet.Encoding_BYTE_STREAM_SPLIT, false, &ColumnParameters{})
+			require.NoError(t, err)
+			require.NoError(t, wr.AddColumnByPath(ColumnPath{"value"}, NewDataColumn(store, parquet.FieldRepetitionType_OPTIONAL)))
+
+			const numRecords = 1000
+
+			for i := 0; i < numRecords; i++ {
+				data := map[string]interface{}{}
+				if i%7 != 0 {
+					data["value"] = float64(i) * 0.25
+				}
+				require.NoError(t, wr.AddData(data))
+			}
+
+			require.NoError(t, wr.Close())
+
+			r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+
+			for i := 0; i < numRecords; i++ {
+				row, err := r.NextRow()
+				require.NoError(t, err)
+				if i%7 != 0 {
+					require.Equal(t, map[string]interface{}{"value": float64(i) * 0.25}, row)
+				} else {
+					require.Equal(t, map[string]interface{}{}, row)
+				}
+			}
+
+			require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_BYTE_STREAM_SPLIT}, r.CurrentRowGroup().Columns[0].MetaData.Encodings)
+		})
+	}
+}
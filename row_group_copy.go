@@ -0,0 +1,173 @@
+package goparquet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// AppendRowGroupFrom copies the row group with the index rowGroup from reader to the file without
+// decoding and re-encoding its data. The compressed column chunks are copied verbatim, and only their
+// offsets are rewritten. The page index and bloom filters of the column chunks are copied as well.
+// The schema of reader needs to match the schema of the writer exactly; if no schema has been set
+// in the writer yet, the schema of reader is used. Data that was added using AddData or
+// WriteColumnBatch needs to be flushed before a row group can be appended, and row groups of
+// encrypted files can't be copied.
+func (fw *FileWriter) AppendRowGroupFrom(reader *FileReader, rowGroup int) error {
+	return fw.AppendRowGroupFromWithContext(fw.ctx, reader, rowGroup)
+}
+
+// AppendRowGroupFromWithContext copies the row group with the index rowGroup from reader to the file
+// without decoding and re-encoding its data. See AppendRowGroupFrom for details.
+func (fw *FileWriter) AppendRowGroupFromWithContext(ctx context.Context, reader *FileReader, rowGroup int) error {
+	if fw.err != nil {
+		return fw.err
+	}
+	if fw.schemaWriter.rowGroupNumRecords() > 0 || fw.sorter.numRows() > 0 || fw.schemaWriter.columnBatches {
+		return errors.New("the current row group needs to be flushed before appending a row group")
+	}
+	if fw.encryption != nil {
+		return errors.New("row groups can't be appended to encrypted files")
+	}
+	if fw.globalSort != nil {
+		return errors.New("row groups can't be appended when sorting globally")
+	}
+	if rowGroup < 0 || rowGroup >= len(reader.meta.RowGroups) {
+		return fmt.Errorf("row group %d out of range", rowGroup)
+	}
+
+	if err := fw.checkSchema(reader); err != nil {
+		return err
+	}
+
+	if err := fw.writeMagic(); err != nil {
+		return err
+	}
+
+	rg := reader.meta.RowGroups[rowGroup]
+
+	var (
+		columns             = make([]*parquet.ColumnChunk, 0, len(rg.Columns))
+		indexes             = make([]*chunkIndexes, 0, len(rg.Columns))
+		totalCompressedSize int64
+	)
+	for _, chunk := range rg.Columns {
+		ch, idx, err := fw.copyColumnChunk(ctx, reader, chunk)
+		if err != nil {
+			return err
+		}
+		columns = append(columns, ch)
+		indexes = append(indexes, idx)
+		totalCompressedSize += ch.MetaData.TotalCompressedSize
+	}
+
+	fw.rowGroups = append(fw.rowGroups, &parquet.RowGroup{
+		Columns:             columns,
+		TotalByteSize:       rg.TotalByteSize,
+		TotalCompressedSize: &totalCompressedSize,
+		NumRows:             rg.NumRows,
+		SortingColumns:      rg.SortingColumns,
+	})
+	fw.chunkIndexes = append(fw.chunkIndexes, indexes)
+	fw.totalNumRecords += rg.NumRows
+
+	return nil
+}
+
+// checkSchema checks that the schema of the reader matches the schema of the writer. If the
+// writer doesn't have a schema yet, the schema of the reader is used.
+func (fw *FileWriter) checkSchema(reader *FileReader) error {
+	if fw.schemaWriter.root == nil || len(fw.schemaWriter.root.children) == 0 {
+		if err := fw.schemaWriter.SetSchemaDefinition(reader.schemaReader.GetSchemaDefinition()); err != nil {
+			return fmt.Errorf("setting schema failed: %w", err)
+		}
+	}
+
+	want, got := reader.schemaReader.getSchemaArray(), fw.schemaWriter.getSchemaArray()
+	if len(want) != len(got) {
+		return fmt.Errorf("schema mismatch: reader has %d schema elements, writer has %d", len(want), len(got))
+	}
+
+	for i := range want {
+		w, g := *want[i], *got[i]
+		if i == 0 {
+			// the name of the root element doesn't matter.
+			w.Name, g.Name = "", ""
+		}
+		if !w.Equals(&g) {
+			return fmt.Errorf("schema mismatch: schema element %q of reader doesn't match schema element %q of writer", want[i].Name, got[i].Name)
+		}
+	}
+
+	return nil
+}
+
+// copyColumnChunk copies the pages of a column chunk from reader to the file, and returns the
+// column chunk with the rewritten offsets and its page index and bloom filter.
+func (fw *FileWriter) copyColumnChunk(ctx context.Context, reader *FileReader, chunk *parquet.ColumnChunk) (*parquet.ColumnChunk, *chunkIndexes, error) {
+	if chunk.CryptoMetadata != nil || chunk.EncryptedColumnMetadata != nil {
+		return nil, nil, errors.New("encrypted column chunks can't be copied")
+	}
+	if chunk.FilePath != nil {
+		return nil, nil, fmt.Errorf("nyi: data is in another file: '%s'", *chunk.FilePath)
+	}
+
+	r, err := chunkByteRange(chunk)
+	if err != nil {
+		return nil, nil, err
+	}
+	path := ColumnPath(chunk.MetaData.PathInSchema).flatName()
+
+	if _, err := reader.reader.Seek(r.offset, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+
+	pos := fw.w.Pos()
+	if _, err := io.CopyN(fw.w, reader.reader, r.length); err != nil {
+		return nil, nil, fmt.Errorf("copying column chunk %q failed: %w", path, err)
+	}
+	delta := pos - r.offset
+
+	meta := *chunk.MetaData
+	meta.DataPageOffset += delta
+	meta.DictionaryPageOffset = shiftOffset(meta.DictionaryPageOffset, delta)
+	meta.IndexPageOffset = shiftOffset(meta.IndexPageOffset, delta)
+	// the bloom filter is written again when the file is closed.
+	meta.BloomFilterOffset = nil
+
+	ch := &parquet.ColumnChunk{
+		FileOffset: pos,
+		MetaData:   &meta,
+	}
+
+	idx := &chunkIndexes{}
+	if fw.writePageIndex {
+		if idx.columnIndex, err = readColumnIndex(ctx, reader.reader, chunk, nil); err != nil {
+			return nil, nil, fmt.Errorf("column %q: %w", path, err)
+		}
+		if idx.offsetIndex, err = readOffsetIndex(ctx, reader.reader, chunk, nil); err != nil {
+			return nil, nil, fmt.Errorf("column %q: %w", path, err)
+		}
+		if idx.offsetIndex != nil {
+			for _, loc := range idx.offsetIndex.PageLocations {
+				loc.Offset += delta
+			}
+		}
+	}
+	if idx.bloomFilter, err = readBloomFilter(ctx, reader.reader, chunk, nil); err != nil {
+		return nil, nil, fmt.Errorf("column %q: %w", path, err)
+	}
+
+	return ch, idx, nil
+}
+
+func shiftOffset(offset *int64, delta int64) *int64 {
+	if offset == nil {
+		return nil
+	}
+	shifted := *offset + delta
+	return &shifted
+}
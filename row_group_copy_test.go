@@ -0,0 +1,92 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendRowGroupFrom(t *testing.T) {
+	files := [][]byte{
+		writeEncryptionTestFile(t, WithBloomFilter(ColumnPath{"id"}, 0, 0), WithCRC(true)),
+		writeEncryptionTestFile(t, WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithDataPageV2()),
+	}
+
+	var buf bytes.Buffer
+	wr := NewFileWriter(&buf)
+	for _, data := range files {
+		r, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		for rg := 0; rg < r.RowGroupCount(); rg++ {
+			require.NoError(t, wr.AppendRowGroupFrom(r, rg))
+		}
+	}
+	require.NoError(t, wr.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithCRC32Validation(true))
+	require.NoError(t, err)
+	require.Equal(t, 4, r.RowGroupCount())
+	require.Equal(t, int64(2000), r.NumRows())
+	require.Equal(t, parquet.CompressionCodec_UNCOMPRESSED, r.meta.RowGroups[0].Columns[0].MetaData.Codec)
+	require.Equal(t, parquet.CompressionCodec_SNAPPY, r.meta.RowGroups[2].Columns[0].MetaData.Codec)
+
+	for i := int64(0); i < 2000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, i%1000, row["id"])
+		require.Equal(t, []int32{int32(i % 1000), int32(i % 1000 % 7)}, row["values"])
+	}
+
+	for _, n := range []int64{300, 1300, 1999} {
+		require.NoError(t, r.SeekToRow(n))
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, n%1000, row["id"])
+	}
+
+	ok, err := r.MightContainInRowGroup(1, ColumnPath{"id"}, int64(742))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	colIdx, err := r.ReadColumnIndex(3, ColumnPath{"id"})
+	require.NoError(t, err)
+	require.NotNil(t, colIdx)
+}
+
+func TestAppendRowGroupFromErrors(t *testing.T) {
+	data := writeEncryptionTestFile(t)
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		repeated int64 values;
+	}`)
+	require.NoError(t, err)
+
+	wr := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+	require.Error(t, wr.AppendRowGroupFrom(r, 0), "schema mismatch")
+
+	wr = NewFileWriter(&bytes.Buffer{})
+	require.Error(t, wr.AppendRowGroupFrom(r, 2), "row group out of range")
+
+	require.NoError(t, wr.AppendRowGroupFrom(r, 0))
+	require.NoError(t, wr.AddData(map[string]interface{}{"id": int64(1)}))
+	require.Error(t, wr.AppendRowGroupFrom(r, 1), "unflushed data")
+	require.NoError(t, wr.FlushRowGroup())
+	require.NoError(t, wr.AppendRowGroupFrom(r, 1))
+	require.NoError(t, wr.Close())
+
+	wr = NewFileWriter(&bytes.Buffer{}, WithEncryption(testFooterKey))
+	require.Error(t, wr.AppendRowGroupFrom(r, 0), "encrypted writer")
+
+	encrypted := writeEncryptionTestFile(t, WithEncryption(testFooterKey, WithFooterKeyMetadata([]byte("footer"))))
+	r, err = NewFileReaderWithOptions(bytes.NewReader(encrypted), WithDecryption(testKeys))
+	require.NoError(t, err)
+	wr = NewFileWriter(&bytes.Buffer{})
+	require.Error(t, wr.AppendRowGroupFrom(r, 0), "encrypted reader")
+}
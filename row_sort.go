@@ -0,0 +1,517 @@
+package goparquet
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// SortingColumn describes a column that the rows of a file are sorted by.
+type SortingColumn struct {
+	// Path of the column.
+	Path ColumnPath
+	// Descending sorts the values in descending order instead of ascending order.
+	Descending bool
+	// NullsFirst sorts null values before all other values instead of after them.
+	NullsFirst bool
+}
+
+// WithSortingColumns sorts the rows of every row group by the provided columns before the row group
+// is written, and records the sort order in the meta data of the row group. Rows are compared by the
+// first column, then by the second column if they are equal, and so on. Values are compared
+// according to the sort order of their logical type, and NaN values are sorted after all other
+// values. The sorting columns need to be primitive columns that are neither repeated nor part of a
+// repeated group, and their type needs to have a defined sort order, otherwise adding data fails.
+//
+// The rows of the current row group are kept in memory until it is flushed, and they are only
+// encoded when the row group is sorted. The size of the row group is estimated from the size of the
+// values of the rows, and errors in rows that don't affect the sorting columns are returned when
+// the row group is flushed instead of by AddData. Column batches can't be written if sorting
+// columns are configured.
+func WithSortingColumns(cols ...SortingColumn) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.sortingColumns = cols
+	}
+}
+
+// WithGlobalSort sorts all rows of the file by the columns configured using WithSortingColumns,
+// instead of only sorting the rows within every row group. Rows are kept in memory until their
+// encoded size reaches bufferSize, then they are sorted and spilled to a temporary file in dir. If
+// dir is empty, the default directory for temporary files is used, and if bufferSize is 0 or less,
+// all rows are kept in memory. When the file is closed, the temporary files are merged, the rows
+// are written in row groups of the size configured using WithMaxRowGroupSize, or in a single row
+// group if it isn't set, and the temporary files are removed.
+//
+// Row groups can't be flushed explicitly or appended from other files when sorting globally.
+func WithGlobalSort(dir string, bufferSize int64) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.globalSort = &globalSort{dir: dir, bufferSize: bufferSize}
+	}
+}
+
+// globalSort contains the state of a file writer that sorts all rows of the file.
+type globalSort struct {
+	dir        string
+	bufferSize int64
+
+	// temporary files that contain sorted runs of rows.
+	runs []string
+}
+
+func (g *globalSort) removeRuns() {
+	for _, run := range g.runs {
+		_ = os.Remove(run)
+	}
+	g.runs = nil
+}
+
+type sortColumn struct {
+	SortingColumn
+	typ     parquet.Type
+	compare func(a, b []byte) int
+	// index of the column in the list of data columns of the schema.
+	idx int32
+}
+
+type sortedRow struct {
+	row  map[string]interface{}
+	keys [][]byte
+}
+
+// rowSorter sorts rows by their sorting columns.
+type rowSorter struct {
+	cols []sortColumn
+	rows []sortedRow
+	// estimated size of the rows, see estimateValueSize.
+	size int64
+}
+
+func newRowSorter(sch *schema, cols []SortingColumn) (*rowSorter, error) {
+	if len(cols) == 0 {
+		return nil, errors.New("sorting requires at least one sorting column")
+	}
+
+	dataCols := sch.Columns()
+
+	s := &rowSorter{}
+	for _, c := range cols {
+		idx := -1
+		for i := range dataCols {
+			if dataCols[i].Path().Equal(c.Path) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("sorting column %q is not a data column", c.Path.flatName())
+		}
+
+		col := dataCols[idx]
+		if col.MaxRepetitionLevel() > 0 {
+			return nil, fmt.Errorf("sorting column %q is repeated", c.Path.flatName())
+		}
+
+		compare := statsComparator(col.Element())
+		if compare == nil {
+			return nil, fmt.Errorf("sorting column %q has no defined sort order", c.Path.flatName())
+		}
+
+		s.cols = append(s.cols, sortColumn{SortingColumn: c, typ: *col.Type(), compare: compare, idx: int32(idx)})
+	}
+
+	return s, nil
+}
+
+// keys returns the PLAIN encoded values of the sorting columns of row. The keys of null values are nil.
+func (s *rowSorter) keys(row map[string]interface{}) ([][]byte, error) {
+	keys := make([][]byte, len(s.cols))
+	for i, c := range s.cols {
+		values := rowValues(row, c.Path)
+		if len(values) == 0 {
+			continue
+		}
+
+		b, err := plainValueBytes(c.typ, values[0])
+		if err != nil {
+			return nil, fmt.Errorf("sorting column %q: %w", c.Path.flatName(), err)
+		}
+		keys[i] = b
+	}
+	return keys, nil
+}
+
+func (s *rowSorter) add(row map[string]interface{}, keys [][]byte) {
+	s.rows = append(s.rows, sortedRow{row: row, keys: keys})
+	s.size += estimateValueSize(row)
+}
+
+func (s *rowSorter) reset() {
+	s.rows = nil
+	s.size = 0
+}
+
+// numRows returns the number of rows that haven't been sorted yet.
+func (s *rowSorter) numRows() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.rows)
+}
+
+// dataSize returns the estimated size of the rows that haven't been sorted yet.
+func (s *rowSorter) dataSize() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.size
+}
+
+// estimateValueSize estimates the size of a value of a row without encoding it. Values of types
+// that can't be stored in a column don't add to the size.
+func estimateValueSize(v interface{}) int64 {
+	switch typed := v.(type) {
+	case bool:
+		return 1
+	case int32, uint32, float32:
+		return 4
+	case int64, uint64, float64:
+		return 8
+	case [12]byte:
+		return 12
+	case []byte:
+		return int64(len(typed))
+	case string:
+		return int64(len(typed))
+	case []bool:
+		return int64(len(typed))
+	case []int32:
+		return 4 * int64(len(typed))
+	case []float32:
+		return 4 * int64(len(typed))
+	case []int64:
+		return 8 * int64(len(typed))
+	case []float64:
+		return 8 * int64(len(typed))
+	case [][12]byte:
+		return 12 * int64(len(typed))
+	case [][]byte:
+		var size int64
+		for _, b := range typed {
+			size += int64(len(b))
+		}
+		return size
+	case map[string]interface{}:
+		var size int64
+		for _, v := range typed {
+			size += estimateValueSize(v)
+		}
+		return size
+	case []map[string]interface{}:
+		var size int64
+		for _, m := range typed {
+			size += estimateValueSize(m)
+		}
+		return size
+	case []interface{}:
+		var size int64
+		for _, v := range typed {
+			size += estimateValueSize(v)
+		}
+		return size
+	}
+	return 0
+}
+
+func (s *rowSorter) sort() {
+	sort.SliceStable(s.rows, func(i, j int) bool {
+		return s.compareKeys(s.rows[i].keys, s.rows[j].keys) < 0
+	})
+}
+
+func (s *rowSorter) compareKeys(a, b [][]byte) int {
+	for i, c := range s.cols {
+		if r := c.compareValues(a[i], b[i]); r != 0 {
+			return r
+		}
+	}
+	return 0
+}
+
+func (c *sortColumn) compareValues(a, b []byte) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		if c.NullsFirst {
+			return -1
+		}
+		return 1
+	case b == nil:
+		if c.NullsFirst {
+			return 1
+		}
+		return -1
+	}
+
+	// NaN values are sorted after all other values, independent of the sort direction.
+	switch aNaN, bNaN := isNaNValue(c.typ, a), isNaNValue(c.typ, b); {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return 1
+	case bNaN:
+		return -1
+	}
+
+	if c.Descending {
+		return -c.compare(a, b)
+	}
+	return c.compare(a, b)
+}
+
+func (s *rowSorter) sortingColumns() []*parquet.SortingColumn {
+	ret := make([]*parquet.SortingColumn, 0, len(s.cols))
+	for _, c := range s.cols {
+		ret = append(ret, &parquet.SortingColumn{
+			ColumnIdx:  c.idx,
+			Descending: c.Descending,
+			NullsFirst: c.NullsFirst,
+		})
+	}
+	return ret
+}
+
+// addSortedData adds a row to the rows that are sorted before they are written.
+func (fw *FileWriter) addSortedData(m map[string]interface{}) error {
+	if fw.sorter == nil {
+		sorter, err := newRowSorter(fw.schemaWriter, fw.sortingColumns)
+		if err != nil {
+			return err
+		}
+		fw.sorter = sorter
+	}
+
+	keys, err := fw.sorter.keys(m)
+	if err != nil {
+		return err
+	}
+	fw.sorter.add(m, keys)
+
+	if fw.globalSort != nil {
+		if fw.globalSort.bufferSize > 0 && fw.sorter.dataSize() >= fw.globalSort.bufferSize {
+			return fw.spillSortedRun(fw.ctx)
+		}
+		return nil
+	}
+
+	if fw.rowGroupFlushSize > 0 && fw.sorter.dataSize() >= fw.rowGroupFlushSize {
+		return fw.FlushRowGroup()
+	}
+
+	return nil
+}
+
+// sortRowGroup sorts the rows of the current row group and adds them to the row group. If a row
+// can't be added, the rows of the row group are discarded.
+func (fw *FileWriter) sortRowGroup() error {
+	fw.sorter.sort()
+	rows := fw.sorter.rows
+	fw.sorter.reset()
+
+	for _, r := range rows {
+		if err := fw.schemaWriter.AddData(r.row); err != nil {
+			fw.schemaWriter.resetData()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (fw *FileWriter) sortSchemaDefinition() *parquetschema.SchemaDefinition {
+	if sd := fw.schemaWriter.GetSchemaDefinition(); sd != nil {
+		return sd
+	}
+	return parquetschema.SchemaDefinitionFromColumnDefinition(createColumnDefinitionFromColumn(fw.schemaWriter.root))
+}
+
+// spillSortedRun sorts the buffered rows and writes them to a temporary file.
+func (fw *FileWriter) spillSortedRun(ctx context.Context) (err error) {
+	fw.sorter.sort()
+
+	f, err := ioutil.TempFile(fw.globalSort.dir, "parquet-go-sort-")
+	if err != nil {
+		return err
+	}
+	fw.globalSort.runs = append(fw.globalSort.runs, f.Name())
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	w := NewFileWriter(f, WithSchemaDefinition(fw.sortSchemaDefinition()), WithPageIndex(false), WithWriterContext(ctx))
+	for _, r := range fw.sorter.rows {
+		if err := w.AddData(r.row); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("writing sorted rows to temporary file failed: %w", err)
+	}
+
+	fw.sorter.reset()
+
+	return nil
+}
+
+// writeGloballySorted merges the sorted runs and the buffered rows, and writes them to the file.
+func (fw *FileWriter) writeGloballySorted(ctx context.Context, opts []FlushRowGroupOption) error {
+	defer fw.globalSort.removeRuns()
+
+	switch {
+	case len(fw.globalSort.runs) > 0:
+		if fw.sorter.numRows() > 0 {
+			if err := fw.spillSortedRun(ctx); err != nil {
+				return err
+			}
+		}
+		if err := fw.mergeSortedRuns(ctx); err != nil {
+			return err
+		}
+	case fw.sorter != nil:
+		// all rows fit into memory.
+		fw.sorter.sort()
+		rows := fw.sorter.rows
+		fw.sorter.reset()
+
+		for _, r := range rows {
+			if err := fw.addMergedRow(ctx, r.row); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(fw.rowGroups) == 0 || fw.schemaWriter.rowGroupNumRecords() > 0 {
+		return fw.flushRowGroup(ctx, opts...)
+	}
+
+	return nil
+}
+
+func (fw *FileWriter) addMergedRow(ctx context.Context, row map[string]interface{}) error {
+	if err := fw.schemaWriter.AddData(row); err != nil {
+		return err
+	}
+
+	if fw.rowGroupFlushSize > 0 && fw.schemaWriter.DataSize() >= fw.rowGroupFlushSize {
+		return fw.flushRowGroup(ctx)
+	}
+
+	return nil
+}
+
+// sortedRun is the next row of a temporary file that contains sorted rows.
+type sortedRun struct {
+	sortedRow
+	reader *FileReader
+	idx    int
+}
+
+type sortedRunHeap struct {
+	runs   []*sortedRun
+	sorter *rowSorter
+}
+
+func (h *sortedRunHeap) Len() int { return len(h.runs) }
+
+func (h *sortedRunHeap) Less(i, j int) bool {
+	if c := h.sorter.compareKeys(h.runs[i].keys, h.runs[j].keys); c != 0 {
+		return c < 0
+	}
+	// rows from earlier runs were added first.
+	return h.runs[i].idx < h.runs[j].idx
+}
+
+func (h *sortedRunHeap) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+
+func (h *sortedRunHeap) Push(x interface{}) { h.runs = append(h.runs, x.(*sortedRun)) }
+
+func (h *sortedRunHeap) Pop() interface{} {
+	run := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return run
+}
+
+// next reads the next row of run, and returns false if there are no more rows.
+func (h *sortedRunHeap) next(ctx context.Context, run *sortedRun) (bool, error) {
+	row, err := run.reader.NextRowWithContext(ctx)
+	if err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	keys, err := h.sorter.keys(row)
+	if err != nil {
+		return false, err
+	}
+	run.sortedRow = sortedRow{row: row, keys: keys}
+
+	return true, nil
+}
+
+// mergeSortedRuns merges the rows of all temporary files and adds them to the file.
+func (fw *FileWriter) mergeSortedRuns(ctx context.Context) error {
+	h := &sortedRunHeap{sorter: fw.sorter}
+
+	for idx, name := range fw.globalSort.runs {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r, err := NewFileReaderWithOptions(f, WithReaderContext(ctx))
+		if err != nil {
+			return fmt.Errorf("reading temporary file failed: %w", err)
+		}
+
+		run := &sortedRun{reader: r, idx: idx}
+		ok, err := h.next(ctx, run)
+		if err != nil {
+			return err
+		}
+		if ok {
+			h.runs = append(h.runs, run)
+		}
+	}
+
+	heap.Init(h)
+	for h.Len() > 0 {
+		run := h.runs[0]
+		if err := fw.addMergedRow(ctx, run.row); err != nil {
+			return err
+		}
+
+		ok, err := h.next(ctx, run)
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/stretchr/testify/require"
+)
+
+const sortTestSchema = `message test {
+	required int64 id;
+	optional binary name (STRING);
+	optional double score;
+	required int32 count (INT(32, false));
+	repeated int32 values;
+}`
+
+func sortTestRow(i int) map[string]interface{} {
+	// a permutation of 0..999 so that rows aren't added in order.
+	id := int64(i*337) % 1000
+	row := map[string]interface{}{
+		"id":     id,
+		"count":  int32(uint32(math.MaxUint32) - uint32(id%10)),
+		"values": []int32{int32(id), int32(i)},
+	}
+	if id%5 != 0 {
+		row["name"] = []byte(fmt.Sprintf("name-%03d", id%100))
+	}
+	if id%7 != 0 {
+		row["score"] = float64(id % 13)
+	} else if id%14 == 0 {
+		row["score"] = math.NaN()
+	}
+	return row
+}
+
+func writeSortTestFile(t *testing.T, opts ...FileWriterOption) *FileReader {
+	data := writeTestFile(t, sortTestSchema, 2, 500, func(rg, i int) map[string]interface{} {
+		return sortTestRow(rg*500 + i)
+	}, opts...)
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	return r
+}
+
+// requireSorted checks that the rows are sorted by name (ascending, nulls first) and score
+// (descending, nulls last, NaN last).
+func requireSorted(t *testing.T, rows []map[string]interface{}) {
+	for i := 1; i < len(rows); i++ {
+		a, b := rows[i-1], rows[i]
+
+		an, aok := a["name"].([]byte)
+		bn, bok := b["name"].([]byte)
+		if aok != bok {
+			require.False(t, aok, "null names need to be first: %v, %v", a, b)
+			continue
+		}
+		if c := bytes.Compare(an, bn); c != 0 {
+			require.Negative(t, c, "names need to be ascending: %v, %v", a, b)
+			continue
+		}
+
+		as, aok := a["score"].(float64)
+		bs, bok := b["score"].(float64)
+		switch {
+		case !bok:
+		case !aok:
+			require.Fail(t, "null scores need to be last", "%v, %v", a, b)
+		case math.IsNaN(bs):
+		case math.IsNaN(as):
+			require.Fail(t, "NaN scores need to be after other scores", "%v, %v", a, b)
+		default:
+			require.GreaterOrEqual(t, as, bs, "scores need to be descending: %v, %v", a, b)
+		}
+	}
+}
+
+func TestSortingColumns(t *testing.T) {
+	cols := []SortingColumn{
+		{Path: ColumnPath{"name"}, NullsFirst: true},
+		{Path: ColumnPath{"score"}, Descending: true},
+	}
+	r := writeSortTestFile(t, WithSortingColumns(cols...))
+	require.Equal(t, 2, r.RowGroupCount())
+
+	for _, rg := range r.meta.RowGroups {
+		require.Equal(t, []*parquet.SortingColumn{
+			{ColumnIdx: 1, NullsFirst: true},
+			{ColumnIdx: 2, Descending: true},
+		}, rg.SortingColumns)
+	}
+
+	rows := readAllRows(t, r)
+	requireSorted(t, rows[:500])
+	requireSorted(t, rows[500:])
+
+	ids := map[int64]bool{}
+	for _, row := range rows {
+		id := row["id"].(int64)
+		require.Equal(t, []int32{int32(id), row["values"].([]int32)[1]}, row["values"])
+		ids[id] = true
+	}
+	require.Len(t, ids, 1000)
+}
+
+func TestSortingColumnsUnsigned(t *testing.T) {
+	r := writeSortTestFile(t, WithSortingColumns(SortingColumn{Path: ColumnPath{"count"}}, SortingColumn{Path: ColumnPath{"id"}}))
+
+	rows := readAllRows(t, r)
+	for i := 1; i < 500; i++ {
+		a, b := uint32(rows[i-1]["count"].(int32)), uint32(rows[i]["count"].(int32))
+		require.LessOrEqual(t, a, b)
+		if a == b {
+			require.Less(t, rows[i-1]["id"], rows[i]["id"])
+		}
+	}
+}
+
+// TestSortingColumnsBuffering checks that rows are only encoded when their row group is flushed.
+func TestSortingColumnsBuffering(t *testing.T) {
+	var buf bytes.Buffer
+	wr := newTestFileWriter(t, &buf, sortTestSchema, WithSortingColumns(SortingColumn{Path: ColumnPath{"id"}}), WithMaxRowGroupSize(4096))
+	for i := 0; i < 100; i++ {
+		require.NoError(t, wr.AddData(sortTestRow(i)))
+	}
+	require.Zero(t, wr.schemaWriter.rowGroupNumRecords())
+	require.Equal(t, wr.sorter.dataSize(), wr.CurrentRowGroupSize())
+	require.Greater(t, wr.CurrentRowGroupSize(), int64(100*20))
+
+	for i := 100; i < 1000; i++ {
+		require.NoError(t, wr.AddData(sortTestRow(i)))
+	}
+	require.NoError(t, wr.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Greater(t, r.RowGroupCount(), 1)
+	require.Equal(t, int64(1000), r.NumRows())
+}
+
+func TestGlobalSort(t *testing.T) {
+	cols := []SortingColumn{
+		{Path: ColumnPath{"name"}, NullsFirst: true},
+		{Path: ColumnPath{"score"}, Descending: true},
+	}
+
+	for _, bufferSize := range []int64{0, 2048} {
+		t.Run(fmt.Sprintf("buffer size %d", bufferSize), func(t *testing.T) {
+			dir := t.TempDir()
+			r := writeSortTestFile(t, WithSortingColumns(cols...), WithGlobalSort(dir, bufferSize), WithMaxRowGroupSize(4096))
+			require.Greater(t, r.RowGroupCount(), 1)
+			require.NotNil(t, r.meta.RowGroups[0].SortingColumns)
+
+			rows := readAllRows(t, r)
+			require.Len(t, rows, 1000)
+			requireSorted(t, rows)
+
+			files, err := ioutil.ReadDir(dir)
+			require.NoError(t, err)
+			require.Empty(t, files, "temporary files need to be removed")
+		})
+	}
+}
+
+func TestSortingColumnsErrors(t *testing.T) {
+	for _, col := range []ColumnPath{{"missing"}, {"values"}} {
+		wr := newTestFileWriter(t, &bytes.Buffer{}, sortTestSchema, WithSortingColumns(SortingColumn{Path: col}))
+		require.Error(t, wr.AddData(sortTestRow(1)), "column %s", col.flatName())
+	}
+
+	wr := newTestFileWriter(t, &bytes.Buffer{}, sortTestSchema, WithGlobalSort("", 0))
+	require.Error(t, wr.AddData(sortTestRow(1)), "no sorting columns")
+
+	wr = newTestFileWriter(t, &bytes.Buffer{}, sortTestSchema, WithSortingColumns(SortingColumn{Path: ColumnPath{"id"}}))
+	require.Error(t, wr.AddData(map[string]interface{}{"id": 1}), "invalid type")
+	require.Error(t, wr.WriteColumnBatch(ColumnPath{"id"}, []int64{1}, nil, nil))
+
+	wr = newTestFileWriter(t, &bytes.Buffer{}, sortTestSchema, WithSortingColumns(SortingColumn{Path: ColumnPath{"id"}}))
+	require.NoError(t, wr.AddData(map[string]interface{}{"id": int64(1), "count": "invalid"}))
+	require.Error(t, wr.FlushRowGroup(), "invalid type of a column that isn't sorted")
+
+	wr = newTestFileWriter(t, &bytes.Buffer{}, sortTestSchema, WithSortingColumns(SortingColumn{Path: ColumnPath{"id"}}), WithGlobalSort("", 0))
+	require.NoError(t, wr.AddData(sortTestRow(1)))
+	require.Error(t, wr.FlushRowGroup())
+	require.NoError(t, wr.Close())
+}
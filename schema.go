@@ -316,9 +316,93 @@ type schema struct {
 
 	// selected columns in reading. if the size is zero, it means all the columns
 	selectedColumns []ColumnPath
+	// noneSelected is true if no column is read, as opposed to an empty selectedColumns.
+	noneSelected bool
 
 	enableCRC   bool // if true, CRC32 checksums will be computed for pages upon writing.
 	validateCRC bool // if true, CRC32 checksums will be validated for pages upon reading.
+
+	// encodings configured for particular columns when creating the columns from a schema definition.
+	columnEncodings []columnEncoding
+
+	// compression codecs configured for particular columns.
+	columnCodecs []columnCodec
+
+	// columns for which bloom filters are written.
+	bloomFilters []bloomFilterConfig
+
+	// if true, the data of the current row group was added using column batches instead of AddData.
+	columnBatches bool
+}
+
+type columnEncoding struct {
+	path    ColumnPath
+	enc     parquet.Encoding
+	useDict bool
+}
+
+func (r *schema) setColumnEncoding(path ColumnPath, enc parquet.Encoding, useDict bool) {
+	for i := range r.columnEncodings {
+		if r.columnEncodings[i].path.Equal(path) {
+			r.columnEncodings[i].enc = enc
+			r.columnEncodings[i].useDict = useDict
+			return
+		}
+	}
+	r.columnEncodings = append(r.columnEncodings, columnEncoding{path: path, enc: enc, useDict: useDict})
+}
+
+func (r *schema) getColumnEncoding(path ColumnPath) (columnEncoding, bool) {
+	for _, ce := range r.columnEncodings {
+		if ce.path.Equal(path) {
+			return ce, true
+		}
+	}
+	return columnEncoding{}, false
+}
+
+type columnCodec struct {
+	path  ColumnPath
+	codec parquet.CompressionCodec
+}
+
+func (r *schema) setColumnCodec(path ColumnPath, codec parquet.CompressionCodec) {
+	for i := range r.columnCodecs {
+		if r.columnCodecs[i].path.Equal(path) {
+			r.columnCodecs[i].codec = codec
+			return
+		}
+	}
+	r.columnCodecs = append(r.columnCodecs, columnCodec{path: path, codec: codec})
+}
+
+func (r *schema) getColumnCodec(path ColumnPath) (parquet.CompressionCodec, bool) {
+	for _, cc := range r.columnCodecs {
+		if cc.path.Equal(path) {
+			return cc.codec, true
+		}
+	}
+	return 0, false
+}
+
+func (r *schema) setBloomFilter(path ColumnPath, ndv int64, fpp float64) {
+	for i := range r.bloomFilters {
+		if r.bloomFilters[i].path.Equal(path) {
+			r.bloomFilters[i].ndv = ndv
+			r.bloomFilters[i].fpp = fpp
+			return
+		}
+	}
+	r.bloomFilters = append(r.bloomFilters, bloomFilterConfig{path: path, ndv: ndv, fpp: fpp})
+}
+
+func (r *schema) getBloomFilter(path ColumnPath) (bloomFilterConfig, bool) {
+	for _, bf := range r.bloomFilters {
+		if bf.path.Equal(path) {
+			return bf, true
+		}
+	}
+	return bloomFilterConfig{}, false
 }
 
 func (r *schema) ensureRoot() {
@@ -338,9 +422,19 @@ func (r *schema) ensureRoot() {
 
 func (r *schema) SetSelectedColumns(cols ...ColumnPath) {
 	r.selectedColumns = cols
+	r.noneSelected = false
+}
+
+// selectNoColumns deselects all columns, so that no column chunks are read.
+func (r *schema) selectNoColumns() {
+	r.selectedColumns = nil
+	r.noneSelected = true
 }
 
 func (r *schema) isSelectedByPath(path ColumnPath) bool {
+	if r.noneSelected {
+		return false
+	}
 	if len(r.selectedColumns) == 0 {
 		return true
 	}
@@ -424,6 +518,7 @@ func (r *schema) resetData() {
 	}
 
 	r.numRecords = 0
+	r.columnBatches = false
 }
 
 func (r *schema) setNumRecords(n int64) {
@@ -456,7 +551,7 @@ func (r *schema) sortIndex() {
 func (r *schema) SetSchemaDefinition(sd *parquetschema.SchemaDefinition) error {
 	r.schemaDef = sd
 
-	root, err := r.createColumnFromColumnDefinition(r.schemaDef.RootColumn)
+	root, err := r.createColumnFromColumnDefinition(r.schemaDef.RootColumn, ColumnPath{})
 	if err != nil {
 		return err
 	}
@@ -470,7 +565,7 @@ func (r *schema) SetSchemaDefinition(sd *parquetschema.SchemaDefinition) error {
 	return nil
 }
 
-func (r *schema) createColumnFromColumnDefinition(root *parquetschema.ColumnDefinition) (*Column, error) {
+func (r *schema) createColumnFromColumnDefinition(root *parquetschema.ColumnDefinition, path ColumnPath) (*Column, error) {
 	params := &ColumnParameters{
 		LogicalType:   root.SchemaElement.LogicalType,
 		ConvertedType: root.SchemaElement.ConvertedType,
@@ -488,14 +583,15 @@ func (r *schema) createColumnFromColumnDefinition(root *parquetschema.ColumnDefi
 
 	if len(root.Children) > 0 {
 		for _, c := range root.Children {
-			childColumn, err := r.createColumnFromColumnDefinition(c)
+			childPath := append(append(ColumnPath{}, path...), c.SchemaElement.GetName())
+			childColumn, err := r.createColumnFromColumnDefinition(c, childPath)
 			if err != nil {
 				return nil, err
 			}
 			col.children = append(col.children, childColumn)
 		}
 	} else {
-		dataColumn, err := r.getColumnStore(root.SchemaElement, params)
+		dataColumn, err := r.getColumnStore(root.SchemaElement, params, path)
 		if err != nil {
 			return nil, err
 		}
@@ -507,7 +603,9 @@ func (r *schema) createColumnFromColumnDefinition(root *parquetschema.ColumnDefi
 	return col, nil
 }
 
-func (r *schema) getColumnStore(elem *parquet.SchemaElement, params *ColumnParameters) (*ColumnStore, error) {
+// getColumnStore creates the column store for a data column. If no encoding was configured for the column,
+// PLAIN encoding is used and a dictionary is used whenever it reduces the size of the column chunk.
+func (r *schema) getColumnStore(elem *parquet.SchemaElement, params *ColumnParameters, path ColumnPath) (*ColumnStore, error) {
 	if elem.Type == nil {
 		return nil, nil
 	}
@@ -517,33 +615,43 @@ func (r *schema) getColumnStore(elem *parquet.SchemaElement, params *ColumnParam
 		err      error
 	)
 
+	enc, useDict := parquet.Encoding_PLAIN, true
+	ce, configured := r.getColumnEncoding(path)
+	if configured {
+		enc, useDict = ce.enc, ce.useDict
+	}
+
 	typ := elem.GetType()
 
 	switch typ {
 	case parquet.Type_BYTE_ARRAY:
-		colStore, err = NewByteArrayStore(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewByteArrayStore(enc, useDict, params)
 	case parquet.Type_FLOAT:
-		colStore, err = NewFloatStore(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewFloatStore(enc, useDict, params)
 	case parquet.Type_DOUBLE:
-		colStore, err = NewDoubleStore(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewDoubleStore(enc, useDict, params)
 	case parquet.Type_BOOLEAN:
-		colStore, err = NewBooleanStore(parquet.Encoding_PLAIN, params)
+		if configured && useDict {
+			return nil, fmt.Errorf("column %q: dictionary encoding is not supported for type %q", path.flatName(), typ.String())
+		}
+		colStore, err = NewBooleanStore(enc, params)
 	case parquet.Type_INT32:
-		colStore, err = NewInt32Store(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewInt32Store(enc, useDict, params)
 	case parquet.Type_INT64:
-		colStore, err = NewInt64Store(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewInt64Store(enc, useDict, params)
 	case parquet.Type_INT96:
-		colStore, err = NewInt96Store(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewInt96Store(enc, useDict, params)
 	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
-		colStore, err = NewFixedByteArrayStore(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewFixedByteArrayStore(enc, useDict, params)
 	default:
 		return nil, fmt.Errorf("unsupported type %q when creating Column store", typ.String())
 	}
 	if err != nil {
-		return nil, fmt.Errorf("creating Column store for type %q failed: %v", typ.String(), err)
+		return nil, fmt.Errorf("creating Column store for column %q of type %q failed: %v", path.flatName(), typ.String(), err)
 	}
 
 	colStore.maxPageSize = r.maxPageSize
+	colStore.autoDict = !configured
 
 	return colStore, nil
 }
@@ -752,6 +860,9 @@ func (r *schema) findDataColumn(path string) (*Column, error) {
 }
 
 func (r *schema) AddData(m map[string]interface{}) error {
+	if r.columnBatches {
+		return errors.New("can't add data to a row group that was written using column batches")
+	}
 	r.readOnly = 1
 	r.ensureRoot()
 	err := r.recursiveAddColumnData(r.root.children, m, 0, 0, 0)
@@ -759,12 +870,11 @@ func (r *schema) AddData(m map[string]interface{}) error {
 		return err
 	}
 
-	if err := r.recursiveFlushPages(r.root.children); err != nil {
-		return err
-	}
-
+	// the record is counted before flushing pages, so that a page flushed now includes it in its
+	// number of rows and the first row indexes in the offset index are correct.
 	r.numRecords++
-	return nil
+
+	return r.recursiveFlushPages(r.root.children)
 }
 
 func (r *schema) getData() (map[string]interface{}, error) {
@@ -779,6 +889,27 @@ func (r *schema) getData() (map[string]interface{}, error) {
 	return d.(map[string]interface{}), nil
 }
 
+// peekRowValues returns the values of the next row of the data column path that are not null,
+// without reading the row. It returns false if they can't be determined from the current page of
+// the column.
+func (r *schema) peekRowValues(path ColumnPath) ([]interface{}, bool, error) {
+	col := r.GetColumnByPath(path)
+	if col == nil || col.data == nil {
+		return nil, false, nil
+	}
+	return col.data.peekRow(int32(col.MaxDefinitionLevel()))
+}
+
+// skipRow skips the next row in all data columns.
+func (r *schema) skipRow() error {
+	for _, col := range r.Columns() {
+		if err := col.data.skipRow(int32(col.MaxDefinitionLevel())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *schema) recursiveAddColumnNil(c []*Column, defLvl, maxRepLvl uint16, repLvl uint16) error {
 	for i := range c {
 		if c[i].data != nil {
@@ -801,7 +932,7 @@ func (r *schema) recursiveAddColumnNil(c []*Column, defLvl, maxRepLvl uint16, re
 func (r *schema) recursiveFlushPages(c []*Column) error {
 	for i := range c {
 		if c[i].data != nil {
-			if err := c[i].data.flushPage(r, false); err != nil {
+			if err := c[i].data.flushPage(r.numRecords, false); err != nil {
 				return err
 			}
 		}
@@ -0,0 +1,153 @@
+package goparquet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// schemaProjection converts rows that were read using the schema of a file to rows of a
+// reader schema.
+type schemaProjection struct {
+	fields []*fieldProjection
+	// columns of the file that are required to create the rows of the reader schema.
+	columns []ColumnPath
+}
+
+// fieldProjection describes how a field of the reader schema is created from the data of the file.
+type fieldProjection struct {
+	// name of the field in the reader schema.
+	name string
+	// name of the field in the file schema, or empty if the file doesn't contain the field.
+	source   string
+	repeated bool
+	// children of the field if it is a group.
+	children []*fieldProjection
+	// promote converts the values of the file to the type of the reader schema, or is nil if
+	// the types are the same.
+	promote func(interface{}) interface{}
+}
+
+// newSchemaProjection creates the projection of the file schema to the reader schema. It returns an
+// error if the reader schema can't be used to read the file.
+func newSchemaProjection(file, reader *parquetschema.SchemaDefinition) (*schemaProjection, error) {
+	if reader == nil || reader.RootColumn == nil {
+		return nil, errors.New("reader schema is empty")
+	}
+	if err := reader.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid reader schema: %w", err)
+	}
+
+	if err := parquetschema.CheckCompatibility(reader, file, parquetschema.BackwardCompatible); err != nil {
+		return nil, err
+	}
+
+	p := &schemaProjection{}
+	p.fields = p.projectColumns(nil, file.RootColumn.Children, reader.RootColumn.Children)
+
+	return p, nil
+}
+
+func (p *schemaProjection) projectColumns(path ColumnPath, fileCols, readerCols []*parquetschema.ColumnDefinition) []*fieldProjection {
+	fields := make([]*fieldProjection, 0, len(readerCols))
+	for _, rc := range readerCols {
+		re := rc.SchemaElement
+
+		field := &fieldProjection{
+			name:     re.Name,
+			repeated: re.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED,
+		}
+		fields = append(fields, field)
+
+		fc := parquetschema.FindMatchingColumn(fileCols, re)
+		if fc == nil {
+			continue
+		}
+		fe := fc.SchemaElement
+		field.source = fe.Name
+		filePath := append(path[:len(path):len(path)], fe.Name)
+
+		if re.Type == nil {
+			field.children = p.projectColumns(filePath, fc.Children, rc.Children)
+			continue
+		}
+
+		switch {
+		case fe.GetType() == parquet.Type_INT32 && re.GetType() == parquet.Type_INT64:
+			field.promote = promoteInt32
+		case fe.GetType() == parquet.Type_FLOAT && re.GetType() == parquet.Type_DOUBLE:
+			field.promote = promoteFloat
+		}
+		p.columns = append(p.columns, filePath)
+	}
+
+	return fields
+}
+
+func promoteInt32(v interface{}) interface{} {
+	switch x := v.(type) {
+	case int32:
+		return int64(x)
+	case []int32:
+		ret := make([]int64, len(x))
+		for i := range x {
+			ret[i] = int64(x[i])
+		}
+		return ret
+	}
+	return v
+}
+
+func promoteFloat(v interface{}) interface{} {
+	switch x := v.(type) {
+	case float32:
+		return float64(x)
+	case []float32:
+		ret := make([]float64, len(x))
+		for i := range x {
+			ret[i] = float64(x[i])
+		}
+		return ret
+	}
+	return v
+}
+
+// convert converts a row that was read using the file schema to a row of the reader schema.
+func (p *schemaProjection) convert(row map[string]interface{}) map[string]interface{} {
+	return convertFields(p.fields, row)
+}
+
+func convertFields(fields []*fieldProjection, data map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if f.source == "" {
+			continue
+		}
+		v, ok := data[f.source]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case f.children != nil && f.repeated:
+			if groups, ok := v.([]map[string]interface{}); ok {
+				converted := make([]map[string]interface{}, len(groups))
+				for i := range groups {
+					converted[i] = convertFields(f.children, groups[i])
+				}
+				v = converted
+			}
+		case f.children != nil:
+			if group, ok := v.(map[string]interface{}); ok {
+				v = convertFields(f.children, group)
+			}
+		case f.promote != nil:
+			v = f.promote(v)
+		}
+
+		ret[f.name] = v
+	}
+	return ret
+}
@@ -0,0 +1,189 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderSchema(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int32 id = 1;
+		optional float score;
+		required binary name (STRING);
+		optional group address {
+			required binary city (STRING);
+			optional binary zip (STRING);
+		}
+		repeated int32 values;
+		optional binary legacy (STRING);
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	wr := NewFileWriter(&buf, WithSchemaDefinition(sd))
+	for i := 0; i < 10; i++ {
+		row := map[string]interface{}{
+			"id":     int32(i),
+			"name":   []byte(fmt.Sprintf("name-%d", i)),
+			"values": []int32{int32(i), int32(i * 2)},
+			"legacy": []byte("legacy"),
+		}
+		if i%2 == 0 {
+			row["score"] = float32(i) / 2
+			row["address"] = map[string]interface{}{"city": []byte("Berlin"), "zip": []byte("10115")}
+		}
+		require.NoError(t, wr.AddData(row))
+	}
+	require.NoError(t, wr.Close())
+
+	readerSchema, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 ident = 1;
+		optional double score;
+		optional binary name (STRING);
+		optional group address {
+			required binary city (STRING);
+			optional binary country (STRING);
+		}
+		repeated int64 values;
+		optional int64 added;
+	}`)
+	require.NoError(t, err)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithReaderSchema(readerSchema))
+	require.NoError(t, err)
+	require.Equal(t, readerSchema, r.GetSchemaDefinition())
+
+	for i := 0; i < 10; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+
+		expected := map[string]interface{}{
+			"ident":  int64(i),
+			"name":   []byte(fmt.Sprintf("name-%d", i)),
+			"values": []int64{int64(i), int64(i * 2)},
+		}
+		if i%2 == 0 {
+			expected["score"] = float64(i) / 2
+			expected["address"] = map[string]interface{}{"city": []byte("Berlin")}
+		}
+		require.Equal(t, expected, row, "row %d", i)
+	}
+
+	rows, err := r.ReadRows(8, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), rows[0]["ident"])
+	require.NotContains(t, rows[1], "legacy")
+}
+
+func TestReaderSchemaMatching(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int32 a = 1;
+		required int32 b = 2;
+		required binary c;
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	wr := NewFileWriter(&buf, WithSchemaDefinition(sd))
+	require.NoError(t, wr.AddData(map[string]interface{}{"a": int32(1), "b": int32(2), "c": []byte("c")}))
+	require.NoError(t, wr.Close())
+
+	readerSchema, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int32 b = 1;
+		optional int32 a = 3;
+		required binary c = 4;
+	}`)
+	require.NoError(t, err)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithReaderSchema(readerSchema))
+	require.NoError(t, err)
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"b": int32(1), "c": []byte("c")}, row)
+}
+
+func TestReaderSchemaIncompatible(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		repeated int32 values;
+		optional group address {
+			required binary city (STRING);
+		}
+		required int32 day (DATE);
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	wr := NewFileWriter(&buf, WithSchemaDefinition(sd))
+	require.NoError(t, wr.AddData(map[string]interface{}{"id": int64(1), "day": int32(1)}))
+	require.NoError(t, wr.Close())
+
+	testData := map[string]string{
+		"missing required column": `message test { required int64 other; }`,
+		"optional as required":    `message test { required binary name (STRING); }`,
+		"repeated as optional":    `message test { optional int32 values; }`,
+		"int64 as int32":          `message test { required int32 id; }`,
+		"int64 as double":         `message test { required double id; }`,
+		"group as primitive":      `message test { optional binary address; }`,
+		"different logical type":  `message test { optional binary name (JSON); }`,
+		"date as int64":           `message test { required int64 day; }`,
+		"nested column":           `message test { optional group address { required binary city (STRING); required binary zip (STRING); } }`,
+	}
+
+	for name, schema := range testData {
+		t.Run(name, func(t *testing.T) {
+			readerSchema, err := parquetschema.ParseSchemaDefinition(schema)
+			require.NoError(t, err)
+
+			_, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithReaderSchema(readerSchema))
+			require.Error(t, err)
+		})
+	}
+
+	readerSchema, err := parquetschema.ParseSchemaDefinition(`message test { required int64 id; }`)
+	require.NoError(t, err)
+	_, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithReaderSchema(readerSchema), WithColumns("id"))
+	require.Error(t, err)
+}
+
+func TestReaderSchemaWithoutFileColumns(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	wr := NewFileWriter(&buf, WithSchemaDefinition(sd))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, wr.AddData(map[string]interface{}{"id": int64(i), "name": []byte("name")}))
+	}
+	require.NoError(t, wr.Close())
+
+	readerSchema, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional int64 other;
+	}`)
+	require.NoError(t, err)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithReaderSchema(readerSchema))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{}, row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	for _, col := range r.schemaReader.Columns() {
+		require.True(t, col.data.skipped, "column %q was read", col.FlatName())
+	}
+}
@@ -3,7 +3,11 @@ package goparquet
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"math"
+	"math/big"
+
+	"github.com/fraugster/parquet-go/parquet"
 )
 
 type nilStats struct{}
@@ -222,3 +226,209 @@ func (s *int64Stats) setMinMax(j int64) {
 		s.max = j
 	}
 }
+
+// statsComparator returns a function that compares two PLAIN encoded statistics values of the
+// column described by elem, according to the column's sort order. The function returns a negative
+// number if a is less than b, zero if they are equal, and a positive number if a is greater than b.
+// If the sort order of the column is undefined, nil is returned.
+func statsComparator(elem *parquet.SchemaElement) func(a, b []byte) int {
+	if elem == nil || elem.Type == nil {
+		return nil
+	}
+
+	switch *elem.Type {
+	case parquet.Type_BOOLEAN:
+		return compareBoolean
+	case parquet.Type_INT32:
+		if isUnsignedInt(elem) {
+			return compareUint32
+		}
+		return compareInt32
+	case parquet.Type_INT64:
+		if isUnsignedInt(elem) {
+			return compareUint64
+		}
+		return compareInt64
+	case parquet.Type_FLOAT:
+		return compareFloat
+	case parquet.Type_DOUBLE:
+		return compareDouble
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		if elem.ConvertedType != nil && *elem.ConvertedType == parquet.ConvertedType_INTERVAL {
+			return nil
+		}
+		if isDecimal(elem) {
+			return compareDecimal
+		}
+		return bytes.Compare
+	default:
+		return nil
+	}
+}
+
+func isUnsignedInt(elem *parquet.SchemaElement) bool {
+	if elem.LogicalType != nil && elem.LogicalType.INTEGER != nil {
+		return !elem.LogicalType.INTEGER.IsSigned
+	}
+
+	if elem.ConvertedType != nil {
+		switch *elem.ConvertedType {
+		case parquet.ConvertedType_UINT_8, parquet.ConvertedType_UINT_16, parquet.ConvertedType_UINT_32, parquet.ConvertedType_UINT_64:
+			return true
+		}
+	}
+
+	return false
+}
+
+func isDecimal(elem *parquet.SchemaElement) bool {
+	if elem.LogicalType != nil && elem.LogicalType.DECIMAL != nil {
+		return true
+	}
+	return elem.ConvertedType != nil && *elem.ConvertedType == parquet.ConvertedType_DECIMAL
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareBoolean(a, b []byte) int {
+	if len(a) < 1 || len(b) < 1 {
+		return 0
+	}
+	return compareOrdered(a[0] < b[0], a[0] > b[0])
+}
+
+func compareInt32(a, b []byte) int {
+	if len(a) < 4 || len(b) < 4 {
+		return 0
+	}
+	x, y := int32(binary.LittleEndian.Uint32(a)), int32(binary.LittleEndian.Uint32(b))
+	return compareOrdered(x < y, x > y)
+}
+
+func compareUint32(a, b []byte) int {
+	if len(a) < 4 || len(b) < 4 {
+		return 0
+	}
+	x, y := binary.LittleEndian.Uint32(a), binary.LittleEndian.Uint32(b)
+	return compareOrdered(x < y, x > y)
+}
+
+func compareInt64(a, b []byte) int {
+	if len(a) < 8 || len(b) < 8 {
+		return 0
+	}
+	x, y := int64(binary.LittleEndian.Uint64(a)), int64(binary.LittleEndian.Uint64(b))
+	return compareOrdered(x < y, x > y)
+}
+
+func compareUint64(a, b []byte) int {
+	if len(a) < 8 || len(b) < 8 {
+		return 0
+	}
+	x, y := binary.LittleEndian.Uint64(a), binary.LittleEndian.Uint64(b)
+	return compareOrdered(x < y, x > y)
+}
+
+func compareFloat(a, b []byte) int {
+	if len(a) < 4 || len(b) < 4 {
+		return 0
+	}
+	x, y := math.Float32frombits(binary.LittleEndian.Uint32(a)), math.Float32frombits(binary.LittleEndian.Uint32(b))
+	return compareOrdered(x < y, x > y)
+}
+
+func compareDouble(a, b []byte) int {
+	if len(a) < 8 || len(b) < 8 {
+		return 0
+	}
+	x, y := math.Float64frombits(binary.LittleEndian.Uint64(a)), math.Float64frombits(binary.LittleEndian.Uint64(b))
+	return compareOrdered(x < y, x > y)
+}
+
+// compareDecimal compares two decimals that are stored as big-endian two's complement integers.
+func compareDecimal(a, b []byte) int {
+	return decimalToBigInt(a).Cmp(decimalToBigInt(b))
+}
+
+func decimalToBigInt(data []byte) *big.Int {
+	v := new(big.Int).SetBytes(data)
+	if len(data) > 0 && data[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(8*len(data))))
+	}
+	return v
+}
+
+// plainValueBytes returns the PLAIN encoding of a single value of a column of type typ, as it is used
+// for statistics. Byte arrays are returned without length prefix. Byte arrays may also be provided
+// as strings.
+func plainValueBytes(typ parquet.Type, v interface{}) ([]byte, error) {
+	switch typ {
+	case parquet.Type_BOOLEAN:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type for boolean column: %T", v)
+		}
+		if b {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+	case parquet.Type_INT32:
+		i, ok := v.(int32)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type for int32 column: %T", v)
+		}
+		buf := make([]byte, 4)
+		binary.LittleEndian.PutUint32(buf, uint32(i))
+		return buf, nil
+	case parquet.Type_INT64:
+		i, ok := v.(int64)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type for int64 column: %T", v)
+		}
+		buf := make([]byte, 8)
+		binary.LittleEndian.PutUint64(buf, uint64(i))
+		return buf, nil
+	case parquet.Type_INT96:
+		i, ok := v.([12]byte)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type for int96 column: %T", v)
+		}
+		return i[:], nil
+	case parquet.Type_FLOAT:
+		f, ok := v.(float32)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type for float column: %T", v)
+		}
+		buf := make([]byte, 4)
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(f))
+		return buf, nil
+	case parquet.Type_DOUBLE:
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type for double column: %T", v)
+		}
+		buf := make([]byte, 8)
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(f))
+		return buf, nil
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		switch b := v.(type) {
+		case []byte:
+			return b, nil
+		case string:
+			return []byte(b), nil
+		default:
+			return nil, fmt.Errorf("unsupported type for %s column: %T", typ, v)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported column type %s", typ)
+	}
+}
@@ -15,18 +15,9 @@ type booleanPlainDecoder struct {
 // copy the left overs from the previous call. instead of returning an empty subset of the old slice,
 // it delete the slice (by returning nil) so there is no memory leak because of the underlying array
 // the return value is the new left over and the number of read message
-func copyLeftOvers(dst []interface{}, src []bool) ([]bool, int) {
-	size := len(dst)
-	var clean bool
-	if len(src) <= size {
-		size = len(src)
-		clean = true
-	}
-
-	for i := 0; i < size; i++ {
-		dst[i] = src[i]
-	}
-	if clean {
+func copyLeftOvers(dst []bool, src []bool) ([]bool, int) {
+	size := copy(dst, src)
+	if size == len(src) {
 		return nil, size
 	}
 
@@ -41,6 +32,16 @@ func (b *booleanPlainDecoder) init(r io.Reader) error {
 }
 
 func (b *booleanPlainDecoder) decodeValues(dst []interface{}) (int, error) {
+	values := make([]bool, len(dst))
+	n, err := b.decodeBooleans(values)
+	for i := 0; i < n; i++ {
+		dst[i] = values[i]
+	}
+
+	return n, err
+}
+
+func (b *booleanPlainDecoder) decodeBooleans(dst []bool) (int, error) {
 	var start int
 	if len(b.left) > 0 {
 		// there is a leftover from the last run
@@ -97,6 +98,18 @@ func (b *booleanPlainEncoder) encodeValues(values []interface{}) error {
 	return nil
 }
 
+func (b *booleanPlainEncoder) encodeBooleans(values []bool) error {
+	for _, v := range values {
+		var i int32
+		if v {
+			i = 1
+		}
+		b.data.appendSingle(i)
+	}
+
+	return nil
+}
+
 type booleanRLEDecoder struct {
 	decoder *hybridDecoder
 }
@@ -119,6 +132,18 @@ func (b *booleanRLEDecoder) decodeValues(dst []interface{}) (int, error) {
 	return total, nil
 }
 
+func (b *booleanRLEDecoder) decodeBooleans(dst []bool) (int, error) {
+	for i := range dst {
+		n, err := b.decoder.next()
+		if err != nil {
+			return i, err
+		}
+		dst[i] = n == 1
+	}
+
+	return len(dst), nil
+}
+
 type booleanRLEEncoder struct {
 	encoder *hybridEncoder
 }
@@ -145,6 +170,17 @@ func (b *booleanRLEEncoder) encodeValues(values []interface{}) error {
 	return b.encoder.encode(buf)
 }
 
+func (b *booleanRLEEncoder) encodeBooleans(values []bool) error {
+	buf := make([]int32, len(values))
+	for i, v := range values {
+		if v {
+			buf[i] = 1
+		}
+	}
+
+	return b.encoder.encode(buf)
+}
+
 type booleanStore struct {
 	repTyp parquet.FieldRepetitionType
 	*ColumnParameters
@@ -54,6 +54,16 @@ func (b *byteArrayPlainDecoder) decodeValues(dst []interface{}) (int, error) {
 	return len(dst), nil
 }
 
+func (b *byteArrayPlainDecoder) decodeByteArrays(dst [][]byte) (int, error) {
+	var err error
+	for i := range dst {
+		if dst[i], err = b.next(); err != nil {
+			return i, err
+		}
+	}
+	return len(dst), nil
+}
+
 type byteArrayPlainEncoder struct {
 	w io.Writer
 
@@ -91,6 +101,16 @@ func (b *byteArrayPlainEncoder) encodeValues(values []interface{}) error {
 	return nil
 }
 
+func (b *byteArrayPlainEncoder) encodeByteArrays(values [][]byte) error {
+	for _, v := range values {
+		if err := b.writeBytes(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (*byteArrayPlainEncoder) Close() error {
 	return nil
 }
@@ -139,11 +159,21 @@ func (b *byteArrayDeltaLengthDecoder) decodeValues(dst []interface{}) (int, erro
 	return total, nil
 }
 
+func (b *byteArrayDeltaLengthDecoder) decodeByteArrays(dst [][]byte) (int, error) {
+	var err error
+	for i := range dst {
+		if dst[i], err = b.next(); err != nil {
+			return i, err
+		}
+	}
+	return len(dst), nil
+}
+
 // this type is used inside the byteArrayDeltaEncoder, the Close method should do the actual write, not before.
 type byteArrayDeltaLengthEncoder struct {
 	w    io.Writer
 	buf  *bytes.Buffer
-	lens []interface{}
+	lens []int32
 }
 
 func (b *byteArrayDeltaLengthEncoder) init(w io.Writer) error {
@@ -160,7 +190,7 @@ func (b *byteArrayDeltaLengthEncoder) writeOne(data []byte) error {
 func (b *byteArrayDeltaLengthEncoder) encodeValues(values []interface{}) error {
 	if b.lens == nil {
 		// this is just for the first time, maybe we need to copy and increase the cap in the next calls?
-		b.lens = make([]interface{}, 0, len(values))
+		b.lens = make([]int32, 0, len(values))
 	}
 	for i := range values {
 		if err := b.writeOne(values[i].([]byte)); err != nil {
@@ -171,6 +201,19 @@ func (b *byteArrayDeltaLengthEncoder) encodeValues(values []interface{}) error {
 	return nil
 }
 
+func (b *byteArrayDeltaLengthEncoder) encodeByteArrays(values [][]byte) error {
+	if b.lens == nil {
+		b.lens = make([]int32, 0, len(values))
+	}
+	for _, v := range values {
+		if err := b.writeOne(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (b *byteArrayDeltaLengthEncoder) Close() error {
 	enc := &int32DeltaBPEncoder{
 		deltaBitPackEncoder32: deltaBitPackEncoder32{
@@ -179,7 +222,7 @@ func (b *byteArrayDeltaLengthEncoder) Close() error {
 		},
 	}
 
-	if err := encodeValue(b.w, enc, b.lens); err != nil {
+	if err := encodeInt32Values(b.w, enc, b.lens); err != nil {
 		return err
 	}
 
@@ -214,35 +257,54 @@ func (d *byteArrayDeltaDecoder) init(r io.Reader) error {
 	return nil
 }
 
+func (d *byteArrayDeltaDecoder) next() ([]byte, error) {
+	suffix, err := d.suffixDecoder.next()
+	if err != nil {
+		return nil, err
+	}
+	// after this line no error is acceptable
+	prefixLen := int(d.prefixLens[d.suffixDecoder.position-1])
+	value := make([]byte, 0, prefixLen+len(suffix))
+	if len(d.previousValue) < prefixLen {
+		// prevent panic from invalid input
+		return nil, fmt.Errorf("invalid prefix len in the stream, the value is %d byte but the it needs %d byte", len(d.previousValue), prefixLen)
+	}
+	if prefixLen > 0 {
+		value = append(value, d.previousValue[:prefixLen]...)
+	}
+	value = append(value, suffix...)
+	d.previousValue = value
+
+	return value, nil
+}
+
 func (d *byteArrayDeltaDecoder) decodeValues(dst []interface{}) (int, error) {
 	total := len(dst)
 	for i := 0; i < total; i++ {
-		suffix, err := d.suffixDecoder.next()
+		value, err := d.next()
 		if err != nil {
 			return i, err
 		}
-		// after this line no error is acceptable
-		prefixLen := int(d.prefixLens[d.suffixDecoder.position-1])
-		value := make([]byte, 0, prefixLen+len(suffix))
-		if len(d.previousValue) < prefixLen {
-			// prevent panic from invalid input
-			return 0, fmt.Errorf("invalid prefix len in the stream, the value is %d byte but the it needs %d byte", len(d.previousValue), prefixLen)
-		}
-		if prefixLen > 0 {
-			value = append(value, d.previousValue[:prefixLen]...)
-		}
-		value = append(value, suffix...)
-		d.previousValue = value
 		dst[i] = value
 	}
 
 	return total, nil
 }
 
+func (d *byteArrayDeltaDecoder) decodeByteArrays(dst [][]byte) (int, error) {
+	var err error
+	for i := range dst {
+		if dst[i], err = d.next(); err != nil {
+			return i, err
+		}
+	}
+	return len(dst), nil
+}
+
 type byteArrayDeltaEncoder struct {
 	w io.Writer
 
-	prefixLens    []interface{}
+	prefixLens    []int32
 	previousValue []byte
 
 	values *byteArrayDeltaLengthEncoder
@@ -258,23 +320,45 @@ func (b *byteArrayDeltaEncoder) init(w io.Writer) error {
 
 func (b *byteArrayDeltaEncoder) encodeValues(values []interface{}) error {
 	if b.prefixLens == nil {
-		b.prefixLens = make([]interface{}, 0, len(values))
-		b.values.lens = make([]interface{}, 0, len(values))
+		b.prefixLens = make([]int32, 0, len(values))
+		b.values.lens = make([]int32, 0, len(values))
 	}
 
 	for i := range values {
-		data := values[i].([]byte)
-		pLen := prefix(b.previousValue, data)
-		b.prefixLens = append(b.prefixLens, int32(pLen))
-		if err := b.values.writeOne(data[pLen:]); err != nil {
+		if err := b.encodeOne(values[i].([]byte)); err != nil {
 			return err
 		}
-		b.previousValue = data
 	}
 
 	return nil
 }
 
+func (b *byteArrayDeltaEncoder) encodeByteArrays(values [][]byte) error {
+	if b.prefixLens == nil {
+		b.prefixLens = make([]int32, 0, len(values))
+		b.values.lens = make([]int32, 0, len(values))
+	}
+
+	for _, v := range values {
+		if err := b.encodeOne(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *byteArrayDeltaEncoder) encodeOne(data []byte) error {
+	pLen := prefix(b.previousValue, data)
+	b.prefixLens = append(b.prefixLens, int32(pLen))
+	if err := b.values.writeOne(data[pLen:]); err != nil {
+		return err
+	}
+	b.previousValue = data
+
+	return nil
+}
+
 func (b *byteArrayDeltaEncoder) Close() error {
 	// write the lens first
 	enc := &int32DeltaBPEncoder{
@@ -284,7 +368,7 @@ func (b *byteArrayDeltaEncoder) Close() error {
 		},
 	}
 
-	if err := encodeValue(b.w, enc, b.prefixLens); err != nil {
+	if err := encodeInt32Values(b.w, enc, b.prefixLens); err != nil {
 		return err
 	}
 
@@ -368,6 +452,12 @@ func (is *byteArrayStore) getValues(v interface{}) ([]interface{}, error) {
 		return nil, fmt.Errorf("unsupported type for storing in []byte column %T => %+v", v, v)
 	}
 
+	for _, val := range vals {
+		if err := is.setMinMax(val.([]byte)); err != nil {
+			return nil, err
+		}
+	}
+
 	return vals, nil
 }
 
@@ -37,28 +37,80 @@ func (d *dictDecoder) init(r io.Reader) error {
 	return errors.New("bit width zero with non-empty dictionary")
 }
 
-func (d *dictDecoder) decodeValues(dst []interface{}) (int, error) {
+func (d *dictDecoder) next() (interface{}, error) {
 	if d.keys == nil {
-		return 0, errors.New("no value is inside dictionary")
+		return nil, errors.New("no value is inside dictionary")
+	}
+
+	key, err := d.keys.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if size := int32(len(d.uniqueValues)); key < 0 || key >= size {
+		return nil, fmt.Errorf("dict: invalid index %d, values count are %d", key, size)
 	}
-	size := int32(len(d.uniqueValues))
 
+	return d.uniqueValues[key], nil
+}
+
+func (d *dictDecoder) decodeValues(dst []interface{}) (int, error) {
+	var err error
 	for i := range dst {
-		key, err := d.keys.next()
-		if err != nil {
+		if dst[i], err = d.next(); err != nil {
 			return i, err
 		}
+	}
+
+	return len(dst), nil
+}
 
-		if key < 0 || key >= size {
-			return 0, fmt.Errorf("dict: invalid index %d, values count are %d", key, size)
+// decodeDictValues decodes values of type T using the dictionary decoder d. The values of the
+// dictionary are already stored in interface{} values, so they are only copied into dst.
+func decodeDictValues[T any](d *dictDecoder, dst []T) (int, error) {
+	for i := range dst {
+		v, err := d.next()
+		if err != nil {
+			return i, err
 		}
 
-		dst[i] = d.uniqueValues[key]
+		var ok bool
+		if dst[i], ok = v.(T); !ok {
+			return i, fmt.Errorf("dict: unexpected value of type %T", v)
+		}
 	}
 
 	return len(dst), nil
 }
 
+func (d *dictDecoder) decodeBooleans(dst []bool) (int, error) {
+	return decodeDictValues(d, dst)
+}
+
+func (d *dictDecoder) decodeInt32s(dst []int32) (int, error) {
+	return decodeDictValues(d, dst)
+}
+
+func (d *dictDecoder) decodeInt64s(dst []int64) (int, error) {
+	return decodeDictValues(d, dst)
+}
+
+func (d *dictDecoder) decodeInt96s(dst [][12]byte) (int, error) {
+	return decodeDictValues(d, dst)
+}
+
+func (d *dictDecoder) decodeFloats(dst []float32) (int, error) {
+	return decodeDictValues(d, dst)
+}
+
+func (d *dictDecoder) decodeDoubles(dst []float64) (int, error) {
+	return decodeDictValues(d, dst)
+}
+
+func (d *dictDecoder) decodeByteArrays(dst [][]byte) (int, error) {
+	return decodeDictValues(d, dst)
+}
+
 type dictStore struct {
 	valueList        []interface{}
 	uniqueValues     map[interface{}]struct{}
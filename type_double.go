@@ -31,6 +31,14 @@ func (d *doublePlainDecoder) decodeValues(dst []interface{}) (int, error) {
 	return len(dst), nil
 }
 
+func (d *doublePlainDecoder) decodeDoubles(dst []float64) (int, error) {
+	if err := binary.Read(d.r, binary.LittleEndian, dst); err != nil {
+		return 0, err
+	}
+
+	return len(dst), nil
+}
+
 type doublePlainEncoder struct {
 	w io.Writer
 }
@@ -54,6 +62,10 @@ func (d *doublePlainEncoder) encodeValues(values []interface{}) error {
 	return binary.Write(d.w, binary.LittleEndian, data)
 }
 
+func (d *doublePlainEncoder) encodeDoubles(values []float64) error {
+	return binary.Write(d.w, binary.LittleEndian, values)
+}
+
 type doubleStore struct {
 	repTyp parquet.FieldRepetitionType
 
@@ -31,6 +31,14 @@ func (f *floatPlainDecoder) decodeValues(dst []interface{}) (int, error) {
 	return len(dst), nil
 }
 
+func (f *floatPlainDecoder) decodeFloats(dst []float32) (int, error) {
+	if err := binary.Read(f.r, binary.LittleEndian, dst); err != nil {
+		return 0, err
+	}
+
+	return len(dst), nil
+}
+
 type floatPlainEncoder struct {
 	w io.Writer
 }
@@ -54,6 +62,10 @@ func (d *floatPlainEncoder) encodeValues(values []interface{}) error {
 	return binary.Write(d.w, binary.LittleEndian, data)
 }
 
+func (d *floatPlainEncoder) encodeFloats(values []float32) error {
+	return binary.Write(d.w, binary.LittleEndian, values)
+}
+
 type floatStore struct {
 	repTyp parquet.FieldRepetitionType
 
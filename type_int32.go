@@ -30,6 +30,14 @@ func (i *int32PlainDecoder) decodeValues(dst []interface{}) (int, error) {
 	return len(dst), nil
 }
 
+func (i *int32PlainDecoder) decodeInt32s(dst []int32) (int, error) {
+	if err := binary.Read(i.r, binary.LittleEndian, dst); err != nil {
+		return 0, err
+	}
+
+	return len(dst), nil
+}
+
 type int32PlainEncoder struct {
 	w io.Writer
 }
@@ -52,6 +60,10 @@ func (i *int32PlainEncoder) encodeValues(values []interface{}) error {
 	return binary.Write(i.w, binary.LittleEndian, d)
 }
 
+func (i *int32PlainEncoder) encodeInt32s(values []int32) error {
+	return binary.Write(i.w, binary.LittleEndian, values)
+}
+
 type int32DeltaBPDecoder struct {
 	deltaBitPackDecoder32
 }
@@ -68,6 +80,18 @@ func (d *int32DeltaBPDecoder) decodeValues(dst []interface{}) (int, error) {
 	return len(dst), nil
 }
 
+func (d *int32DeltaBPDecoder) decodeInt32s(dst []int32) (int, error) {
+	for i := range dst {
+		u, err := d.next()
+		if err != nil {
+			return i, err
+		}
+		dst[i] = u
+	}
+
+	return len(dst), nil
+}
+
 type int32DeltaBPEncoder struct {
 	deltaBitPackEncoder32
 }
@@ -82,6 +106,16 @@ func (d *int32DeltaBPEncoder) encodeValues(values []interface{}) error {
 	return nil
 }
 
+func (d *int32DeltaBPEncoder) encodeInt32s(values []int32) error {
+	for _, v := range values {
+		if err := d.addInt32(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type int32Store struct {
 	repTyp parquet.FieldRepetitionType
 
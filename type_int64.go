@@ -30,6 +30,14 @@ func (i *int64PlainDecoder) decodeValues(dst []interface{}) (int, error) {
 	return len(dst), nil
 }
 
+func (i *int64PlainDecoder) decodeInt64s(dst []int64) (int, error) {
+	if err := binary.Read(i.r, binary.LittleEndian, dst); err != nil {
+		return 0, err
+	}
+
+	return len(dst), nil
+}
+
 type int64PlainEncoder struct {
 	w io.Writer
 }
@@ -52,6 +60,10 @@ func (i *int64PlainEncoder) encodeValues(values []interface{}) error {
 	return binary.Write(i.w, binary.LittleEndian, d)
 }
 
+func (i *int64PlainEncoder) encodeInt64s(values []int64) error {
+	return binary.Write(i.w, binary.LittleEndian, values)
+}
+
 type int64DeltaBPDecoder struct {
 	deltaBitPackDecoder64
 }
@@ -68,6 +80,18 @@ func (d *int64DeltaBPDecoder) decodeValues(dst []interface{}) (int, error) {
 	return len(dst), nil
 }
 
+func (d *int64DeltaBPDecoder) decodeInt64s(dst []int64) (int, error) {
+	for i := range dst {
+		u, err := d.next()
+		if err != nil {
+			return i, err
+		}
+		dst[i] = u
+	}
+
+	return len(dst), nil
+}
+
 type int64DeltaBPEncoder struct {
 	deltaBitPackEncoder64
 }
@@ -82,6 +106,16 @@ func (d *int64DeltaBPEncoder) encodeValues(values []interface{}) error {
 	return nil
 }
 
+func (d *int64DeltaBPEncoder) encodeInt64s(values []int64) error {
+	for _, v := range values {
+		if err := d.addInt64(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type int64Store struct {
 	repTyp parquet.FieldRepetitionType
 
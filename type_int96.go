@@ -41,6 +41,18 @@ func (i *int96PlainDecoder) decodeValues(dst []interface{}) (int, error) {
 	return len(dst), nil
 }
 
+func (i *int96PlainDecoder) decodeInt96s(dst [][12]byte) (int, error) {
+	for idx := range dst {
+		if n, err := io.ReadFull(i.r, dst[idx][:]); err != nil {
+			if n == 0 {
+				return idx, err
+			}
+			return idx, fmt.Errorf("not enough byte to read Int96: %w", err)
+		}
+	}
+	return len(dst), nil
+}
+
 type int96PlainEncoder struct {
 	w io.Writer
 }
@@ -65,6 +77,15 @@ func (i *int96PlainEncoder) encodeValues(values []interface{}) error {
 	return writeFull(i.w, data)
 }
 
+func (i *int96PlainEncoder) encodeInt96s(values [][12]byte) error {
+	data := make([]byte, len(values)*12)
+	for j := range values {
+		copy(data[j*12:], values[j][:])
+	}
+
+	return writeFull(i.w, data)
+}
+
 type int96Store struct {
 	byteArrayStore
 }